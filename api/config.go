@@ -26,13 +26,18 @@ type Config struct {
 	Port               int    `config:"port"`
 	User               string `config:"named_pipe.user"`
 	SecurityDescriptor string `config:"named_pipe.security_descriptor"`
+	MetricsPrefix      string `config:"metrics.prefix"`
+	AuthToken          string `config:"auth.token"`
+	AuthExemptLocal    bool   `config:"auth.exempt_local"`
+	ShutdownEnabled    bool   `config:"shutdown.enabled"`
 }
 
 // DefaultConfig is the default configuration used by the API endpoint.
 var DefaultConfig = Config{
-	Enabled: false,
-	Host:    "localhost",
-	Port:    5066,
+	Enabled:       false,
+	Host:          "localhost",
+	Port:          5066,
+	MetricsPrefix: "beat",
 }
 
 // File mode for the socket file, owner of the process can do everything, member of the group can read.
@@ -0,0 +1,49 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// ProcessorInfo describes a processor available in the Beat.
+type ProcessorInfo struct {
+	Name string
+	Used bool
+}
+
+// AttachProcessorsHandler attaches a `GET /processors` endpoint reporting the
+// processors returned by list, and whether they are used by the configuration.
+func (s *Server) AttachProcessorsHandler(list func() []ProcessorInfo) error {
+	return s.mux.Handle("/processors", makeProcessorsHandler(list)).Methods(http.MethodGet).GetError()
+}
+
+func makeProcessorsHandler(list func() []ProcessorInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		infos := list()
+		procs := make([]mapstr.M, len(infos))
+		for i, info := range infos {
+			procs[i] = mapstr.M{"name": info.Name, "used": info.Used}
+		}
+		prettyPrint(w, mapstr.M{"processors": procs}, r.URL)
+	}
+}
@@ -0,0 +1,211 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/njcx/libbeat_v8/monitoring/report/gauge"
+)
+
+// Name suffixes of monotonically increasing metrics. All other numeric
+// metrics are exposed as gauges. Byte sizes known to be gauges, like the
+// queue fill level, are never counters.
+var prometheusCounterSuffixes = []string{
+	"total", "ticks", "acked", "failed", "dropped", "published", "filtered",
+	"duplicates", "toomany", "errors", "bytes", "batches", "retry",
+	"dropped_too_big", "sampled_out", "dead_letter",
+}
+
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+type prometheusSample struct {
+	labels string
+	value  float64
+}
+
+type prometheusMetric struct {
+	kind    string
+	samples []prometheusSample
+}
+
+// makePrometheusHandler renders the stats and dataset namespaces in the
+// Prometheus text exposition format. Metric names are built from the
+// namespace prefix and the registry path. Every registry in the dataset
+// namespace describes an input, its string values are exposed as labels.
+func makePrometheusHandler(prefix string, ns lookupFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		metrics := map[string]*prometheusMetric{}
+		if stats := ns("stats"); stats != nil {
+			snapshot := monitoring.CollectFlatSnapshot(stats.GetRegistry(), monitoring.Full, false)
+			addPrometheusSnapshot(metrics, prefix, "", snapshot)
+		}
+		if dataset := ns("dataset"); dataset != nil {
+			addPrometheusDatasets(metrics, prometheusName(prefix, "dataset"), dataset.GetRegistry())
+		}
+
+		writePrometheus(w, metrics)
+	}
+}
+
+func addPrometheusDatasets(metrics map[string]*prometheusMetric, prefix string, reg *monitoring.Registry) {
+	snapshot := monitoring.CollectFlatSnapshot(reg, monitoring.Full, false)
+
+	// group values by dataset registry
+	datasets := map[string]*monitoring.FlatSnapshot{}
+	dataset := func(name string) (*monitoring.FlatSnapshot, string) {
+		key, rest, ok := strings.Cut(name, ".")
+		if !ok {
+			return nil, ""
+		}
+		s, exists := datasets[key]
+		if !exists {
+			s = &monitoring.FlatSnapshot{
+				Bools:   map[string]bool{},
+				Ints:    map[string]int64{},
+				Floats:  map[string]float64{},
+				Strings: map[string]string{},
+			}
+			datasets[key] = s
+		}
+		return s, rest
+	}
+	for name, v := range snapshot.Bools {
+		if s, rest := dataset(name); s != nil {
+			s.Bools[rest] = v
+		}
+	}
+	for name, v := range snapshot.Ints {
+		if s, rest := dataset(name); s != nil {
+			s.Ints[rest] = v
+		}
+	}
+	for name, v := range snapshot.Floats {
+		if s, rest := dataset(name); s != nil {
+			s.Floats[rest] = v
+		}
+	}
+	for name, v := range snapshot.Strings {
+		if s, rest := dataset(name); s != nil {
+			s.Strings[rest] = v
+		}
+	}
+
+	for _, s := range datasets {
+		labels := make([]string, 0, len(s.Strings))
+		for k, v := range s.Strings {
+			labels = append(labels, prometheusName("", k)+`="`+prometheusLabelEscaper.Replace(v)+`"`)
+		}
+		sort.Strings(labels)
+		addPrometheusSnapshot(metrics, prefix, "{"+strings.Join(labels, ",")+"}", *s)
+	}
+}
+
+func addPrometheusSnapshot(metrics map[string]*prometheusMetric, prefix, labels string, snapshot monitoring.FlatSnapshot) {
+	if labels == "{}" {
+		labels = ""
+	}
+
+	add := func(name string, value float64) {
+		full := prometheusName(prefix, name)
+		m, ok := metrics[full]
+		if !ok {
+			m = &prometheusMetric{kind: prometheusKind(name)}
+			metrics[full] = m
+		}
+		m.samples = append(m.samples, prometheusSample{labels: labels, value: value})
+	}
+
+	for name, v := range snapshot.Ints {
+		add(name, float64(v))
+	}
+	for name, v := range snapshot.Floats {
+		add(name, v)
+	}
+	for name, v := range snapshot.Bools {
+		value := 0.0
+		if v {
+			value = 1
+		}
+		add(name, value)
+	}
+}
+
+func writePrometheus(w io.Writer, metrics map[string]*prometheusMetric) {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		m := metrics[name]
+		sort.Slice(m.samples, func(i, j int) bool { return m.samples[i].labels < m.samples[j].labels })
+
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, m.kind)
+		for _, s := range m.samples {
+			fmt.Fprintf(w, "%s%s %s\n", name, s.labels, strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+}
+
+func prometheusKind(name string) string {
+	if strings.HasSuffix(name, "bytes") && gauge.Is(name) {
+		return "gauge"
+	}
+	last := name
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		last = name[i+1:]
+	}
+	for _, suffix := range prometheusCounterSuffixes {
+		if last == suffix || strings.HasSuffix(last, "_"+suffix) {
+			return "counter"
+		}
+	}
+	return "gauge"
+}
+
+// prometheusName joins prefix and name, replacing all characters not
+// allowed in Prometheus metric names with underscores.
+func prometheusName(prefix, name string) string {
+	if prefix != "" {
+		name = prefix + "_" + name
+	}
+
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,148 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func newTestNamespaces() (lookupFunc, map[string]*monitoring.Registry) {
+	registries := map[string]*monitoring.Registry{
+		"info":    monitoring.NewRegistry(),
+		"state":   monitoring.NewRegistry(),
+		"stats":   monitoring.NewRegistry(),
+		"dataset": monitoring.NewRegistry(),
+	}
+	namespaces := map[string]*monitoring.Namespace{}
+	for name, reg := range registries {
+		ns := &monitoring.Namespace{}
+		ns.SetRegistry(reg)
+		namespaces[name] = ns
+	}
+	return func(name string) *monitoring.Namespace { return namespaces[name] }, registries
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	lookup, registries := newTestNamespaces()
+
+	stats := registries["stats"]
+	monitoring.NewInt(stats, "libbeat.output.events.acked").Set(10)
+	monitoring.NewInt(stats, "libbeat.pipeline.queue.max_events").Set(3200)
+	monitoring.NewFloat(stats, "system.load.1").Set(0.5)
+	monitoring.NewBool(stats, "registrar.healthy").Set(true)
+
+	for _, id := range []string{"b-input", "a-input"} {
+		reg := registries["dataset"].NewRegistry(id)
+		monitoring.NewString(reg, "id").Set(id)
+		monitoring.NewString(reg, "input").Set("filestream")
+		monitoring.NewInt(reg, "events_processed_total").Set(int64(len(id)))
+	}
+	quoted := registries["dataset"].NewRegistry("quoted")
+	monitoring.NewString(quoted, "id").Set("a \"quoted\"\nid")
+	monitoring.NewInt(quoted, "events_processed_total").Set(1)
+
+	resp := httptest.NewRecorder()
+	makePrometheusHandler("beat", lookup)(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `# TYPE beat_dataset_events_processed_total counter
+beat_dataset_events_processed_total{id="a \"quoted\"\nid"} 1
+beat_dataset_events_processed_total{id="a-input",input="filestream"} 7
+beat_dataset_events_processed_total{id="b-input",input="filestream"} 7
+# TYPE beat_libbeat_output_events_acked counter
+beat_libbeat_output_events_acked 10
+# TYPE beat_libbeat_pipeline_queue_max_events gauge
+beat_libbeat_pipeline_queue_max_events 3200
+# TYPE beat_registrar_healthy gauge
+beat_registrar_healthy 1
+# TYPE beat_system_load_1 gauge
+beat_system_load_1 0.5
+`, resp.Body.String())
+}
+
+func TestPrometheusName(t *testing.T) {
+	tests := map[string]struct {
+		prefix, name, expected string
+	}{
+		"no prefix":        {"", "cpu.total.ticks", "cpu_total_ticks"},
+		"prefix":           {"beat", "cpu.total.ticks", "beat_cpu_total_ticks"},
+		"leading digit":    {"", "1m", "_m"},
+		"invalid chars":    {"my-beat", "queue/filled.pct", "my_beat_queue_filled_pct"},
+		"colons are valid": {"", "a:b", "a:b"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, prometheusName(test.prefix, test.name))
+		})
+	}
+}
+
+func TestPrometheusKind(t *testing.T) {
+	tests := map[string]string{
+		"libbeat.output.events.acked":         "counter",
+		"libbeat.output.write.bytes":          "counter",
+		"beat.cpu.total.ticks":                "counter",
+		"libbeat.pipeline.queue.filled.bytes": "gauge",
+		"libbeat.pipeline.queue.max_bytes":    "gauge",
+		"beat.cgroup.memory.mem.usage.bytes":  "gauge",
+		"libbeat.pipeline.queue.max_events":   "gauge",
+	}
+
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected, prometheusKind(name))
+		})
+	}
+}
+
+func TestMetricsRoute(t *testing.T) {
+	lookup, registries := newTestNamespaces()
+	monitoring.NewInt(registries["stats"], "libbeat.output.events.total").Set(3)
+
+	cfg := config.MustNewConfigFrom(map[string]interface{}{
+		"host":           "http://localhost:0",
+		"metrics.prefix": "filebeat",
+	})
+
+	s, err := NewWithDefaultRoutes(nil, cfg, lookup)
+	require.NoError(t, err)
+	go s.Start()
+	defer func() {
+		require.NoError(t, s.Stop())
+	}()
+
+	r, err := http.Get("http://" + s.l.Addr().String() + "/metrics")
+	require.NoError(t, err)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	assert.Equal(t, "# TYPE filebeat_libbeat_output_events_total counter\nfilebeat_libbeat_output_events_total 3\n", string(body))
+}
@@ -44,6 +44,7 @@ func NewWithDefaultRoutes(log *logp.Logger, config *config.C, ns lookupFunc) (*S
 		api.AttachHandler("/state", makeAPIHandler(ns("state"))),
 		api.AttachHandler("/stats", makeAPIHandler(ns("stats"))),
 		api.AttachHandler("/dataset", makeAPIHandler(ns("dataset"))),
+		api.AttachHandler("/metrics", makePrometheusHandler(api.config.MetricsPrefix, ns)),
 	)
 	if err != nil {
 		return nil, err
@@ -56,12 +56,18 @@ func New(log *logp.Logger, config *config.C) (*Server, error) {
 		return nil, err
 	}
 
-	return &Server{
+	s := &Server{
 		mux:    mux.NewRouter().StrictSlash(true),
 		l:      l,
 		config: cfg,
 		log:    log.Named("api"),
-	}, nil
+	}
+
+	if cfg.AuthToken != "" && !(cfg.AuthExemptLocal && isLocalListener(s)) {
+		s.mux.Use(makeBearerTokenMiddleware(cfg.AuthToken))
+	}
+
+	return s, nil
 }
 
 // Start starts the HTTP server and accepting new connection.
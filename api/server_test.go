@@ -26,6 +26,7 @@ import (
 	"os"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -185,6 +186,160 @@ func TestHTTP(t *testing.T) {
 	assert.Equal(t, "ehlo!", string(body))
 }
 
+func TestBearerToken(t *testing.T) {
+	get := func(t *testing.T, c http.Client, url, token string) (int, string) {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		r, err := c.Do(req)
+		require.NoError(t, err)
+		defer r.Body.Close()
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		return r.StatusCode, string(body)
+	}
+
+	t.Run("tcp", func(t *testing.T) {
+		cfg := config.MustNewConfigFrom(map[string]interface{}{
+			"host":              "http://localhost:0",
+			"auth.token":        "secret",
+			"auth.exempt_local": true,
+		})
+
+		s, err := New(nil, cfg)
+		require.NoError(t, err)
+		attachEchoHelloHandler(t, s)
+		go s.Start()
+		defer func() {
+			require.NoError(t, s.Stop())
+		}()
+
+		url := "http://" + s.l.Addr().String() + "/echo-hello"
+
+		status, _ := get(t, http.Client{}, url, "")
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		status, _ = get(t, http.Client{}, url, "wrong")
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		status, body := get(t, http.Client{}, url, "secret")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "ehlo!", body)
+	})
+
+	t.Run("exempt unix socket", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Unix Sockets don't work under windows")
+			return
+		}
+
+		tmpDir, err := os.MkdirTemp("", "testsocket")
+		require.NoError(t, err)
+		defer os.RemoveAll(tmpDir)
+
+		sockFile := tmpDir + "/test.sock"
+
+		cfg := config.MustNewConfigFrom(map[string]interface{}{
+			"host":              "unix://" + sockFile,
+			"auth.token":        "secret",
+			"auth.exempt_local": true,
+		})
+
+		s, err := New(nil, cfg)
+		require.NoError(t, err)
+		attachEchoHelloHandler(t, s)
+		go s.Start()
+		defer func() {
+			require.NoError(t, s.Stop())
+		}()
+
+		c := http.Client{
+			Transport: &http.Transport{
+				DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+					return net.Dial("unix", sockFile)
+				},
+			},
+		}
+
+		status, body := get(t, c, "http://unix/echo-hello", "")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "ehlo!", body)
+	})
+}
+
+func TestShutdownHandler(t *testing.T) {
+	newServer := func(t *testing.T, enabled bool, stop func()) *Server {
+		t.Helper()
+
+		cfg := config.MustNewConfigFrom(map[string]interface{}{
+			"host":             "http://localhost:0",
+			"shutdown.enabled": enabled,
+		})
+
+		s, err := New(nil, cfg)
+		require.NoError(t, err)
+		require.NoError(t, s.AttachShutdownHandler(stop))
+		return s
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		s, err := New(nil, config.MustNewConfigFrom(map[string]interface{}{
+			"host": "http://localhost:0",
+		}))
+		require.NoError(t, err)
+		require.NoError(t, s.AttachShutdownHandler(func() { t.Error("unexpected stop") }))
+		defer s.Stop()
+
+		resp := httptest.NewRecorder()
+		s.mux.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/shutdown", nil))
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s := newServer(t, false, func() { t.Error("unexpected stop") })
+		defer s.Stop()
+
+		resp := httptest.NewRecorder()
+		s.mux.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/shutdown", nil))
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		stopped := make(chan struct{})
+		s := newServer(t, true, func() { close(stopped) })
+		go s.Start()
+		defer func() {
+			require.NoError(t, s.Stop())
+		}()
+
+		r, err := http.Post("http://"+s.l.Addr().String()+"/shutdown", "", nil)
+		require.NoError(t, err)
+		r.Body.Close()
+		assert.Equal(t, http.StatusAccepted, r.StatusCode)
+
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatal("stop was not called")
+		}
+	})
+
+	t.Run("only POST", func(t *testing.T) {
+		s := newServer(t, true, func() { t.Error("unexpected stop") })
+		defer s.Stop()
+
+		resp := httptest.NewRecorder()
+		s.mux.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/shutdown", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	})
+}
+
 func attachEchoHelloHandler(t *testing.T, s *Server) {
 	t.Helper()
 
@@ -223,3 +378,30 @@ func newTestHandler(response string) http.Handler {
 		_, _ = io.WriteString(w, response)
 	})
 }
+
+func TestProcessorsHandler(t *testing.T) {
+	s, err := New(nil, config.MustNewConfigFrom(map[string]interface{}{
+		"host": "http://localhost:0",
+	}))
+	require.NoError(t, err)
+	defer s.Stop()
+
+	err = s.AttachProcessorsHandler(func() []ProcessorInfo {
+		return []ProcessorInfo{
+			{Name: "add_fields", Used: true},
+			{Name: "drop_event"},
+		}
+	})
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	s.mux.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/processors", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t,
+		`{"processors":[{"name":"add_fields","used":true},{"name":"drop_event","used":false}]}`,
+		resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	s.mux.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/processors", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
@@ -61,3 +61,45 @@ func TestNamedPipe(t *testing.T) {
 
 	assert.Equal(t, "ehlo!", string(body))
 }
+
+func TestNamedPipeBearerToken(t *testing.T) {
+	p := "npipe:///hello-auth"
+
+	cfg := config.MustNewConfigFrom(map[string]interface{}{
+		"host":       p,
+		"auth.token": "secret",
+	})
+
+	s, err := New(nil, cfg)
+	require.NoError(t, err)
+	attachEchoHelloHandler(t, s)
+	go s.Start()
+	defer func() {
+		require.NoError(t, s.Stop())
+	}()
+
+	c := http.Client{
+		Transport: &http.Transport{
+			DialContext: npipe.DialContext(npipe.TransformString(p)),
+		},
+	}
+
+	r, err := c.Get("http://npipe/echo-hello")
+	require.NoError(t, err)
+	r.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, r.StatusCode)
+
+	req, err := http.NewRequest(http.MethodGet, "http://npipe/echo-hello", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+
+	r, err = c.Do(req)
+	require.NoError(t, err)
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	assert.Equal(t, "ehlo!", string(body))
+}
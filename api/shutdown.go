@@ -0,0 +1,49 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"net/http"
+)
+
+// AttachShutdownHandler attaches a `POST /shutdown` endpoint calling stop to
+// gracefully shut down the Beat. The endpoint is only attached when enabled
+// with `shutdown.enabled`, otherwise requests are answered with 404.
+func (s *Server) AttachShutdownHandler(stop func()) error {
+	if !s.config.ShutdownEnabled {
+		return nil
+	}
+
+	err := s.mux.Handle("/shutdown", makeShutdownHandler(s, stop)).Methods(http.MethodPost).GetError()
+	if err != nil {
+		return err
+	}
+	s.log.Debug("Attached shutdown handler to server.")
+	return nil
+}
+
+func makeShutdownHandler(s *Server, stop func()) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.log.Infof("Shutdown requested by %s", r.RemoteAddr)
+		w.WriteHeader(http.StatusAccepted)
+
+		// stop blocks until the Beat is stopped, run it after answering the
+		// request.
+		go stop()
+	}
+}
@@ -20,9 +20,11 @@
 package docker
 
 import (
+	"strings"
 	"time"
 
 	"github.com/njcx/libbeat_v8/autodiscover/template"
+	"github.com/njcx/libbeat_v8/common/match"
 	"github.com/elastic/elastic-agent-autodiscover/docker"
 	"github.com/elastic/elastic-agent-libs/config"
 )
@@ -41,6 +43,15 @@ type Config struct {
 	Templates      template.MapperSettings `config:"templates"`
 	Dedot          bool                    `config:"labels.dedot"`
 	CleanupTimeout time.Duration           `config:"cleanup_timeout" validate:"positive"`
+	StartDelay     time.Duration           `config:"start_delay" validate:"positive"`
+
+	// Label filters selecting the containers autodiscover events are
+	// emitted for. Plain filters are a label key or `key=value`, regular
+	// expressions are matched against `key=value`.
+	IncludeLabels      []string        `config:"include_labels"`
+	ExcludeLabels      []string        `config:"exclude_labels"`
+	IncludeLabelsRegex []match.Matcher `config:"include_labels_regex"`
+	ExcludeLabelsRegex []match.Matcher `config:"exclude_labels_regex"`
 }
 
 // DefaultCleanupTimeout Public variable, so specific beats (as Filebeat) can set a different cleanup timeout if they need it.
@@ -62,3 +73,39 @@ func (c *Config) Validate() {
 		c.Prefix = c.Prefix[:len(c.Prefix)-2]
 	}
 }
+
+// matchesLabels checks if a container with the given labels passes the
+// label filters. Without include filters all containers are included,
+// exclude filters take precedence over include filters.
+func (c *Config) matchesLabels(labels map[string]string) bool {
+	if matchAnyLabel(labels, c.ExcludeLabels, c.ExcludeLabelsRegex) {
+		return false
+	}
+	if len(c.IncludeLabels) == 0 && len(c.IncludeLabelsRegex) == 0 {
+		return true
+	}
+	return matchAnyLabel(labels, c.IncludeLabels, c.IncludeLabelsRegex)
+}
+
+func matchAnyLabel(labels map[string]string, filters []string, regexps []match.Matcher) bool {
+	for _, filter := range filters {
+		key, value, hasValue := strings.Cut(filter, "=")
+		v, ok := labels[key]
+		if ok && (!hasValue || v == value) {
+			return true
+		}
+	}
+
+	if len(regexps) == 0 {
+		return false
+	}
+	for k, v := range labels {
+		label := k + "=" + v
+		for _, m := range regexps {
+			if m.MatchString(label) {
+				return true
+			}
+		}
+	}
+	return false
+}
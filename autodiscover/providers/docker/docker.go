@@ -59,6 +59,8 @@ type Provider struct {
 	stopListener  bus.Listener
 	stoppers      map[string]*time.Timer
 	stopTrigger   chan *dockerContainerMetadata
+	starters      map[string]*delayedStart
+	startTrigger  chan *delayedStart
 	logger        *logp.Logger
 }
 
@@ -125,6 +127,8 @@ func AutodiscoverBuilder(
 		stopListener:  stop,
 		stoppers:      make(map[string]*time.Timer),
 		stopTrigger:   make(chan *dockerContainerMetadata),
+		starters:      make(map[string]*delayedStart),
+		startTrigger:  make(chan *delayedStart),
 		logger:        logger,
 	}, nil
 }
@@ -142,6 +146,9 @@ func (d *Provider) Start() {
 				for _, stopper := range d.stoppers {
 					stopper.Stop()
 				}
+				for _, starter := range d.starters {
+					starter.timer.Stop()
+				}
 				close(d.stopTrigger)
 				return
 
@@ -153,6 +160,9 @@ func (d *Provider) Start() {
 
 			case target := <-d.stopTrigger:
 				d.stopContainer(target.container, target.metadata)
+
+			case starter := <-d.startTrigger:
+				d.delayedStartContainer(starter)
 			}
 		}
 	}()
@@ -163,6 +173,12 @@ type dockerContainerMetadata struct {
 	metadata  *dockerMetadata
 }
 
+// delayedStart is a container start waiting for the start delay to pass.
+type delayedStart struct {
+	dockerContainerMetadata
+	timer *time.Timer
+}
+
 type dockerMetadata struct {
 	// Old selectors [Deprecated]
 	Docker mapstr.M
@@ -240,7 +256,26 @@ func (d *Provider) generateMetaDocker(event bus.Event) (*docker.Container, *dock
 	return container, meta
 }
 
+// filterContainer checks if the container of the watcher event passes the
+// label filters of the provider.
+func (d *Provider) filterContainer(event bus.Event) bool {
+	container, ok := event["container"].(*docker.Container)
+	if !ok {
+		// let generateMetaDocker report the invalid event
+		return true
+	}
+	if d.config.matchesLabels(container.Labels) {
+		return true
+	}
+	d.logger.Debugf("Container %s filtered out by label filters", container.ID)
+	return false
+}
+
 func (d *Provider) startContainer(event bus.Event) {
+	if !d.filterContainer(event) {
+		return
+	}
+
 	container, meta := d.generateMetaDocker(event)
 	if container == nil || meta == nil {
 		return
@@ -253,15 +288,59 @@ func (d *Provider) startContainer(event bus.Event) {
 		return
 	}
 
-	d.emitContainer(container, meta, "start")
+	if d.config.StartDelay <= 0 {
+		d.emitContainer(container, meta, "start")
+		return
+	}
+
+	if _, ok := d.starters[container.ID]; ok {
+		return
+	}
+
+	starter := &delayedStart{
+		dockerContainerMetadata: dockerContainerMetadata{
+			container: container,
+			metadata:  meta,
+		},
+	}
+	starter.timer = time.AfterFunc(d.config.StartDelay, func() {
+		select {
+		case d.startTrigger <- starter:
+		case <-d.stop:
+		}
+	})
+	d.starters[container.ID] = starter
+}
+
+func (d *Provider) delayedStartContainer(starter *delayedStart) {
+	// The start might have been cancelled by a stop event while the
+	// trigger was pending
+	if d.starters[starter.container.ID] != starter {
+		return
+	}
+	delete(d.starters, starter.container.ID)
+
+	d.emitContainer(starter.container, starter.metadata, "start")
 }
 
 func (d *Provider) scheduleStopContainer(event bus.Event) {
+	// Filtered containers were never started
+	if !d.filterContainer(event) {
+		return
+	}
+
 	container, meta := d.generateMetaDocker(event)
 	if container == nil || meta == nil {
 		return
 	}
 
+	if starter, ok := d.starters[container.ID]; ok {
+		d.logger.Debugf("Container %s stopped before the start delay, aborting pending start", container.ID)
+		starter.timer.Stop()
+		delete(d.starters, container.ID)
+		return
+	}
+
 	if d.config.CleanupTimeout <= 0 {
 		d.stopContainer(container, meta)
 		return
@@ -19,11 +19,15 @@ package docker
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/elastic/elastic-agent-autodiscover/bus"
 	"github.com/elastic/elastic-agent-autodiscover/docker"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
 
@@ -234,3 +238,145 @@ func TestGenerateMetaDockerWithDedot(t *testing.T) {
 	assert.Equal(t, expectedMeta.Container, meta.Container)
 	assert.Equal(t, expectedMeta.Metadata, meta.Metadata)
 }
+
+func TestLabelFilters(t *testing.T) {
+	labels := map[string]string{
+		"com.example.team": "payments",
+		"com.example.tier": "backend",
+	}
+
+	tests := map[string]struct {
+		config   map[string]interface{}
+		expected bool
+	}{
+		"no filters": {
+			config:   map[string]interface{}{},
+			expected: true,
+		},
+		"include by key": {
+			config:   map[string]interface{}{"include_labels": []string{"com.example.team"}},
+			expected: true,
+		},
+		"include by key and value": {
+			config:   map[string]interface{}{"include_labels": []string{"com.example.team=payments"}},
+			expected: true,
+		},
+		"include with other value": {
+			config:   map[string]interface{}{"include_labels": []string{"com.example.team=search"}},
+			expected: false,
+		},
+		"include missing key": {
+			config:   map[string]interface{}{"include_labels": []string{"com.example.owner"}},
+			expected: false,
+		},
+		"include regex": {
+			config:   map[string]interface{}{"include_labels_regex": []string{`^com\.example\.tier=(backend|db)$`}},
+			expected: true,
+		},
+		"exclude by key": {
+			config:   map[string]interface{}{"exclude_labels": []string{"com.example.tier"}},
+			expected: false,
+		},
+		"exclude regex": {
+			config:   map[string]interface{}{"exclude_labels_regex": []string{`=pay`}},
+			expected: false,
+		},
+		"exclude takes precedence": {
+			config: map[string]interface{}{
+				"include_labels": []string{"com.example.team"},
+				"exclude_labels": []string{"com.example.tier=backend"},
+			},
+			expected: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := defaultConfig()
+			require.NoError(t, config.MustNewConfigFrom(test.config).Unpack(&cfg))
+			assert.Equal(t, test.expected, cfg.matchesLabels(labels))
+		})
+	}
+}
+
+func TestFilterContainer(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.ExcludeLabels = []string{"skip"}
+	p := Provider{
+		config: cfg,
+		logger: logp.NewLogger("docker"),
+	}
+
+	assert.False(t, p.filterContainer(bus.Event{
+		"container": &docker.Container{ID: "abc", Labels: map[string]string{"skip": "true"}},
+	}))
+	assert.True(t, p.filterContainer(bus.Event{
+		"container": &docker.Container{ID: "def", Labels: map[string]string{"keep": "true"}},
+	}))
+}
+
+func TestStartDelay(t *testing.T) {
+	newProvider := func() *Provider {
+		cfg := defaultConfig()
+		cfg.StartDelay = 10 * time.Millisecond
+		return &Provider{
+			config:       cfg,
+			bus:          bus.New(logp.NewLogger("bus"), "test"),
+			stop:         make(chan interface{}),
+			stoppers:     make(map[string]*time.Timer),
+			stopTrigger:  make(chan *dockerContainerMetadata),
+			starters:     make(map[string]*delayedStart),
+			startTrigger: make(chan *delayedStart),
+			logger:       logp.NewLogger("docker"),
+		}
+	}
+	event := bus.Event{
+		"container": &docker.Container{ID: "abc", Name: "init"},
+	}
+
+	t.Run("start is emitted after the delay", func(t *testing.T) {
+		p := newProvider()
+		defer close(p.stop)
+		listener := p.bus.Subscribe()
+		defer listener.Stop()
+
+		p.startContainer(event)
+		require.Contains(t, p.starters, "abc")
+
+		select {
+		case starter := <-p.startTrigger:
+			p.delayedStartContainer(starter)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for delayed start")
+		}
+		assert.Empty(t, p.starters)
+
+		select {
+		case e := <-listener.Events():
+			assert.Equal(t, true, e["start"])
+			assert.Equal(t, "abc", e["id"])
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for start event")
+		}
+	})
+
+	t.Run("stop before the delay cancels the start", func(t *testing.T) {
+		p := newProvider()
+		defer close(p.stop)
+		listener := p.bus.Subscribe()
+		defer listener.Stop()
+
+		p.startContainer(event)
+		p.scheduleStopContainer(event)
+		assert.Empty(t, p.starters)
+		assert.Empty(t, p.stoppers)
+
+		select {
+		case <-p.startTrigger:
+			t.Fatal("cancelled start was triggered")
+		case e := <-listener.Events():
+			t.Fatalf("unexpected event: %v", e)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
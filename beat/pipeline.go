@@ -18,6 +18,7 @@
 package beat
 
 import (
+	"context"
 	"time"
 
 	"github.com/elastic/elastic-agent-libs/mapstr"
@@ -42,6 +43,57 @@ type Client interface {
 	Close() error
 }
 
+// QueueFillReporter is implemented by clients that can report how full the
+// pipeline queue is, so inputs can slow down before Publish starts blocking.
+type QueueFillReporter interface {
+	// QueueFillRatio returns the fraction of the queue capacity in use,
+	// between 0.0 (empty) and 1.0 (full). The value is read without blocking
+	// from the queue metrics and is only a hint: it may already be stale when
+	// returned, and it is 0 if the queue does not exist yet or queue metrics
+	// are disabled.
+	QueueFillRatio() float64
+}
+
+// QueueFillRatio returns the queue fill ratio reported by client, and false if
+// the client does not implement QueueFillReporter.
+func QueueFillRatio(client Client) (float64, bool) {
+	r, ok := client.(QueueFillReporter)
+	if !ok {
+		return 0, false
+	}
+	return r.QueueFillRatio(), true
+}
+
+// ContextCloser is implemented by clients that can bound how long closing
+// waits for outstanding events.
+type ContextCloser interface {
+	// CloseWithContext closes the client like Close, but stops waiting for
+	// outstanding events to be acknowledged once ctx is done, and cancels any
+	// Publish call still blocked on a full queue. It returns ctx.Err() if
+	// ctx was done before the client finished closing.
+	CloseWithContext(ctx context.Context) error
+}
+
+// CloseWithContext closes client, waiting at most until ctx is done. If client
+// does not implement ContextCloser, Close is run in the background and
+// ctx.Err() is returned if it does not finish in time.
+func CloseWithContext(ctx context.Context, client Client) error {
+	if c, ok := client.(ContextCloser); ok {
+		return c.CloseWithContext(ctx)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Close()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // ClientConfig defines common configuration options one can pass to
 // Pipeline.ConnectWith to control the clients behavior and provide ACK support.
 type ClientConfig struct {
@@ -55,6 +107,20 @@ type ClientConfig struct {
 	// is configured
 	WaitClose time.Duration
 
+	// PublishTimeout, if positive, bounds the time Publish blocks while the
+	// queue is full. Events that could not be queued in time are dropped and
+	// reported like events dropped with DropIfFull. It has no effect with
+	// DropIfFull, which never blocks, and is only supported by the memory
+	// queue; other queues block until the event is accepted.
+	PublishTimeout time.Duration
+
+	// SampleRate enables head-based sampling of the client's events: only
+	// 1 in SampleRate events is forwarded to the queue, the others are
+	// dropped and counted as sampled out. Sampling is deterministic and
+	// applied after the client's processors, so processors still see every
+	// event. Values 0 and 1 disable sampling.
+	SampleRate uint
+
 	// Callbacks for when events are added / acknowledged
 	EventListener EventListener
 
@@ -149,6 +215,28 @@ type Processor interface {
 	Run(in *Event) (event *Event, err error)
 }
 
+// MultiEventProcessor is implemented by processors that can emit more than
+// one event for an input event, for example to split a batch of records.
+// Processor lists and the publisher pipeline call RunMulti instead of Run if
+// it is implemented. The returned events are published in order, returning
+// no events drops the input event.
+type MultiEventProcessor interface {
+	Processor
+	RunMulti(in *Event) (events []*Event, err error)
+}
+
+// RunMulti runs p on in, using RunMulti if p implements MultiEventProcessor.
+func RunMulti(p Processor, in *Event) ([]*Event, error) {
+	if mp, ok := p.(MultiEventProcessor); ok {
+		return mp.RunMulti(in)
+	}
+	out, err := p.Run(in)
+	if out == nil {
+		return nil, err
+	}
+	return []*Event{out}, err
+}
+
 // PublishMode enum sets some requirements on the client connection to the beats
 // publisher pipeline
 type PublishMode uint8
@@ -18,28 +18,54 @@
 package export
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 
+	"github.com/njcx/libbeat_v8/cfgfile"
 	"github.com/njcx/libbeat_v8/cmd/instance"
 	"github.com/njcx/libbeat_v8/common/cli"
+	"github.com/elastic/elastic-agent-libs/keystore"
 )
 
+// redactedValue replaces secrets in the exported configuration.
+const redactedValue = "[REDACTED]"
+
+// secretSettings are the names of settings whose values are always redacted
+// when the configuration is exported with --resolve.
+var secretSettings = map[string]bool{
+	"password":       true,
+	"passphrase":     true,
+	"key_passphrase": true,
+	"api_key":        true,
+	"secret_token":   true,
+}
+
 // GenExportConfigCmd write to stdout the current configuration in the YAML format.
 func GenExportConfigCmd(settings instance.Settings) *cobra.Command {
-	return &cobra.Command{
+	genExportConfigCmd := &cobra.Command{
 		Use:   "config",
 		Short: "Export current config to stdout",
 		Run: cli.RunWith(func(cmd *cobra.Command, args []string) error {
-			return exportConfig(settings)
+			resolve, _ := cmd.Flags().GetBool("resolve")
+			return exportConfig(settings, resolve)
 		}),
 	}
+
+	genExportConfigCmd.Flags().Bool("resolve", false, "Resolve variables and keystore references, redacting secrets")
+	cfgfile.AddAllowedBackwardsCompatibleFlag("resolve")
+
+	return genExportConfigCmd
 }
 
-func exportConfig(settings instance.Settings) error {
-	settings.DisableConfigResolver = true
+func exportConfig(settings instance.Settings, resolve bool) error {
+	if resolve && settings.DisableConfigResolver {
+		return fmt.Errorf("--resolve is not supported, %s disables resolving the configuration", settings.Name)
+	}
+	settings.DisableConfigResolver = settings.DisableConfigResolver || !resolve
 	b, err := instance.NewInitializedBeat(settings)
 	if err != nil {
 		fatalfInitCmd(err)
@@ -50,6 +76,13 @@ func exportConfig(settings instance.Settings) error {
 	if err != nil {
 		fatalf("Error unpacking config: %+v.", err)
 	}
+	if resolve {
+		secrets, err := keystoreSecrets(b.Keystore())
+		if err != nil {
+			fatalf("Error reading the keystore to redact secrets: %+v.", err)
+		}
+		redactConfig(config, secrets)
+	}
 	res, err := yaml.Marshal(config)
 	if err != nil {
 		fatalf("Error converting config to YAML format: %+v.", err)
@@ -58,3 +91,65 @@ func exportConfig(settings instance.Settings) error {
 	os.Stdout.Write(res)
 	return nil
 }
+
+// keystoreSecrets returns the values stored in the keystore, so they can be
+// redacted wherever they are referenced in the resolved configuration.
+func keystoreSecrets(store keystore.Keystore) ([]string, error) {
+	if store == nil {
+		return nil, nil
+	}
+	listingKeystore, err := keystore.AsListingKeystore(store)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := listingKeystore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make([]string, 0, len(keys))
+	for _, key := range keys {
+		secure, err := store.Retrieve(key)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve %s: %w", key, err)
+		}
+		value, err := secure.Get()
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve %s: %w", key, err)
+		}
+		if len(value) > 0 {
+			secrets = append(secrets, string(value))
+		}
+	}
+	return secrets, nil
+}
+
+// redactConfig replaces the values of secretSettings, and all values
+// containing one of secrets, with redactedValue.
+func redactConfig(config map[string]interface{}, secrets []string) {
+	for key, value := range config {
+		if secretSettings[key] && value != nil {
+			config[key] = redactedValue
+			continue
+		}
+		config[key] = redactValue(value, secrets)
+	}
+}
+
+func redactValue(value interface{}, secrets []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redactConfig(v, secrets)
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i], secrets)
+		}
+	case string:
+		for _, secret := range secrets {
+			if strings.Contains(v, secret) {
+				return redactedValue
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/njcx/libbeat_v8/cmd/instance"
+)
+
+func TestExportConfigResolveDisabled(t *testing.T) {
+	err := exportConfig(instance.Settings{Name: "testbeat", DisableConfigResolver: true}, true)
+	assert.ErrorContains(t, err, "--resolve is not supported")
+}
+
+func TestRedactConfig(t *testing.T) {
+	config := map[string]interface{}{
+		"output": map[string]interface{}{
+			"elasticsearch": map[string]interface{}{
+				"hosts":    []interface{}{"localhost:9200"},
+				"username": "elastic",
+				"password": "changeme",
+				"headers": map[string]interface{}{
+					"Authorization": "Bearer keystore-token",
+				},
+			},
+		},
+		"processors": []interface{}{
+			map[string]interface{}{
+				"add_fields": map[string]interface{}{
+					"fields": map[string]interface{}{"api_key": "id:key"},
+				},
+			},
+		},
+	}
+
+	redactConfig(config, []string{"keystore-token"})
+
+	es := config["output"].(map[string]interface{})["elasticsearch"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"localhost:9200"}, es["hosts"])
+	assert.Equal(t, "elastic", es["username"])
+	assert.Equal(t, redactedValue, es["password"])
+	assert.Equal(t, redactedValue, es["headers"].(map[string]interface{})["Authorization"],
+		"Values resolved from the keystore should be redacted under any setting name")
+
+	fields := config["processors"].([]interface{})[0].(map[string]interface{})["add_fields"].(map[string]interface{})["fields"].(map[string]interface{})
+	assert.Equal(t, redactedValue, fields["api_key"])
+}
@@ -67,6 +67,7 @@ import (
 	"github.com/njcx/libbeat_v8/outputs/elasticsearch"
 	"github.com/njcx/libbeat_v8/plugin"
 	"github.com/njcx/libbeat_v8/pprof"
+	"github.com/njcx/libbeat_v8/processors"
 	"github.com/njcx/libbeat_v8/publisher/pipeline"
 	"github.com/njcx/libbeat_v8/publisher/processing"
 	"github.com/njcx/libbeat_v8/publisher/queue/diskqueue"
@@ -239,10 +240,12 @@ func NewInitializedBeat(settings Settings) (*Beat, error) {
 }
 
 // NewBeat creates a new beat instance
-func NewBeat(name, indexPrefix, v string, elasticLicensed bool, initFuncs []func()) (*Beat, error) {
+func NewBeat(name, indexPrefix, v string, elasticLicensed bool, initFuncs []func() error) (*Beat, error) {
 	// call all initialization functions
 	for _, f := range initFuncs {
-		f()
+		if err := f(); err != nil {
+			return nil, fmt.Errorf("initialization function failed: %w", err)
+		}
 	}
 
 	if v == "" {
@@ -691,6 +694,9 @@ func (b *Beat) launch(settings Settings, bt beat.Creator) error {
 		defer func() {
 			_ = b.API.Stop()
 		}()
+		if err := b.API.AttachProcessorsHandler(registeredProcessors); err != nil {
+			return fmt.Errorf("failed to attach processors handler: %w", err)
+		}
 		if b.Config.HTTPPprof.IsEnabled() {
 			pprof.SetRuntimeProfilingParameters(b.Config.HTTPPprof)
 
@@ -762,11 +768,37 @@ func (b *Beat) launch(settings Settings, bt beat.Creator) error {
 	// Allow the manager to stop a currently running beats out of bound.
 	b.Manager.SetStopCallback(stopBeat)
 
+	if b.API != nil {
+		err := b.API.AttachShutdownHandler(func() {
+			cancel()
+			stopBeat()
+		})
+		if err != nil {
+			return fmt.Errorf("failed to attach shutdown handler: %w", err)
+		}
+	}
+
 	err = b.loadDashboards(ctx, false)
 	if err != nil {
 		return err
 	}
 
+	// Background index management tasks, like the retention schedule, only
+	// run while the Beat is running, not during setup.
+	if r, ok := b.IdxSupporter.(lifecycle.Runner); ok {
+		idxCtx, idxCancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Run(idxCtx)
+		}()
+		defer func() {
+			idxCancel()
+			wg.Wait()
+		}()
+	}
+
 	logp.Info("%s start running.", b.Info.Beat)
 
 	err = beater.Run(&b.Beat)
@@ -844,9 +876,18 @@ func (b *Beat) TestConfig(settings Settings, bt beat.Creator) error {
 	return handleError(func() error {
 		err := b.InitWithSettings(settings)
 		if err != nil {
+			// Report the problems with their config path if possible.
+			if b.RawConfig != nil {
+				printConfigProblems(b.ValidateConfig())
+			}
 			return err
 		}
 
+		if problems := b.ValidateConfig(); len(problems) > 0 {
+			printConfigProblems(problems)
+			return fmt.Errorf("found %d configuration problems", len(problems))
+		}
+
 		// Create beater to ensure all settings are OK
 		_, err = b.createBeater(bt)
 		if err != nil {
@@ -858,6 +899,12 @@ func (b *Beat) TestConfig(settings Settings, bt beat.Creator) error {
 	}())
 }
 
+func printConfigProblems(problems []ConfigProblem) {
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "Config problem: %v\n", p)
+	}
+}
+
 // SetupSettings holds settings necessary for beat setup
 type SetupSettings struct {
 	Dashboard       bool
@@ -1298,12 +1345,17 @@ func (b *Beat) loadDashboards(ctx context.Context, force bool) error {
 			return fmt.Errorf("error generating index pattern: %w", err)
 		}
 
-		err = dashboards.ImportDashboards(ctx, b.Info, paths.Resolve(paths.Home, ""),
+		report, err := dashboards.ImportDashboards(ctx, b.Info, paths.Resolve(paths.Home, ""),
 			kibanaConfig, b.Config.Dashboards, nil, pattern)
 		if err != nil {
 			return fmt.Errorf("error importing Kibana dashboards: %w", err)
 		}
-		logp.Info("Kibana dashboards successfully loaded.")
+		if report != nil {
+			logp.Info("Kibana dashboards successfully validated (dry run), %d objects would be created, %d overwritten:\n%s",
+				len(report.Created()), len(report.Overwritten()), report)
+		} else {
+			logp.Info("Kibana dashboards successfully loaded.")
+		}
 	}
 
 	return nil
@@ -1582,6 +1634,17 @@ func (b *Beat) setupMonitoring(settings Settings) (report.Reporter, error) {
 	return nil, nil
 }
 
+// registeredProcessors lists the processors compiled into the Beat for the
+// `/processors` API endpoint.
+func registeredProcessors() []api.ProcessorInfo {
+	names := processors.RegisteredProcessors()
+	infos := make([]api.ProcessorInfo, len(names))
+	for i, name := range names {
+		infos[i] = api.ProcessorInfo{Name: name, Used: processors.IsUsed(name)}
+	}
+	return infos
+}
+
 // handleError handles the given error by logging it and then returning the
 // error. If the err is nil or is a GracefulExit error then the method will
 // return nil without logging anything.
@@ -21,6 +21,7 @@ package instance
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -64,6 +65,20 @@ func TestNewInstance(t *testing.T) {
 
 }
 
+func TestNewInstanceInitialize(t *testing.T) {
+	var called []int
+	initFuncs := []func() error{
+		func() error { called = append(called, 1); return nil },
+		func() error { called = append(called, 2); return errors.New("license not found") },
+		func() error { called = append(called, 3); return nil },
+	}
+
+	_, err := NewBeat("testbeat", "", "0.9", false, initFuncs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "license not found")
+	assert.Equal(t, []int{1, 2}, called, "initialization must stop at the first error")
+}
+
 func TestNewInstanceUUID(t *testing.T) {
 	b, err := NewBeat("testbeat", "", "0.9", false, nil)
 	if err != nil {
@@ -493,3 +508,33 @@ func (m mockManager) Status() status.Status                         { return sta
 func (m mockManager) Stop()                                         {}
 func (m mockManager) UnregisterAction(action client.Action)         {}
 func (m mockManager) UpdateStatus(status status.Status, msg string) {}
+
+func TestValidateConfig(t *testing.T) {
+	b, err := NewBeat("testbeat", "", "0.9", false, nil)
+	require.NoError(t, err)
+
+	b.RawConfig = config.MustNewConfigFrom(map[string]interface{}{
+		"processors": []interface{}{
+			map[string]interface{}{"add_fields": map[string]interface{}{"fields": map[string]interface{}{"a": 1}}},
+			map[string]interface{}{"not_a_processor": map[string]interface{}{}},
+		},
+		"queue.unknown":             map[string]interface{}{},
+		"setup.dashboards.space_id": "Not A Space",
+	})
+	require.NoError(t, b.RawConfig.Unpack(&b.Config))
+
+	var paths []string
+	for _, p := range b.ValidateConfig() {
+		paths = append(paths, p.Path)
+	}
+	assert.ElementsMatch(t, []string{"processors.1", "queue.unknown", "setup.dashboards"}, paths)
+
+	b.RawConfig = config.MustNewConfigFrom(map[string]interface{}{
+		"processors": []interface{}{
+			map[string]interface{}{"add_fields": map[string]interface{}{"fields": map[string]interface{}{"a": 1}}},
+		},
+	})
+	b.Config = beatConfig{}
+	require.NoError(t, b.RawConfig.Unpack(&b.Config))
+	assert.Empty(t, b.ValidateConfig())
+}
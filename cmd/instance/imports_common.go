@@ -21,6 +21,8 @@ import (
 	_ "github.com/njcx/libbeat_v8/autodiscover/appenders/config" // Register autodiscover appenders
 	_ "github.com/njcx/libbeat_v8/autodiscover/providers/jolokia"
 	_ "github.com/njcx/libbeat_v8/monitoring/report/elasticsearch" // Register default monitoring reporting
+	_ "github.com/njcx/libbeat_v8/monitoring/report/otlp"          // Register OTLP monitoring reporting
+	_ "github.com/njcx/libbeat_v8/monitoring/report/statsd"        // Register StatsD monitoring reporting
 	_ "github.com/njcx/libbeat_v8/processors/actions"              // Register default processors.
 	_ "github.com/njcx/libbeat_v8/processors/add_cloud_metadata"
 	_ "github.com/njcx/libbeat_v8/processors/add_formatted_index"
@@ -32,6 +34,8 @@ import (
 	_ "github.com/njcx/libbeat_v8/processors/communityid"
 	_ "github.com/njcx/libbeat_v8/processors/convert"
 	_ "github.com/njcx/libbeat_v8/processors/decode_duration"
+	_ "github.com/njcx/libbeat_v8/processors/decode_kv"
+	_ "github.com/njcx/libbeat_v8/processors/decode_traceparent"
 	_ "github.com/njcx/libbeat_v8/processors/decode_xml"
 	_ "github.com/njcx/libbeat_v8/processors/decode_xml_wineventlog"
 	_ "github.com/njcx/libbeat_v8/processors/dissect"
@@ -41,10 +45,14 @@ import (
 	_ "github.com/njcx/libbeat_v8/processors/move_fields"
 	_ "github.com/njcx/libbeat_v8/processors/ratelimit"
 	_ "github.com/njcx/libbeat_v8/processors/registered_domain"
+	_ "github.com/njcx/libbeat_v8/processors/restrict_fields"
 	_ "github.com/njcx/libbeat_v8/processors/script"
+	_ "github.com/njcx/libbeat_v8/processors/split"
 	_ "github.com/njcx/libbeat_v8/processors/syslog"
+	_ "github.com/njcx/libbeat_v8/processors/timestamp_diff"
 	_ "github.com/njcx/libbeat_v8/processors/translate_ldap_attribute"
 	_ "github.com/njcx/libbeat_v8/processors/translate_sid"
 	_ "github.com/njcx/libbeat_v8/processors/urldecode"
+	_ "github.com/njcx/libbeat_v8/processors/urlparse"
 	_ "github.com/njcx/libbeat_v8/publisher/includes" // Register publisher pipeline modules
 )
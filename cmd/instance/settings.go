@@ -52,5 +52,6 @@ type Settings struct {
 	InputQueueSize int
 
 	// Initialize functions that are called in-order to initialize unique items for the beat.
-	Initialize []func()
+	// If one of them fails, the beat is not created and the error is returned.
+	Initialize []func() error
 }
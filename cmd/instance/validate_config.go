@@ -0,0 +1,141 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package instance
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/njcx/libbeat_v8/dashboards"
+	"github.com/njcx/libbeat_v8/outputs"
+	"github.com/njcx/libbeat_v8/processors"
+	"github.com/njcx/libbeat_v8/publisher/queue/diskqueue"
+	"github.com/njcx/libbeat_v8/publisher/queue/memqueue"
+)
+
+// ConfigProblem is an error found in the setting at Path while validating
+// the configuration.
+type ConfigProblem struct {
+	Path string
+	Err  error
+}
+
+func (p ConfigProblem) Error() string {
+	if p.Path == "" {
+		return p.Err.Error()
+	}
+	return fmt.Sprintf("%v: %v", p.Path, p.Err)
+}
+
+// ValidateConfig checks the sections of the configuration that are shared by
+// all Beats: the output, the queue, the global processors and the dashboards
+// setup. Every section is checked, so all problems are reported at once.
+// Outputs are constructed, but no connection is opened. Processors are only
+// checked for known actions and valid conditions, as their constructors may
+// connect to external services.
+func (b *Beat) ValidateConfig() []ConfigProblem {
+	if b.RawConfig == nil {
+		return []ConfigProblem{{Path: "", Err: errors.New("configuration not loaded")}}
+	}
+
+	var problems []ConfigProblem
+	problems = append(problems, b.validateOutputConfig()...)
+	problems = append(problems, b.validateQueueConfig()...)
+	problems = append(problems, b.validateProcessorsConfig()...)
+	problems = append(problems, b.validateDashboardsConfig()...)
+	return problems
+}
+
+func (b *Beat) validateOutputConfig() []ConfigProblem {
+	out := b.Config.Output
+	if !out.IsSet() || !out.Config().Enabled() || b.IdxSupporter == nil {
+		return nil
+	}
+
+	path := "output." + out.Name()
+	group, err := outputs.Load(b.IdxSupporter, b.Info, outputs.NewNilObserver(), out.Name(), out.Config())
+	if err != nil {
+		return []ConfigProblem{{Path: path, Err: err}}
+	}
+	// Clients only connect when publishing, close them right away.
+	for _, client := range group.Clients {
+		_ = client.Close()
+	}
+	return nil
+}
+
+func (b *Beat) validateQueueConfig() []ConfigProblem {
+	queue := b.Config.Pipeline.Queue
+	if !queue.IsSet() {
+		return nil
+	}
+
+	path := "queue." + queue.Name()
+	var err error
+	switch queue.Name() {
+	case memqueue.QueueType:
+		_, err = memqueue.SettingsForUserConfig(queue.Config())
+	case diskqueue.QueueType:
+		_, err = diskqueue.SettingsForUserConfig(queue.Config())
+	default:
+		err = fmt.Errorf("unrecognized queue type '%v'", queue.Name())
+	}
+	if err != nil {
+		return []ConfigProblem{{Path: path, Err: err}}
+	}
+	return nil
+}
+
+func (b *Beat) validateProcessorsConfig() []ConfigProblem {
+	var cfg struct {
+		Processors     processors.PluginConfig `config:"processors"`
+		LastProcessors processors.PluginConfig `config:"last_processors"`
+	}
+	if err := b.RawConfig.Unpack(&cfg); err != nil {
+		return []ConfigProblem{{Path: "processors", Err: err}}
+	}
+
+	var problems []ConfigProblem
+	problems = append(problems, validateProcessors("processors", cfg.Processors)...)
+	problems = append(problems, validateProcessors("last_processors", cfg.LastProcessors)...)
+	return problems
+}
+
+// validateProcessors checks each processor of the list separately, so the
+// problems of all of them are reported.
+func validateProcessors(path string, list processors.PluginConfig) []ConfigProblem {
+	var problems []ConfigProblem
+	for i, procConfig := range list {
+		if err := processors.Validate(processors.PluginConfig{procConfig}); err != nil {
+			problems = append(problems, ConfigProblem{Path: fmt.Sprintf("%v.%d", path, i), Err: err})
+		}
+	}
+	return problems
+}
+
+func (b *Beat) validateDashboardsConfig() []ConfigProblem {
+	if b.Config.Dashboards == nil {
+		return nil
+	}
+
+	var dashboardsConfig dashboards.Config
+	if err := b.Config.Dashboards.Unpack(&dashboardsConfig); err != nil {
+		return []ConfigProblem{{Path: "setup.dashboards", Err: err}}
+	}
+	return nil
+}
@@ -258,6 +258,48 @@ func (a *eventDataACKer) onACK(acked, total int) {
 	}
 }
 
+// PublishedEventPrivateReporter reports the private fields of the events that
+// have been published and ACKed by the outputs, in publish order. Unlike
+// EventPrivateReporter, the private fields of dropped events are not
+// reported, so inputs can advance their read state exactly to the ACKed
+// events.
+func PublishedEventPrivateReporter(fn func(data []interface{})) beat.EventListener {
+	a := &publishedDataACKer{fn: fn}
+	a.EventListener = EventPrivateReporter(a.onACK)
+	return a
+}
+
+type publishedDataACKer struct {
+	beat.EventListener
+	mu        sync.Mutex
+	published []bool
+	fn        func(data []interface{})
+}
+
+func (a *publishedDataACKer) AddEvent(event beat.Event, published bool) {
+	a.mu.Lock()
+	a.published = append(a.published, published)
+	a.mu.Unlock()
+	a.EventListener.AddEvent(event, published)
+}
+
+func (a *publishedDataACKer) onACK(_ int, data []interface{}) {
+	a.mu.Lock()
+	published := a.published[:len(data)]
+	a.published = a.published[len(data):]
+	a.mu.Unlock()
+
+	acked := make([]interface{}, 0, len(data))
+	for i, d := range data {
+		if published[i] {
+			acked = append(acked, d)
+		}
+	}
+	if len(acked) > 0 {
+		a.fn(acked)
+	}
+}
+
 // LastEventPrivateReporter reports only the 'latest' published and acked
 // event if a batch of events have been ACKed.
 func LastEventPrivateReporter(fn func(acked int, data interface{})) beat.EventListener {
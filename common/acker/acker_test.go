@@ -150,6 +150,29 @@ func TestEventPrivateReporter(t *testing.T) {
 	})
 }
 
+func TestPublishedEventPrivateReporter(t *testing.T) {
+	t.Run("dropped event is not reported", func(t *testing.T) {
+		called := false
+		acker := PublishedEventPrivateReporter(func(_ []interface{}) { called = true })
+		acker.AddEvent(beat.Event{Private: 1}, false)
+		require.False(t, called)
+	})
+
+	t.Run("private of dropped events is excluded", func(t *testing.T) {
+		var data []interface{}
+		acker := PublishedEventPrivateReporter(func(d []interface{}) { data = append(data, d...) })
+		acker.AddEvent(beat.Event{Private: 1}, true)
+		acker.AddEvent(beat.Event{Private: 2}, false)
+		acker.AddEvent(beat.Event{Private: 3}, true)
+		acker.AddEvent(beat.Event{Private: 4}, true)
+		acker.ACKEvents(2)
+		require.Equal(t, []interface{}{1, 3}, data)
+
+		acker.ACKEvents(1)
+		require.Equal(t, []interface{}{1, 3, 4}, data)
+	})
+}
+
 func TestLastEventPrivateReporter(t *testing.T) {
 	t.Run("dropped event with private is acked immediately if empty", func(t *testing.T) {
 		var acked int
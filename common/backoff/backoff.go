@@ -17,7 +17,10 @@
 
 package backoff
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Backoff defines the interface for backoff strategies.
 type Backoff interface {
@@ -40,3 +43,43 @@ func WaitOnError(b Backoff, err error) bool {
 	}
 	return b.Wait()
 }
+
+// ContextBackoff is implemented by backoff strategies whose wait can be
+// canceled by a context.
+type ContextBackoff interface {
+	Backoff
+
+	// WaitContext blocks like Wait, but returns false early if ctx is
+	// canceled.
+	WaitContext(ctx context.Context) bool
+}
+
+// WaitOnErrorContext is like WaitOnError, but stops waiting once ctx is
+// canceled, if the backoff strategy supports it.
+func WaitOnErrorContext(ctx context.Context, b Backoff, err error) bool {
+	if err == nil {
+		b.Reset()
+		return true
+	}
+	if cb, ok := b.(ContextBackoff); ok {
+		return cb.WaitContext(ctx)
+	}
+	return b.Wait()
+}
+
+// waitFor blocks for d, until done is closed, or ctx is canceled. It records
+// the time the wait completed in last.
+func waitFor(ctx context.Context, done <-chan struct{}, d time.Duration, last *time.Time) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return false
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		*last = time.Now()
+		return true
+	}
+}
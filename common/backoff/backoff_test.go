@@ -18,6 +18,7 @@
 package backoff
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -43,6 +44,9 @@ func testCloseChannel(t *testing.T) {
 		"EqualJitterBackoff": func(done <-chan struct{}) Backoff {
 			return NewEqualJitterBackoff(done, init, max)
 		},
+		"FullJitterBackoff": func(done <-chan struct{}) Backoff {
+			return NewFullJitterBackoff(done, init, max)
+		},
 	}
 
 	for name, f := range tests {
@@ -55,6 +59,36 @@ func testCloseChannel(t *testing.T) {
 	}
 }
 
+func TestWaitOnErrorContextCanceled(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	b := NewExpBackoff(done, time.Minute, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	startedAt := time.Now()
+	assert.False(t, WaitOnErrorContext(ctx, b, errors.New("bad bad")))
+	assert.Less(t, time.Since(startedAt), time.Minute)
+}
+
+func TestFullJitterBackoffIsCapped(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	max := 20 * time.Millisecond
+	b := NewFullJitterBackoff(done, time.Millisecond, max)
+	for i := 0; i < 10; i++ {
+		startedAt := time.Now()
+		assert.True(t, b.Wait())
+		assert.Less(t, time.Since(startedAt), max+time.Second)
+	}
+	assert.Equal(t, max, b.(*FullJitterBackoff).duration)
+}
+
 func testUnblockAfterInit(t *testing.T) {
 	init := 1 * time.Second
 	max := 5 * time.Minute
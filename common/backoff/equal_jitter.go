@@ -18,6 +18,7 @@
 package backoff
 
 import (
+	"context"
 	"math/rand"
 	"time"
 )
@@ -53,6 +54,12 @@ func (b *EqualJitterBackoff) Reset() {
 
 // Wait blocks until either the timer is completed or channel is done.
 func (b *EqualJitterBackoff) Wait() bool {
+	return b.WaitContext(context.Background())
+}
+
+// WaitContext blocks until either the timer is completed, the channel is done
+// or the context is canceled.
+func (b *EqualJitterBackoff) WaitContext(ctx context.Context) bool {
 	// Make sure we have always some minimal back off and jitter.
 	temp := int64(b.duration / 2)
 	backoff := time.Duration(temp + rand.Int63n(temp))
@@ -63,13 +70,7 @@ func (b *EqualJitterBackoff) Wait() bool {
 		b.duration = b.max
 	}
 
-	select {
-	case <-b.done:
-		return false
-	case <-time.After(backoff):
-		b.last = time.Now()
-		return true
-	}
+	return waitFor(ctx, b.done, backoff, &b.last)
 }
 
 // Last returns the time when the last call to Wait returned
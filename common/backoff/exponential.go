@@ -18,6 +18,7 @@
 package backoff
 
 import (
+	"context"
 	"time"
 )
 
@@ -51,19 +52,19 @@ func (b *ExpBackoff) Reset() {
 
 // Wait block until either the timer is completed or channel is done.
 func (b *ExpBackoff) Wait() bool {
+	return b.WaitContext(context.Background())
+}
+
+// WaitContext blocks until either the timer is completed, the channel is done
+// or the context is canceled.
+func (b *ExpBackoff) WaitContext(ctx context.Context) bool {
 	backoff := b.duration
 	b.duration *= 2
 	if b.duration > b.max {
 		b.duration = b.max
 	}
 
-	select {
-	case <-b.done:
-		return false
-	case <-time.After(backoff):
-		b.last = time.Now()
-		return true
-	}
+	return waitFor(ctx, b.done, backoff, &b.last)
 }
 
 // Last returns the time when the last call to Wait returned
@@ -0,0 +1,80 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package backoff
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// FullJitterBackoff implements an exponential backoff with full jitter: every
+// wait sleeps for a random duration between zero and the current exponential
+// backoff, which is capped at max. This spreads retries of many clients more
+// evenly than EqualJitterBackoff, at the cost of possibly retrying early.
+type FullJitterBackoff struct {
+	duration time.Duration
+	done     <-chan struct{}
+
+	init time.Duration
+	max  time.Duration
+
+	last time.Time
+}
+
+// NewFullJitterBackoff returns a new FullJitterBackoff object.
+func NewFullJitterBackoff(done <-chan struct{}, init, max time.Duration) Backoff {
+	return &FullJitterBackoff{
+		duration: init,
+		done:     done,
+		init:     init,
+		max:      max,
+	}
+}
+
+// Reset resets the duration of the backoff.
+func (b *FullJitterBackoff) Reset() {
+	b.duration = b.init
+}
+
+// Wait blocks until either the timer is completed or channel is done.
+func (b *FullJitterBackoff) Wait() bool {
+	return b.WaitContext(context.Background())
+}
+
+// WaitContext blocks until either the timer is completed, the channel is done
+// or the context is canceled.
+func (b *FullJitterBackoff) WaitContext(ctx context.Context) bool {
+	var backoff time.Duration
+	if b.duration > 0 {
+		backoff = time.Duration(rand.Int63n(int64(b.duration) + 1))
+	}
+
+	// increase duration for next wait.
+	b.duration *= 2
+	if b.duration > b.max {
+		b.duration = b.max
+	}
+
+	return waitFor(ctx, b.done, backoff, &b.last)
+}
+
+// Last returns the time when the last call to Wait returned
+func (b *FullJitterBackoff) Last() time.Time {
+	return b.last
+}
@@ -28,6 +28,8 @@ import (
 type Decoder struct {
 	prependHyphenToAttr bool
 	lowercaseKeys       bool
+	preserveNamespaces  bool
+	attributesKey       string
 	xmlDec              *xml.Decoder
 }
 
@@ -46,6 +48,17 @@ func (d *Decoder) PrependHyphenToAttr() { d.prependHyphenToAttr = true }
 // LowercaseKeys causes the Decoder to transform all key name to lowercase.
 func (d *Decoder) LowercaseKeys() { d.lowercaseKeys = true }
 
+// PreserveNamespaces causes the Decoder to keep the namespace prefix of
+// element and attribute names (e.g. `soap:Body`). Namespace declarations
+// are kept as `xmlns` and `xmlns:<prefix>` attributes. In this mode the
+// Decoder does not verify that start and end elements match.
+func (d *Decoder) PreserveNamespaces() { d.preserveNamespaces = true }
+
+// AttributesKey causes the Decoder to store the attributes of an element in
+// an object under the given key instead of merging them with the child
+// elements.
+func (d *Decoder) AttributesKey(key string) { d.attributesKey = key }
+
 // Decode reads XML from the input stream and return a map containing the data.
 func (d *Decoder) Decode() (map[string]interface{}, error) {
 	_, m, err := d.decode(nil)
@@ -57,7 +70,7 @@ func (d *Decoder) decode(attrs []xml.Attr) (string, map[string]interface{}, erro
 	var cdata string
 
 	for {
-		t, err := d.xmlDec.Token()
+		t, err := d.token()
 		if err != nil {
 			if err == io.EOF { //nolint:errorlint // io.EOF should never be wrapped and is not by xml.Decoder.
 				return "", elements, nil
@@ -82,7 +95,7 @@ func (d *Decoder) decode(attrs []xml.Attr) (string, map[string]interface{}, erro
 
 			// Add the data to the current object while taking into account
 			// if the current key already exists (in the case of lists).
-			key := d.key(elem.Name.Local)
+			key := d.key(d.name(elem.Name))
 			value := elements[key]
 			switch v := value.(type) {
 			case nil:
@@ -101,9 +114,38 @@ func (d *Decoder) decode(attrs []xml.Attr) (string, map[string]interface{}, erro
 	}
 }
 
+// token returns the next XML token. When namespaces are preserved raw tokens
+// are used, so names keep their prefix instead of the resolved namespace URL.
+func (d *Decoder) token() (xml.Token, error) {
+	if d.preserveNamespaces {
+		return d.xmlDec.RawToken()
+	}
+	return d.xmlDec.Token()
+}
+
+func (d *Decoder) name(n xml.Name) string {
+	if d.preserveNamespaces && n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
 func (d *Decoder) addAttributes(attrs []xml.Attr, m map[string]interface{}) {
+	if len(attrs) == 0 {
+		return
+	}
+
+	if d.attributesKey != "" {
+		attributes := make(map[string]interface{}, len(attrs))
+		for _, attr := range attrs {
+			attributes[d.attrKey(d.name(attr.Name))] = attr.Value
+		}
+		m[d.attributesKey] = attributes
+		return
+	}
+
 	for _, attr := range attrs {
-		key := d.attrKey(attr.Name.Local)
+		key := d.attrKey(d.name(attr.Name))
 		m[key] = attr.Value
 	}
 }
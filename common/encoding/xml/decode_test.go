@@ -89,6 +89,75 @@ func TestPrependHyphenToAttr(t *testing.T) {
 	assert.Equal(t, expected, out)
 }
 
+func TestPreserveNamespaces(t *testing.T) {
+	const xml = `
+<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope" xmlns:m="http://www.example.org">
+  <soap:Body>
+    <m:GetPrice m:currency="EUR">
+      <m:Item>Apples</m:Item>
+    </m:GetPrice>
+  </soap:Body>
+</soap:Envelope>
+`
+
+	expected := map[string]interface{}{
+		"soap:Envelope": map[string]interface{}{
+			"xmlns:soap": "http://www.w3.org/2003/05/soap-envelope",
+			"xmlns:m":    "http://www.example.org",
+			"soap:Body": map[string]interface{}{
+				"m:GetPrice": map[string]interface{}{
+					"m:currency": "EUR",
+					"m:Item":     "Apples",
+				},
+			},
+		},
+	}
+
+	d := NewDecoder(strings.NewReader(xml))
+	d.PreserveNamespaces()
+	out, err := d.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// Without the option the prefixes are dropped.
+	d = NewDecoder(strings.NewReader(xml))
+	out, err = d.Decode()
+	require.NoError(t, err)
+	assert.Contains(t, out, "Envelope")
+}
+
+func TestAttributesKey(t *testing.T) {
+	const xml = `
+<person>
+  <Name ID="123" lang="en">John</Name>
+  <Address ID="456"/>
+</person>
+`
+
+	expected := map[string]interface{}{
+		"person": map[string]interface{}{
+			"Name": map[string]interface{}{
+				"#text": "John",
+				"_attributes": map[string]interface{}{
+					"ID":   "123",
+					"lang": "en",
+				},
+			},
+			"Address": map[string]interface{}{
+				"_attributes": map[string]interface{}{
+					"ID": "456",
+				},
+			},
+		},
+	}
+
+	d := NewDecoder(strings.NewReader(xml))
+	d.AttributesKey("_attributes")
+	out, err := d.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+}
+
 func TestDecodeList(t *testing.T) {
 	const xml = `
 <people>
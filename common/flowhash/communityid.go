@@ -98,6 +98,11 @@ func getRawIP(ip net.IP) []byte {
 	return ip
 }
 
+// icmpV4Equiv and icmpV6Equiv map ICMP types to the type of their
+// counterpart message. They mirror the tables used by Zeek, which is the
+// reference implementation of the spec. Note that the mappings are not
+// fully symmetric: an ICMPv4 information reply and an MLD listener report
+// have no counterpart and are hashed as one-way messages.
 var icmpV4Equiv = map[uint8]uint8{
 	iCMPv4TypeEchoRequest:         iCMPv4TypeEchoReply,
 	iCMPv4TypeEchoReply:           iCMPv4TypeEchoRequest,
@@ -19,6 +19,7 @@ package flowhash
 
 import (
 	"bufio"
+	"crypto"
 	"encoding/binary"
 	"flag"
 	"fmt"
@@ -72,6 +73,110 @@ func TestPCAPFiles(t *testing.T) {
 	}
 }
 
+func TestKnownFlows(t *testing.T) {
+	seeded := NewCommunityID(1, Base64Encoding, crypto.SHA1)
+
+	for _, tc := range []struct {
+		name   string
+		flow   Flow
+		id     string
+		seeded string
+	}{
+		{
+			name:   "tcp",
+			flow:   portFlow("128.232.110.120", "66.35.250.204", iPProtoTCP, 34855, 80),
+			id:     "1:LQU9qZlK+B5F3KDmev6m5PMibrg=",
+			seeded: "1:3V71V58M3Ksw/yuFALMcW0LAHvc=",
+		},
+		{
+			name:   "udp",
+			flow:   portFlow("192.168.1.52", "8.8.8.8", iPProtoUDP, 54585, 53),
+			id:     "1:d/FP5EW3wiY1vCndhwleRRKHowQ=",
+			seeded: "1:Q9We8WO3piVF8yEQBNJF4uiSVrI=",
+		},
+		{
+			name:   "sctp",
+			flow:   portFlow("192.168.170.8", "192.168.170.56", iPProtoSCTP, 7, 7),
+			id:     "1:MP2EtRCAUIZvTw6MxJHLV7N7JDs=",
+			seeded: "1:5w3ZRjeKBzqT3zsaEECDGjUbYeg=",
+		},
+		{
+			name:   "tcp over ipv6",
+			flow:   portFlow("2001:470:e5bf:dead:4957:2174:e82c:4887", "2607:f8b0:400c:c03::1a", iPProtoTCP, 63943, 25),
+			id:     "1:/qFaeAR+gFe1KYjMzVDsMv+wgU4=",
+			seeded: "1:eJYTW6AOzFLlSUoESovMjnu7rMw=",
+		},
+		{
+			name:   "icmp echo request",
+			flow:   icmpFlow("192.168.0.89", "192.168.0.1", iPProtoICMPv4, iCMPv4TypeEchoRequest, 0),
+			id:     "1:X0snYXpgwiv9TZtqg64sgzUn6Dk=",
+			seeded: "1:03g6IloqVBdcZlPyX8r0hgoE7kA=",
+		},
+		{
+			name:   "icmp echo reply",
+			flow:   icmpFlow("192.168.0.1", "192.168.0.89", iPProtoICMPv4, iCMPv4TypeEchoReply, 0),
+			id:     "1:X0snYXpgwiv9TZtqg64sgzUn6Dk=",
+			seeded: "1:03g6IloqVBdcZlPyX8r0hgoE7kA=",
+		},
+		{
+			name:   "icmpv6 echo request",
+			flow:   icmpFlow("3ffe:507::1:200:86ff:fe05:80da", "3ffe:507::1:260:97ff:fe07:69ea", iPProtoICMPv6, iCMPv6TypeEchoRequest, 0),
+			id:     "1:GpbEQrKqfWtsfsFiqg8fufoZe5Y=",
+			seeded: "1:60oZCv246WZ5lAat44dGtcIajfc=",
+		},
+		{
+			name:   "icmpv6 echo reply",
+			flow:   icmpFlow("3ffe:507::1:260:97ff:fe07:69ea", "3ffe:507::1:200:86ff:fe05:80da", iPProtoICMPv6, iCMPv6TypeEchoReply, 0),
+			id:     "1:GpbEQrKqfWtsfsFiqg8fufoZe5Y=",
+			seeded: "1:60oZCv246WZ5lAat44dGtcIajfc=",
+		},
+		{
+			name:   "icmpv6 neighbor solicitation",
+			flow:   icmpFlow("fe80::200:86ff:fe05:80da", "fe80::260:97ff:fe07:69ea", iPProtoICMPv6, iCMPv6TypeNeighborSolicitation, 0),
+			id:     "1:dGHyGvjMfljg6Bppwm3bg0LO8TY=",
+			seeded: "1:kHa1FhMYIT6Ym2Vm2AOtoOARDzY=",
+		},
+		{
+			name:   "icmpv6 neighbor advertisement",
+			flow:   icmpFlow("fe80::260:97ff:fe07:69ea", "fe80::200:86ff:fe05:80da", iPProtoICMPv6, iCMPv6TypeNeighborAdvertisement, 0),
+			id:     "1:dGHyGvjMfljg6Bppwm3bg0LO8TY=",
+			seeded: "1:kHa1FhMYIT6Ym2Vm2AOtoOARDzY=",
+		},
+		{
+			name:   "icmpv6 destination unreachable",
+			flow:   icmpFlow("3ffe:501:410::2c0:dfff:fe47:33e", "3ffe:507::1:200:86ff:fe05:80da", iPProtoICMPv6, 1, 4),
+			id:     "1:hLZd0XGWojozrvxqE0dWB1iM6R0=",
+			seeded: "1:W8MHwXpWzpoSTD+cGcaJr4u0ys8=",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.id, CommunityID.Hash(tc.flow))
+			assert.Equal(t, tc.seeded, seeded.Hash(tc.flow))
+		})
+	}
+}
+
+func portFlow(src, dst string, proto uint8, srcPort, dstPort uint16) Flow {
+	return Flow{
+		SourceIP:        net.ParseIP(src),
+		DestinationIP:   net.ParseIP(dst),
+		Protocol:        proto,
+		SourcePort:      srcPort,
+		DestinationPort: dstPort,
+	}
+}
+
+func icmpFlow(src, dst string, proto, typ, code uint8) Flow {
+	flow := Flow{
+		SourceIP:      net.ParseIP(src),
+		DestinationIP: net.ParseIP(dst),
+		Protocol:      proto,
+	}
+	flow.ICMP.Type = typ
+	flow.ICMP.Code = code
+	return flow
+}
+
 func readGoldenFile(t testing.TB, name string) []string {
 	file, err := os.Open(name)
 	if err != nil {
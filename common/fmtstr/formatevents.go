@@ -40,7 +40,15 @@ import (
 // multiple braces. This format `%{[field.name]}` is equivalent to `%{[field][name]}`.
 //
 // Default values are given defined by the colon operator. For example:
-// `%{[field.name]:default value}`.
+// `%{[field.name]:default value}`. The default value is used if the field is
+// missing, empty, or can not be converted to a string. Fields with a default
+// value are not reported by Fields.
+//
+// Verbs transforming the expanded value can be appended to an expression,
+// separated by the pipe character. For example `%{[service.name] | lower}`
+// or `%{+yyyy.MM.dd | utc}`. The `lower` and `upper` verbs change the case of
+// the value, including default values, and the `utc` and `local` verbs
+// convert the timestamp to UTC or local time before formatting.
 type EventFormatString struct {
 	expression string
 	formatter  StringFormatter
@@ -50,17 +58,27 @@ type EventFormatString struct {
 
 type eventFieldEvaler struct {
 	index int
+	verbs []stringVerb
 }
 
 type defaultEventFieldEvaler struct {
 	index        int
 	defaultValue string
+	verbs        []stringVerb
 }
 
 type eventTimestampEvaler struct {
 	formatter *dtfmt.Formatter
+	location  timeVerb
+	verbs     []stringVerb
 }
 
+// stringVerb transforms an expanded value.
+type stringVerb func(string) string
+
+// timeVerb transforms the timestamp before it is formatted.
+type timeVerb func(time.Time) time.Time
+
 type eventFieldCompiler struct {
 	keys      map[string]keyInfo
 	timestamp bool
@@ -87,6 +105,16 @@ var (
 	errConvertString = errors.New("can not convert to string")
 )
 
+var stringVerbs = map[string]stringVerb{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+var timeVerbs = map[string]timeVerb{
+	"utc":   time.Time.UTC,
+	"local": time.Time.Local,
+}
+
 var eventCtxPool = &sync.Pool{
 	New: func() interface{} { return &eventEvalContext{} },
 }
@@ -191,6 +219,17 @@ func (fs *EventFormatString) Fields() []string {
 	return fields
 }
 
+// ReferencedFields returns the list of unique event fields referenced by the
+// format string, including fields that have a default value. Fields are
+// returned in the order they first appear in the expression.
+func (fs *EventFormatString) ReferencedFields() []string {
+	fields := make([]string, len(fs.fields))
+	for i, fi := range fs.fields {
+		fields[i] = fi.path
+	}
+	return fields
+}
+
 // Run executes the format string returning a new expanded string or an error
 // if execution or event field expansion fails.
 func (fs *EventFormatString) Run(event *beat.Event) (string, error) {
@@ -284,15 +323,19 @@ func (e *eventFieldCompiler) compileExpression(
 	s string,
 	opts []VariableOp,
 ) (FormatEvaler, error) {
+	s, verbs, err := splitVerbs(s)
+	if err != nil {
+		return nil, err
+	}
 	if len(s) == 0 {
 		return nil, errors.New("empty expression")
 	}
 
 	switch s[0] {
 	case '[':
-		return e.compileEventField(s, opts)
+		return e.compileEventField(s, verbs, opts)
 	case '+':
-		return e.compileTimestamp(s, opts)
+		return e.compileTimestamp(s, verbs, opts)
 	default:
 		return nil, fmt.Errorf(`unsupported format expression "%v"`, s)
 	}
@@ -300,12 +343,21 @@ func (e *eventFieldCompiler) compileExpression(
 
 func (e *eventFieldCompiler) compileEventField(
 	field string,
+	verbNames []string,
 	ops []VariableOp,
 ) (FormatEvaler, error) {
 	if len(ops) > 1 {
 		return nil, errors.New("too many format modifiers given")
 	}
 
+	verbs, location, err := compileVerbs(verbNames)
+	if err != nil {
+		return nil, err
+	}
+	if location != nil {
+		return nil, errors.New("time zone verbs can only be applied to timestamps")
+	}
+
 	defaultValue := ""
 	if len(ops) == 1 {
 		op := ops[0]
@@ -336,32 +388,86 @@ func (e *eventFieldCompiler) compileEventField(
 	idx := info.index
 
 	if len(ops) == 0 {
-		return &eventFieldEvaler{idx}, nil
+		return &eventFieldEvaler{idx, verbs}, nil
 	}
 
-	return &defaultEventFieldEvaler{idx, defaultValue}, nil
+	return &defaultEventFieldEvaler{idx, defaultValue, verbs}, nil
 }
 
 func (e *eventFieldCompiler) compileTimestamp(
 	expression string,
+	verbNames []string,
 	ops []VariableOp,
 ) (FormatEvaler, error) {
 	if expression[0] != '+' {
 		return nil, errors.New("no timestamp expression")
 	}
 
+	verbs, location, err := compileVerbs(verbNames)
+	if err != nil {
+		return nil, err
+	}
+
 	formatter, err := dtfmt.NewFormatter(expression[1:])
 	if err != nil {
 		return nil, fmt.Errorf("%w in timestamp expression", err)
 	}
 
 	e.timestamp = true
-	return &eventTimestampEvaler{formatter}, nil
+	return &eventTimestampEvaler{formatter, location, verbs}, nil
+}
+
+// splitVerbs splits an expression like `[field] | lower` into the expression
+// and the names of the verbs applied to its value.
+func splitVerbs(s string) (string, []string, error) {
+	parts := strings.Split(s, "|")
+	if len(parts) == 1 {
+		return s, nil, nil
+	}
+
+	verbs := make([]string, 0, len(parts)-1)
+	for _, verb := range parts[1:] {
+		verb = strings.TrimSpace(verb)
+		if verb == "" {
+			return "", nil, fmt.Errorf(`empty verb in format expression "%v"`, s)
+		}
+		verbs = append(verbs, verb)
+	}
+	return strings.TrimSpace(parts[0]), verbs, nil
+}
+
+// compileVerbs looks up the verbs by name. At most one time zone verb can be
+// given.
+func compileVerbs(names []string) ([]stringVerb, timeVerb, error) {
+	var verbs []stringVerb
+	var location timeVerb
+	for _, name := range names {
+		if verb, ok := stringVerbs[name]; ok {
+			verbs = append(verbs, verb)
+			continue
+		}
+		if verb, ok := timeVerbs[name]; ok {
+			if location != nil {
+				return nil, nil, errors.New("too many time zone verbs given")
+			}
+			location = verb
+			continue
+		}
+		return nil, nil, fmt.Errorf("unknown format verb: %v", name)
+	}
+	return verbs, location, nil
+}
+
+func applyVerbs(s string, verbs []stringVerb) string {
+	for _, verb := range verbs {
+		s = verb(s)
+	}
+	return s
 }
 
 func (e *eventFieldEvaler) Eval(c interface{}, out *bytes.Buffer) error {
 	ctx := c.(*eventEvalContext)
-	s := ctx.keys[e.index]
+	s := applyVerbs(ctx.keys[e.index], e.verbs)
 	_, err := out.WriteString(s)
 	return err
 }
@@ -372,13 +478,26 @@ func (e *defaultEventFieldEvaler) Eval(c interface{}, out *bytes.Buffer) error {
 	if s == "" {
 		s = e.defaultValue
 	}
-	_, err := out.WriteString(s)
+	_, err := out.WriteString(applyVerbs(s, e.verbs))
 	return err
 }
 
 func (e *eventTimestampEvaler) Eval(c interface{}, out *bytes.Buffer) error {
 	ctx := c.(*eventEvalContext)
-	_, err := e.formatter.Write(out, ctx.ts)
+	ts := ctx.ts
+	if e.location != nil {
+		ts = e.location(ts)
+	}
+	if len(e.verbs) == 0 {
+		_, err := e.formatter.Write(out, ts)
+		return err
+	}
+
+	s, err := e.formatter.Format(ts)
+	if err != nil {
+		return err
+	}
+	_, err = out.WriteString(applyVerbs(s, e.verbs))
 	return err
 }
 
@@ -64,6 +64,72 @@ func TestEventFormatString(t *testing.T) {
 			"default",
 			nil,
 		},
+		{
+			"expand with default on empty field",
+			"%{[key]:default}",
+			beat.Event{Fields: mapstr.M{"key": ""}},
+			"default",
+			nil,
+		},
+		{
+			"expand with empty default",
+			"%{[key]:}-suffix",
+			beat.Event{Fields: mapstr.M{}},
+			"-suffix",
+			nil,
+		},
+		{
+			"expand with default containing spaces",
+			"%{[key]:default value}",
+			beat.Event{Fields: mapstr.M{}},
+			"default value",
+			nil,
+		},
+		{
+			"expand nested field with default",
+			"%{[service.name]:unknown}",
+			beat.Event{Fields: mapstr.M{"service": mapstr.M{"name": "api"}}},
+			"api",
+			nil,
+		},
+		{
+			"expand missing nested field with default",
+			"%{[service][name]:unknown}",
+			beat.Event{Fields: mapstr.M{"service": mapstr.M{}}},
+			"unknown",
+			nil,
+		},
+		{
+			"expand missing parent of nested field with default",
+			"%{[service.name]:unknown}",
+			beat.Event{Fields: mapstr.M{}},
+			"unknown",
+			nil,
+		},
+		{
+			"expand numeric field with default",
+			"%{[count]:none}-%{[ratio]:none}",
+			beat.Event{Fields: mapstr.M{"count": 0, "ratio": 0.5}},
+			"0-0.5",
+			nil,
+		},
+		{
+			"expand missing numeric field with numeric default",
+			"shard-%{[shard]:0}",
+			beat.Event{Fields: mapstr.M{}},
+			"shard-0",
+			nil,
+		},
+		{
+			"expand mixed required fields and defaults",
+			"%{[key]}-%{[service.name]:unknown}-%{+yyyy}",
+			beat.Event{
+				Timestamp: time.Date(2015, 5, 1, 20, 12, 34, 0, time.UTC),
+				Fields:    mapstr.M{"key": "value"},
+			},
+			"value-unknown-2015",
+			[]string{"key"},
+		},
 		{
 			"expand nested event field",
 			"%{[nested.key]}",
@@ -281,3 +347,124 @@ func TestIsEmpty(t *testing.T) {
 	})
 
 }
+
+func TestReferencedFields(t *testing.T) {
+	tests := []struct {
+		title    string
+		format   string
+		expected []string
+	}{
+		{"no fields", "plain string", []string{}},
+		{"timestamp only", "index-%{+yyyy.MM.dd}", []string{}},
+		{"single field", "%{[key]}", []string{"key"}},
+		{"dotted field", "%{[agent.name]}", []string{"agent.name"}},
+		{"nested field", "%{[agent][name]}", []string{"agent.name"}},
+		{"field with default", "%{[key]:default}", []string{"key"}},
+		{
+			"multiple fields and timestamp",
+			"%{[agent.name]}-%{[data_stream][namespace]:default}-%{+yyyy}-%{[agent][name]}",
+			[]string{"agent.name", "data_stream.namespace"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			fs, err := CompileEvent(test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expected, fs.ReferencedFields())
+		})
+	}
+}
+
+func TestEventFormatStringVerbs(t *testing.T) {
+	ts := time.Date(2015, 5, 1, 22, 30, 0, 0, time.FixedZone("UTC-3", -3*60*60))
+
+	tests := []struct {
+		title    string
+		format   string
+		event    beat.Event
+		expected string
+	}{
+		{
+			"lower",
+			"%{[service.name] | lower}",
+			beat.Event{Fields: mapstr.M{"service": mapstr.M{"name": "MyService"}}},
+			"myservice",
+		},
+		{
+			"upper",
+			"%{[service.name]|upper}",
+			beat.Event{Fields: mapstr.M{"service": mapstr.M{"name": "MyService"}}},
+			"MYSERVICE",
+		},
+		{
+			"verbs applied in order",
+			"%{[key] | upper | lower}",
+			beat.Event{Fields: mapstr.M{"key": "Value"}},
+			"value",
+		},
+		{
+			"verb applied to default value",
+			"%{[service.name] | lower:Unknown}",
+			beat.Event{Fields: mapstr.M{}},
+			"unknown",
+		},
+		{
+			"timestamp in event time zone",
+			"%{+yyyy.MM.dd}",
+			beat.Event{Timestamp: ts},
+			"2015.05.01",
+		},
+		{
+			"timestamp converted to utc",
+			"%{+yyyy.MM.dd | utc}",
+			beat.Event{Timestamp: ts},
+			"2015.05.02",
+		},
+		{
+			"timestamp with case verb",
+			"%{+yyyy.MMM.dd | utc | lower}",
+			beat.Event{Timestamp: ts},
+			"2015.may.02",
+		},
+		{
+			"mixed expressions",
+			"%{[agent.name] | lower}-%{+yyyy.MM.dd | utc}",
+			beat.Event{Timestamp: ts, Fields: mapstr.M{"agent": mapstr.M{"name": "Filebeat"}}},
+			"filebeat-2015.05.02",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			fs, err := CompileEvent(test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := fs.Run(&test.event)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestEventFormatStringVerbErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown verb":            "%{[key] | title}",
+		"empty verb":              "%{[key] | }",
+		"time zone verb on field": "%{[key] | utc}",
+		"too many time zones":     "%{+yyyy | utc | local}",
+		"unknown timestamp verb":  "%{+yyyy | bogus}",
+		"missing expression":      "%{ | lower}",
+	}
+
+	for title, format := range tests {
+		t.Run(title, func(t *testing.T) {
+			_, err := CompileEvent(format)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -81,6 +81,12 @@ func (fs *TimestampFormatString) RunEvent(event *beat.Event) (string, error) {
 	return fs.eventFormatString.Run(event)
 }
 
+// ReferencedFields returns the list of unique event fields referenced by the
+// format string.
+func (fs *TimestampFormatString) ReferencedFields() []string {
+	return fs.eventFormatString.ReferencedFields()
+}
+
 func (fs *TimestampFormatString) String() string {
 	return fs.eventFormatString.expression
 }
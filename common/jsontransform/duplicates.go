@@ -0,0 +1,114 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jsontransform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/njcx/libbeat_v8/beat"
+)
+
+// FindDuplicateKeys scans the raw JSON document in data and returns the
+// dotted path of every object key that appears more than once within the
+// same object. Go's decoder keeps the last value for such keys, so
+// callers can use this to detect the values that were silently dropped.
+// Array elements are addressed by their index in the returned paths.
+func FindDuplicateKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var dups []string
+	if err := scanDuplicates(dec, "", &dups); err != nil {
+		return nil, err
+	}
+	return dups, nil
+}
+
+// WriteDuplicateKeyErrors records the keys in dups under the event's
+// error key if addErrKey is set. Nothing is written if dups is empty.
+func WriteDuplicateKeyErrors(event *beat.Event, dups []string, addErrKey bool) {
+	WriteDuplicateKeyErrorsWithOptions(event, dups, Options{AddErrorKey: addErrKey})
+}
+
+// WriteDuplicateKeyErrorsWithOptions is like WriteDuplicateKeyErrors, writing
+// the error as configured by the AddErrorKey and ErrorTarget options.
+func WriteDuplicateKeyErrorsWithOptions(event *beat.Event, dups []string, opts Options) {
+	if len(dups) == 0 {
+		return
+	}
+	SetError(event, opts, fmt.Sprintf("duplicate JSON keys found, only the last value was kept: %s", strings.Join(dups, ", ")), "", "")
+}
+
+func scanDuplicates(dec *json.Decoder, path string, dups *[]string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		seen := map[string]struct{}{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return fmt.Errorf("unexpected object key %v", keyTok)
+			}
+			if _, exists := seen[key]; exists {
+				*dups = append(*dups, joinPath(path, key))
+			}
+			seen[key] = struct{}{}
+
+			if err := scanDuplicates(dec, joinPath(path, key), dups); err != nil {
+				return err
+			}
+		}
+		// consume closing '}'
+		_, err = dec.Token()
+		return err
+
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := scanDuplicates(dec, joinPath(path, fmt.Sprint(i)), dups); err != nil {
+				return err
+			}
+		}
+		// consume closing ']'
+		_, err = dec.Token()
+		return err
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jsontransform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestFindDuplicateKeys(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		expected []string
+	}{
+		"no duplicates": {
+			data: `{"a": 1, "b": {"c": 2}}`,
+		},
+		"top level": {
+			data:     `{"a": 1, "a": 2}`,
+			expected: []string{"a"},
+		},
+		"nested": {
+			data:     `{"a": {"b": 1, "b": {"c": 1}}}`,
+			expected: []string{"a.b"},
+		},
+		"inside arrays": {
+			data:     `{"list": [{"x": 1}, {"x": 1, "x": 2}]}`,
+			expected: []string{"list.1.x"},
+		},
+		"same key in different objects": {
+			data: `{"a": {"k": 1}, "b": {"k": 1}}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dups, err := FindDuplicateKeys([]byte(test.data))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, dups)
+		})
+	}
+}
+
+func TestFindDuplicateKeysInvalidJSON(t *testing.T) {
+	_, err := FindDuplicateKeys([]byte(`{"a": `))
+	assert.Error(t, err)
+}
+
+func TestWriteDuplicateKeyErrors(t *testing.T) {
+	event := &beat.Event{Fields: mapstr.M{}}
+	WriteDuplicateKeyErrors(event, nil, true)
+	assert.Equal(t, mapstr.M{}, event.Fields)
+
+	WriteDuplicateKeyErrors(event, []string{"a", "b.c"}, false)
+	assert.Equal(t, mapstr.M{}, event.Fields)
+
+	WriteDuplicateKeyErrors(event, []string{"a", "b.c"}, true)
+	assert.Equal(t, mapstr.M{
+		"error": mapstr.M{
+			"message": "duplicate JSON keys found, only the last value was kept: a, b.c",
+			"type":    "json",
+		},
+	}, event.Fields)
+}
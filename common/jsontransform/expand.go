@@ -32,9 +32,15 @@ import (
 // is a non-object), an error key is added to the event if add_error_key
 // is enabled.
 func ExpandFields(logger *logp.Logger, event *beat.Event, m mapstr.M, addErrorKey bool) {
+	ExpandFieldsWithOptions(logger, event, m, Options{AddErrorKey: addErrorKey})
+}
+
+// ExpandFieldsWithOptions is like ExpandFields, writing errors as configured
+// by the AddErrorKey and ErrorTarget options.
+func ExpandFieldsWithOptions(logger *logp.Logger, event *beat.Event, m mapstr.M, opts Options) {
 	if err := expandFields(m); err != nil {
 		logger.Errorf("JSON: failed to expand fields: %s", err)
-		event.SetErrorWithOption(err.Error(), addErrorKey, "", "")
+		SetError(event, opts, err.Error(), "", "")
 	}
 }
 
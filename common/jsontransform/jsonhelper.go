@@ -18,8 +18,10 @@
 package jsontransform
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/njcx/libbeat_v8/beat"
@@ -28,23 +30,75 @@ import (
 
 const (
 	iso8601 = "2006-01-02T15:04:05.000Z0700"
+
+	// TimestampEpochMillis can be used as a timestamp layout to parse
+	// @timestamp values given as milliseconds since the Unix epoch.
+	TimestampEpochMillis = "epoch_ms"
+
+	// TimestampEpochSeconds can be used as a timestamp layout to parse
+	// @timestamp values given as seconds since the Unix epoch.
+	TimestampEpochSeconds = "epoch_s"
 )
 
 var (
 	// ErrInvalidTimestamp is returned when parsing of a @timestamp field fails.
 	// Supported formats: ISO8601, RFC3339
 	ErrInvalidTimestamp = errors.New("failed to parse @timestamp, unknown format")
+
+	errTimestampNotString = errors.New("@timestamp is not a string")
+
+	defaultTimestampLayouts = []string{
+		time.RFC3339,
+		iso8601,
+	}
 )
 
-// WriteJSONKeys writes the json keys to the given event based on the overwriteKeys option and the addErrKey
+// Options configures how WriteJSONKeysWithOptions merges decoded keys into
+// an event.
+type Options struct {
+	ExpandKeys    bool
+	OverwriteKeys bool
+	AddErrorKey   bool
+
+	// ErrorTarget is the key errors are written to if AddErrorKey is set,
+	// for example `json.error`. If empty, errors are written to the
+	// event's error key, replacing any existing error object.
+	ErrorTarget string
+
+	// DropNull drops keys with a JSON null value, including the keys of
+	// nested objects. If false, they are written to the event as explicit
+	// null fields.
+	DropNull bool
+
+	// TimestampLayouts lists the layouts tried, in order, when parsing the
+	// @timestamp key. Besides time.Parse layouts, TimestampEpochMillis and
+	// TimestampEpochSeconds are accepted for numeric epoch values. If empty,
+	// RFC3339 and ISO8601 are tried.
+	TimestampLayouts []string
+}
+
+// WriteJSONKeys writes the json keys to the given event based on the overwriteKeys option and the addErrKey.
+// Keys with a null value are kept.
 func WriteJSONKeys(event *beat.Event, keys map[string]interface{}, expandKeys, overwriteKeys, addErrKey bool) {
-	if expandKeys {
+	WriteJSONKeysWithOptions(event, keys, Options{
+		ExpandKeys:    expandKeys,
+		OverwriteKeys: overwriteKeys,
+		AddErrorKey:   addErrKey,
+	})
+}
+
+// WriteJSONKeysWithOptions writes the json keys to the given event based on opts.
+func WriteJSONKeysWithOptions(event *beat.Event, keys map[string]interface{}, opts Options) {
+	if opts.DropNull {
+		RemoveNulls(keys)
+	}
+	if opts.ExpandKeys {
 		if err := expandFields(keys); err != nil {
-			event.SetErrorWithOption(err.Error(), addErrKey, "", "")
+			SetError(event, opts, err.Error(), "", "")
 			return
 		}
 	}
-	if !overwriteKeys {
+	if !opts.OverwriteKeys {
 		// @timestamp and @metadata fields are root-level fields. We remove them so they
 		// don't become part of event.Fields.
 		removeKeys(keys, "@timestamp", "@metadata")
@@ -57,16 +111,15 @@ func WriteJSONKeys(event *beat.Event, keys map[string]interface{}, expandKeys, o
 	for k, v := range keys {
 		switch k {
 		case "@timestamp":
-			vstr, ok := v.(string)
-			if !ok {
-				event.SetErrorWithOption("@timestamp not overwritten (not string)", addErrKey, "", "")
+			// @timestamp must match one of the configured layouts,
+			// RFC3339 or ISO8601 by default.
+			ts, err := parseTimestamp(v, opts.TimestampLayouts)
+			if errors.Is(err, errTimestampNotString) {
+				SetError(event, opts, "@timestamp not overwritten (not string)", "", "")
 				continue
 			}
-
-			// @timestamp must be of format RFC3339 or ISO8601
-			ts, err := parseTimestamp(vstr)
 			if err != nil {
-				event.SetErrorWithOption(fmt.Sprintf("@timestamp not overwritten (parse error on %s)", vstr), addErrKey, "", "")
+				SetError(event, opts, fmt.Sprintf("@timestamp not overwritten (parse error on %v)", v), "", "")
 				continue
 			}
 			event.Timestamp = ts
@@ -88,17 +141,17 @@ func WriteJSONKeys(event *beat.Event, keys map[string]interface{}, expandKeys, o
 				event.Meta.DeepUpdate(mapstr.M(m))
 
 			default:
-				event.SetErrorWithOption("failed to update @metadata", addErrKey, "", "")
+				SetError(event, opts, "failed to update @metadata", "", "")
 			}
 
 		case "type":
 			vstr, ok := v.(string)
 			if !ok {
-				event.SetErrorWithOption("type not overwritten (not string)", addErrKey, "", "")
+				SetError(event, opts, "type not overwritten (not string)", "", "")
 				continue
 			}
 			if len(vstr) == 0 || vstr[0] == '_' {
-				event.SetErrorWithOption(fmt.Sprintf("type not overwritten (invalid value [%s])", vstr), addErrKey, "", "")
+				SetError(event, opts, fmt.Sprintf("type not overwritten (invalid value [%s])", vstr), "", "")
 				continue
 			}
 			event.Fields[k] = vstr
@@ -111,26 +164,138 @@ func WriteJSONKeys(event *beat.Event, keys map[string]interface{}, expandKeys, o
 	event.Fields.DeepUpdate(keys)
 }
 
+// SetError records a JSON error in the event if opts.AddErrorKey is set. The
+// error is written to opts.ErrorTarget, or to the event's error key if no
+// target is configured.
+func SetError(event *beat.Event, opts Options, message, data, field string) {
+	if opts.ErrorTarget == "" {
+		event.SetErrorWithOption(message, opts.AddErrorKey, data, field)
+		return
+	}
+	if !opts.AddErrorKey {
+		return
+	}
+
+	errorField := mapstr.M{"message": message, "type": "json"}
+	if data != "" {
+		errorField["data"] = data
+	}
+	if field != "" {
+		errorField["field"] = field
+	}
+	_, _ = event.PutValue(opts.ErrorTarget, errorField)
+}
+
+// RemoveNulls deletes the keys with a null value from the objects in v,
+// recursively. Null array elements are kept so the other elements keep
+// their position, and objects left empty are kept as well.
+func RemoveNulls(v interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		removeNullKeys(t)
+	case mapstr.M:
+		removeNullKeys(t)
+	case []interface{}:
+		for _, elem := range t {
+			RemoveNulls(elem)
+		}
+	}
+}
+
+func removeNullKeys(m map[string]interface{}) {
+	for k, v := range m {
+		if v == nil {
+			delete(m, k)
+			continue
+		}
+		RemoveNulls(v)
+	}
+}
+
 func removeKeys(keys map[string]interface{}, names ...string) {
 	for _, name := range names {
 		delete(keys, name)
 	}
 }
 
-func parseTimestamp(timestamp string) (time.Time, error) {
-	validFormats := []string{
-		time.RFC3339,
-		iso8601,
+// parseTimestamp tries each layout in order and returns the first
+// successfully parsed time. Non-string values are only accepted if one of
+// the epoch layouts is configured.
+func parseTimestamp(v interface{}, layouts []string) (time.Time, error) {
+	if len(layouts) == 0 {
+		layouts = defaultTimestampLayouts
 	}
 
-	for _, f := range validFormats {
-		ts, parseErr := time.Parse(f, timestamp)
-		if parseErr != nil {
-			continue
-		}
+	vstr, isString := v.(string)
+	if !isString && !hasEpochLayout(layouts) {
+		return time.Time{}, errTimestampNotString
+	}
 
-		return ts, nil
+	for _, layout := range layouts {
+		switch layout {
+		case TimestampEpochMillis, TimestampEpochSeconds:
+			ts, ok := parseEpoch(v, layout == TimestampEpochMillis)
+			if ok {
+				return ts, nil
+			}
+		default:
+			if !isString {
+				continue
+			}
+			ts, parseErr := time.Parse(layout, vstr)
+			if parseErr != nil {
+				continue
+			}
+			return ts, nil
+		}
 	}
 
 	return time.Time{}, ErrInvalidTimestamp
 }
+
+func hasEpochLayout(layouts []string) bool {
+	for _, layout := range layouts {
+		if layout == TimestampEpochMillis || layout == TimestampEpochSeconds {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEpoch converts a numeric (or numeric string) epoch value in
+// seconds or milliseconds into a UTC time.
+func parseEpoch(v interface{}, millis bool) (time.Time, bool) {
+	switch n := v.(type) {
+	case int64:
+		return epochFromInt(n, millis), true
+	case int:
+		return epochFromInt(int64(n), millis), true
+	case float64:
+		return epochFromFloat(n, millis), true
+	case json.Number:
+		return parseEpoch(n.String(), millis)
+	case string:
+		if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+			return epochFromInt(i, millis), true
+		}
+		if f, err := strconv.ParseFloat(n, 64); err == nil {
+			return epochFromFloat(f, millis), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func epochFromInt(n int64, millis bool) time.Time {
+	if millis {
+		return time.UnixMilli(n).UTC()
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+func epochFromFloat(f float64, millis bool) time.Time {
+	unit := float64(time.Second)
+	if millis {
+		unit = float64(time.Millisecond)
+	}
+	return time.Unix(0, int64(f*unit)).UTC()
+}
@@ -18,6 +18,7 @@
 package jsontransform
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -237,6 +238,174 @@ func TestWriteJSONKeys(t *testing.T) {
 	}
 }
 
+func TestWriteJSONKeysTimestampLayouts(t *testing.T) {
+	eventTimestamp := time.Date(2020, 01, 01, 01, 01, 00, 0, time.UTC)
+	expected := time.Date(2021, 03, 04, 05, 06, 07, 0, time.UTC)
+
+	tests := map[string]struct {
+		timestamp         interface{}
+		layouts           []string
+		expectedTimestamp time.Time
+		expectedError     string
+	}{
+		"default layouts": {
+			timestamp:         expected.Format(time.RFC3339),
+			expectedTimestamp: expected,
+		},
+		"custom layout": {
+			timestamp:         expected.Format("02/01/2006 15:04:05"),
+			layouts:           []string{time.RFC3339, "02/01/2006 15:04:05"},
+			expectedTimestamp: expected,
+		},
+		"epoch_ms number": {
+			timestamp:         expected.UnixMilli(),
+			layouts:           []string{TimestampEpochMillis},
+			expectedTimestamp: expected,
+		},
+		"epoch_s string": {
+			timestamp:         fmt.Sprint(expected.Unix()),
+			layouts:           []string{time.RFC3339, TimestampEpochSeconds},
+			expectedTimestamp: expected,
+		},
+		"number without epoch layout": {
+			timestamp:         expected.UnixMilli(),
+			expectedTimestamp: eventTimestamp,
+			expectedError:     "@timestamp not overwritten (not string)",
+		},
+		"no layout matches": {
+			timestamp:         "yesterday",
+			layouts:           []string{time.RFC3339, TimestampEpochMillis},
+			expectedTimestamp: eventTimestamp,
+			expectedError:     "@timestamp not overwritten (parse error on yesterday)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			event := &beat.Event{
+				Timestamp: eventTimestamp,
+				Fields:    mapstr.M{},
+			}
+
+			WriteJSONKeysWithOptions(event, map[string]interface{}{"@timestamp": test.timestamp}, Options{
+				OverwriteKeys:    true,
+				AddErrorKey:      true,
+				TimestampLayouts: test.layouts,
+			})
+			require.Equal(t, test.expectedTimestamp.UnixNano(), event.Timestamp.UnixNano())
+
+			if test.expectedError == "" {
+				require.NotContains(t, event.Fields, "error")
+				return
+			}
+			msg, err := event.Fields.GetValue("error.message")
+			require.NoError(t, err)
+			require.Equal(t, test.expectedError, msg)
+		})
+	}
+}
+
+func TestWriteJSONKeysErrorTarget(t *testing.T) {
+	event := &beat.Event{
+		Fields: mapstr.M{"error": mapstr.M{"message": "application error"}},
+	}
+
+	WriteJSONKeysWithOptions(event, map[string]interface{}{"type": 1}, Options{
+		OverwriteKeys: true,
+		AddErrorKey:   true,
+		ErrorTarget:   "json.error",
+	})
+
+	expected := mapstr.M{
+		"error": mapstr.M{"message": "application error"},
+		"json": mapstr.M{
+			"error": mapstr.M{"message": "type not overwritten (not string)", "type": "json"},
+		},
+	}
+	require.Equal(t, expected, event.Fields)
+}
+
+func TestWriteJSONKeysDropNull(t *testing.T) {
+	tests := map[string]struct {
+		dropNull      bool
+		expandKeys    bool
+		overwriteKeys bool
+		expected      mapstr.M
+	}{
+		"keep null": {
+			overwriteKeys: true,
+			expected: mapstr.M{
+				"existing": nil,
+				"a":        nil,
+				"b.c":      nil,
+				"d": mapstr.M{
+					"e": nil,
+					"f": mapstr.M{"g": nil, "h": 1},
+				},
+				"list": []interface{}{nil, map[string]interface{}{"i": nil}},
+			},
+		},
+		"drop null": {
+			dropNull:      true,
+			overwriteKeys: true,
+			expected: mapstr.M{
+				"existing": "value",
+				"d": mapstr.M{
+					"f": mapstr.M{"h": 1},
+				},
+				"list": []interface{}{nil, map[string]interface{}{}},
+			},
+		},
+		"drop null with expanded keys": {
+			dropNull:   true,
+			expandKeys: true,
+			expected: mapstr.M{
+				"existing": "value",
+				"d": mapstr.M{
+					"f": mapstr.M{"h": 1},
+				},
+				"list": []interface{}{nil, map[string]interface{}{}},
+			},
+		},
+		"keep null without overwriting": {
+			expected: mapstr.M{
+				"existing": "value",
+				"a":        nil,
+				"b.c":      nil,
+				"d": mapstr.M{
+					"e": nil,
+					"f": mapstr.M{"g": nil, "h": 1},
+				},
+				"list": []interface{}{nil, map[string]interface{}{"i": nil}},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys := map[string]interface{}{
+				"existing": nil,
+				"a":        nil,
+				"b.c":      nil,
+				"d": map[string]interface{}{
+					"e": nil,
+					"f": map[string]interface{}{"g": nil, "h": 1},
+				},
+				"list": []interface{}{nil, map[string]interface{}{"i": nil}},
+			}
+			event := &beat.Event{Fields: mapstr.M{"existing": "value"}}
+
+			WriteJSONKeysWithOptions(event, keys, Options{
+				ExpandKeys:    test.expandKeys,
+				OverwriteKeys: test.overwriteKeys,
+				DropNull:      test.dropNull,
+			})
+
+			require.Equal(t, test.expected.String(), event.Fields.String())
+		})
+	}
+}
+
 func BenchmarkWriteJSONKeys(b *testing.B) {
 	now := time.Now()
 	now = now.Round(time.Second)
@@ -0,0 +1,138 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package tlsreload detects changes to the client certificate and key files
+// of a TLS configuration, so outputs can pick up rotated certificates without
+// a restart.
+package tlsreload
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+// DefaultInterval is the default minimum time between two checks of the
+// certificate files.
+const DefaultInterval = time.Minute
+
+// Watcher checks the certificate and key files of a TLS configuration for
+// changes. A nil Watcher never reports changes.
+type Watcher struct {
+	files    []string
+	interval time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	lastCheck time.Time
+	state     []fileState
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher returns a Watcher for the client certificate and key files of
+// cfg, checking them at most once per interval. It returns nil if TLS is
+// disabled, if the certificate and key are not read from files, or if
+// interval is not positive.
+func NewWatcher(cfg *tlscommon.Config, interval time.Duration) *Watcher {
+	if cfg == nil || !cfg.IsEnabled() || interval <= 0 {
+		return nil
+	}
+
+	var files []string
+	for _, f := range []string{cfg.Certificate.Certificate, cfg.Certificate.Key} {
+		// Certificates embedded in the configuration can't change.
+		if f != "" && !tlscommon.IsPEMString(f) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	w := &Watcher{files: files, interval: interval, now: time.Now}
+	// Failing to read the files here is not an error: loading the TLS
+	// configuration reports it, and the next check will call the reload
+	// function.
+	w.state, _ = w.stat()
+	w.lastCheck = w.now()
+	return w
+}
+
+// Reload calls load if the files changed since the last successful load. The
+// files are checked at most once per interval. If load fails the change is
+// reported again on the next check, so that a certificate and key that are
+// not replaced at the same time are picked up once both are in place.
+// Reload returns true if load was called and succeeded.
+func (w *Watcher) Reload(load func() error) (bool, error) {
+	if w == nil {
+		return false, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	if now.Sub(w.lastCheck) < w.interval {
+		return false, nil
+	}
+	w.lastCheck = now
+
+	state, err := w.stat()
+	if err != nil {
+		return false, err
+	}
+	if w.unchanged(state) {
+		return false, nil
+	}
+
+	if err := load(); err != nil {
+		return false, err
+	}
+	w.state = state
+	return true, nil
+}
+
+func (w *Watcher) stat() ([]fileState, error) {
+	state := make([]fileState, len(w.files))
+	for i, f := range w.files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check TLS file for changes: %w", err)
+		}
+		state[i] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return state, nil
+}
+
+func (w *Watcher) unchanged(state []fileState) bool {
+	if len(state) != len(w.state) {
+		return false
+	}
+	for i := range state {
+		if !state[i].modTime.Equal(w.state[i].modTime) || state[i].size != w.state[i].size {
+			return false
+		}
+	}
+	return true
+}
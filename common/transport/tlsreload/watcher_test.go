@@ -0,0 +1,167 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tlsreload
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+func TestNewWatcher(t *testing.T) {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "cert.pem")
+	key := filepath.Join(dir, "key.pem")
+	disabled := false
+
+	tests := map[string]struct {
+		cfg      *tlscommon.Config
+		interval time.Duration
+		files    []string
+	}{
+		"no config": {
+			cfg:      nil,
+			interval: time.Minute,
+		},
+		"disabled": {
+			cfg: &tlscommon.Config{
+				Enabled:     &disabled,
+				Certificate: tlscommon.CertificateConfig{Certificate: cert, Key: key},
+			},
+			interval: time.Minute,
+		},
+		"no interval": {
+			cfg:      &tlscommon.Config{Certificate: tlscommon.CertificateConfig{Certificate: cert, Key: key}},
+			interval: 0,
+		},
+		"no certificate": {
+			cfg:      &tlscommon.Config{},
+			interval: time.Minute,
+		},
+		"embedded certificate": {
+			cfg: &tlscommon.Config{Certificate: tlscommon.CertificateConfig{
+				Certificate: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----",
+				Key:         key,
+			}},
+			interval: time.Minute,
+			files:    []string{key},
+		},
+		"certificate files": {
+			cfg:      &tlscommon.Config{Certificate: tlscommon.CertificateConfig{Certificate: cert, Key: key}},
+			interval: time.Minute,
+			files:    []string{cert, key},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := NewWatcher(test.cfg, test.interval)
+			if test.files == nil {
+				assert.Nil(t, w)
+				return
+			}
+			require.NotNil(t, w)
+			assert.Equal(t, test.files, w.files)
+		})
+	}
+}
+
+func TestWatcherReload(t *testing.T) {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "cert.pem")
+	key := filepath.Join(dir, "key.pem")
+	writeFile(t, cert, "cert-1")
+	writeFile(t, key, "key-1")
+
+	w := NewWatcher(&tlscommon.Config{
+		Certificate: tlscommon.CertificateConfig{Certificate: cert, Key: key},
+	}, time.Minute)
+	require.NotNil(t, w)
+
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	loads := 0
+	load := func() error {
+		loads++
+		return nil
+	}
+
+	// Nothing changed.
+	now = now.Add(time.Minute)
+	reloaded, err := w.Reload(load)
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// Changes are not detected before the interval elapsed.
+	writeFile(t, cert, "cert-22")
+	now = now.Add(30 * time.Second)
+	reloaded, err = w.Reload(load)
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+	assert.Equal(t, 0, loads)
+
+	now = now.Add(30 * time.Second)
+	reloaded, err = w.Reload(load)
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, 1, loads)
+
+	// A failed load is retried on the next check.
+	writeFile(t, key, "key-22")
+	now = now.Add(time.Minute)
+	reloaded, err = w.Reload(func() error { return errors.New("mismatched key") })
+	assert.Error(t, err)
+	assert.False(t, reloaded)
+
+	now = now.Add(time.Minute)
+	reloaded, err = w.Reload(load)
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, 2, loads)
+
+	// A missing file keeps the current configuration.
+	require.NoError(t, os.Remove(key))
+	now = now.Add(time.Minute)
+	reloaded, err = w.Reload(load)
+	assert.Error(t, err)
+	assert.False(t, reloaded)
+	assert.Equal(t, 2, loads)
+}
+
+func TestNilWatcher(t *testing.T) {
+	var w *Watcher
+	reloaded, err := w.Reload(func() error {
+		t.Fatal("load must not be called")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
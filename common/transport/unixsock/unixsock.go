@@ -0,0 +1,63 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package unixsock supports output hosts defined as a unix socket URI like
+// `unix:///var/run/es.sock`.
+package unixsock
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/transport"
+)
+
+const scheme = "unix://"
+
+// IsUnixSocket returns true if the host is a unix socket URI.
+func IsUnixSocket(host string) bool {
+	return strings.HasPrefix(host, scheme)
+}
+
+// Path takes a host defined as a URI like `unix:///var/run/es.sock` and
+// returns the socket path `/var/run/es.sock`.
+func Path(host string) string {
+	return strings.TrimPrefix(host, scheme)
+}
+
+type dialer struct {
+	path    string
+	timeout time.Duration
+}
+
+// Dialer creates a transport.Dialer connecting to the unix socket at path,
+// whatever network and address are dialed. It can be used as the base
+// dialer of HTTP and TLS connections.
+func Dialer(path string, timeout time.Duration) transport.Dialer {
+	return &dialer{path: path, timeout: timeout}
+}
+
+func (d *dialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d *dialer) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	netDialer := net.Dialer{Timeout: d.timeout}
+	return netDialer.DialContext(ctx, "unix", d.path)
+}
@@ -0,0 +1,58 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package unixsock
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath(t *testing.T) {
+	assert.True(t, IsUnixSocket("unix:///var/run/es.sock"))
+	assert.False(t, IsUnixSocket("localhost:9200"))
+	assert.False(t, IsUnixSocket("https://localhost:9200"))
+
+	assert.Equal(t, "/var/run/es.sock", Path("unix:///var/run/es.sock"))
+}
+
+func TestDialer(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "test.sock")
+	l, err := net.Listen("unix", sockFile)
+	require.NoError(t, err)
+	defer l.Close()
+
+	accepted := make(chan struct{})
+	go func() {
+		defer close(accepted)
+		conn, err := l.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	// The address is ignored, the socket is always dialed.
+	conn, err := Dialer(sockFile, time.Second).Dial("tcp", "localhost:9200")
+	require.NoError(t, err)
+	conn.Close()
+	<-accepted
+}
@@ -17,7 +17,11 @@
 
 package dashboards
 
-import "time"
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
 
 // Config represents the config values for dashboards
 type Config struct {
@@ -33,6 +37,20 @@ type Config struct {
 	AlwaysKibana       bool              `config:"always_kibana"`
 	Retry              *Retry            `config:"retry"`
 	StringReplacements map[string]string `config:"string_replacements"`
+	DryRun             bool              `config:"dry_run"`
+	SpaceID            string            `config:"space_id"`
+}
+
+// Kibana space ids may only contain lowercase letters, numbers, underscores
+// and hyphens.
+var validSpaceID = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Validate checks that the configured space id is a valid Kibana space id.
+func (c *Config) Validate() error {
+	if c.SpaceID != "" && !validSpaceID.MatchString(c.SpaceID) {
+		return fmt.Errorf("invalid space_id '%s': only lowercase letters, numbers, '_' and '-' are allowed", c.SpaceID)
+	}
+	return nil
 }
 
 // Retry handles query retries
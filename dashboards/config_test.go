@@ -0,0 +1,44 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidateSpaceID(t *testing.T) {
+	for _, spaceID := range []string{"", "default", "team-a_1"} {
+		cfg := Config{SpaceID: spaceID}
+		assert.NoError(t, cfg.Validate(), spaceID)
+	}
+	for _, spaceID := range []string{"Team", "a/b", "space id", "spä"} {
+		cfg := Config{SpaceID: spaceID}
+		assert.Error(t, cfg.Validate(), spaceID)
+	}
+}
+
+func TestLoaderAPIPath(t *testing.T) {
+	loader := KibanaLoader{config: &Config{}}
+	require.Equal(t, "/api/saved_objects/_import", loader.apiPath(importAPI))
+
+	loader.config.SpaceID = "team-a"
+	require.Equal(t, "/s/team-a/api/saved_objects/_import", loader.apiPath(importAPI))
+}
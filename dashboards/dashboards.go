@@ -29,16 +29,19 @@ import (
 	"github.com/elastic/elastic-agent-libs/version"
 )
 
-// ImportDashboards tries to import the kibana dashboards.
+// ImportDashboards tries to import the kibana dashboards. If dry_run is
+// configured, the dashboards are only validated, and the returned report
+// lists the saved objects that would be created or overwritten. The report is
+// nil otherwise.
 func ImportDashboards(
 	ctx context.Context,
 	beatInfo beat.Info, homePath string,
 	kibanaConfig, dashboardsConfig *config.C,
 	msgOutputter MessageOutputter,
 	pattern mapstr.M,
-) error {
+) (*DryRunReport, error) {
 	if dashboardsConfig == nil || !dashboardsConfig.Enabled() {
-		return nil
+		return nil, nil
 	}
 
 	// unpack dashboard config
@@ -47,29 +50,32 @@ func ImportDashboards(
 	dashConfig.Dir = filepath.Join(homePath, defaultDirectory)
 	err := dashboardsConfig.Unpack(&dashConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !kibanaConfig.Enabled() {
-		return errors.New("kibana configuration missing for loading dashboards")
+		return nil, errors.New("kibana configuration missing for loading dashboards")
 	}
 
 	return setupAndImportDashboardsViaKibana(ctx, beatInfo.Hostname, beatInfo.Beat, kibanaConfig, &dashConfig, msgOutputter, pattern)
 }
 
 func setupAndImportDashboardsViaKibana(ctx context.Context, hostname, beatname string, kibanaConfig *config.C,
-	dashboardsConfig *Config, msgOutputter MessageOutputter, fields mapstr.M) error {
+	dashboardsConfig *Config, msgOutputter MessageOutputter, fields mapstr.M) (*DryRunReport, error) {
 
 	kibanaLoader, err := NewKibanaLoader(ctx, kibanaConfig, dashboardsConfig, hostname, msgOutputter, beatname)
 	if err != nil {
-		return fmt.Errorf("fail to create the Kibana loader: %v", err)
+		return nil, fmt.Errorf("fail to create the Kibana loader: %v", err)
 	}
 
 	defer kibanaLoader.Close()
 
 	kibanaLoader.statusMsg("Kibana URL %v", kibanaLoader.client.Connection.URL)
 
-	return ImportDashboardsViaKibana(kibanaLoader, fields)
+	if err := ImportDashboardsViaKibana(kibanaLoader, fields); err != nil {
+		return nil, err
+	}
+	return kibanaLoader.DryRunReport(), nil
 }
 
 // ImportDashboardsViaKibana imports Dashboards to Kibana
@@ -0,0 +1,199 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dashboards
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// the saved objects API used by dry runs to check which objects exist
+var bulkGetAPI = "/api/saved_objects/_bulk_get"
+
+// DryRunReport lists the saved objects a dry run validated, and that would
+// have been imported otherwise.
+type DryRunReport struct {
+	Objects []DryRunObject
+}
+
+// DryRunObject is a saved object that would be imported.
+type DryRunObject struct {
+	ID    string
+	Type  string
+	Title string
+
+	// Exists is set if the object is already present in Kibana and would be
+	// overwritten. Otherwise the object would be created.
+	Exists bool
+}
+
+// Created returns the objects that would be created.
+func (r *DryRunReport) Created() []DryRunObject {
+	return r.filter(false)
+}
+
+// Overwritten returns the objects that already exist and would be
+// overwritten.
+func (r *DryRunReport) Overwritten() []DryRunObject {
+	return r.filter(true)
+}
+
+func (r *DryRunReport) filter(exists bool) []DryRunObject {
+	if r == nil {
+		return nil
+	}
+	var objs []DryRunObject
+	for _, obj := range r.Objects {
+		if obj.Exists == exists {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+// String summarizes the report, one object per line.
+func (r *DryRunReport) String() string {
+	if r == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, obj := range r.Objects {
+		action := "create"
+		if obj.Exists {
+			action = "overwrite"
+		}
+		fmt.Fprintf(&b, "%s %s %s", action, obj.Type, obj.ID)
+		if obj.Title != "" {
+			fmt.Fprintf(&b, " (%s)", obj.Title)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// parseSavedObjects validates an NDJSON bundle of saved objects as it would be
+// sent to the import API, and returns the objects it contains.
+func parseSavedObjects(content []byte) ([]DryRunObject, error) {
+	var objs []DryRunObject
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, 64*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var obj struct {
+			ID         string `json:"id"`
+			Type       string `json:"type"`
+			Attributes struct {
+				Title string `json:"title"`
+			} `json:"attributes"`
+		}
+		if err := json.Unmarshal(line, &obj); err != nil {
+			return nil, fmt.Errorf("invalid saved object on line %d: %w", n, err)
+		}
+		if obj.ID == "" || obj.Type == "" {
+			return nil, fmt.Errorf("saved object on line %d is missing its id or type", n)
+		}
+		objs = append(objs, DryRunObject{ID: obj.ID, Type: obj.Type, Title: obj.Attributes.Title})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+// dryRunImport validates the saved objects instead of importing them, and
+// adds them to the loader's dry run report.
+func (loader KibanaLoader) dryRunImport(content string) error {
+	objs, err := parseSavedObjects([]byte(content))
+	if err != nil {
+		return err
+	}
+	if len(objs) == 0 {
+		return nil
+	}
+
+	if err := loader.markExisting(objs); err != nil {
+		return fmt.Errorf("failed to check for existing saved objects: %w", err)
+	}
+	for _, obj := range objs {
+		action := "create"
+		if obj.Exists {
+			action = "overwrite"
+		}
+		loader.statusMsg("Dry run: would %s %s %s", action, obj.Type, obj.ID)
+	}
+
+	loader.dryRunReport.Objects = append(loader.dryRunReport.Objects, objs...)
+	return nil
+}
+
+// markExisting looks up the objects in Kibana, and sets Exists on the ones
+// that are already present.
+func (loader KibanaLoader) markExisting(objs []DryRunObject) error {
+	type objectRef struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}
+	refs := make([]objectRef, len(objs))
+	for i, obj := range objs {
+		refs[i] = objectRef{ID: obj.ID, Type: obj.Type}
+	}
+	body, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	status, response, err := loader.client.Connection.Request(http.MethodPost, loader.apiPath(bulkGetAPI), nil, headers, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if status >= 300 {
+		return fmt.Errorf("returned %d to bulk get saved objects. Response: %s", status, response)
+	}
+
+	var result struct {
+		SavedObjects []struct {
+			ID    string          `json:"id"`
+			Type  string          `json:"type"`
+			Error json.RawMessage `json:"error"`
+		} `json:"saved_objects"`
+	}
+	if err := json.Unmarshal(response, &result); err != nil {
+		return fmt.Errorf("failed to parse bulk get response: %w", err)
+	}
+
+	existing := make(map[objectRef]bool, len(result.SavedObjects))
+	for _, so := range result.SavedObjects {
+		if len(so.Error) == 0 {
+			existing[objectRef{ID: so.ID, Type: so.Type}] = true
+		}
+	}
+	for i := range objs {
+		objs[i].Exists = existing[objectRef{ID: objs[i].ID, Type: objs[i].Type}]
+	}
+	return nil
+}
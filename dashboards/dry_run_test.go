@@ -0,0 +1,54 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSavedObjects(t *testing.T) {
+	objs, err := parseSavedObjects([]byte(`{"id":"metricbeat-*","type":"index-pattern","attributes":{"title":"metricbeat-*"}}
+
+{"id":"dash","type":"dashboard","attributes":{"title":"Overview"}}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []DryRunObject{
+		{ID: "metricbeat-*", Type: "index-pattern", Title: "metricbeat-*"},
+		{ID: "dash", Type: "dashboard", Title: "Overview"},
+	}, objs)
+
+	_, err = parseSavedObjects([]byte(`{"type":"dashboard"}`))
+	assert.Error(t, err)
+
+	_, err = parseSavedObjects([]byte(`{"id":`))
+	assert.Error(t, err)
+}
+
+func TestDryRunReport(t *testing.T) {
+	report := &DryRunReport{Objects: []DryRunObject{
+		{ID: "dash", Type: "dashboard", Title: "Overview"},
+		{ID: "vis", Type: "visualization", Exists: true},
+	}}
+
+	assert.Equal(t, []DryRunObject{report.Objects[0]}, report.Created())
+	assert.Equal(t, []DryRunObject{report.Objects[1]}, report.Overwritten())
+	assert.Equal(t, "create dashboard dash (Overview)\noverwrite visualization vis\n", report.String())
+}
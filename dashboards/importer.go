@@ -135,6 +135,34 @@ func (imp Importer) ImportDir(dirType string, dir string) error {
 	return nil
 }
 
+// ImportNDJSONDir imports every NDJSON saved objects bundle in a directory.
+// Index patterns in the bundles are skipped if the importer has the fields
+// to generate the index pattern from.
+func (imp Importer) ImportNDJSONDir(dir string) error {
+	imp.loader.statusMsg("Import saved objects directory %s", dir)
+
+	var errors []string
+
+	files, err := filepath.Glob(path.Join(dir, "*.ndjson"))
+	if err != nil {
+		return fmt.Errorf("Failed to read directory %s. Error: %s", dir, err)
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("The directory %s contains no .ndjson files, nothing to import", dir)
+	}
+	for _, file := range files {
+		_, err = imp.loader.ImportNDJSONFile(file, imp.fields == nil)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("  error loading %s: %s", file, err))
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("Failed to load directory %s:\n%s", dir, strings.Join(errors, "\n"))
+	}
+	return nil
+}
+
 func (imp Importer) unzip(archive, target string) error {
 	imp.loader.statusMsg("Unzip archive %s", target)
 
@@ -334,7 +362,15 @@ func (imp Importer) ImportKibanaDir(dir string) error {
 		}
 	}
 
-	if len(types) == 0 {
+	// Saved objects exported from Kibana as NDJSON bundles can be put
+	// directly into the directory.
+	hasNDJSON := false
+	if wantDashboards {
+		bundles, _ := filepath.Glob(path.Join(dir, "*.ndjson"))
+		hasNDJSON = len(bundles) > 0
+	}
+
+	if len(types) == 0 && !hasNDJSON {
 		return newErrNotFound("The directory %s does not contain the %s subdirectory."+
 			" There is nothing to import into Kibana.", dir, strings.Join(check, " or "))
 	}
@@ -351,6 +387,13 @@ func (imp Importer) ImportKibanaDir(dir string) error {
 		}
 	}
 
+	if hasNDJSON {
+		if err = imp.ImportNDJSONDir(dir); err != nil {
+			return fmt.Errorf("Failed to import saved objects: %v", err)
+		}
+		importDashboards = true
+	}
+
 	if wantDashboards && !importDashboards {
 		return newErrNotFound("No dashboards to import. Please make sure the %s directory "+
 			"contains a dashboard directory.", dir)
@@ -44,6 +44,9 @@ var (
 	// the base path of the saved objects API
 	// On serverless, you must add an x-elastic-internal-header to reach this API
 	importAPI = "/api/saved_objects/_import"
+
+	// Kibana spaces were introduced in 6.5.
+	minimumRequiredVersionSpaces = version.MustNew("6.5.0")
 )
 
 // KibanaLoader loads Kibana files
@@ -56,6 +59,10 @@ type KibanaLoader struct {
 	defaultLogger *logp.Logger
 
 	loadedAssets map[string]bool
+
+	// dryRunReport collects the validated saved objects if the loader runs
+	// in dry run mode, nil otherwise.
+	dryRunReport *DryRunReport
 }
 
 // NewKibanaLoader creates a new loader to load Kibana files
@@ -78,8 +85,16 @@ func NewKibanaLoader(ctx context.Context, cfg *config.C, dashboardsConfig *Confi
 		defaultLogger: logp.NewLogger("dashboards"),
 		loadedAssets:  make(map[string]bool, 0),
 	}
+	if dashboardsConfig.DryRun {
+		loader.dryRunReport = &DryRunReport{}
+	}
 
 	version := client.GetVersion()
+	if dashboardsConfig.SpaceID != "" && version.LessThan(minimumRequiredVersionSpaces) {
+		client.Close()
+		return nil, fmt.Errorf("Kibana version %s does not support spaces, version must be at least %s to use space_id '%s'",
+			version.String(), minimumRequiredVersionSpaces.String(), dashboardsConfig.SpaceID)
+	}
 	loader.statusMsg("Initialize the Kibana %s loader", version.String())
 
 	return &loader, nil
@@ -139,7 +154,7 @@ func (loader KibanaLoader) ImportIndex(pattern mapstr.M) error {
 		errs = append(errs, fmt.Errorf("error setting index '%s' in index pattern: %w", loader.config.Index, err))
 	}
 
-	err := loader.client.ImportMultiPartFormFile(importAPI, params, "index-template.ndjson", pattern.String())
+	err := loader.importFile(params, "index-template.ndjson", pattern.String())
 	if err != nil {
 		errs = append(errs, fmt.Errorf("error loading index pattern: %w", err))
 	}
@@ -170,7 +185,7 @@ func (loader KibanaLoader) ImportDashboard(file string) error {
 		return fmt.Errorf("error getting references of dashboard: %w", err)
 	}
 
-	if err := loader.client.ImportMultiPartFormFile(importAPI, params, correctExtension(file), dashboardWithReferences); err != nil {
+	if err := loader.importFile(params, correctExtension(file), dashboardWithReferences); err != nil {
 		return fmt.Errorf("error dashboard asset: %w", err)
 	}
 
@@ -178,6 +193,30 @@ func (loader KibanaLoader) ImportDashboard(file string) error {
 	return nil
 }
 
+// importFile sends the NDJSON saved objects to the import API. In dry run mode
+// the objects are only validated and added to the dry run report.
+func (loader KibanaLoader) importFile(params url.Values, filename string, content string) error {
+	if loader.dryRunReport != nil {
+		return loader.dryRunImport(content)
+	}
+	return loader.client.ImportMultiPartFormFile(loader.apiPath(importAPI), params, filename, content)
+}
+
+// apiPath returns the path of a Kibana API in the configured space. The
+// default space uses the unprefixed path.
+func (loader KibanaLoader) apiPath(api string) string {
+	if loader.config.SpaceID == "" {
+		return api
+	}
+	return "/s/" + loader.config.SpaceID + api
+}
+
+// DryRunReport returns the saved objects validated by a loader in dry run
+// mode, or nil if the loader imports the objects.
+func (loader KibanaLoader) DryRunReport() *DryRunReport {
+	return loader.dryRunReport
+}
+
 type dashboardObj struct {
 	References []dashboardReference `json:"references"`
 }
@@ -0,0 +1,209 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dashboards
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// ImportResult is the response of the Kibana saved objects import API.
+type ImportResult struct {
+	Success        bool                `json:"success"`
+	SuccessCount   int                 `json:"successCount"`
+	SuccessResults []ImportedObject    `json:"successResults"`
+	Errors         []ImportObjectError `json:"errors"`
+}
+
+// ImportedObject identifies a saved object that was imported successfully.
+type ImportedObject struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Meta struct {
+		Title string `json:"title"`
+	} `json:"meta"`
+}
+
+// ImportObjectError describes why a saved object could not be imported.
+type ImportObjectError struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (e ImportObjectError) String() string {
+	reason := e.Error.Type
+	if e.Error.Message != "" {
+		reason = e.Error.Message
+	}
+	return fmt.Sprintf("%s %s (%s): %s", e.Type, e.ID, e.Title, reason)
+}
+
+// ImportNDJSONFile imports a saved objects export in NDJSON format, as
+// created by Kibana's saved objects management, and reports the outcome of
+// every object. If withIndexPattern is false, index-pattern objects are
+// removed from the bundle, so the index pattern generated from the fields
+// is not overwritten. In dry run mode the objects are only validated and the
+// returned result is nil.
+func (loader KibanaLoader) ImportNDJSONFile(file string, withIndexPattern bool) (*ImportResult, error) {
+	if loader.version.LessThan(minimumRequiredVersionSavedObjects) {
+		return nil, fmt.Errorf("Kibana version must be at least %s", minimumRequiredVersionSavedObjects.String())
+	}
+
+	loader.statusMsg("Importing saved objects from %s", file)
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read saved objects from file %s: %w", file, err)
+	}
+
+	if !withIndexPattern {
+		content, err = RemoveIndexPattern(content)
+		if err != nil {
+			return nil, fmt.Errorf("fail to remove index pattern from file %s: %w", file, err)
+		}
+	}
+
+	content, err = loader.formatNDJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("fail to prepare saved objects from file %s: %w", file, err)
+	}
+
+	if loader.dryRunReport != nil {
+		return nil, loader.dryRunImport(string(content))
+	}
+
+	params := url.Values{}
+	params.Set("overwrite", "true")
+
+	result, err := loader.postNDJSON(params, filepath.Base(file), content)
+	if err != nil {
+		return nil, fmt.Errorf("error importing saved objects from %s: %w", file, err)
+	}
+
+	for _, obj := range result.SuccessResults {
+		loader.statusMsg("Imported %s %s", obj.Type, obj.ID)
+	}
+	for _, objErr := range result.Errors {
+		loader.statusMsg("Failed to import %s", objErr)
+	}
+
+	if len(result.Errors) > 0 {
+		msgs := make([]string, len(result.Errors))
+		for i, objErr := range result.Errors {
+			msgs[i] = "  " + objErr.String()
+		}
+		return result, fmt.Errorf("failed to import %d saved objects from %s:\n%s",
+			len(result.Errors), file, strings.Join(msgs, "\n"))
+	}
+	return result, nil
+}
+
+// formatNDJSON applies the configured index and string replacements to every
+// saved object of an NDJSON bundle. Lines that are not saved objects, like the
+// export summary Kibana appends to its exports, are dropped.
+func (loader KibanaLoader) formatNDJSON(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var obj mapstr.M
+		if err := json.Unmarshal(line, &obj); err != nil {
+			return nil, err
+		}
+		objType, _ := obj["type"].(string)
+		switch objType {
+		case "":
+			continue
+		case "index-pattern":
+			if err := ReplaceIndexInIndexPattern(loader.config.Index, obj); err != nil {
+				return nil, err
+			}
+			buf.WriteString(obj.String())
+		default:
+			buf.Write(loader.formatDashboardAssets(line))
+		}
+		buf.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// postNDJSON sends the NDJSON bundle as multipart form to the saved objects
+// import API and decodes the per-object results.
+func (loader KibanaLoader) postNDJSON(params url.Values, filename string, content []byte) (*ImportResult, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	partHeaders := textproto.MIMEHeader{}
+	partHeaders.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
+	partHeaders.Set("Content-Type", "application/ndjson")
+	part, err := w.CreatePart(partHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create multipart form: %w", err)
+	}
+	if _, err := io.Copy(part, bytes.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("failed to write multipart form: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart form: %w", err)
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", w.FormDataContentType())
+
+	status, response, err := loader.client.Connection.Request(http.MethodPost, loader.apiPath(importAPI), params, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 {
+		return nil, fmt.Errorf("returned %d to import file. Response: %s", status, response)
+	}
+	return parseImportResult(response)
+}
+
+func parseImportResult(response []byte) (*ImportResult, error) {
+	var result ImportResult
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse import response: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,69 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dashboards
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatNDJSON(t *testing.T) {
+	loader := KibanaLoader{
+		config:   &Config{Index: "otherindex-*"},
+		hostname: "hostname.local",
+	}
+
+	content := []byte(`{"id":"metricbeat-*","type":"index-pattern","attributes":{"title":"metricbeat-*"}}
+{"id":"dash","type":"dashboard","attributes":{"title":"CHANGEME_HOSTNAME"},"references":[{"id":"metricbeat-*","name":"ref","type":"index-pattern"}]}
+
+{"exportedCount":2,"missingRefCount":0,"missingReferences":[]}
+`)
+
+	formatted, err := loader.formatNDJSON(content)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(formatted)), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"id":"otherindex-*","type":"index-pattern","attributes":{"title":"otherindex-*"}}`, lines[0])
+	assert.JSONEq(t, `{"id":"dash","type":"dashboard","attributes":{"title":"hostname.local"},"references":[{"id":"otherindex-*","name":"ref","type":"index-pattern"}]}`, lines[1])
+}
+
+func TestParseImportResult(t *testing.T) {
+	response := []byte(`{
+		"success": false,
+		"successCount": 1,
+		"successResults": [{"id": "dash", "type": "dashboard", "meta": {"title": "Dashboard"}}],
+		"errors": [{"id": "vis", "type": "visualization", "title": "Vis", "error": {"type": "missing_references"}}]
+	}`)
+
+	result, err := parseImportResult(response)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, result.SuccessCount)
+	require.Len(t, result.SuccessResults, 1)
+	assert.Equal(t, "dash", result.SuccessResults[0].ID)
+	assert.Equal(t, "Dashboard", result.SuccessResults[0].Meta.Title)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "visualization vis (Vis): missing_references", result.Errors[0].String())
+
+	_, err = parseImportResult([]byte("not json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,191 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ToMapStr converts an ECS struct (or a pointer to one) into a mapstr.M
+// using the `ecs` struct tags as keys. Dotted tags create nested objects,
+// fields holding structs are converted recursively and zero values, nil
+// pointers and empty maps or slices are omitted. Fields without an `ecs`
+// tag or tagged with "-" are ignored, untagged embedded structs are
+// inlined.
+func ToMapStr(v interface{}) (mapstr.M, error) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil, fmt.Errorf("ecs: cannot convert nil %T", v)
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ecs: expected a struct, got %T", v)
+	}
+
+	m := mapstr.M{}
+	if err := structToMapStr(val, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func structToMapStr(val reflect.Value, m mapstr.M) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, tagged := field.Tag.Lookup("ecs")
+		if key == "-" {
+			continue
+		}
+		if !tagged || key == "" {
+			if field.Anonymous {
+				fv := indirect(val.Field(i))
+				if fv.IsValid() && fv.Kind() == reflect.Struct {
+					if err := structToMapStr(fv, m); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+
+		value, ok, err := fieldValue(val.Field(i))
+		if err != nil {
+			return fmt.Errorf("ecs: field %s: %w", field.Name, err)
+		}
+		if !ok {
+			continue
+		}
+		if err := putValue(m, key, value); err != nil {
+			return fmt.Errorf("ecs: failed to set %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// putValue sets value at key. Objects are merged with objects already
+// present, so fields like threat.enrichments.indicator and
+// threat.enrichments.indicator.first_seen can be combined.
+func putValue(m mapstr.M, key string, value interface{}) error {
+	if nested, ok := value.(mapstr.M); ok {
+		if existing, err := m.GetValue(key); err == nil {
+			if existingMap, ok := existing.(mapstr.M); ok {
+				existingMap.DeepUpdate(nested)
+				return nil
+			}
+		}
+	}
+	_, err := m.Put(key, value)
+	return err
+}
+
+// fieldValue returns the value to be stored for fv. The second return
+// value is false if the field should be omitted.
+func fieldValue(fv reflect.Value) (interface{}, bool, error) {
+	fv = indirect(fv)
+	if !fv.IsValid() {
+		return nil, false, nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Struct:
+		if fv.Type() == timeType {
+			if fv.IsZero() {
+				return nil, false, nil
+			}
+			return fv.Interface(), true, nil
+		}
+
+		nested := mapstr.M{}
+		if err := structToMapStr(fv, nested); err != nil {
+			return nil, false, err
+		}
+		return nested, len(nested) > 0, nil
+	case reflect.Map:
+		if fv.Len() == 0 {
+			return nil, false, nil
+		}
+		if m, ok := fv.Interface().(map[string]interface{}); ok {
+			return mapstr.M(m).Clone(), true, nil
+		}
+		return fv.Interface(), true, nil
+	case reflect.Slice, reflect.Array:
+		if fv.Len() == 0 {
+			return nil, false, nil
+		}
+		if !isStructType(fv.Type().Elem()) {
+			return fv.Interface(), true, nil
+		}
+
+		// convert lists of objects, e.g. threat.enrichments
+		list := make([]mapstr.M, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			elem := indirect(fv.Index(i))
+			if !elem.IsValid() {
+				continue
+			}
+			nested := mapstr.M{}
+			if err := structToMapStr(elem, nested); err != nil {
+				return nil, false, err
+			}
+			list = append(list, nested)
+		}
+		return list, len(list) > 0, nil
+	case reflect.Interface:
+		if fv.IsNil() {
+			return nil, false, nil
+		}
+		return fieldValue(fv.Elem())
+	}
+
+	if fv.IsZero() {
+		return nil, false, nil
+	}
+	return fv.Interface(), true, nil
+}
+
+func isStructType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// indirect dereferences pointers, returning the zero Value for nil
+// pointers.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
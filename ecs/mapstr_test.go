@@ -0,0 +1,129 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ecs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestToMapStr(t *testing.T) {
+	ts := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	tests := map[string]struct {
+		in       interface{}
+		expected mapstr.M
+	}{
+		"flat struct omits zero values": {
+			in: Rule{ID: "1", Name: "block ssh"},
+			expected: mapstr.M{
+				"id":   "1",
+				"name": "block ssh",
+			},
+		},
+		"pointer to struct": {
+			in: &Rule{Category: "network"},
+			expected: mapstr.M{
+				"category": "network",
+			},
+		},
+		"dotted tags and nested pointer": {
+			in: &Process{
+				PID:        10,
+				Name:       "child",
+				ThreadID:   3,
+				Args:       []string{"a", "b"},
+				Start:      ts,
+				Parent:     &Process{PID: 1, Name: "init"},
+				ThreadName: "",
+			},
+			expected: mapstr.M{
+				"pid":   int64(10),
+				"name":  "child",
+				"args":  []string{"a", "b"},
+				"start": ts,
+				"thread": mapstr.M{
+					"id": int64(3),
+				},
+				"parent": mapstr.M{
+					"pid":  int64(1),
+					"name": "init",
+				},
+			},
+		},
+		"nil pointer and empty nested struct are omitted": {
+			in:       Process{Parent: &Process{}},
+			expected: mapstr.M{},
+		},
+		"maps are merged with dotted fields": {
+			in: Enrichments{
+				Indicator:          map[string]interface{}{"type": "ipv4-addr"},
+				IndicatorFirstSeen: ts,
+			},
+			expected: mapstr.M{
+				"indicator": mapstr.M{
+					"type":       "ipv4-addr",
+					"first_seen": ts,
+				},
+			},
+		},
+		"list of structs": {
+			in: Threat{
+				Enrichments: []Enrichments{
+					{MatchedAtomic: "1.2.3.4"},
+				},
+			},
+			expected: mapstr.M{
+				"enrichments": []mapstr.M{
+					{"matched": mapstr.M{"atomic": "1.2.3.4"}},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := ToMapStr(test.in)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, m)
+		})
+	}
+}
+
+func TestToMapStrDoesNotModifyInput(t *testing.T) {
+	labels := map[string]interface{}{"env": "prod"}
+	in := Enrichments{Indicator: labels, IndicatorFirstSeen: time.Now()}
+
+	_, err := ToMapStr(in)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"env": "prod"}, labels)
+}
+
+func TestToMapStrInvalidInput(t *testing.T) {
+	_, err := ToMapStr("not a struct")
+	assert.Error(t, err)
+
+	var rule *Rule
+	_, err = ToMapStr(rule)
+	assert.Error(t, err)
+}
@@ -0,0 +1,396 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// Root level field sets. Field sets only reused inside other field sets
+// (e.g. geo or hash) are not allowed at the root of an event.
+var topLevelFieldSets = map[string]reflect.Type{
+	"agent":         reflect.TypeOf(Agent{}),
+	"as":            reflect.TypeOf(AS{}),
+	"client":        reflect.TypeOf(Client{}),
+	"cloud":         reflect.TypeOf(Cloud{}),
+	"container":     reflect.TypeOf(Container{}),
+	"data_stream":   reflect.TypeOf(DataStream{}),
+	"destination":   reflect.TypeOf(Destination{}),
+	"dll":           reflect.TypeOf(Dll{}),
+	"dns":           reflect.TypeOf(Dns{}),
+	"ecs":           reflect.TypeOf(ECS{}),
+	"error":         reflect.TypeOf(Error{}),
+	"event":         reflect.TypeOf(Event{}),
+	"file":          reflect.TypeOf(File{}),
+	"group":         reflect.TypeOf(Group{}),
+	"host":          reflect.TypeOf(Host{}),
+	"http":          reflect.TypeOf(Http{}),
+	"log":           reflect.TypeOf(Log{}),
+	"network":       reflect.TypeOf(Network{}),
+	"observer":      reflect.TypeOf(Observer{}),
+	"orchestrator":  reflect.TypeOf(Orchestrator{}),
+	"organization":  reflect.TypeOf(Organization{}),
+	"package":       reflect.TypeOf(Package{}),
+	"process":       reflect.TypeOf(Process{}),
+	"registry":      reflect.TypeOf(Registry{}),
+	"related":       reflect.TypeOf(Related{}),
+	"rule":          reflect.TypeOf(Rule{}),
+	"server":        reflect.TypeOf(Server{}),
+	"service":       reflect.TypeOf(Service{}),
+	"source":        reflect.TypeOf(Source{}),
+	"threat":        reflect.TypeOf(Threat{}),
+	"tls":           reflect.TypeOf(Tls{}),
+	"url":           reflect.TypeOf(Url{}),
+	"user":          reflect.TypeOf(User{}),
+	"user_agent":    reflect.TypeOf(UserAgent{}),
+	"vulnerability": reflect.TypeOf(Vulnerability{}),
+	"x509":          reflect.TypeOf(X509{}),
+}
+
+// Field sets whose fields are stored at the root of the event.
+var rootFieldSets = []reflect.Type{
+	reflect.TypeOf(Base{}),
+	reflect.TypeOf(Tracing{}),
+}
+
+// FieldError reports a field not conforming to the ECS definitions.
+type FieldError struct {
+	// Path is the full dotted path of the field.
+	Path string
+	// Expected is the ECS type of the field. It is empty for unknown
+	// fields.
+	Expected string
+	// Actual is the Go type of the value found.
+	Actual string
+}
+
+func (e FieldError) Error() string {
+	if e.Expected == "" {
+		return fmt.Sprintf("unknown ECS field %s", e.Path)
+	}
+	return fmt.Sprintf("ECS field %s: expected %s, got %s", e.Path, e.Expected, e.Actual)
+}
+
+// Validate checks the fields present in m against the ECS field
+// definitions of this package. Type mismatches and unknown top level
+// fields are returned sorted by path. Fields not defined in a known field
+// set are accepted, as field sets are reused in other field sets (e.g.
+// source.geo).
+func Validate(m mapstr.M) []FieldError {
+	var errs []FieldError
+	validateObject(&errs, "", rootSchema(), m)
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+type schemaKind uint8
+
+const (
+	kindObject schemaKind = iota // nested field set or dotted tag prefix
+	kindLeaf
+	kindNested // list of objects
+)
+
+type schemaNode struct {
+	kind     schemaKind
+	ecsType  string
+	check    func(interface{}) bool
+	children map[string]*schemaNode
+	root     bool
+}
+
+var (
+	rootSchemaOnce sync.Once
+	rootSchemaNode *schemaNode
+
+	// only accessed while the root schema is built
+	schemaCache = map[reflect.Type]*schemaNode{}
+)
+
+func rootSchema() *schemaNode {
+	rootSchemaOnce.Do(func() {
+		root := &schemaNode{kind: kindObject, children: map[string]*schemaNode{}, root: true}
+		for _, t := range rootFieldSets {
+			for k, v := range structSchema(t).children {
+				root.children[k] = v
+			}
+		}
+		for name, t := range topLevelFieldSets {
+			root.children[name] = structSchema(t)
+		}
+		rootSchemaNode = root
+	})
+	return rootSchemaNode
+}
+
+// structSchema builds the schema of an ECS struct from its `ecs` tags.
+// Nodes are cached and created before their fields are added, to support
+// recursive definitions like process.parent.
+func structSchema(t reflect.Type) *schemaNode {
+	if node, ok := schemaCache[t]; ok {
+		return node
+	}
+
+	node := &schemaNode{kind: kindObject, ecsType: "object", children: map[string]*schemaNode{}}
+	schemaCache[t] = node
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("ecs")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+
+		parent := node
+		parts := strings.Split(key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := parent.children[part]
+			if !ok || child.kind != kindObject {
+				// an object field like threat.enrichments.indicator is
+				// extended by fields like indicator.first_seen
+				child = &schemaNode{kind: kindObject, ecsType: "object", children: map[string]*schemaNode{}}
+				parent.children[part] = child
+			}
+			parent = child
+		}
+
+		last := parts[len(parts)-1]
+		if existing, ok := parent.children[last]; ok && existing.kind == kindObject {
+			continue
+		}
+		parent.children[last] = typeSchema(field.Type)
+	}
+	return node
+}
+
+func typeSchema(t reflect.Type) *schemaNode {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		return scalar("long", isNumber)
+	case t == timeType:
+		return scalar("date", isDate)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Map:
+		return scalar("object", isObject)
+	case reflect.Slice, reflect.Array:
+		if isStructType(t.Elem()) {
+			elem := t.Elem()
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			return &schemaNode{kind: kindNested, ecsType: "nested", children: structSchema(elem).children}
+		}
+		// arrays are accepted for every scalar field
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return scalar("keyword", isString)
+	case reflect.Bool:
+		return scalar("boolean", isBool)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return scalar("long", isNumber)
+	case reflect.Float32, reflect.Float64:
+		return scalar("float", isNumber)
+	}
+	return leaf(t.String(), func(interface{}) bool { return true })
+}
+
+// scalar creates a leaf accepting a single value or an array of values,
+// as Elasticsearch does not distinguish between both.
+func scalar(ecsType string, check func(interface{}) bool) *schemaNode {
+	return leaf(ecsType, func(v interface{}) bool { return isArrayOf(v, check) })
+}
+
+func leaf(ecsType string, check func(interface{}) bool) *schemaNode {
+	return &schemaNode{kind: kindLeaf, ecsType: ecsType, check: check}
+}
+
+func validateObject(errs *[]FieldError, prefix string, node *schemaNode, m map[string]interface{}) {
+	for key, value := range m {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		// dotted keys are resolved segment by segment
+		current, parts := node, strings.Split(key, ".")
+		known := true
+		for i, part := range parts[:len(parts)-1] {
+			child, ok := current.children[part]
+			if !ok || child.kind != kindObject {
+				known = false
+				if current.root && i == 0 {
+					*errs = append(*errs, FieldError{Path: joinPath(prefix, part)})
+				}
+				break
+			}
+			current = child
+		}
+		if !known {
+			continue
+		}
+
+		last := parts[len(parts)-1]
+		child, ok := current.children[last]
+		if !ok {
+			if current.root {
+				*errs = append(*errs, FieldError{Path: path})
+			}
+			continue
+		}
+		validateValue(errs, path, child, value)
+	}
+}
+
+func validateValue(errs *[]FieldError, path string, node *schemaNode, value interface{}) {
+	if value == nil {
+		return
+	}
+
+	switch node.kind {
+	case kindLeaf:
+		if !node.check(value) {
+			*errs = append(*errs, typeError(path, node, value))
+		}
+	case kindObject:
+		obj, ok := toObject(value)
+		if !ok {
+			*errs = append(*errs, typeError(path, node, value))
+			return
+		}
+		validateObject(errs, path, node, obj)
+	case kindNested:
+		objNode := &schemaNode{kind: kindObject, ecsType: "object", children: node.children}
+		if obj, ok := toObject(value); ok {
+			validateObject(errs, path, objNode, obj)
+			return
+		}
+
+		list := reflect.ValueOf(value)
+		if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+			*errs = append(*errs, typeError(path, node, value))
+			return
+		}
+		for i := 0; i < list.Len(); i++ {
+			obj, ok := toObject(list.Index(i).Interface())
+			if !ok {
+				*errs = append(*errs, typeError(path, node, value))
+				return
+			}
+			validateObject(errs, path, objNode, obj)
+		}
+	}
+}
+
+func typeError(path string, node *schemaNode, value interface{}) FieldError {
+	return FieldError{Path: path, Expected: node.ecsType, Actual: fmt.Sprintf("%T", value)}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func toObject(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case mapstr.M:
+		return m, true
+	case map[string]interface{}:
+		return m, true
+	}
+	return nil, false
+}
+
+func isObject(v interface{}) bool {
+	_, ok := toObject(v)
+	return ok
+}
+
+func isString(v interface{}) bool {
+	switch v.(type) {
+	case string, fmt.Stringer:
+		return true
+	}
+	return false
+}
+
+func isBool(v interface{}) bool {
+	_, ok := v.(bool)
+	return ok
+}
+
+func isNumber(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isDate(v interface{}) bool {
+	switch t := v.(type) {
+	case time.Time:
+		return true
+	case string:
+		_, err := time.Parse(time.RFC3339Nano, t)
+		return err == nil
+	}
+	// accept types based on time.Time like common.Time and epoch
+	// milliseconds
+	val := reflect.ValueOf(v)
+	if val.Kind() == reflect.Struct && val.Type().ConvertibleTo(timeType) {
+		return true
+	}
+	return isNumber(v)
+}
+
+func isArrayOf(v interface{}, check func(interface{}) bool) bool {
+	if check(v) {
+		return true
+	}
+
+	list := reflect.ValueOf(v)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return false
+	}
+	for i := 0; i < list.Len(); i++ {
+		if !check(list.Index(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ecs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		in       mapstr.M
+		expected []FieldError
+	}{
+		"valid event": {
+			in: mapstr.M{
+				"@timestamp": time.Now(),
+				"message":    "hello",
+				"labels":     mapstr.M{"env": "prod"},
+				"trace":      mapstr.M{"id": "abc"},
+				"event": mapstr.M{
+					"severity": 3,
+					"duration": int64(1000),
+					"created":  "2021-03-04T05:06:07Z",
+				},
+				"host": mapstr.M{
+					"ip": []string{"127.0.0.1", "::1"},
+				},
+				"process": mapstr.M{
+					"pid":    float64(10),
+					"parent": mapstr.M{"pid": 1, "name": "init"},
+					"thread": mapstr.M{"id": 3},
+				},
+				"dns": mapstr.M{
+					"answers": []mapstr.M{{"name": "elastic.co"}},
+				},
+				"threat": mapstr.M{
+					"enrichments": []mapstr.M{
+						{"matched": mapstr.M{"atomic": "1.2.3.4"}},
+					},
+				},
+			},
+		},
+		"dotted keys": {
+			in: mapstr.M{
+				"process.pid":   10,
+				"rule.name":     "block",
+				"event.created": "not a date",
+			},
+			expected: []FieldError{
+				{Path: "event.created", Expected: "date", Actual: "string"},
+			},
+		},
+		"type mismatches": {
+			in: mapstr.M{
+				"process": mapstr.M{
+					"pid":    "10",
+					"parent": mapstr.M{"name": 1},
+				},
+				"event": mapstr.M{
+					"risk_score": true,
+				},
+				"rule": "not an object",
+			},
+			expected: []FieldError{
+				{Path: "event.risk_score", Expected: "float", Actual: "bool"},
+				{Path: "process.parent.name", Expected: "keyword", Actual: "int"},
+				{Path: "process.pid", Expected: "long", Actual: "string"},
+				{Path: "rule", Expected: "object", Actual: "string"},
+			},
+		},
+		"unknown top level fields": {
+			in: mapstr.M{
+				"custom":       "value",
+				"geo.name":     "home",
+				"source":       mapstr.M{"geo": mapstr.M{"name": "home"}},
+				"process.todo": 1,
+			},
+			expected: []FieldError{
+				{Path: "custom"},
+				{Path: "geo"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Validate(test.in))
+		})
+	}
+}
+
+func TestFieldErrorString(t *testing.T) {
+	assert.Equal(t, "unknown ECS field custom", FieldError{Path: "custom"}.Error())
+	assert.Equal(t, "ECS field process.pid: expected long, got string",
+		FieldError{Path: "process.pid", Expected: "long", Actual: "string"}.Error())
+}
@@ -33,6 +33,8 @@ import (
 	"github.com/njcx/libbeat_v8/common"
 	"github.com/njcx/libbeat_v8/common/productorigin"
 	"github.com/njcx/libbeat_v8/common/transport/kerberos"
+	"github.com/njcx/libbeat_v8/common/transport/tlsreload"
+	"github.com/njcx/libbeat_v8/common/transport/unixsock"
 	"github.com/njcx/libbeat_v8/version"
 	cfg "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
@@ -64,6 +66,11 @@ type Connection struct {
 
 	isServerless bool
 
+	// tlsWatcher detects changes to the client certificate files, the
+	// HTTP client is then recreated with transportOpts.
+	tlsWatcher    *tlsreload.Watcher
+	transportOpts []httpcommon.TransportOption
+
 	// requests will share the same cancellable context
 	// so they can be aborted on Close()
 	reqsContext context.Context
@@ -92,6 +99,16 @@ type ConnectionSettings struct {
 
 	Transport httpcommon.HTTPTransportSettings
 
+	// UnixSocket is the path of a unix socket to connect to. If set, all
+	// requests are sent over the socket, whatever the host in URL.
+	UnixSocket string
+
+	// TLSReloadInterval is the minimum time between two checks of the TLS
+	// client certificate and key files for changes. If a change is found,
+	// the HTTP client is recreated with the new certificate. Zero disables
+	// reloading.
+	TLSReloadInterval time.Duration
+
 	// UserAgent can be used to report the agent running mode
 	// to ES via the User Agent string. If running under Agent (fleetmode.Enabled() == true)
 	// then this string will be appended to the user agent.
@@ -159,7 +176,7 @@ func NewConnection(s ConnectionSettings) (*Connection, error) {
 		s.Headers[productorigin.Header] = productorigin.Beats
 	}
 
-	httpClient, err := s.Transport.Client(
+	transportOpts := []httpcommon.TransportOption{
 		httpcommon.WithLogger(logger),
 		httpcommon.WithIOStats(s.Observer),
 		httpcommon.WithKeepaliveSettings{IdleConnTimeout: s.IdleConnTimeout},
@@ -169,18 +186,15 @@ func NewConnection(s ConnectionSettings) (*Connection, error) {
 			return apmelasticsearch.WrapRoundTripper(rt)
 		}),
 		httpcommon.WithHeaderRoundTripper(map[string]string{"User-Agent": s.UserAgent}),
-	)
-	if err != nil {
-		return nil, err
+	}
+	if s.UnixSocket != "" {
+		logger.Infof("elasticsearch unix socket: %s", s.UnixSocket)
+		transportOpts = append(transportOpts, httpcommon.WithBaseDialer(unixsock.Dialer(s.UnixSocket, s.Transport.Timeout)))
 	}
 
-	esClient := esHTTPClient(httpClient)
-	if s.Kerberos.IsEnabled() {
-		esClient, err = kerberos.NewClient(s.Kerberos, httpClient, s.URL)
-		if err != nil {
-			return nil, err
-		}
-		logger.Info("kerberos client created")
+	esClient, err := newHTTPClient(s, transportOpts, logger)
+	if err != nil {
+		return nil, err
 	}
 
 	conn := Connection{
@@ -189,6 +203,8 @@ func NewConnection(s ConnectionSettings) (*Connection, error) {
 		Encoder:            encoder,
 		log:                logger,
 		responseBuffer:     bytes.NewBuffer(nil),
+		tlsWatcher:         tlsreload.NewWatcher(s.Transport.TLS, s.TLSReloadInterval),
+		transportOpts:      transportOpts,
 	}
 
 	if s.APIKey != "" {
@@ -198,6 +214,23 @@ func NewConnection(s ConnectionSettings) (*Connection, error) {
 	return &conn, nil
 }
 
+func newHTTPClient(s ConnectionSettings, transportOpts []httpcommon.TransportOption, logger *logp.Logger) (esHTTPClient, error) {
+	httpClient, err := s.Transport.Client(transportOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.Kerberos.IsEnabled() {
+		return httpClient, nil
+	}
+	esClient, err := kerberos.NewClient(s.Kerberos, httpClient, s.URL)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("kerberos client created")
+	return esClient, nil
+}
+
 // NewClients returns a list of Elasticsearch clients based on the given
 // configuration. It accepts the same configuration parameters as the Elasticsearch
 // output, except for the output specific configuration options.  If multiple hosts
@@ -221,6 +254,11 @@ func NewClients(cfg *cfg.C, beatname string) ([]Connection, error) {
 
 	clients := []Connection{}
 	for _, host := range config.Hosts {
+		var socket string
+		if unixsock.IsUnixSocket(host) {
+			socket, host = unixsock.Path(host), "localhost"
+		}
+
 		esURL, err := common.MakeURL(config.Protocol, config.Path, host, 9200)
 		if err != nil {
 			logp.Err("invalid host param set: %s, Error: %v", host, err)
@@ -238,6 +276,7 @@ func NewClients(cfg *cfg.C, beatname string) ([]Connection, error) {
 			Headers:          config.Headers,
 			CompressionLevel: config.CompressionLevel,
 			Transport:        config.Transport,
+			UnixSocket:       socket,
 		})
 		if err != nil {
 			return clients, err
@@ -338,7 +377,12 @@ func (conn *Connection) Test(d testing.Driver) {
 		address := u.Host
 
 		d.Run("connection", func(d testing.Driver) {
-			netDialer := transport.TestNetDialer(d, conn.Transport.Timeout)
+			var netDialer transport.Dialer
+			if conn.UnixSocket != "" {
+				netDialer = unixsock.Dialer(conn.UnixSocket, conn.Transport.Timeout)
+			} else {
+				netDialer = transport.TestNetDialer(d, conn.Transport.Timeout)
+			}
 			_, err = netDialer.Dial("tcp", address)
 			d.Fatal("dial up", err)
 		})
@@ -353,6 +397,9 @@ func (conn *Connection) Test(d testing.Driver) {
 				}
 
 				netDialer := transport.NetDialer(conn.Transport.Timeout)
+				if conn.UnixSocket != "" {
+					netDialer = unixsock.Dialer(conn.UnixSocket, conn.Transport.Timeout)
+				}
 				tlsDialer := transport.TestTLSDialer(d, netDialer, tls, conn.Transport.Timeout)
 				_, err = tlsDialer.Dial("tcp", address)
 				d.Fatal("dial up", err)
@@ -498,6 +545,8 @@ func (conn *Connection) execHTTPRequest(req *http.Request) (int, []byte, error)
 		req.Host = host
 	}
 
+	conn.reloadTLS()
+
 	resp, err := conn.HTTP.Do(req)
 	if err != nil {
 		return 0, nil, err
@@ -519,6 +568,28 @@ func (conn *Connection) execHTTPRequest(req *http.Request) (int, []byte, error)
 	return status, conn.responseBuffer.Bytes(), err
 }
 
+// reloadTLS recreates the HTTP client if the TLS client certificate files
+// changed. Requests on a connection are sequential, so no request is in
+// flight when the connections of the previous client are closed. If the new
+// certificate can't be loaded, the previous client is kept.
+func (conn *Connection) reloadTLS() {
+	reloaded, err := conn.tlsWatcher.Reload(func() error {
+		client, err := newHTTPClient(conn.ConnectionSettings, conn.transportOpts, conn.log)
+		if err != nil {
+			return err
+		}
+		conn.HTTP.CloseIdleConnections()
+		conn.HTTP = client
+		return nil
+	})
+	if err != nil {
+		conn.log.Errorf("Failed to reload the TLS client certificate, keeping the current one: %v", err)
+	}
+	if reloaded {
+		conn.log.Info("TLS client certificate reloaded")
+	}
+}
+
 func closing(c io.Closer, logger *logp.Logger) {
 	err := c.Close()
 	if err != nil {
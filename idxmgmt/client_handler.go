@@ -37,6 +37,15 @@ type clientHandler struct {
 	template.Loader
 }
 
+// ESClient returns the Elasticsearch client of the lifecycle client
+// handler, or nil if the handler does not talk to Elasticsearch.
+func (h *clientHandler) ESClient() lifecycle.ESClient {
+	if p, ok := h.ClientHandler.(interface{ ESClient() lifecycle.ESClient }); ok {
+		return p.ESClient()
+	}
+	return nil
+}
+
 // ESClient defines the minimal interface required for the index manager to
 // prepare an index.
 type ESClient interface {
@@ -139,7 +139,23 @@ func MakeDefaultSupport(ilmSupport lifecycle.SupportFactory) SupportFactory {
 			return nil, err
 		}
 
-		return newIndexSupport(log, info, ilmSupport, cfg.Template, enabled, cfg.Migration.Enabled())
+		lifecycleSupport := ilmSupport
+		if cfg.Lifecycle.ILM != nil && cfg.Lifecycle.ILM.HasField("retention") {
+			retention := lifecycle.DefaultRetentionConfig()
+			sub, err := cfg.Lifecycle.ILM.Child("retention", -1)
+			if err != nil {
+				return nil, fmt.Errorf("error reading setup.ilm.retention: %w", err)
+			}
+			if err := sub.Unpack(&retention); err != nil {
+				return nil, fmt.Errorf("error unpacking setup.ilm.retention: %w", err)
+			}
+			if retention.Enabled {
+				lifecycleSupport = lifecycle.RetentionSupport(retention)
+				enabled = true
+			}
+		}
+
+		return newIndexSupport(log, info, lifecycleSupport, cfg.Template, enabled, cfg.Migration.Enabled())
 	}
 }
 
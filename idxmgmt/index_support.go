@@ -18,6 +18,7 @@
 package idxmgmt
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -140,6 +141,14 @@ func (s *indexSupport) enabled(c componentType) bool {
 	return false
 }
 
+// Run runs the background tasks of the lifecycle support, like the
+// retention schedule, until ctx is cancelled.
+func (s *indexSupport) Run(ctx context.Context) {
+	if r, ok := s.ilm.(lifecycle.Runner); ok {
+		r.Run(ctx)
+	}
+}
+
 // Manager returns an indexManager object that
 // can be used to perform the actual setup functions for the provided index management features
 func (s *indexSupport) Manager(
@@ -284,7 +293,7 @@ func (m *indexManager) Setup(loadTemplate, loadILM LoadMode) error {
 		tmplCfg := m.support.templateCfg
 		tmplCfg.Overwrite, tmplCfg.Enabled = templateComponent.overwrite, templateComponent.enabled
 
-		if ilmComponent.enabled {
+		if ilmComponent.enabled && lifecycle.UsesTemplateSettings(m.ilm) {
 			tmplCfg, err = applyLifecycleSettingsToTemplate(log, tmplCfg, m.clientHandler)
 			if err != nil {
 				return fmt.Errorf("error applying ILM settings: %w", err)
@@ -179,6 +179,11 @@ func (h *ESClientHandler) CreatePolicyFromConfig() error {
 	return nil
 }
 
+// ESClient returns the Elasticsearch client used by the handler
+func (h *ESClientHandler) ESClient() ESClient {
+	return h.client
+}
+
 // PolicyName returns the policy name
 func (h *ESClientHandler) PolicyName() string {
 	return h.name
@@ -18,6 +18,8 @@
 package lifecycle
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/njcx/libbeat_v8/beat"
@@ -57,6 +59,83 @@ type Manager interface {
 	EnsurePolicy(overwrite bool) (created bool, err error)
 }
 
+// Runner can be implemented by a Supporter running background tasks while
+// the Beat is running.
+type Runner interface {
+	// Run runs the background tasks until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+// TemplateSettingsUser can be implemented by a Manager to signal whether
+// the lifecycle settings are to be added to the index template. Managers
+// not implementing the interface use the template settings.
+type TemplateSettingsUser interface {
+	UsesTemplateSettings() bool
+}
+
+// UsesTemplateSettings checks if the lifecycle settings of m are to be
+// added to the index template.
+func UsesTemplateSettings(m Manager) bool {
+	if u, ok := m.(TemplateSettingsUser); ok {
+		return u.UsesTemplateSettings()
+	}
+	return true
+}
+
+// PolicyStatus describes the outcome of the last attempt to apply a
+// lifecycle policy.
+type PolicyStatus struct {
+	// Enabled is false if the manager does not apply any policy.
+	Enabled bool
+	// Applied is true once the policy has been applied or found to exist.
+	Applied bool
+	// LastError is the error of the last attempt, nil if it succeeded.
+	LastError error
+	// At is the time of the last attempt, zero if no attempt was made yet.
+	At time.Time
+}
+
+// StatusReporter can be implemented by a Manager to report the state of
+// its policy, e.g. for health reporting.
+type StatusReporter interface {
+	PolicyStatus() PolicyStatus
+}
+
+// PolicyStatusOf returns the policy status of m. Managers not
+// implementing StatusReporter are reported as enabled without any
+// attempt made.
+func PolicyStatusOf(m Manager) PolicyStatus {
+	if r, ok := m.(StatusReporter); ok {
+		return r.PolicyStatus()
+	}
+	return PolicyStatus{Enabled: true}
+}
+
+// policyState records policy status updates. It is shared by all managers
+// created from the same Supporter.
+type policyState struct {
+	mu     sync.Mutex
+	status PolicyStatus
+}
+
+func (s *policyState) update(applied bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.status.Applied = s.status.Applied || applied
+	}
+	s.status.LastError = err
+	s.status.At = time.Now()
+}
+
+func (s *policyState) get() PolicyStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.status
+}
+
 // Policy describes a policy to be loaded into Elasticsearch.
 // See: [Policy phases and actions documentation](https://www.elastic.co/guide/en/elasticsearch/reference/master/ilm-policy-definition.html).
 type Policy struct {
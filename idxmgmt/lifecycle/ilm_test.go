@@ -212,6 +212,56 @@ func runEnsurePolicyTest(t *testing.T, testPolicy Policy, cfg LifecycleConfig) {
 	}
 }
 
+func TestDefaultSupport_Manager_PolicyStatus(t *testing.T) {
+	cfg := DefaultILMConfig(beat.Info{Name: "test"})
+	testPolicy := Policy{Name: "test", Body: DefaultILMPolicy}
+
+	info := beat.Info{Beat: "test", Version: "9.9.9"}
+	s, err := DefaultSupport(nil, info, true)
+	require.NoError(t, err)
+
+	h := newMockHandler(cfg, testPolicy,
+		onCheckExists().Return(true),
+		onHasPolicy().Return(false, nil),
+	)
+	h.On("CreatePolicyFromConfig").Return(ErrRequestFailed).Once()
+	h.On("CreatePolicyFromConfig").Return(nil).Once()
+
+	status := PolicyStatusOf(s.Manager(h))
+	assert.True(t, status.Enabled)
+	assert.False(t, status.Applied)
+	assert.True(t, status.At.IsZero())
+
+	_, err = s.Manager(h).EnsurePolicy(false)
+	require.Error(t, err)
+	status = PolicyStatusOf(s.Manager(h))
+	assert.False(t, status.Applied)
+	assert.ErrorIs(t, status.LastError, ErrRequestFailed)
+	assert.False(t, status.At.IsZero())
+
+	// status is shared by all managers of the supporter
+	_, err = s.Manager(h).EnsurePolicy(false)
+	require.NoError(t, err)
+	status = PolicyStatusOf(s.Manager(h))
+	assert.True(t, status.Applied)
+	assert.NoError(t, status.LastError)
+}
+
+func TestStdSupport_Manager_PolicyStatusDisabled(t *testing.T) {
+	cfg := DefaultILMConfig(beat.Info{Name: "test"})
+	testPolicy := Policy{Name: "test", Body: DefaultILMPolicy}
+
+	s := NewStdSupport(nil, false)
+	h := newMockHandler(cfg, testPolicy)
+	assert.Equal(t, PolicyStatus{}, PolicyStatusOf(s.Manager(h)))
+}
+
+func TestNoopSupport_Manager_PolicyStatus(t *testing.T) {
+	s, err := NoopSupport(nil, beat.Info{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, PolicyStatus{}, PolicyStatusOf(s.Manager(nil)))
+}
+
 func createManager(t *testing.T, h ClientHandler, enabled bool) Manager {
 	info := beat.Info{Beat: "test", Version: "9.9.9"}
 	s, err := DefaultSupport(nil, info, enabled)
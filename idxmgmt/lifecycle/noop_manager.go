@@ -50,3 +50,6 @@ func (*noopManager) EnsurePolicy(_ bool) (bool, error) { return false, ErrOpNotA
 
 // Policyname no-op
 func (*noopManager) PolicyName() string { return "" }
+
+// PolicyStatus no-op, reports the policy as disabled
+func (*noopManager) PolicyStatus() PolicyStatus { return PolicyStatus{} }
@@ -0,0 +1,282 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// RetentionConfig configures the retention manager, deleting indices older
+// than MaxAgeDays on a schedule. It is an alternative to ILM for
+// self-managed clusters without lifecycle policies.
+type RetentionConfig struct {
+	Enabled bool `config:"enabled"`
+
+	// Pattern selects the indices to be deleted. Defaults to the index
+	// prefix of the beat followed by `-*`.
+	Pattern string `config:"pattern"`
+
+	// MaxAgeDays is the age after which an index is deleted, based on the
+	// index creation date.
+	MaxAgeDays int `config:"max_age_days" validate:"min=1"`
+
+	// Interval between two runs.
+	Interval time.Duration `config:"interval" validate:"positive,nonzero"`
+}
+
+// DefaultRetentionConfig returns the default retention configuration.
+func DefaultRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		Enabled:    false,
+		MaxAgeDays: 30,
+		Interval:   time.Hour,
+	}
+}
+
+// Validate rejects patterns matching all indices.
+func (c *RetentionConfig) Validate() error {
+	switch strings.TrimSpace(c.Pattern) {
+	case "*", "_all", "-*":
+		return fmt.Errorf("retention pattern '%s' matches all indices", c.Pattern)
+	}
+	return nil
+}
+
+// esClientProvider is implemented by client handlers giving access to the
+// Elasticsearch client.
+type esClientProvider interface {
+	ESClient() ESClient
+}
+
+// retentionSupport holds the retention config and the state of the
+// deletion schedule, shared by all managers created from it.
+type retentionSupport struct {
+	log      *logp.Logger
+	pattern  string
+	maxAge   time.Duration
+	interval time.Duration
+
+	state policyState
+
+	mu      sync.Mutex
+	current ESClient // client used by the schedule
+	restart chan struct{}
+	now     func() time.Time
+}
+
+var _ Runner = (*retentionSupport)(nil)
+
+type retentionManager struct {
+	*retentionSupport
+	client ESClient
+}
+
+// RetentionSupport creates a SupportFactory for the retention manager.
+func RetentionSupport(cfg RetentionConfig) SupportFactory {
+	return func(log *logp.Logger, info beat.Info, _ bool) (Supporter, error) {
+		if log == nil {
+			log = logp.NewLogger("retention")
+		} else {
+			log = log.Named("retention")
+		}
+		return NewRetentionSupport(log, info, cfg)
+	}
+}
+
+// NewRetentionSupport creates a Supporter deleting indices older than the
+// configured age. Indices are only deleted while Run is running, using the
+// client passed to EnsurePolicy.
+func NewRetentionSupport(log *logp.Logger, info beat.Info, cfg RetentionConfig) (Supporter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.MaxAgeDays < 1 || cfg.Interval <= 0 {
+		return nil, errors.New("retention max_age_days and interval must be positive")
+	}
+
+	pattern := cfg.Pattern
+	if pattern == "" {
+		prefix := info.IndexPrefix
+		if prefix == "" {
+			prefix = info.Beat
+		}
+		pattern = prefix + "-*"
+	}
+
+	return &retentionSupport{
+		log:      log,
+		pattern:  pattern,
+		maxAge:   time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+		interval: cfg.Interval,
+		state:    policyState{status: PolicyStatus{Enabled: true}},
+		restart:  make(chan struct{}, 1),
+		now:      time.Now,
+	}, nil
+}
+
+// Enabled always returns true for the retention support.
+func (s *retentionSupport) Enabled() bool { return true }
+
+// Manager creates a manager deleting indices using the Elasticsearch
+// client of h. Other client handlers are not supported.
+func (s *retentionSupport) Manager(h ClientHandler) Manager {
+	m := &retentionManager{retentionSupport: s}
+	if p, ok := h.(esClientProvider); ok {
+		m.client = p.ESClient()
+	}
+	return m
+}
+
+// CheckEnabled reports whether indices can be deleted, which requires an
+// Elasticsearch connection.
+func (m *retentionManager) CheckEnabled() (bool, error) {
+	return m.client != nil, nil
+}
+
+// EnsurePolicy makes the deletion schedule use the client of the manager.
+// It doesn't delete indices itself, this is only done by Run, so setting up
+// index management doesn't delete indices as a side effect. The first
+// client, or overwrite, makes the schedule check the indices right away.
+// created is true only for the first client.
+func (m *retentionManager) EnsurePolicy(overwrite bool) (bool, error) {
+	if m.client == nil {
+		return false, ErrOpNotAvailable
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	created := m.current == nil
+	m.current = m.client
+	if !created && !overwrite {
+		m.log.Infof("retention of %v is configured already.", m.pattern)
+		return false, nil
+	}
+
+	select {
+	case m.restart <- struct{}{}:
+	default: // restart pending already
+	}
+	m.log.Infof("retention of %v configured, deleting indices older than %v every %v.", m.pattern, m.maxAge, m.interval)
+	return created, nil
+}
+
+// PolicyName returns the index pattern managed.
+func (m *retentionManager) PolicyName() string {
+	return m.pattern
+}
+
+// PolicyStatus returns the outcome of the last retention run.
+func (m *retentionManager) PolicyStatus() PolicyStatus {
+	return m.state.get()
+}
+
+// UsesTemplateSettings returns false, as the retention manager does not
+// rely on lifecycle settings in the index template.
+func (m *retentionManager) UsesTemplateSettings() bool { return false }
+
+// Run runs the deletion schedule until ctx is cancelled. Nothing is deleted
+// until EnsurePolicy provided a client.
+func (s *retentionSupport) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.restart:
+			ticker.Reset(s.interval)
+		}
+
+		s.mu.Lock()
+		client := s.current
+		s.mu.Unlock()
+		if client == nil {
+			continue
+		}
+
+		_, err := s.deleteExpired(client)
+		if err != nil {
+			s.log.Errorf("Failed to delete expired indices: %v", err)
+		}
+		s.state.update(true, err)
+	}
+}
+
+// deleteExpired deletes all indices matching the pattern created before
+// the max age. It returns the names of the deleted indices.
+func (s *retentionSupport) deleteExpired(client ESClient) ([]string, error) {
+	params := map[string]string{
+		"format": "json",
+		"h":      "index,creation.date",
+	}
+	path := "/_cat/indices/" + url.PathEscape(s.pattern)
+	status, body, err := client.Request(http.MethodGet, path, "", params, nil)
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list indices '%v': (status=%v) (err=%w) %s",
+			ErrRequestFailed, s.pattern, status, err, body)
+	}
+
+	var indices []struct {
+		Index        string `json:"index"`
+		CreationDate string `json:"creation.date"`
+	}
+	if err := json.Unmarshal(body, &indices); err != nil {
+		return nil, fmt.Errorf("failed to decode index list: %w", err)
+	}
+
+	cutoff := s.now().Add(-s.maxAge)
+	var deleted []string
+	var errs []error
+	for _, idx := range indices {
+		millis, err := strconv.ParseInt(idx.CreationDate, 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid creation date '%v' of index %v", idx.CreationDate, idx.Index))
+			continue
+		}
+		if !time.UnixMilli(millis).Before(cutoff) {
+			continue
+		}
+
+		status, body, err := client.Request(http.MethodDelete, "/"+url.PathEscape(idx.Index), "", nil, nil)
+		if err != nil && status != http.StatusNotFound {
+			errs = append(errs, fmt.Errorf("failed to delete index %v: (status=%v) (err=%w) %s", idx.Index, status, err, body))
+			continue
+		}
+		s.log.Infof("Deleted index %v created at %v.", idx.Index, time.UnixMilli(millis).UTC())
+		deleted = append(deleted, idx.Index)
+	}
+	return deleted, errors.Join(errs...)
+}
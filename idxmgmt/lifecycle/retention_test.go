@@ -0,0 +1,192 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/version"
+)
+
+type retentionESClient struct {
+	mu      sync.Mutex
+	indices map[string]time.Time
+	lists   int
+	deleted []string
+}
+
+func (c *retentionESClient) GetVersion() version.V { return *version.MustNew("8.10.1") }
+func (c *retentionESClient) IsServerless() bool    { return false }
+
+func (c *retentionESClient) Request(method, path string, _ string, _ map[string]string, _ interface{}) (int, []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch method {
+	case http.MethodGet:
+		c.lists++
+		var list []map[string]string
+		for name, created := range c.indices {
+			list = append(list, map[string]string{
+				"index":         name,
+				"creation.date": strconv.FormatInt(created.UnixMilli(), 10),
+			})
+		}
+		b, _ := json.Marshal(list)
+		return http.StatusOK, b, nil
+	case http.MethodDelete:
+		name := path[1:]
+		delete(c.indices, name)
+		c.deleted = append(c.deleted, name)
+	}
+	return http.StatusOK, []byte("{}"), nil
+}
+
+func (c *retentionESClient) listCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lists
+}
+
+type esProviderHandler struct {
+	*mockHandler
+	client ESClient
+}
+
+func (h *esProviderHandler) ESClient() ESClient { return h.client }
+
+func newTestRetentionSupport(t *testing.T, cfg RetentionConfig) *retentionSupport {
+	t.Helper()
+	s, err := NewRetentionSupport(logp.NewLogger("retention"), beat.Info{Beat: "testbeat", IndexPrefix: "testbeat"}, cfg)
+	require.NoError(t, err)
+	return s.(*retentionSupport)
+}
+
+func TestRetentionDeleteExpired(t *testing.T) {
+	now := time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC)
+	client := &retentionESClient{indices: map[string]time.Time{
+		"testbeat-2023.04.01": now.Add(-39 * 24 * time.Hour),
+		"testbeat-2023.05.01": now.Add(-9 * 24 * time.Hour),
+		"testbeat-2023.05.09": now.Add(-24 * time.Hour),
+	}}
+
+	cfg := DefaultRetentionConfig()
+	cfg.Enabled = true
+	cfg.MaxAgeDays = 7
+	s := newTestRetentionSupport(t, cfg)
+	s.now = func() time.Time { return now }
+	assert.Equal(t, "testbeat-*", s.pattern)
+
+	deleted, err := s.deleteExpired(client)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"testbeat-2023.04.01", "testbeat-2023.05.01"}, deleted)
+	assert.Equal(t, []string{"testbeat-2023.05.09"}, keys(client.indices))
+}
+
+func TestRetentionEnsurePolicy(t *testing.T) {
+	client := &retentionESClient{indices: map[string]time.Time{}}
+	cfg := DefaultRetentionConfig()
+	cfg.Enabled = true
+	s := newTestRetentionSupport(t, cfg)
+
+	h := &esProviderHandler{mockHandler: newMockHandler(LifecycleConfig{}, Policy{}), client: client}
+	m := s.Manager(h)
+
+	enabled, err := m.CheckEnabled()
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	assert.False(t, UsesTemplateSettings(m))
+
+	// setting up index management doesn't delete anything
+	created, err := m.EnsurePolicy(false)
+	require.NoError(t, err)
+	assert.True(t, created)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 0, client.listCount())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+	require.Eventually(t, func() bool { return client.listCount() == 1 }, time.Second, time.Millisecond)
+
+	// a second manager shares the running schedule
+	created, err = s.Manager(h).EnsurePolicy(false)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	// overwrite re-applies the schedule and runs right away
+	created, err = s.Manager(h).EnsurePolicy(true)
+	require.NoError(t, err)
+	assert.False(t, created)
+	require.Eventually(t, func() bool { return client.listCount() == 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("retention schedule did not stop")
+	}
+}
+
+func TestRetentionWithoutElasticsearch(t *testing.T) {
+	cfg := DefaultRetentionConfig()
+	cfg.Enabled = true
+	s := newTestRetentionSupport(t, cfg)
+
+	m := s.Manager(newMockHandler(LifecycleConfig{}, Policy{}))
+	enabled, err := m.CheckEnabled()
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	_, err = m.EnsurePolicy(true)
+	assert.ErrorIs(t, err, ErrOpNotAvailable)
+}
+
+func TestRetentionConfigValidate(t *testing.T) {
+	for _, pattern := range []string{"*", "_all"} {
+		cfg := DefaultRetentionConfig()
+		cfg.Pattern = pattern
+		assert.Error(t, cfg.Validate(), pattern)
+	}
+
+	cfg := DefaultRetentionConfig()
+	cfg.Pattern = "logs-*"
+	assert.NoError(t, cfg.Validate())
+}
+
+func keys(m map[string]time.Time) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
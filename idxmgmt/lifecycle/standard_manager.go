@@ -28,6 +28,7 @@ import (
 type stdSupport struct {
 	log              *logp.Logger
 	lifecycleEnabled bool
+	state            policyState
 }
 
 // stdManager creates, checks, and updates lifecycle policies.
@@ -55,6 +56,7 @@ func NewStdSupport(
 	return &stdSupport{
 		log:              log,
 		lifecycleEnabled: lifecycleEnabled,
+		state:            policyState{status: PolicyStatus{Enabled: lifecycleEnabled}},
 	}
 }
 
@@ -86,6 +88,11 @@ func (m *stdManager) CheckEnabled() (bool, error) {
 	return ilmEnabled, nil
 }
 
+// PolicyStatus returns the outcome of the last EnsurePolicy call.
+func (m *stdManager) PolicyStatus() PolicyStatus {
+	return m.state.get()
+}
+
 // EnsurePolicy creates the upstream lifecycle policy, depending on if it exists, and if overwrite is set.
 // returns true if the policy has been created
 func (m *stdManager) EnsurePolicy(overwrite bool) (bool, error) {
@@ -102,23 +109,28 @@ func (m *stdManager) EnsurePolicy(overwrite bool) (bool, error) {
 		var err error
 		exists, err = m.client.HasPolicy()
 		if err != nil {
-			return false, fmt.Errorf("error checking if policy %s exists: %w", name, err)
+			err = fmt.Errorf("error checking if policy %s exists: %w", name, err)
+			m.state.update(false, err)
+			return false, err
 		}
 	}
 
 	switch {
 	case exists && !overwrite:
 		log.Infof("lifecycle policy %v exists already.", name)
+		m.state.update(true, nil)
 		return false, nil
 
 	case !exists || overwrite:
 		err := m.client.CreatePolicyFromConfig()
 		if err != nil {
 			log.Errorf("lifecycle policy %v creation failed: %v", name, err)
+			m.state.update(false, err)
 			return false, err
 		}
 
 		log.Infof("lifecycle policy %v successfully created.", name)
+		m.state.update(true, nil)
 		return true, err
 
 	default:
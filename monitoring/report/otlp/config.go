@@ -0,0 +1,98 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+)
+
+const (
+	protocolGRPC = "grpc"
+	protocolHTTP = "http"
+
+	defaultGRPCEndpoint = "localhost:4317"
+	defaultHTTPEndpoint = "http://localhost:4318"
+	metricsPath         = "/v1/metrics"
+)
+
+type config struct {
+	// Protocol is either grpc or http, the latter sending protobuf encoded
+	// requests.
+	Protocol   string            `config:"protocol"`
+	Endpoint   string            `config:"endpoint"`
+	Headers    map[string]string `config:"headers"`
+	Period     time.Duration     `config:"period" validate:"nonzero,positive"`
+	Namespaces []string          `config:"namespaces"`
+
+	Transport httpcommon.HTTPTransportSettings `config:",inline"`
+}
+
+func defaultConfig() config {
+	return config{
+		Protocol:   protocolGRPC,
+		Period:     10 * time.Second,
+		Namespaces: []string{"stats"},
+		Transport:  httpcommon.DefaultHTTPTransportSettings(),
+	}
+}
+
+func (c *config) Validate() error {
+	switch c.Protocol {
+	case protocolGRPC, protocolHTTP:
+	default:
+		return fmt.Errorf("unsupported protocol %q, must be %q or %q", c.Protocol, protocolGRPC, protocolHTTP)
+	}
+	if len(c.Namespaces) == 0 {
+		return errors.New("at least one monitoring namespace must be reported")
+	}
+	return nil
+}
+
+// endpoint returns the address to export to: host:port for gRPC, and the
+// full URL of the metrics endpoint for HTTP.
+func (c *config) endpoint() (string, error) {
+	if c.Protocol == protocolGRPC {
+		if c.Endpoint == "" {
+			return defaultGRPCEndpoint, nil
+		}
+		return c.Endpoint, nil
+	}
+
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = defaultHTTPEndpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid endpoint %q: the scheme must be http or https", endpoint)
+	}
+	// Like the OTLP exporters, append the metrics path to base URLs.
+	if strings.TrimSuffix(u.Path, "/") == "" {
+		u.Path = metricsPath
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,151 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+// exporter sends metrics to an OTLP endpoint.
+type exporter interface {
+	Export(ctx context.Context, md pmetric.Metrics) error
+	Close() error
+}
+
+func newExporter(config config, userAgent string) (exporter, error) {
+	endpoint, err := config.endpoint()
+	if err != nil {
+		return nil, err
+	}
+	if config.Protocol == protocolHTTP {
+		return newHTTPExporter(config, endpoint, userAgent)
+	}
+	return newGRPCExporter(config, endpoint, userAgent)
+}
+
+type grpcExporter struct {
+	conn    *grpc.ClientConn
+	client  pmetricotlp.GRPCClient
+	headers metadata.MD
+}
+
+// newGRPCExporter creates a gRPC client for the endpoint. The connection is
+// established lazily, so an unreachable collector doesn't fail the setup.
+func newGRPCExporter(config config, endpoint, userAgent string) (*grpcExporter, error) {
+	creds := insecure.NewCredentials()
+	tlsConfig, err := tlscommon.LoadTLSConfig(config.Transport.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS configuration: %w", err)
+	}
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig.BuildModuleClientConfig(endpoint))
+	}
+
+	conn, err := grpc.NewClient(endpoint,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent(userAgent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client for %v: %w", endpoint, err)
+	}
+	return &grpcExporter{
+		conn:    conn,
+		client:  pmetricotlp.NewGRPCClient(conn),
+		headers: metadata.New(config.Headers),
+	}, nil
+}
+
+func (e *grpcExporter) Export(ctx context.Context, md pmetric.Metrics) error {
+	if len(e.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, e.headers)
+	}
+	_, err := e.client.Export(ctx, pmetricotlp.NewExportRequestFromMetrics(md))
+	return err
+}
+
+func (e *grpcExporter) Close() error {
+	return e.conn.Close()
+}
+
+type httpExporter struct {
+	client    *http.Client
+	url       string
+	headers   map[string]string
+	userAgent string
+}
+
+func newHTTPExporter(config config, url, userAgent string) (*httpExporter, error) {
+	client, err := config.Transport.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	return &httpExporter{
+		client:    client,
+		url:       url,
+		headers:   config.Headers,
+		userAgent: userAgent,
+	}, nil
+}
+
+func (e *httpExporter) Export(ctx context.Context, md pmetric.Metrics) error {
+	body, err := pmetricotlp.NewExportRequestFromMetrics(md).MarshalProto()
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	if e.userAgent != "" {
+		req.Header.Set("User-Agent", e.userAgent)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%v responded with %v", e.url, resp.Status)
+	}
+	return nil
+}
+
+func (e *httpExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
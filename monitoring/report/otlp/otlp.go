@@ -0,0 +1,242 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/monitoring/report"
+	"github.com/njcx/libbeat_v8/monitoring/report/gauge"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+const (
+	logSelector = "monitoring"
+	scopeName   = "github.com/njcx/libbeat_v8/monitoring"
+)
+
+func init() {
+	report.RegisterReporterFactory("otlp", makeReporter)
+}
+
+type reporter struct {
+	config
+	exporter   exporter
+	registries map[string]*monitoring.Registry
+	info       beat.Info
+	start      time.Time
+
+	wg   sync.WaitGroup
+	done chan struct{}
+	log  *logp.Logger
+}
+
+func makeReporter(beat beat.Info, _ report.Settings, cfg *conf.C) (report.Reporter, error) {
+	config := defaultConfig()
+	if cfg != nil {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	exp, err := newExporter(config, beat.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	r := newReporter(config, beat, exp)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.snapshotLoop()
+	}()
+	return r, nil
+}
+
+func newReporter(config config, info beat.Info, exp exporter) *reporter {
+	start := info.StartTime
+	if start.IsZero() {
+		start = time.Now()
+	}
+
+	r := &reporter{
+		config:     config,
+		exporter:   exp,
+		registries: map[string]*monitoring.Registry{},
+		info:       info,
+		start:      start,
+		done:       make(chan struct{}),
+		log:        logp.NewLogger(logSelector),
+	}
+	for _, ns := range config.Namespaces {
+		r.registries[ns] = monitoring.GetNamespace(ns).GetRegistry()
+	}
+	return r
+}
+
+func (r *reporter) Stop() {
+	close(r.done)
+	r.wg.Wait()
+	if err := r.exporter.Close(); err != nil {
+		r.log.Debugf("Failed to close OTLP exporter: %v", err)
+	}
+}
+
+func (r *reporter) snapshotLoop() {
+	r.log.Infof("Start exporting metrics over OTLP/%v every %v", r.Protocol, r.Period)
+	defer r.log.Info("Stop exporting metrics over OTLP")
+
+	ticker := time.NewTicker(r.Period)
+	defer ticker.Stop()
+
+	failing := false
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+
+		err := r.export(time.Now())
+		// Export errors are not fatal, metrics are dropped until the
+		// collector is reachable again. Only state changes are logged to
+		// not flood the logs.
+		switch {
+		case err != nil && !failing:
+			r.log.Warnf("Failed to export metrics over OTLP: %v", err)
+		case err != nil:
+			r.log.Debugf("Failed to export metrics over OTLP: %v", err)
+		case failing:
+			r.log.Info("Exporting metrics over OTLP succeeded again")
+		}
+		failing = err != nil
+	}
+}
+
+func (r *reporter) export(now time.Time) error {
+	// Don't let a slow collector delay the next export.
+	timeout := r.Transport.Timeout
+	if timeout <= 0 || timeout > r.Period {
+		timeout = r.Period
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return r.exporter.Export(ctx, r.makeMetrics(now))
+}
+
+// makeMetrics converts the monitored registries to OTel metrics. Counters
+// become cumulative sums starting at the Beat start time, gauges and boolean
+// values become gauges. Strings are not reported.
+func (r *reporter) makeMetrics(now time.Time) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	r.setResourceAttributes(rm.Resource().Attributes())
+
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(scopeName)
+	sm.Scope().SetVersion(r.info.Version)
+
+	b := metricsBuilder{
+		metrics: sm.Metrics(),
+		start:   pcommon.NewTimestampFromTime(r.start),
+		now:     pcommon.NewTimestampFromTime(now),
+	}
+	for _, ns := range sortedKeys(r.registries) {
+		snap := monitoring.CollectFlatSnapshot(r.registries[ns], monitoring.Full, false)
+
+		// Keep the metric names of the default namespace short, others are
+		// prefixed by their namespace to avoid collisions.
+		prefix := ""
+		if ns != "stats" {
+			prefix = ns + "."
+		}
+		for _, k := range sortedKeys(snap.Ints) {
+			b.numberDataPoint(prefix+k, gauge.Is(k)).SetIntValue(snap.Ints[k])
+		}
+		for _, k := range sortedKeys(snap.Floats) {
+			b.numberDataPoint(prefix+k, gauge.Is(k)).SetDoubleValue(snap.Floats[k])
+		}
+		for _, k := range sortedKeys(snap.Bools) {
+			var v int64
+			if snap.Bools[k] {
+				v = 1
+			}
+			b.numberDataPoint(prefix+k, true).SetIntValue(v)
+		}
+	}
+	return md
+}
+
+func (r *reporter) setResourceAttributes(attrs pcommon.Map) {
+	attrs.PutStr("service.name", r.info.Beat)
+	if r.info.Version != "" {
+		attrs.PutStr("service.version", r.info.Version)
+	}
+	if !r.info.ID.IsNil() {
+		attrs.PutStr("service.instance.id", r.info.ID.String())
+	}
+	if r.info.Hostname != "" {
+		attrs.PutStr("host.name", r.info.Hostname)
+	}
+	if r.info.Name != "" {
+		attrs.PutStr("beat.name", r.info.Name)
+	}
+}
+
+type metricsBuilder struct {
+	metrics    pmetric.MetricSlice
+	start, now pcommon.Timestamp
+}
+
+// numberDataPoint adds a metric with a single data point and returns the
+// data point for its value to be set.
+func (b *metricsBuilder) numberDataPoint(name string, isGauge bool) pmetric.NumberDataPoint {
+	m := b.metrics.AppendEmpty()
+	m.SetName(name)
+
+	var dp pmetric.NumberDataPoint
+	if isGauge {
+		dp = m.SetEmptyGauge().DataPoints().AppendEmpty()
+	} else {
+		sum := m.SetEmptySum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		dp = sum.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(b.start)
+	}
+	dp.SetTimestamp(b.now)
+	return dp
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,210 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/monitoring/report"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func TestMakeMetrics(t *testing.T) {
+	reg := monitoring.GetNamespace("otlp_test_metrics").GetRegistry()
+	monitoring.NewInt(reg, "events.total").Set(42)
+	monitoring.NewInt(reg, "events.active_gauge").Set(3)
+	monitoring.NewFloat(reg, "load_gauge").Set(0.5)
+	monitoring.NewBool(reg, "enabled").Set(true)
+	monitoring.NewString(reg, "name").Set("ignored")
+
+	config := defaultConfig()
+	config.Namespaces = []string{"otlp_test_metrics"}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(time.Minute)
+	info := beat.Info{
+		Beat:      "testbeat",
+		Version:   "8.0.0",
+		Hostname:  "host1",
+		ID:        uuid.Must(uuid.NewV4()),
+		StartTime: start,
+	}
+	r := newReporter(config, info, nil)
+
+	md := r.makeMetrics(now)
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+	rm := md.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]interface{}{
+		"service.name":        "testbeat",
+		"service.version":     "8.0.0",
+		"service.instance.id": info.ID.String(),
+		"host.name":           "host1",
+	}, rm.Resource().Attributes().AsRaw())
+
+	metrics := map[string]pmetric.Metric{}
+	ms := rm.ScopeMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		metrics[ms.At(i).Name()] = ms.At(i)
+	}
+	require.Len(t, metrics, 4)
+
+	counter := metrics["otlp_test_metrics.events.total"]
+	require.Equal(t, pmetric.MetricTypeSum, counter.Type())
+	assert.True(t, counter.Sum().IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, counter.Sum().AggregationTemporality())
+	dp := counter.Sum().DataPoints().At(0)
+	assert.Equal(t, int64(42), dp.IntValue())
+	assert.Equal(t, start, dp.StartTimestamp().AsTime())
+	assert.Equal(t, now, dp.Timestamp().AsTime())
+
+	gauge := metrics["otlp_test_metrics.events.active_gauge"]
+	require.Equal(t, pmetric.MetricTypeGauge, gauge.Type())
+	assert.Equal(t, int64(3), gauge.Gauge().DataPoints().At(0).IntValue())
+
+	load := metrics["otlp_test_metrics.load_gauge"]
+	require.Equal(t, pmetric.MetricTypeGauge, load.Type())
+	assert.Equal(t, 0.5, load.Gauge().DataPoints().At(0).DoubleValue())
+
+	enabled := metrics["otlp_test_metrics.enabled"]
+	require.Equal(t, pmetric.MetricTypeGauge, enabled.Type())
+	assert.Equal(t, int64(1), enabled.Gauge().DataPoints().At(0).IntValue())
+}
+
+func TestHTTPExport(t *testing.T) {
+	reg := monitoring.GetNamespace("otlp_test_http").GetRegistry()
+	monitoring.NewInt(reg, "pipeline.clients_gauge").Set(7)
+
+	requests := make(chan pmetricotlp.ExportRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/metrics", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		req := pmetricotlp.NewExportRequest()
+		assert.NoError(t, req.UnmarshalProto(body))
+		requests <- req
+	}))
+	defer server.Close()
+
+	cfg := conf.MustNewConfigFrom(mapstr.M{
+		"protocol":   "http",
+		"endpoint":   server.URL,
+		"period":     "10ms",
+		"headers":    map[string]string{"Authorization": "secret"},
+		"namespaces": []string{"otlp_test_http"},
+	})
+	r, err := makeReporter(beat.Info{Beat: "testbeat"}, report.Settings{}, cfg)
+	require.NoError(t, err)
+	defer r.Stop()
+
+	select {
+	case req := <-requests:
+		ms := req.Metrics().ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+		require.Equal(t, 1, ms.Len())
+		assert.Equal(t, "otlp_test_http.pipeline.clients_gauge", ms.At(0).Name())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no metrics received")
+	}
+}
+
+func TestUnreachableCollectorIsNotFatal(t *testing.T) {
+	for _, protocol := range []string{"grpc", "http"} {
+		t.Run(protocol, func(t *testing.T) {
+			endpoint := "127.0.0.1:1"
+			if protocol == "http" {
+				endpoint = "http://" + endpoint
+			}
+			cfg := conf.MustNewConfigFrom(mapstr.M{
+				"protocol": protocol,
+				"endpoint": endpoint,
+				"period":   "10ms",
+			})
+			r, err := makeReporter(beat.Info{Beat: "testbeat"}, report.Settings{}, cfg)
+			require.NoError(t, err)
+
+			time.Sleep(50 * time.Millisecond)
+			r.Stop()
+		})
+	}
+}
+
+func TestConfig(t *testing.T) {
+	tests := map[string]struct {
+		config   mapstr.M
+		endpoint string
+		wantErr  bool
+	}{
+		"default grpc endpoint": {
+			config:   mapstr.M{},
+			endpoint: "localhost:4317",
+		},
+		"default http endpoint": {
+			config:   mapstr.M{"protocol": "http"},
+			endpoint: "http://localhost:4318/v1/metrics",
+		},
+		"http base URL": {
+			config:   mapstr.M{"protocol": "http", "endpoint": "https://collector:4318/"},
+			endpoint: "https://collector:4318/v1/metrics",
+		},
+		"http custom path": {
+			config:   mapstr.M{"protocol": "http", "endpoint": "https://collector/otlp/metrics"},
+			endpoint: "https://collector/otlp/metrics",
+		},
+		"http endpoint without scheme": {
+			config:  mapstr.M{"protocol": "http", "endpoint": "collector:4318"},
+			wantErr: true,
+		},
+		"unknown protocol": {
+			config:  mapstr.M{"protocol": "udp"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := defaultConfig()
+			err := conf.MustNewConfigFrom(test.config).Unpack(&config)
+			if err == nil {
+				var endpoint string
+				endpoint, err = config.endpoint()
+				if !test.wantErr {
+					assert.Equal(t, test.endpoint, endpoint)
+				}
+			}
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"errors"
+	"time"
+)
+
+type config struct {
+	// Host is the address of the StatsD server, in host:port form.
+	Host          string            `config:"host" validate:"required"`
+	Period        time.Duration     `config:"period" validate:"nonzero,positive"`
+	Prefix        string            `config:"prefix"`
+	Tags          map[string]string `config:"tags"`
+	Namespaces    []string          `config:"namespaces"`
+	MaxPacketSize int               `config:"max_packet_size" validate:"min=1"`
+}
+
+func defaultConfig(prefix string) config {
+	return config{
+		Host:          "localhost:8125",
+		Period:        10 * time.Second,
+		Prefix:        prefix,
+		Namespaces:    []string{"stats"},
+		MaxPacketSize: 1432,
+	}
+}
+
+func (c *config) Validate() error {
+	if len(c.Namespaces) == 0 {
+		return errors.New("at least one monitoring namespace must be reported")
+	}
+	return nil
+}
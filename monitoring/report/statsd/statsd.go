@@ -0,0 +1,224 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/monitoring/report"
+	"github.com/njcx/libbeat_v8/monitoring/report/gauge"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+const logSelector = "monitoring"
+
+func init() {
+	report.RegisterReporterFactory("statsd", makeReporter)
+}
+
+type reporter struct {
+	config
+	conn       net.Conn
+	registries map[string]*monitoring.Registry
+	tags       string
+
+	// last holds the previous counter values, used to compute the
+	// increments sent to StatsD.
+	last map[string]float64
+
+	wg   sync.WaitGroup
+	done chan struct{}
+	log  *logp.Logger
+}
+
+// sanitizer replaces the characters that have a meaning in the StatsD line
+// protocol.
+var sanitizer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", "\n", "_", " ", "_")
+
+func makeReporter(beat beat.Info, _ report.Settings, cfg *conf.C) (report.Reporter, error) {
+	config := defaultConfig(beat.Beat)
+	if cfg != nil {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := net.Dial("udp", config.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to StatsD server %v: %w", config.Host, err)
+	}
+
+	r := newReporter(config, conn)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.snapshotLoop()
+	}()
+	return r, nil
+}
+
+func newReporter(config config, conn net.Conn) *reporter {
+	r := &reporter{
+		config:     config,
+		conn:       conn,
+		registries: map[string]*monitoring.Registry{},
+		tags:       formatTags(config.Tags),
+		last:       map[string]float64{},
+		done:       make(chan struct{}),
+		log:        logp.NewLogger(logSelector),
+	}
+	for _, ns := range config.Namespaces {
+		r.registries[ns] = monitoring.GetNamespace(ns).GetRegistry()
+	}
+	return r
+}
+
+func (r *reporter) Stop() {
+	close(r.done)
+	r.wg.Wait()
+	r.conn.Close()
+}
+
+func (r *reporter) snapshotLoop() {
+	r.log.Infof("Start sending metrics to StatsD server %v every %v", r.Host, r.Period)
+	defer r.log.Info("Stop sending metrics to StatsD")
+
+	ticker := time.NewTicker(r.Period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+
+		for _, packet := range makePackets(r.collect(), r.MaxPacketSize) {
+			if _, err := r.conn.Write(packet); err != nil {
+				r.log.Debugf("Failed to send metrics to StatsD: %v", err)
+			}
+		}
+	}
+}
+
+// collect flattens the monitored registries into StatsD lines. Gauges are
+// reported with their current value, counters with their increment since
+// the previous call. Strings can't be represented in StatsD and are skipped.
+func (r *reporter) collect() []string {
+	var lines []string
+	for ns, reg := range r.registries {
+		snap := monitoring.CollectFlatSnapshot(reg, monitoring.Full, false)
+
+		for k, v := range snap.Ints {
+			lines = r.appendMetric(lines, ns, k, float64(v))
+		}
+		for k, v := range snap.Floats {
+			lines = r.appendMetric(lines, ns, k, v)
+		}
+		for k, v := range snap.Bools {
+			var f float64
+			if v {
+				f = 1
+			}
+			lines = append(lines, r.format(ns, k, f, "g"))
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func (r *reporter) appendMetric(lines []string, ns, key string, value float64) []string {
+	if gauge.Is(key) {
+		return append(lines, r.format(ns, key, value, "g"))
+	}
+
+	id := ns + ":" + key
+	prev, found := r.last[id]
+	r.last[id] = value
+	delta := value - prev
+	if found && delta < 0 {
+		// The counter has been reset.
+		delta = value
+	}
+	if delta == 0 {
+		return lines
+	}
+	return append(lines, r.format(ns, key, delta, "c"))
+}
+
+func (r *reporter) format(ns, key string, value float64, typ string) string {
+	name := sanitizer.Replace(key)
+	// Keep the metric names of the default namespace short, others are
+	// prefixed by their namespace to avoid collisions.
+	if ns != "stats" {
+		name = ns + "." + name
+	}
+	if r.Prefix != "" {
+		name = r.Prefix + "." + name
+	}
+	return name + ":" + strconv.FormatFloat(value, 'f', -1, 64) + "|" + typ + r.tags
+}
+
+// formatTags returns the DogStatsD tags suffix for the given tags, or an
+// empty string if there are none.
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		tag := sanitizer.Replace(k)
+		if v != "" {
+			tag += ":" + strings.NewReplacer("|", "_", ",", "_", "\n", "_").Replace(v)
+		}
+		pairs = append(pairs, tag)
+	}
+	sort.Strings(pairs)
+	return "|#" + strings.Join(pairs, ",")
+}
+
+// makePackets joins lines into newline separated packets of at most max
+// bytes. Lines longer than max are sent in a packet of their own.
+func makePackets(lines []string, max int) [][]byte {
+	var packets [][]byte
+	var buf []byte
+	for _, line := range lines {
+		if len(buf) > 0 && len(buf)+1+len(line) > max {
+			packets = append(packets, buf)
+			buf = nil
+		}
+		if len(buf) > 0 {
+			buf = append(buf, '\n')
+		}
+		buf = append(buf, line...)
+	}
+	if len(buf) > 0 {
+		packets = append(packets, buf)
+	}
+	return packets
+}
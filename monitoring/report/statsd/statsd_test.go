@@ -0,0 +1,126 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/monitoring/report"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func TestCollect(t *testing.T) {
+	reg := monitoring.GetNamespace("statsd_test_collect").GetRegistry()
+	events := monitoring.NewInt(reg, "output.events.total")
+	active := monitoring.NewInt(reg, "queue.active_gauge")
+	enabled := monitoring.NewBool(reg, "enabled")
+	monitoring.NewString(reg, "name").Set("ignored")
+
+	config := defaultConfig("testbeat")
+	config.Namespaces = []string{"statsd_test_collect"}
+	config.Tags = map[string]string{"env": "prod", "team": ""}
+	r := newReporter(config, nil)
+
+	events.Set(10)
+	active.Set(3)
+	enabled.Set(true)
+	assert.Equal(t, []string{
+		"testbeat.statsd_test_collect.enabled:1|g|#env:prod,team",
+		"testbeat.statsd_test_collect.output.events.total:10|c|#env:prod,team",
+		"testbeat.statsd_test_collect.queue.active_gauge:3|g|#env:prod,team",
+	}, r.collect())
+
+	// Counters only report their increment, and only if they changed.
+	events.Set(15)
+	active.Set(1)
+	assert.Equal(t, []string{
+		"testbeat.statsd_test_collect.enabled:1|g|#env:prod,team",
+		"testbeat.statsd_test_collect.output.events.total:5|c|#env:prod,team",
+		"testbeat.statsd_test_collect.queue.active_gauge:1|g|#env:prod,team",
+	}, r.collect())
+
+	active.Set(2)
+	assert.Equal(t, []string{
+		"testbeat.statsd_test_collect.enabled:1|g|#env:prod,team",
+		"testbeat.statsd_test_collect.queue.active_gauge:2|g|#env:prod,team",
+	}, r.collect())
+
+	// A counter that was reset reports its new value.
+	events.Set(4)
+	r.Prefix = ""
+	r.tags = ""
+	assert.Contains(t, r.collect(), "statsd_test_collect.output.events.total:4|c")
+}
+
+func TestMakePackets(t *testing.T) {
+	lines := []string{"a:1|c", "b:2|c", "c:3|c", "a_very_long_metric_name:4|g"}
+	packets := makePackets(lines, 11)
+
+	var got []string
+	for _, p := range packets {
+		got = append(got, string(p))
+	}
+	assert.Equal(t, []string{"a:1|c\nb:2|c", "c:3|c", "a_very_long_metric_name:4|g"}, got)
+}
+
+func TestReporterSendsMetrics(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	reg := monitoring.GetNamespace("statsd_test_send").GetRegistry()
+	monitoring.NewInt(reg, "pipeline.clients_gauge").Set(7)
+
+	cfg := conf.MustNewConfigFrom(mapstr.M{
+		"host":       server.LocalAddr().String(),
+		"period":     "10ms",
+		"prefix":     "beat",
+		"namespaces": []string{"statsd_test_send"},
+	})
+	r, err := makeReporter(beat.Info{Beat: "testbeat"}, report.Settings{}, cfg)
+	require.NoError(t, err)
+	defer r.Stop()
+
+	require.NoError(t, server.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 1500)
+	n, _, err := server.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"beat.statsd_test_send.pipeline.clients_gauge:7|g"},
+		strings.Split(string(buf[:n]), "\n"))
+}
+
+func TestConfigValidation(t *testing.T) {
+	_, err := makeReporter(beat.Info{}, report.Settings{}, conf.MustNewConfigFrom(mapstr.M{
+		"period": "-1s",
+	}))
+	assert.Error(t, err)
+
+	_, err = makeReporter(beat.Info{}, report.Settings{}, conf.MustNewConfigFrom(mapstr.M{
+		"host": "",
+	}))
+	assert.Error(t, err)
+}
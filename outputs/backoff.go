@@ -30,8 +30,20 @@ import (
 type backoffClient struct {
 	client NetworkClient
 
-	done    chan struct{}
-	backoff backoff.Backoff
+	done     chan struct{}
+	backoff  backoff.Backoff
+	observer Observer
+}
+
+// BackoffConfig configures the backoff of a client created with
+// WithBackoffConfig.
+type BackoffConfig struct {
+	Init time.Duration
+	Max  time.Duration
+
+	// Jitter selects an exponential backoff with full jitter. If false, the
+	// equal jitter backoff of WithBackoff is used.
+	Jitter bool
 }
 
 // WithBackoff wraps a NetworkClient, adding exponential backoff support to a network client if connection/publishing failed.
@@ -45,9 +57,32 @@ func WithBackoff(client NetworkClient, init, max time.Duration) NetworkClient {
 	}
 }
 
+// WithBackoffConfig wraps a NetworkClient like WithBackoff, using the backoff
+// strategy selected by cfg. The time spent in backoff is reported to observer.
+func WithBackoffConfig(client NetworkClient, cfg BackoffConfig, observer Observer) NetworkClient {
+	done := make(chan struct{})
+
+	var b backoff.Backoff
+	if cfg.Jitter {
+		b = backoff.NewFullJitterBackoff(done, cfg.Init, cfg.Max)
+	} else {
+		b = backoff.NewEqualJitterBackoff(done, cfg.Init, cfg.Max)
+	}
+
+	if observer == nil {
+		observer = NewNilObserver()
+	}
+	return &backoffClient{
+		client:   client,
+		done:     done,
+		backoff:  b,
+		observer: observer,
+	}
+}
+
 func (b *backoffClient) Connect(ctx context.Context) error {
 	err := b.client.Connect(ctx)
-	backoff.WaitOnError(b.backoff, err)
+	b.waitOnError(ctx, err)
 	return err
 }
 
@@ -62,10 +97,25 @@ func (b *backoffClient) Publish(ctx context.Context, batch publisher.Batch) erro
 	if err != nil {
 		b.client.Close()
 	}
-	backoff.WaitOnError(b.backoff, err)
+	b.waitOnError(ctx, err)
 	return err
 }
 
+// waitOnError waits for the backoff if err is set, returning early if ctx is
+// canceled or the client is closed.
+func (b *backoffClient) waitOnError(ctx context.Context, err error) {
+	start := time.Now()
+	backoff.WaitOnErrorContext(ctx, b.backoff, err)
+	if err != nil && b.observer != nil {
+		b.observer.BackoffTime(time.Since(start))
+	}
+}
+
+func (b *backoffClient) SetDeadLetterHandler(h DeadLetterHandler) bool {
+	r, ok := b.client.(DeadLetterReporter)
+	return ok && r.SetDeadLetterHandler(h)
+}
+
 func (b *backoffClient) Client() NetworkClient {
 	return b.client
 }
@@ -18,6 +18,9 @@
 package console
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/njcx/libbeat_v8/outputs/codec"
 	"github.com/elastic/elastic-agent-libs/config"
 )
@@ -28,8 +31,39 @@ type Config struct {
 	// old pretty settings to use if no codec is configured
 	Pretty bool `config:"pretty"`
 
+	// Keys lists the dotted field paths written as newline-delimited JSON,
+	// together with `@timestamp`. If empty, the full event is written.
+	Keys []string `config:"keys"`
+
+	// Format selects how events are written. Supported formats are json,
+	// logfmt and text.
+	Format string `config:"format"`
+
+	// Colors enables ANSI colors for the log level in the logfmt and text
+	// formats. Colors are only used if stdout is a terminal.
+	Colors bool `config:"colors"`
+
 	BatchSize int
 	Queue     config.Namespace `config:"queue"`
 }
 
-var defaultConfig = Config{}
+var defaultConfig = Config{
+	Format: formatJSON,
+	Colors: true,
+}
+
+func (c *Config) Validate() error {
+	if len(c.Keys) > 0 && c.Codec.Namespace.IsSet() {
+		return errors.New("'keys' can not be used together with 'codec'")
+	}
+	switch c.Format {
+	case "", formatJSON:
+	case formatLogfmt, formatText:
+		if c.Codec.Namespace.IsSet() || len(c.Keys) > 0 {
+			return fmt.Errorf("format '%s' can not be used together with 'codec' or 'keys'", c.Format)
+		}
+	default:
+		return fmt.Errorf("unsupported format '%s', must be one of %s, %s or %s", c.Format, formatJSON, formatLogfmt, formatText)
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ package console
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
@@ -42,6 +43,9 @@ type console struct {
 	index    string
 }
 
+// errEncodeEvent is returned by publishEvent if the event can't be encoded.
+var errEncodeEvent = errors.New("failed to encode event")
+
 func init() {
 	outputs.RegisterType("console", makeConsole)
 }
@@ -59,7 +63,11 @@ func makeConsole(
 	}
 
 	var enc codec.Codec
-	if config.Codec.Namespace.IsSet() {
+	if config.Format == formatLogfmt || config.Format == formatText {
+		enc = newTextEncoder(config.Format, config.Colors && isTerminal(os.Stdout))
+	} else if len(config.Keys) > 0 {
+		enc = newKeysEncoder(config.Keys)
+	} else if config.Codec.Namespace.IsSet() {
 		enc, err = codec.CreateEncoder(beat, config.Codec)
 		if err != nil {
 			return outputs.Fail(err)
@@ -85,6 +93,8 @@ func makeConsole(
 		}
 	}
 
+	// The console codec formats the raw event in Publish, so it doesn't use
+	// early encoding.
 	return outputs.Success(config.Queue, config.BatchSize, 0, nil, c)
 }
 
@@ -101,9 +111,12 @@ func (c *console) Publish(_ context.Context, batch publisher.Batch) error {
 	st.NewBatch(len(events))
 
 	dropped := 0
+	encodeFailed := 0
 	for i := range events {
-		ok := c.publishEvent(&events[i])
-		if !ok {
+		switch err := c.publishEvent(&events[i]); {
+		case errors.Is(err, errEncodeEvent):
+			encodeFailed++
+		case err != nil:
 			dropped++
 		}
 	}
@@ -111,40 +124,41 @@ func (c *console) Publish(_ context.Context, batch publisher.Batch) error {
 	c.writer.Flush()
 	batch.ACK()
 
+	st.EncodeErrors(encodeFailed)
 	st.PermanentErrors(dropped)
-	st.AckedEvents(len(events) - dropped)
+	st.AckedEvents(len(events) - dropped - encodeFailed)
 
 	return nil
 }
 
 var nl = []byte("\n")
 
-func (c *console) publishEvent(event *publisher.Event) bool {
+func (c *console) publishEvent(event *publisher.Event) error {
 	serializedEvent, err := c.codec.Encode(c.index, &event.Content)
 	if err != nil {
 		if !event.Guaranteed() {
-			return false
+			return errEncodeEvent
 		}
 
 		c.log.Errorf("Unable to encode event: %+v", err)
 		c.log.Debugf("Failed event: %v", event)
-		return false
+		return errEncodeEvent
 	}
 
 	if err := c.writeBuffer(serializedEvent); err != nil {
 		c.observer.WriteError(err)
 		c.log.Errorf("Unable to publish events to console: %+v", err)
-		return false
+		return err
 	}
 
 	if err := c.writeBuffer(nl); err != nil {
 		c.observer.WriteError(err)
 		c.log.Errorf("Error when appending newline to event: %+v", err)
-		return false
+		return err
 	}
 
 	c.observer.WriteBytes(len(serializedEvent) + 1)
-	return true
+	return nil
 }
 
 func (c *console) writeBuffer(buf []byte) error {
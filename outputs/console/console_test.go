@@ -99,6 +99,54 @@ func TestConsoleOutput(t *testing.T) {
 			},
 			"{\n  \"@timestamp\": \"0001-01-01T00:00:00.000Z\",\n  \"@metadata\": {\n    \"beat\": \"test\",\n    \"type\": \"_doc\",\n    \"version\": \"1.2.3\"\n  },\n  \"field\": \"value\"\n}\n",
 		},
+		{
+			"event with selected keys",
+			newKeysEncoder([]string{"nested.a", "missing"}),
+			[]beat.Event{
+				{Fields: mapstr.M{
+					"field":  "value",
+					"other":  "dropped",
+					"nested": mapstr.M{"a": 1, "b": 2},
+				}},
+			},
+			"{\"@timestamp\":\"0001-01-01T00:00:00.000Z\",\"nested\":{\"a\":1}}\n",
+		},
+		{
+			"event in text format",
+			newTextEncoder(formatText, false),
+			[]beat.Event{
+				{Fields: mapstr.M{
+					"message": "hello world",
+					"log":     mapstr.M{"level": "info"},
+					"host":    mapstr.M{"name": "local"},
+					"count":   2,
+				}},
+			},
+			"0001-01-01T00:00:00.000Z INFO hello world count=2 host.name=local\n",
+		},
+		{
+			"event in logfmt format",
+			newTextEncoder(formatLogfmt, false),
+			[]beat.Event{
+				{Fields: mapstr.M{
+					"message": "hello world",
+					"log":     mapstr.M{"level": "warn"},
+					"tags":    []string{"a", "b"},
+				}},
+			},
+			"@timestamp=0001-01-01T00:00:00.000Z log.level=warn message=\"hello world\" tags=\"[\\\"a\\\",\\\"b\\\"]\"\n",
+		},
+		{
+			"event in text format with colors",
+			newTextEncoder(formatText, true),
+			[]beat.Event{
+				{Fields: mapstr.M{
+					"message": "failed",
+					"log":     mapstr.M{"level": "error"},
+				}},
+			},
+			"0001-01-01T00:00:00.000Z \x1b[31mERROR\x1b[0m failed\n",
+		},
 		// TODO: enable test after update fmtstr support to beat.Event
 		{
 			"event with custom format string",
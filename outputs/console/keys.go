@@ -0,0 +1,89 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package console
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/outputs/codec"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/go-structform/gotype"
+	"github.com/elastic/go-structform/json"
+)
+
+// keysEncoder serializes a projection of an event to a single line of JSON.
+// Only the configured keys and `@timestamp` are written. Missing keys are
+// omitted.
+type keysEncoder struct {
+	keys   []string
+	buf    bytes.Buffer
+	folder *gotype.Iterator
+}
+
+type keysEvent struct {
+	Timestamp time.Time `struct:"@timestamp"`
+	Fields    mapstr.M  `struct:",inline"`
+}
+
+func newKeysEncoder(keys []string) *keysEncoder {
+	e := &keysEncoder{keys: keys}
+	e.reset()
+	return e
+}
+
+func (e *keysEncoder) reset() {
+	visitor := json.NewVisitor(&e.buf)
+	visitor.SetEscapeHTML(false)
+	visitor.SetIgnoreInvalidFloat(true)
+
+	var err error
+	e.folder, err = gotype.NewIterator(visitor,
+		gotype.Folders(
+			codec.MakeTimestampEncoder(),
+			codec.MakeBCTimestampEncoder(),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Encode serializes the selected fields of the event. The projection
+// references the values of the event's fields, only the maps on the path to a
+// selected key are allocated.
+func (e *keysEncoder) Encode(_ string, event *beat.Event) ([]byte, error) {
+	doc := keysEvent{Timestamp: event.Timestamp, Fields: mapstr.M{}}
+	for _, key := range e.keys {
+		v, err := event.Fields.GetValue(key)
+		if err != nil {
+			continue
+		}
+		if _, err := doc.Fields.Put(key, v); err != nil {
+			return nil, err
+		}
+	}
+
+	e.buf.Reset()
+	if err := e.folder.Fold(doc); err != nil {
+		e.reset()
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
@@ -0,0 +1,192 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package console
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/njcx/libbeat_v8/beat"
+)
+
+const (
+	formatJSON   = "json"
+	formatLogfmt = "logfmt"
+	formatText   = "text"
+)
+
+// textTimestampLayout is the layout of `@timestamp` in the text and logfmt
+// formats.
+const textTimestampLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// ANSI color codes for log levels.
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorGray   = "\x1b[90m"
+)
+
+// textEncoder writes events as a single human readable line. In the text
+// format the line starts with `@timestamp`, `log.level` and `message`,
+// followed by all other fields as key=value pairs. The logfmt format writes
+// all fields, including the leading ones, as key=value pairs. Nested fields
+// are flattened to their dotted keys, sorted by key.
+type textEncoder struct {
+	logfmt bool
+	colors bool
+	buf    bytes.Buffer
+}
+
+func newTextEncoder(format string, colors bool) *textEncoder {
+	return &textEncoder{logfmt: format == formatLogfmt, colors: colors}
+}
+
+func (e *textEncoder) Encode(_ string, event *beat.Event) ([]byte, error) {
+	fields := event.Fields.Flatten()
+	level, _ := fields["log.level"].(string)
+	message, hasMessage := fields["message"]
+	delete(fields, "log.level")
+	delete(fields, "message")
+
+	e.buf.Reset()
+	ts := event.Timestamp.UTC().Format(textTimestampLayout)
+	if e.logfmt {
+		e.writePair("@timestamp", ts)
+		if level != "" {
+			e.buf.WriteByte(' ')
+			e.buf.WriteString("log.level=")
+			e.writeLevel(formatValue(level))
+		}
+		if hasMessage {
+			e.buf.WriteByte(' ')
+			e.writePair("message", formatValue(message))
+		}
+	} else {
+		e.buf.WriteString(ts)
+		if level != "" {
+			e.buf.WriteByte(' ')
+			e.writeLevel(strings.ToUpper(level))
+		}
+		if hasMessage {
+			e.buf.WriteByte(' ')
+			if s, ok := message.(string); ok {
+				e.buf.WriteString(s)
+			} else {
+				e.buf.WriteString(formatValue(message))
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		e.buf.WriteByte(' ')
+		e.writePair(k, formatValue(fields[k]))
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (e *textEncoder) writePair(key, value string) {
+	if e.colors {
+		e.buf.WriteString(colorGray)
+		e.buf.WriteString(key)
+		e.buf.WriteString("=")
+		e.buf.WriteString(colorReset)
+	} else {
+		e.buf.WriteString(key)
+		e.buf.WriteByte('=')
+	}
+	e.buf.WriteString(value)
+}
+
+func (e *textEncoder) writeLevel(level string) {
+	color := levelColor(level)
+	if !e.colors || color == "" {
+		e.buf.WriteString(level)
+		return
+	}
+	e.buf.WriteString(color)
+	e.buf.WriteString(level)
+	e.buf.WriteString(colorReset)
+}
+
+// levelColor returns the ANSI color for a log level, or the empty string for
+// unknown levels.
+func levelColor(level string) string {
+	switch strings.ToLower(strings.Trim(level, `"`)) {
+	case "error", "err", "fatal", "critical", "crit", "panic", "alert", "emergency":
+		return colorRed
+	case "warn", "warning":
+		return colorYellow
+	case "info", "notice":
+		return colorGreen
+	case "debug":
+		return colorBlue
+	case "trace":
+		return colorGray
+	}
+	return ""
+}
+
+// formatValue renders a field value for a key=value pair. Values that
+// contain spaces, quotes, or '=' are quoted.
+func formatValue(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case nil:
+		s = "null"
+	case time.Time:
+		s = v.UTC().Format(textTimestampLayout)
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			s = fmt.Sprint(v)
+		} else {
+			s = string(b)
+		}
+	}
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,316 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package outputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/publisher"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/testing"
+)
+
+// DeadLetterHandler receives events that an output permanently failed to
+// publish, e.g. because of a mapping conflict.
+type DeadLetterHandler interface {
+	// DeadLetter forwards the event, along with the reason it was rejected.
+	// DeadLetter may block to apply backpressure to the calling output.
+	DeadLetter(event beat.Event, reason string)
+}
+
+// DeadLetterReporter is implemented by clients that can pass permanently
+// failed events to a DeadLetterHandler instead of dropping them.
+type DeadLetterReporter interface {
+	// SetDeadLetterHandler registers the handler with the client. It returns
+	// false if the client can not report failed events. It must be called
+	// before the client is used.
+	SetDeadLetterHandler(h DeadLetterHandler) bool
+}
+
+const (
+	deadLetterBatchSize  = 50
+	deadLetterMaxRetries = 3
+	deadLetterBackoff    = time.Second
+)
+
+var errDeadLetterNotSupported = errors.New("output does not support a dead letter output")
+
+type deadLetterConfig struct {
+	DeadLetter config.Namespace `config:"dead_letter"`
+}
+
+// withDeadLetter wraps the clients of the group, if the output configuration
+// contains a `dead_letter` output. Events permanently rejected by the
+// group's clients are published to the dead letter output, with the reason
+// stored in `error.message`.
+func withDeadLetter(im IndexManager, info beat.Info, cfg *config.C, group Group) (Group, error) {
+	var dlConfig deadLetterConfig
+	if err := cfg.Unpack(&dlConfig); err != nil {
+		return Group{}, err
+	}
+	if !dlConfig.DeadLetter.IsSet() {
+		return group, nil
+	}
+
+	dlGroup, err := Load(im, info, nil, dlConfig.DeadLetter.Name(), dlConfig.DeadLetter.Config())
+	if err != nil {
+		return Group{}, fmt.Errorf("failed to load dead letter output: %w", err)
+	}
+	sink := newDeadLetterSink(dlGroup.Clients)
+
+	clients := make([]Client, len(group.Clients))
+	for i, client := range group.Clients {
+		reporter, ok := client.(DeadLetterReporter)
+		if !ok || !reporter.SetDeadLetterHandler(sink) {
+			sink.Close()
+			return Group{}, fmt.Errorf("%v: %w", client, errDeadLetterNotSupported)
+		}
+		clients[i] = sink.wrap(client)
+	}
+	group.Clients = clients
+	return group, nil
+}
+
+// deadLetterSink publishes dead letter events to the clients of the dead
+// letter output. It is shared by all clients of the primary output, and is
+// closed once all of them are closed.
+type deadLetterSink struct {
+	log     *logp.Logger
+	clients []Client
+	events  chan beat.Event
+
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	mutex sync.Mutex
+	refs  int
+}
+
+func newDeadLetterSink(clients []Client) *deadLetterSink {
+	s := &deadLetterSink{
+		log:     logp.NewLogger("dead_letter"),
+		clients: clients,
+		events:  make(chan beat.Event, deadLetterBatchSize),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+// DeadLetter queues the event for the dead letter output. It blocks while
+// the dead letter output is busy.
+func (s *deadLetterSink) DeadLetter(event beat.Event, reason string) {
+	event.Fields = event.Fields.Clone()
+	if event.Fields == nil {
+		event.Fields = mapstr.M{}
+	}
+	_, _ = event.Fields.Put("error.message", reason)
+
+	select {
+	case s.events <- event:
+	case <-s.done:
+		s.log.Warnf("Dead letter output closed, dropping event: %s", reason)
+	}
+}
+
+func (s *deadLetterSink) wrap(client Client) Client {
+	s.mutex.Lock()
+	s.refs++
+	s.mutex.Unlock()
+
+	c := &deadLetterClient{Client: client, sink: s}
+	if nc, ok := client.(NetworkClient); ok {
+		return &deadLetterNetClient{deadLetterClient: c, conn: nc}
+	}
+	return c
+}
+
+// release closes the sink once the last primary client is closed.
+func (s *deadLetterSink) release() {
+	s.mutex.Lock()
+	s.refs--
+	last := s.refs == 0
+	s.mutex.Unlock()
+
+	if last {
+		s.Close()
+	}
+}
+
+// Close stops publishing to the dead letter output and closes its clients.
+func (s *deadLetterSink) Close() {
+	close(s.done)
+	s.wg.Wait()
+	for _, client := range s.clients {
+		if err := client.Close(); err != nil {
+			s.log.Errorf("Failed to close dead letter output %v: %v", client, err)
+		}
+	}
+}
+
+func (s *deadLetterSink) run() {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.done
+		cancel()
+	}()
+
+	connected := make([]bool, len(s.clients))
+	next := 0
+	for {
+		var events []publisher.Event
+		select {
+		case <-s.done:
+			return
+		case event := <-s.events:
+			events = append(events, publisher.Event{Content: event})
+		}
+	collect:
+		for len(events) < deadLetterBatchSize {
+			select {
+			case event := <-s.events:
+				events = append(events, publisher.Event{Content: event})
+			default:
+				break collect
+			}
+		}
+
+		if len(s.clients) == 0 {
+			s.log.Errorf("No dead letter output configured, dropping %d events", len(events))
+			continue
+		}
+		for attempt := 0; len(events) > 0; attempt++ {
+			if attempt > deadLetterMaxRetries {
+				s.log.Errorf("Failed to publish %d events to the dead letter output, dropping them", len(events))
+				break
+			}
+			if attempt > 0 {
+				select {
+				case <-s.done:
+					return
+				case <-time.After(deadLetterBackoff):
+				}
+			}
+
+			i := next
+			next = (next + 1) % len(s.clients)
+			events = s.publish(ctx, i, connected, events)
+		}
+	}
+}
+
+// publish sends events to the i-th client, and returns the events that need
+// to be retried.
+func (s *deadLetterSink) publish(ctx context.Context, i int, connected []bool, events []publisher.Event) []publisher.Event {
+	client := s.clients[i]
+	if nc, ok := client.(NetworkClient); ok && !connected[i] {
+		if err := nc.Connect(ctx); err != nil {
+			s.log.Errorf("Failed to connect to dead letter output %v: %v", client, err)
+			return events
+		}
+		connected[i] = true
+	}
+
+	batch := newDeadLetterBatch(events)
+	if err := client.Publish(ctx, batch); err != nil {
+		s.log.Errorf("Failed to publish to dead letter output %v: %v", client, err)
+		connected[i] = false
+	}
+
+	select {
+	case retry := <-batch.result:
+		return retry
+	case <-s.done:
+		return nil
+	}
+}
+
+// deadLetterBatch is a batch of events published to the dead letter output.
+// The events to retry are reported on result once the batch is signaled.
+type deadLetterBatch struct {
+	events []publisher.Event
+	result chan []publisher.Event
+	once   sync.Once
+}
+
+func newDeadLetterBatch(events []publisher.Event) *deadLetterBatch {
+	return &deadLetterBatch{events: events, result: make(chan []publisher.Event, 1)}
+}
+
+func (b *deadLetterBatch) signal(retry []publisher.Event) {
+	b.once.Do(func() { b.result <- retry })
+}
+
+func (b *deadLetterBatch) Events() []publisher.Event            { return b.events }
+func (b *deadLetterBatch) ACK()                                 { b.signal(nil) }
+func (b *deadLetterBatch) Drop()                                { b.signal(nil) }
+func (b *deadLetterBatch) Retry()                               { b.signal(b.events) }
+func (b *deadLetterBatch) RetryEvents(events []publisher.Event) { b.signal(events) }
+func (b *deadLetterBatch) Cancelled()                           { b.signal(b.events) }
+
+func (b *deadLetterBatch) SplitRetry() bool {
+	b.signal(b.events)
+	return true
+}
+
+// deadLetterClient wraps a client of an output configured with a dead
+// letter output.
+type deadLetterClient struct {
+	Client
+	sink      *deadLetterSink
+	closeOnce sync.Once
+}
+
+// deadLetterNetClient is a deadLetterClient for network clients.
+type deadLetterNetClient struct {
+	*deadLetterClient
+	conn NetworkClient
+}
+
+func (c *deadLetterClient) Close() error {
+	err := c.Client.Close()
+	c.closeOnce.Do(c.sink.release)
+	return err
+}
+
+func (c *deadLetterClient) Test(d testing.Driver) {
+	t, ok := c.Client.(testing.Testable)
+	if !ok {
+		d.Fatal("output", errors.New("client doesn't support testing"))
+	}
+	t.Test(d)
+}
+
+func (c *deadLetterClient) String() string {
+	return "dead_letter(" + c.Client.String() + ")"
+}
+
+func (c *deadLetterNetClient) Connect(ctx context.Context) error {
+	return c.conn.Connect(ctx)
+}
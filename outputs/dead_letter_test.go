@@ -0,0 +1,107 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package outputs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/publisher"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type deadLetterTestClient struct {
+	handler   DeadLetterHandler
+	supported bool
+	published chan publisher.Event
+	closed    bool
+}
+
+func (c *deadLetterTestClient) Close() error   { c.closed = true; return nil }
+func (c *deadLetterTestClient) String() string { return "test" }
+
+func (c *deadLetterTestClient) Publish(_ context.Context, batch publisher.Batch) error {
+	for _, event := range batch.Events() {
+		c.published <- event
+	}
+	batch.ACK()
+	return nil
+}
+
+func (c *deadLetterTestClient) SetDeadLetterHandler(h DeadLetterHandler) bool {
+	c.handler = h
+	return c.supported
+}
+
+func TestOutputWithDeadLetter(t *testing.T) {
+	primary := &deadLetterTestClient{supported: true}
+	secondary := &deadLetterTestClient{published: make(chan publisher.Event, 1)}
+	RegisterType("test_dead_letter_primary", func(IndexManager, beat.Info, Observer, *config.C) (Group, error) {
+		return Success(config.Namespace{}, 0, 0, nil, primary)
+	})
+	RegisterType("test_dead_letter_secondary", func(IndexManager, beat.Info, Observer, *config.C) (Group, error) {
+		return Success(config.Namespace{}, 0, 0, nil, secondary)
+	})
+
+	cfg := config.MustNewConfigFrom(mapstr.M{
+		"dead_letter.test_dead_letter_secondary": mapstr.M{"enabled": true},
+	})
+	group, err := Load(nil, beat.Info{}, nil, "test_dead_letter_primary", cfg)
+	require.NoError(t, err)
+	require.Len(t, group.Clients, 1)
+	require.NotNil(t, primary.handler)
+
+	primary.handler.DeadLetter(beat.Event{Fields: mapstr.M{"field": "value"}}, "mapping conflict")
+
+	select {
+	case event := <-secondary.published:
+		assert.Equal(t, mapstr.M{
+			"field": "value",
+			"error": mapstr.M{"message": "mapping conflict"},
+		}, event.Content.Fields)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not forwarded to the dead letter output")
+	}
+
+	require.NoError(t, group.Clients[0].Close())
+	assert.True(t, primary.closed)
+	assert.True(t, secondary.closed)
+}
+
+func TestOutputWithDeadLetterNotSupported(t *testing.T) {
+	secondary := &deadLetterTestClient{}
+	RegisterType("test_dead_letter_unsupported", func(IndexManager, beat.Info, Observer, *config.C) (Group, error) {
+		return Success(config.Namespace{}, 0, 0, nil, &deadLetterTestClient{})
+	})
+	RegisterType("test_dead_letter_unsupported_secondary", func(IndexManager, beat.Info, Observer, *config.C) (Group, error) {
+		return Success(config.Namespace{}, 0, 0, nil, secondary)
+	})
+
+	cfg := config.MustNewConfigFrom(mapstr.M{
+		"dead_letter.test_dead_letter_unsupported_secondary": mapstr.M{"enabled": true},
+	})
+	_, err := Load(nil, beat.Info{}, nil, "test_dead_letter_unsupported", cfg)
+	assert.ErrorIs(t, err, errDeadLetterNotSupported)
+	assert.True(t, secondary.closed)
+}
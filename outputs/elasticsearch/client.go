@@ -19,6 +19,7 @@ package elasticsearch
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -59,6 +60,15 @@ type Client struct {
 	// forwarded to this index. Otherwise, they will be dropped.
 	deadLetterIndex string
 
+	// If deadLetterHandler is set and no deadLetterIndex is configured,
+	// events with bulk-ingest errors are passed to the handler instead of
+	// being dropped.
+	deadLetterHandler outputs.DeadLetterHandler
+
+	// If bulkMaxBytes is > 0, a batch is split into multiple bulk requests
+	// so that no request body exceeds bulkMaxBytes.
+	bulkMaxBytes int
+
 	log                    *logp.Logger
 	pLogIndex              *periodic.Doer
 	pLogIndexTryDeadLetter *periodic.Doer
@@ -79,6 +89,9 @@ type clientSettings struct {
 	// If deadLetterIndex is set, events with bulk-ingest errors will be
 	// forwarded to this index. Otherwise, they will be dropped.
 	deadLetterIndex string
+
+	// The maximum size in bytes of a bulk request body, 0 for no limit.
+	bulkMaxBytes int
 }
 
 type bulkResultStats struct {
@@ -191,6 +204,7 @@ func NewClient(
 		pipelineSelector: pipeline,
 		observer:         observer,
 		deadLetterIndex:  s.deadLetterIndex,
+		bulkMaxBytes:     s.bulkMaxBytes,
 
 		log:                    log,
 		pLogDeadLetter:         pLogDeadLetter,
@@ -221,6 +235,8 @@ func (client *Client) Clone() *Client {
 		Observer:          nil,
 		EscapeHTML:        false,
 		Transport:         client.conn.Transport,
+		UnixSocket:        client.conn.UnixSocket,
+		TLSReloadInterval: client.conn.TLSReloadInterval,
 	}
 
 	// Without the following nil check on proxyURL, a nil Proxy field will try
@@ -234,9 +250,13 @@ func (client *Client) Clone() *Client {
 			indexSelector:    client.indexSelector,
 			pipelineSelector: client.pipelineSelector,
 			deadLetterIndex:  client.deadLetterIndex,
+			bulkMaxBytes:     client.bulkMaxBytes,
 		},
 		nil, // XXX: do not pass connection callback?
 	)
+	if c != nil {
+		c.deadLetterHandler = client.deadLetterHandler
+	}
 	return c
 }
 
@@ -246,20 +266,37 @@ func (client *Client) Publish(ctx context.Context, batch publisher.Batch) error
 	span.Context.SetLabel("events_original", len(batch.Events()))
 	client.observer.NewBatch(len(batch.Events()))
 
-	// Create and send the bulk request.
-	bulkResult := client.doBulkRequest(ctx, batch)
-	span.Context.SetLabel("events_encoded", len(bulkResult.events))
-	if bulkResult.connErr != nil {
-		// If there was a connection-level error there is no per-item response,
-		// handle it and return.
-		return client.handleBulkResultError(ctx, batch, bulkResult)
+	// Encode the events, dropping those that failed to encode, and group
+	// them into bulk requests no larger than bulk_max_bytes.
+	requests, encoded := client.encodeBulkRequests(batch)
+	span.Context.SetLabel("events_encoded", encoded)
+	if len(requests) > 1 {
+		client.observer.BulkSplit()
 	}
-	span.Context.SetLabel("events_published", len(bulkResult.events))
 
-	// At this point we have an Elasticsearch response for our request,
-	// check and report the per-item results.
-	eventsToRetry, stats := client.bulkCollectPublishFails(bulkResult)
-	stats.reportToObserver(client.observer)
+	var eventsToRetry []publisher.Event
+	for i, request := range requests {
+		bulkResult := client.doBulkRequest(ctx, request)
+		if bulkResult.connErr != nil {
+			if i == 0 {
+				// Nothing has been indexed yet, handle the error for the
+				// whole batch.
+				bulkResult.events = pendingBulkEvents(requests)
+				return client.handleBulkResultError(ctx, batch, bulkResult)
+			}
+			// Some bulk requests were already processed. Retry their failed
+			// events together with all events not yet indexed.
+			return client.handlePartialBulkResultError(
+				ctx, batch, bulkResult, eventsToRetry, pendingBulkEvents(requests[i:]))
+		}
+
+		// At this point we have an Elasticsearch response for our request,
+		// check and report the per-item results.
+		failed, stats := client.bulkCollectPublishFails(bulkResult)
+		stats.reportToObserver(client.observer)
+		eventsToRetry = append(eventsToRetry, failed...)
+	}
+	span.Context.SetLabel("events_published", encoded)
 
 	if len(eventsToRetry) > 0 {
 		span.Context.SetLabel("events_failed", len(eventsToRetry))
@@ -270,31 +307,103 @@ func (client *Client) Publish(ctx context.Context, batch publisher.Batch) error
 	return nil
 }
 
-// Encode a batch's events into a bulk publish request, send the request to
-// Elasticsearch, and return the resulting metadata.
-// Reports the network request latency to the client's metrics observer.
-// The events list in the result will be shorter than the original batch if
-// some events couldn't be encoded. In this case, the removed events will
-// be reported to the Client's metrics observer via PermanentErrors.
-func (client *Client) doBulkRequest(
-	ctx context.Context,
-	batch publisher.Batch,
-) bulkResult {
-	var result bulkResult
+// bulkRequest is a group of encoded events sent in a single bulk API call.
+type bulkRequest struct {
+	events []publisher.Event
+	items  []interface{}
+}
 
+// encodeBulkRequests encodes a batch's events into bulk requests and returns
+// them along with the number of events encoded. If bulk_max_bytes is set,
+// the events are split across as many requests as needed for each request
+// body to stay below the limit. An event which exceeds the limit on its own
+// is sent in a request of its own.
+// Events that couldn't be encoded are reported to the Client's metrics
+// observer via EncodeErrors.
+func (client *Client) encodeBulkRequests(batch publisher.Batch) ([]bulkRequest, int) {
 	rawEvents := batch.Events()
 
 	// encode events into bulk request buffer, dropping failed elements from
 	// events slice
 	resultEvents, bulkItems := client.bulkEncodePublishRequest(client.conn.GetVersion(), rawEvents)
-	result.events = resultEvents
-	client.observer.PermanentErrors(len(rawEvents) - len(resultEvents))
+	client.observer.EncodeErrors(len(rawEvents) - len(resultEvents))
+
+	if len(resultEvents) == 0 {
+		return nil, 0
+	}
+	if client.bulkMaxBytes <= 0 {
+		return []bulkRequest{{events: resultEvents, items: bulkItems}}, len(resultEvents)
+	}
+
+	var requests []bulkRequest
+	current := bulkRequest{}
+	currentSize := 0
+	itemIdx := 0
+	for _, event := range resultEvents {
+		// Every event has a meta line, plus a source line unless it is a
+		// delete operation.
+		n := 2
+		if event.EncodedEvent.(*encodedEvent).opType == events.OpTypeDelete {
+			n = 1
+		}
+		items := bulkItems[itemIdx : itemIdx+n]
+		itemIdx += n
+
+		size := bulkItemsSize(items)
+		if len(current.events) > 0 && currentSize+size > client.bulkMaxBytes {
+			requests = append(requests, current)
+			current = bulkRequest{}
+			currentSize = 0
+		}
+		current.events = append(current.events, event)
+		current.items = append(current.items, items...)
+		currentSize += size
+	}
+	requests = append(requests, current)
+	return requests, len(resultEvents)
+}
+
+// bulkItemsSize returns the number of bytes the bulk items of one event
+// occupy in a bulk request body, including the newline after each line.
+func bulkItemsSize(items []interface{}) int {
+	size := 0
+	for _, item := range items {
+		if raw, ok := item.(eslegclient.RawEncoding); ok {
+			size += len(raw.Encoding) + 1
+			continue
+		}
+		b, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		size += len(b) + 1
+	}
+	return size
+}
+
+// pendingBulkEvents returns the events of all the given bulk requests.
+func pendingBulkEvents(requests []bulkRequest) []publisher.Event {
+	var pending []publisher.Event
+	for _, request := range requests {
+		pending = append(pending, request.events...)
+	}
+	return pending
+}
+
+// Send a bulk request to Elasticsearch, and return the resulting metadata.
+// Reports the network request latency to the client's metrics observer.
+func (client *Client) doBulkRequest(
+	ctx context.Context,
+	request bulkRequest,
+) bulkResult {
+	var result bulkResult
+	result.events = request.events
 
 	// If we encoded any events, send the network request.
 	if len(result.events) > 0 {
 		begin := time.Now()
 		result.status, result.response, result.connErr =
-			client.conn.Bulk(ctx, "", "", bulkRequestParams, bulkItems)
+			client.conn.Bulk(ctx, "", "", bulkRequestParams, request.items)
 		if result.connErr == nil {
 			duration := time.Since(begin)
 			client.observer.ReportLatency(duration)
@@ -307,6 +416,30 @@ func (client *Client) doBulkRequest(
 	return result
 }
 
+// handlePartialBulkResultError handles a connection-level error on a bulk
+// request after earlier bulk requests of the same batch were indexed. The
+// failed events of the earlier requests and all events that have not been
+// indexed yet are retried.
+func (client *Client) handlePartialBulkResultError(
+	ctx context.Context, batch publisher.Batch, bulkResult bulkResult,
+	failed, pending []publisher.Event,
+) error {
+	batch.RetryEvents(append(failed, pending...))
+	client.observer.RetryableErrors(len(pending))
+
+	if bulkResult.status == http.StatusRequestEntityTooLarge {
+		// The retried batch starts with the rejected request, so on the next
+		// attempt it is handled like a single too-large bulk request.
+		client.log.Warnf("Bulk request of %d events is too large for the server, "+
+			"consider lowering `bulk_max_bytes`. The events will be retried.", len(bulkResult.events))
+		return nil
+	}
+	err := apm.CaptureError(ctx, fmt.Errorf("failed to perform bulk index operations: %w", bulkResult.connErr))
+	err.Send()
+	client.log.Error(err)
+	return bulkResult.connErr
+}
+
 func (client *Client) handleBulkResultError(
 	ctx context.Context, batch publisher.Batch, bulkResult bulkResult,
 ) error {
@@ -511,6 +644,13 @@ func (client *Client) applyItemStatus(
 			stats.nonIndexable++
 			return false
 		}
+		if client.deadLetterIndex == "" && client.deadLetterHandler != nil {
+			// Fatal error, forward to the dead letter output.
+			client.log.Warnw(fmt.Sprintf("Cannot index event '%s' (status=%v): %s, forwarding to dead letter output", encodedEvent, itemStatus, itemMessage), logp.TypeKey, logp.EventType)
+			client.deadLetterHandler.DeadLetter(encodedEvent.event(), string(itemMessage))
+			stats.deadLetter++
+			return false
+		}
 		if client.deadLetterIndex == "" {
 			// Fatal error and no dead letter index, drop.
 			client.pLogIndex.Add()
@@ -532,6 +672,13 @@ func (client *Client) applyItemStatus(
 	return true
 }
 
+// SetDeadLetterHandler registers a handler receiving events that failed to
+// be indexed. It is only used if no dead letter index is configured.
+func (client *Client) SetDeadLetterHandler(h outputs.DeadLetterHandler) bool {
+	client.deadLetterHandler = h
+	return true
+}
+
 func (client *Client) Connect(ctx context.Context) error {
 	return client.conn.Connect(ctx)
 }
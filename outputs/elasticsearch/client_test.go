@@ -161,6 +161,46 @@ func TestPublish(t *testing.T) {
 		assertRegistryUint(t, reg, "events.failed", 2, "HTTP failure should report failed events")
 	})
 
+	t.Run("splits bulk requests larger than bulk_max_bytes", func(t *testing.T) {
+		requests := 0
+		esMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			b, _ := io.ReadAll(r.Body)
+			lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+			items := make([]string, len(lines)/2)
+			for i := range items {
+				items[i] = `{"index":{"status":200}}`
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = io.WriteString(w, `{"items":[`+strings.Join(items, ",")+`]}`)
+		}))
+		defer esMock.Close()
+
+		reg := monitoring.NewRegistry()
+		client, err := NewClient(
+			clientSettings{
+				observer:      outputs.NewStats(reg),
+				connection:    eslegclient.ConnectionSettings{URL: esMock.URL},
+				indexSelector: testIndexSelector{},
+				bulkMaxBytes:  1,
+			},
+			nil,
+		)
+		require.NoError(t, err)
+
+		batch := encodeBatch(client, &batchMock{
+			events: []publisher.Event{event1, event2, event3},
+		})
+		err = client.Publish(ctx, batch)
+
+		assert.NoError(t, err)
+		assert.True(t, batch.ack, "batch should be acknowledged")
+		assert.Equal(t, 3, requests, "every event should be sent in its own bulk request")
+		assertRegistryUint(t, reg, "batches.bulk_split", 1, "Splitting a batch into bulk requests should be reported")
+		assertRegistryUint(t, reg, "events.acked", 3, "All events should be acked")
+		assertRegistryUint(t, reg, "events.active", 0, "Active events should be zero when Publish returns")
+	})
+
 	t.Run("live batches, still too big after split", func(t *testing.T) {
 		// Test a live (non-mocked) batch where all three events by themselves are
 		// rejected by the server as too large after the initial batch splits.
@@ -409,6 +449,44 @@ func TestCollectPublishFailFatalErrorNotRetried(t *testing.T) {
 	assert.Equal(t, 0, len(res))
 }
 
+type testDeadLetterHandler struct {
+	events  []beat.Event
+	reasons []string
+}
+
+func (h *testDeadLetterHandler) DeadLetter(event beat.Event, reason string) {
+	h.events = append(h.events, event)
+	h.reasons = append(h.reasons, reason)
+}
+
+func TestCollectPublishFailDeadLetterHandler(t *testing.T) {
+	client, err := NewClient(
+		clientSettings{observer: outputs.NewNilObserver()},
+		nil,
+	)
+	assert.NoError(t, err)
+
+	handler := &testDeadLetterHandler{}
+	assert.True(t, client.SetDeadLetterHandler(handler))
+
+	response := []byte(`{"items": [{"create": {"status": 400, "error": "mapping conflict"}}]}`)
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := encodeEvent(client, publisher.Event{Content: beat.Event{Timestamp: timestamp, Fields: mapstr.M{"bar": 1}}})
+
+	res, stats := client.bulkCollectPublishFails(bulkResult{
+		events:   []publisher.Event{event},
+		status:   200,
+		response: response,
+	})
+	assert.Equal(t, bulkResultStats{deadLetter: 1}, stats)
+	assert.Empty(t, res)
+
+	require.Len(t, handler.events, 1)
+	assert.Equal(t, timestamp, handler.events[0].Timestamp)
+	assert.Equal(t, mapstr.M{"bar": float64(1)}, handler.events[0].Fields)
+	assert.Contains(t, handler.reasons[0], "mapping conflict")
+}
+
 func TestCollectPublishFailInvalidBulkIndexResponse(t *testing.T) {
 	client, err := NewClient(
 		clientSettings{observer: outputs.NewNilObserver()},
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/njcx/libbeat_v8/common/cfgtype"
 	"github.com/njcx/libbeat_v8/common/transport/kerberos"
+	"github.com/njcx/libbeat_v8/common/transport/tlsreload"
 	"github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
 )
@@ -39,18 +41,30 @@ type elasticsearchConfig struct {
 	EscapeHTML         bool              `config:"escape_html"`
 	Kerberos           *kerberos.Config  `config:"kerberos"`
 	BulkMaxSize        int               `config:"bulk_max_size"`
+	BulkMaxBytes       cfgtype.ByteSize  `config:"bulk_max_bytes"`
 	MaxRetries         int               `config:"max_retries"`
 	Backoff            Backoff           `config:"backoff"`
 	NonIndexablePolicy *config.Namespace `config:"non_indexable_policy"`
 	AllowOlderVersion  bool              `config:"allow_older_versions"`
 	Queue              config.Namespace  `config:"queue"`
 
+	// ProxyHosts overrides the proxy settings for individual hosts.
+	ProxyHosts []proxyOverride `config:"proxy_hosts"`
+	// ProxyBypass lists the hosts connected to without a proxy, using the
+	// NO_PROXY conventions.
+	ProxyBypass []string `config:"proxy_bypass"`
+
+	// SSLReloadInterval is the minimum time between two checks of the TLS
+	// client certificate and key files for changes. Zero disables reloading.
+	SSLReloadInterval time.Duration `config:"ssl_reload_interval"`
+
 	Transport httpcommon.HTTPTransportSettings `config:",inline"`
 }
 
 type Backoff struct {
-	Init time.Duration
-	Max  time.Duration
+	Init   time.Duration
+	Max    time.Duration
+	Jitter bool `config:"jitter"`
 }
 
 const (
@@ -71,10 +85,12 @@ var (
 		Kerberos:         nil,
 		LoadBalance:      true,
 		Backoff: Backoff{
-			Init: 1 * time.Second,
-			Max:  60 * time.Second,
+			Init:   1 * time.Second,
+			Max:    60 * time.Second,
+			Jitter: false,
 		},
-		Transport: esDefaultTransportSettings(),
+		SSLReloadInterval: tlsreload.DefaultInterval,
+		Transport:         esDefaultTransportSettings(),
 	}
 )
 
@@ -90,6 +106,9 @@ func (c *elasticsearchConfig) Validate() error {
 	if c.APIKey != "" && (c.Username != "" || c.Password != "") {
 		return fmt.Errorf("cannot set both api_key and username/password")
 	}
+	if c.SSLReloadInterval < 0 {
+		return fmt.Errorf("ssl_reload_interval must not be negative")
+	}
 
 	return nil
 }
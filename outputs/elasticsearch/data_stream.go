@@ -0,0 +1,98 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/njcx/libbeat_v8/beat"
+)
+
+const (
+	// fieldMetaDataStream is the metadata key enabling data stream routing.
+	fieldMetaDataStream = "data_stream"
+
+	defaultDataStreamType      = "logs"
+	defaultDataStreamDataset   = "generic"
+	defaultDataStreamNamespace = "default"
+
+	// maxDataStreamPartBytes is the maximum length of a data stream dataset
+	// or namespace.
+	maxDataStreamPartBytes = 100
+
+	// invalidDataStreamChars are characters that are not allowed in a data
+	// stream dataset or namespace.
+	invalidDataStreamChars = `\/*?"<>| ,#:-`
+)
+
+// dataStreamIndex returns the data stream `{type}-{dataset}-{namespace}` an
+// event is routed to, if `@metadata.data_stream` is set. The type, dataset
+// and namespace are read from `@metadata.data_stream`, falling back to the
+// event's `data_stream` fields and the defaults `logs-generic-default`.
+// The boolean result is false if the event does not request data stream
+// routing.
+func dataStreamIndex(e *beat.Event) (string, bool, error) {
+	if _, err := e.Meta.GetValue(fieldMetaDataStream); err != nil {
+		return "", false, nil
+	}
+
+	typ := dataStreamValue(e, "type", defaultDataStreamType)
+	dataset := dataStreamValue(e, "dataset", defaultDataStreamDataset)
+	namespace := dataStreamValue(e, "namespace", defaultDataStreamNamespace)
+
+	if err := validateDataStreamPart("type", typ); err != nil {
+		return "", true, err
+	}
+	if err := validateDataStreamPart("dataset", dataset); err != nil {
+		return "", true, err
+	}
+	if err := validateDataStreamPart("namespace", namespace); err != nil {
+		return "", true, err
+	}
+
+	return typ + "-" + dataset + "-" + namespace, true, nil
+}
+
+func dataStreamValue(e *beat.Event, key, defaultValue string) string {
+	path := fieldMetaDataStream + "." + key
+	if v, err := e.Meta.GetValue(path); err == nil {
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	if v, err := e.Fields.GetValue(path); err == nil {
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	return defaultValue
+}
+
+func validateDataStreamPart(name, value string) error {
+	switch {
+	case len(value) > maxDataStreamPartBytes:
+		return fmt.Errorf("data stream %s '%s' exceeds %d bytes", name, value, maxDataStreamPartBytes)
+	case value != strings.ToLower(value):
+		return fmt.Errorf("data stream %s '%s' must be lowercase", name, value)
+	case strings.ContainsAny(value, invalidDataStreamChars):
+		return fmt.Errorf("data stream %s '%s' contains invalid characters, must not contain any of '%s'",
+			name, value, invalidDataStreamChars)
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ package elasticsearch
 import (
 	"github.com/njcx/libbeat_v8/beat"
 	"github.com/njcx/libbeat_v8/common"
+	"github.com/njcx/libbeat_v8/common/transport/unixsock"
 	"github.com/njcx/libbeat_v8/esleg/eslegclient"
 	"github.com/njcx/libbeat_v8/outputs"
 	"github.com/njcx/libbeat_v8/outputs/outil"
@@ -94,45 +95,70 @@ func makeES(
 		params = nil
 	}
 
+	// Events are encoded to their bulk request form once, when entering the
+	// queue, so retries reuse the cached bytes.
 	encoderFactory := newEventEncoderFactory(
 		esConfig.EscapeHTML, indexSelector, pipelineSelector)
 
 	clients := make([]outputs.NetworkClient, len(hosts))
 	for i, host := range hosts {
+		var socket string
+		if unixsock.IsUnixSocket(host) {
+			socket, host = unixsock.Path(host), "localhost"
+		}
+
 		esURL, err := common.MakeURL(esConfig.Protocol, esConfig.Path, host, 9200)
 		if err != nil {
 			log.Errorf("Invalid host param set: %s, Error: %+v", host, err)
 			return outputs.Fail(err)
 		}
 
+		transport := esConfig.Transport
+		var overridden bool
+		transport.Proxy, overridden, err = esConfig.proxyForHost(esURL)
+		if err != nil {
+			return outputs.Fail(err)
+		}
+		if overridden {
+			log.Infof("Using proxy settings for host %s: proxy_url=%v proxy_disable=%v",
+				esURL, transport.Proxy.URL, transport.Proxy.Disable)
+		}
+
 		var client outputs.NetworkClient
 		client, err = NewClient(clientSettings{
 			connection: eslegclient.ConnectionSettings{
-				URL:              esURL,
-				Beatname:         beatInfo.Beat,
-				Kerberos:         esConfig.Kerberos,
-				Username:         esConfig.Username,
-				Password:         esConfig.Password,
-				APIKey:           esConfig.APIKey,
-				Parameters:       params,
-				Headers:          esConfig.Headers,
-				CompressionLevel: esConfig.CompressionLevel,
-				Observer:         observer,
-				EscapeHTML:       esConfig.EscapeHTML,
-				Transport:        esConfig.Transport,
-				IdleConnTimeout:  esConfig.Transport.IdleConnTimeout,
-				UserAgent:        beatInfo.UserAgent,
+				URL:               esURL,
+				Beatname:          beatInfo.Beat,
+				Kerberos:          esConfig.Kerberos,
+				Username:          esConfig.Username,
+				Password:          esConfig.Password,
+				APIKey:            esConfig.APIKey,
+				Parameters:        params,
+				Headers:           esConfig.Headers,
+				CompressionLevel:  esConfig.CompressionLevel,
+				Observer:          observer,
+				EscapeHTML:        esConfig.EscapeHTML,
+				Transport:         transport,
+				IdleConnTimeout:   esConfig.Transport.IdleConnTimeout,
+				UnixSocket:        socket,
+				TLSReloadInterval: esConfig.SSLReloadInterval,
+				UserAgent:         beatInfo.UserAgent,
 			},
 			indexSelector:    indexSelector,
 			pipelineSelector: pipelineSelector,
 			observer:         observer,
 			deadLetterIndex:  deadLetterIndex,
+			bulkMaxBytes:     int(esConfig.BulkMaxBytes),
 		}, &connectCallbackRegistry)
 		if err != nil {
 			return outputs.Fail(err)
 		}
 
-		client = outputs.WithBackoff(client, esConfig.Backoff.Init, esConfig.Backoff.Max)
+		client = outputs.WithBackoffConfig(client, outputs.BackoffConfig{
+			Init:   esConfig.Backoff.Init,
+			Max:    esConfig.Backoff.Max,
+			Jitter: esConfig.Backoff.Jitter,
+		}, observer)
 		clients[i] = client
 	}
 
@@ -19,6 +19,7 @@ package elasticsearch
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -111,8 +112,15 @@ func (pe *eventEncoder) encodeRawEvent(e *beat.Event) *encodedEvent {
 	if err != nil {
 		return &encodedEvent{err: fmt.Errorf("failed to select event pipeline: %w", err)}
 	}
-	var index string
-	if pe.indexSelector != nil {
+	// Events requesting data stream routing are always created in the data
+	// stream composed from their fields, otherwise the index selector is used.
+	index, isDataStream, err := dataStreamIndex(e)
+	if err != nil {
+		return &encodedEvent{err: fmt.Errorf("failed to select event data stream: %w", err)}
+	}
+	if isDataStream {
+		opType = events.OpTypeCreate
+	} else if pe.indexSelector != nil {
 		index, err = pe.indexSelector.Select(e)
 		if err != nil {
 			return &encodedEvent{err: fmt.Errorf("failed to select event index: %w", err)}
@@ -152,6 +160,18 @@ func (e *encodedEvent) setDeadLetter(
 	e.encoding = []byte(deadLetterReencoding.String())
 }
 
+// event decodes the encoded event back into a beat.Event, e.g. to forward it
+// to a dead letter output. If the encoding can not be decoded, the raw encoding
+// is returned in the "message" field.
+func (e *encodedEvent) event() beat.Event {
+	fields := mapstr.M{}
+	if err := json.Unmarshal(e.encoding, &fields); err != nil {
+		fields = mapstr.M{"message": string(e.encoding)}
+	}
+	delete(fields, "@timestamp")
+	return beat.Event{Timestamp: e.timestamp, Fields: fields}
+}
+
 // String converts e.encoding to string and returns it.
 // The goal of this method is to provide an easy way to log
 // the event encoded.
@@ -19,6 +19,7 @@ package elasticsearch
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -99,6 +100,50 @@ func TestEncodeEntry(t *testing.T) {
 	assert.Equal(t, "nested_value", eventContent.Nested.NestedField, "Encoded field should match original")
 }
 
+func TestEncodeEntryDataStream(t *testing.T) {
+	encoder := newEventEncoder(true, testIndexSelector{}, nil)
+
+	encode := func(event beat.Event) *encodedEvent {
+		encoded, _ := encoder.EncodeEntry(publisher.Event{Content: event})
+		return encoded.(publisher.Event).EncodedEvent.(*encodedEvent)
+	}
+
+	t.Run("composed from metadata and fields", func(t *testing.T) {
+		enc := encode(beat.Event{
+			Fields: mapstr.M{"data_stream": mapstr.M{"namespace": "prod"}},
+			Meta: mapstr.M{
+				"data_stream":          mapstr.M{"dataset": "nginx.access"},
+				events.FieldMetaOpType: "index",
+			},
+		})
+		require.NoError(t, enc.err)
+		assert.Equal(t, "logs-nginx.access-prod", enc.index)
+		assert.Equal(t, events.OpTypeCreate, enc.opType)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		enc := encode(beat.Event{Meta: mapstr.M{"data_stream": mapstr.M{}}})
+		require.NoError(t, enc.err)
+		assert.Equal(t, "logs-generic-default", enc.index)
+	})
+
+	t.Run("static index without metadata", func(t *testing.T) {
+		enc := encode(beat.Event{
+			Fields: mapstr.M{"data_stream": mapstr.M{"dataset": "nginx.access"}},
+		})
+		require.NoError(t, enc.err)
+		assert.Equal(t, "test", enc.index)
+		assert.Equal(t, events.OpTypeDefault, enc.opType)
+	})
+
+	t.Run("invalid dataset", func(t *testing.T) {
+		for _, dataset := range []string{"Nginx", "nginx-access", "nginx access", strings.Repeat("a", 101)} {
+			enc := encode(beat.Event{Meta: mapstr.M{"data_stream": mapstr.M{"dataset": dataset}}})
+			assert.Error(t, enc.err, "dataset %q should be rejected", dataset)
+		}
+	})
+}
+
 // encodeBatch encodes a publisher.Batch so it can be provided to
 // Client.Publish and other helpers.
 // This modifies the batch in place, but also returns its input batch
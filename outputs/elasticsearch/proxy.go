@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package elasticsearch
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+)
+
+// proxyOverride configures the proxy used for a single host, overriding
+// the output's proxy settings.
+type proxyOverride struct {
+	Host    string               `config:"host" validate:"required"`
+	URL     *httpcommon.ProxyURI `config:"proxy_url"`
+	Disable bool                 `config:"proxy_disable"`
+}
+
+// proxyForHost returns the proxy settings used to connect to esURL, and
+// whether they differ from the proxy settings of the output. A matching entry
+// in proxy_hosts takes precedence over proxy_bypass, which takes precedence
+// over the proxy settings of the output.
+func (c *elasticsearchConfig) proxyForHost(esURL string) (httpcommon.HTTPClientProxySettings, bool, error) {
+	proxy := c.Transport.Proxy
+
+	u, err := url.Parse(esURL)
+	if err != nil {
+		return proxy, false, fmt.Errorf("failed to parse host URL %v: %w", esURL, err)
+	}
+
+	for _, o := range c.ProxyHosts {
+		if matchHost(o.Host, u) {
+			proxy.URL = o.URL
+			// Without a proxy URL, the override disables the proxy instead
+			// of falling back to the proxy environment variables.
+			proxy.Disable = o.Disable || o.URL == nil
+			return proxy, true, nil
+		}
+	}
+
+	for _, pattern := range c.ProxyBypass {
+		if matchHost(pattern, u) {
+			proxy.URL = nil
+			proxy.Disable = true
+			return proxy, true, nil
+		}
+	}
+	return proxy, false, nil
+}
+
+// matchHost reports whether the host of u matches pattern. Patterns follow
+// the NO_PROXY conventions: `*` matches all hosts, an IP address or CIDR
+// block matches the addresses it contains, and a domain name matches the
+// domain and its subdomains. A leading dot only matches subdomains. If the
+// pattern contains a port, the port must match as well.
+func matchHost(pattern string, u *url.URL) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return false
+	}
+	if pattern == "*" {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if _, ipNet, err := net.ParseCIDR(pattern); err == nil {
+		ip := net.ParseIP(host)
+		return ip != nil && ipNet.Contains(ip)
+	}
+
+	if h, port, err := net.SplitHostPort(pattern); err == nil {
+		if port != urlPort(u) {
+			return false
+		}
+		pattern = h
+	}
+	pattern = strings.Trim(pattern, "[]")
+
+	if ip := net.ParseIP(pattern); ip != nil {
+		return ip.Equal(net.ParseIP(host))
+	}
+	if strings.HasPrefix(pattern, ".") {
+		return strings.HasSuffix(host, pattern)
+	}
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+// urlPort returns the port of u, or the default port of its scheme.
+func urlPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
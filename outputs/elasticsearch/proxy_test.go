@@ -0,0 +1,185 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package elasticsearch
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/outputs"
+	"github.com/njcx/libbeat_v8/outputs/outil"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		match   bool
+	}{
+		{"*", "http://es.example.com:9200", true},
+		{"es.example.com", "http://es.example.com:9200", true},
+		{"ES.example.com", "http://es.example.com:9200", true},
+		{"example.com", "http://es.example.com:9200", true},
+		{"example.com", "http://example.com:9200", true},
+		{"example.com", "http://badexample.com:9200", false},
+		{".example.com", "http://example.com:9200", false},
+		{".example.com", "http://es.example.com:9200", true},
+		{"es.example.com:9200", "http://es.example.com:9200", true},
+		{"es.example.com:9201", "http://es.example.com:9200", false},
+		{"es.example.com:443", "https://es.example.com", true},
+		{"10.0.0.1", "http://10.0.0.1:9200", true},
+		{"10.0.0.0/8", "http://10.1.2.3:9200", true},
+		{"10.0.0.0/8", "http://192.168.1.1:9200", false},
+		{"10.0.0.0/8", "http://es.example.com:9200", false},
+		{"[::1]:9200", "http://[::1]:9200", true},
+		{"", "http://es.example.com:9200", false},
+	}
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		require.NoError(t, err)
+		assert.Equal(t, test.match, matchHost(test.pattern, u), "pattern %q, url %q", test.pattern, test.url)
+	}
+}
+
+func TestProxyForHost(t *testing.T) {
+	cfg := defaultConfig
+	require.NoError(t, config.MustNewConfigFrom(mapstr.M{
+		"proxy_url":    "http://proxy.example.com:3128",
+		"proxy_bypass": []string{"internal"},
+		"proxy_hosts": []mapstr.M{
+			{"host": "es-us.example.com", "proxy_url": "http://proxy-us.example.com:3128"},
+			{"host": "es-direct.example.com"},
+			{"host": "es.internal", "proxy_url": "http://proxy-internal:3128"},
+		},
+	}).Unpack(&cfg))
+
+	tests := map[string]struct {
+		url        string
+		proxy      string
+		disabled   bool
+		overridden bool
+	}{
+		"output proxy": {
+			url:   "https://es-eu.example.com:9200",
+			proxy: "http://proxy.example.com:3128",
+		},
+		"per host proxy": {
+			url:        "https://es-us.example.com:9200",
+			proxy:      "http://proxy-us.example.com:3128",
+			overridden: true,
+		},
+		"per host without proxy url": {
+			url:        "https://es-direct.example.com:9200",
+			disabled:   true,
+			overridden: true,
+		},
+		"bypass": {
+			url:        "https://other.internal:9200",
+			disabled:   true,
+			overridden: true,
+		},
+		"per host takes precedence over bypass": {
+			url:        "https://es.internal:9200",
+			proxy:      "http://proxy-internal:3128",
+			overridden: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			proxy, overridden, err := cfg.proxyForHost(test.url)
+			require.NoError(t, err)
+			assert.Equal(t, test.overridden, overridden)
+			assert.Equal(t, test.disabled, proxy.Disable)
+			if test.proxy == "" {
+				assert.Nil(t, proxy.URL)
+			} else {
+				require.NotNil(t, proxy.URL)
+				assert.Equal(t, test.proxy, proxy.URL.URI().String())
+			}
+		})
+	}
+}
+
+type testIndexManager struct{}
+
+func (testIndexManager) BuildSelector(*config.C) (outputs.IndexSelector, error) {
+	return outil.MakeSelector(outil.ConstSelectorExpr("test", outil.SelectorLowerCase)), nil
+}
+
+// TestOutputProxyRouting checks that the clients created by the output send
+// their requests through the proxy selected for their host.
+func TestOutputProxyRouting(t *testing.T) {
+	tests := map[string]struct {
+		settings      func(servers *serverState) mapstr.M
+		serverCount   int
+		proxyRequests int
+	}{
+		"output proxy": {
+			settings: func(s *serverState) mapstr.M {
+				return mapstr.M{"proxy_url": s.proxyURL}
+			},
+			proxyRequests: 1,
+		},
+		"per host proxy": {
+			settings: func(s *serverState) mapstr.M {
+				return mapstr.M{
+					"proxy_disable": true,
+					"proxy_hosts":   []mapstr.M{{"host": "127.0.0.1", "proxy_url": s.proxyURL}},
+				}
+			},
+			proxyRequests: 1,
+		},
+		"bypass": {
+			settings: func(s *serverState) mapstr.M {
+				return mapstr.M{"proxy_url": s.proxyURL, "proxy_bypass": []string{"127.0.0.0/8"}}
+			},
+			serverCount: 1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			servers, teardown := startServers(t)
+			defer teardown()
+
+			settings := test.settings(servers)
+			settings["hosts"] = []string{servers.serverURL}
+			settings["headers"] = mapstr.M{headerTestField: headerTestValue}
+			settings["max_retries"] = 0
+
+			group, err := makeES(testIndexManager{}, beat.Info{Beat: "test"}, outputs.NewNilObserver(), config.MustNewConfigFrom(settings))
+			require.NoError(t, err)
+			require.Len(t, group.Clients, 1)
+			client, ok := group.Clients[0].(outputs.NetworkClient)
+			require.True(t, ok)
+			defer client.Close()
+
+			// The stub servers don't respond like Elasticsearch, so the
+			// connection fails after the first request.
+			_ = client.Connect(context.Background())
+			assert.Equal(t, test.serverCount, servers.serverRequestCount())
+			assert.Equal(t, test.proxyRequests, servers.proxyRequestCount())
+		})
+	}
+}
@@ -100,6 +100,16 @@ func (f *failoverClient) Publish(ctx context.Context, batch publisher.Batch) err
 	return f.clients[f.active].Publish(ctx, batch)
 }
 
+func (f *failoverClient) SetDeadLetterHandler(h DeadLetterHandler) bool {
+	for _, client := range f.clients {
+		r, ok := client.(DeadLetterReporter)
+		if !ok || !r.SetDeadLetterHandler(h) {
+			return false
+		}
+	}
+	return true
+}
+
 func (f *failoverClient) Test(d testing.Driver) {
 	for i, client := range f.clients {
 		c, ok := client.(testing.Testable)
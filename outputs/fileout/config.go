@@ -19,21 +19,36 @@ package fileout
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/njcx/libbeat_v8/common/fmtstr"
 	"github.com/njcx/libbeat_v8/outputs/codec"
 	"github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/file"
 )
 
 type fileOutConfig struct {
-	Path            *PathFormatString `config:"path"`
-	Filename        string            `config:"filename"`
-	RotateEveryKb   uint              `config:"rotate_every_kb" validate:"min=1"`
-	NumberOfFiles   uint              `config:"number_of_files"`
-	Codec           codec.Config      `config:"codec"`
-	Permissions     uint32            `config:"permissions"`
-	RotateOnStartup bool              `config:"rotate_on_startup"`
-	Queue           config.Namespace  `config:"queue"`
+	Path             *PathFormatString `config:"path"`
+	Filename         string            `config:"filename"`
+	RotateEveryKb    uint              `config:"rotate_every_kb" validate:"min=1"`
+	NumberOfFiles    uint              `config:"number_of_files"`
+	Codec            codec.Config      `config:"codec"`
+	Permissions      uint32            `config:"permissions"`
+	RotateOnStartup  bool              `config:"rotate_on_startup"`
+	RotateOnInterval time.Duration     `config:"rotate_on_interval"`
+	Compress         bool              `config:"compress"`
+	Queue            config.Namespace  `config:"queue"`
+
+	// PathFormat is the name of the file an event is written to, formatted
+	// from the event. If unset, all events are written to the same file.
+	PathFormat   *fmtstr.EventFormatString `config:"path_format"`
+	MaxOpenFiles int                       `config:"max_open_files" validate:"min=1"`
+
+	// FlushInterval bounds the time written events are not yet committed
+	// to stable storage. Requires Fsync.
+	FlushInterval time.Duration `config:"flush_interval"`
+	// Fsync commits the files to stable storage on every flush interval.
+	Fsync bool `config:"fsync"`
 }
 
 func defaultConfig() fileOutConfig {
@@ -43,6 +58,7 @@ func defaultConfig() fileOutConfig {
 		RotateEveryKb:   10 * 1024,
 		Permissions:     0600,
 		RotateOnStartup: true,
+		MaxOpenFiles:    64,
 	}
 }
 
@@ -64,5 +80,19 @@ func (c *fileOutConfig) Validate() error {
 			file.MaxBackupsLimit)
 	}
 
+	if c.RotateOnInterval != 0 && c.RotateOnInterval < time.Second {
+		return fmt.Errorf("the rotate_on_interval must be at least 1s, got %v", c.RotateOnInterval)
+	}
+
+	if c.FlushInterval < 0 {
+		return fmt.Errorf("the flush_interval must not be negative, got %v", c.FlushInterval)
+	}
+	if c.Fsync && c.FlushInterval == 0 {
+		return fmt.Errorf("fsync requires a flush_interval to be set")
+	}
+	if !c.Fsync && c.FlushInterval > 0 {
+		return fmt.Errorf("flush_interval requires fsync to be enabled")
+	}
+
 	return nil
 }
@@ -20,16 +20,19 @@ package fileout
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/common/fmtstr"
 	"github.com/njcx/libbeat_v8/outputs"
 	"github.com/njcx/libbeat_v8/outputs/codec"
 	"github.com/njcx/libbeat_v8/publisher"
 	c "github.com/elastic/elastic-agent-libs/config"
-	"github.com/elastic/elastic-agent-libs/file"
 	"github.com/elastic/elastic-agent-libs/logp"
 )
 
@@ -42,8 +45,34 @@ type fileOutput struct {
 	filePath string
 	beat     beat.Info
 	observer outputs.Observer
-	rotator  *file.Rotator
 	codec    codec.Codec
+	rotation rotationSettings
+	flush    flushSettings
+
+	// mu protects the writers, which are flushed from a separate goroutine
+	// if a flush interval is configured.
+	mu sync.Mutex
+
+	// writer writes to the configured file. If pathFormat is set, it only
+	// receives the events for which no per event file name can be formatted.
+	writer *fileWriter
+
+	// If pathFormat is set, the name of the file an event is written to is
+	// formatted from the event, relative to dir. At most maxOpenFiles of
+	// these files are kept open.
+	dir        string
+	pathFormat *fmtstr.EventFormatString
+	writers    *writerCache
+
+	// Per event files are only rotated on startup if they were not written
+	// since started, so files reopened after being closed are appended to.
+	rotateOnStartup bool
+	started         time.Time
+
+	// flushDone is closed on Close to stop the flush loop, flushStopped is
+	// closed once the loop has returned.
+	flushDone    chan struct{}
+	flushStopped chan struct{}
 }
 
 // makeFileout instantiates a new file output instance.
@@ -83,16 +112,30 @@ func (out *fileOutput) init(beat beat.Info, c fileOutConfig) error {
 	}
 
 	out.filePath = path
+	out.rotation = rotationSettings{
+		rotateInterval: c.RotateOnInterval,
+		compress:       c.Compress,
+		maxSizeBytes:   c.RotateEveryKb * 1024,
+		maxBackups:     c.NumberOfFiles,
+		permissions:    os.FileMode(c.Permissions),
+	}
+	out.flush = flushSettings{
+		fsync: c.Fsync,
+	}
+	out.rotation.managedRotation = out.rotation.rotateInterval > 0 || out.rotation.compress
+	out.rotateOnStartup = c.RotateOnStartup
+
+	if c.PathFormat != nil && !c.PathFormat.IsEmpty() {
+		out.dir = configPath
+		out.pathFormat = c.PathFormat
+		out.writers = newWriterCache(c.MaxOpenFiles)
+		// Truncated to account for file systems with a coarse modification
+		// time resolution.
+		out.started = time.Now().Truncate(time.Second)
+	}
 
 	var err error
-	out.rotator, err = file.NewFileRotator(
-		path,
-		file.MaxSizeBytes(c.RotateEveryKb*1024),
-		file.MaxBackups(c.NumberOfFiles),
-		file.Permissions(os.FileMode(c.Permissions)),
-		file.RotateOnStartup(c.RotateOnStartup),
-		file.WithLogger(logp.NewLogger("rotator").With(logp.Namespace("rotator"))),
-	)
+	out.writer, err = newFileWriter(path, out.rotation, out.flush, out.rotateOnStartup)
 	if err != nil {
 		return err
 	}
@@ -102,16 +145,134 @@ func (out *fileOutput) init(beat beat.Info, c fileOutConfig) error {
 		return err
 	}
 
+	if c.FlushInterval > 0 {
+		out.flushDone = make(chan struct{})
+		out.flushStopped = make(chan struct{})
+		go out.flushLoop(c.FlushInterval)
+	}
+
 	out.log.Infof("Initialized file output. "+
-		"path=%v max_size_bytes=%v max_backups=%v permissions=%v",
-		path, c.RotateEveryKb*1024, c.NumberOfFiles, os.FileMode(c.Permissions))
+		"path=%v max_size_bytes=%v max_backups=%v permissions=%v rotate_on_interval=%v compress=%v path_format=%v "+
+		"flush_interval=%v fsync=%v",
+		path, c.RotateEveryKb*1024, c.NumberOfFiles, os.FileMode(c.Permissions), c.RotateOnInterval, c.Compress, c.PathFormat,
+		c.FlushInterval, c.Fsync)
 
 	return nil
 }
 
 // Implement Outputer
 func (out *fileOutput) Close() error {
-	return out.rotator.Close()
+	// Stop the flush loop first, the writers are flushed when they are
+	// closed.
+	if out.flushDone != nil {
+		close(out.flushDone)
+		<-out.flushStopped
+		out.flushDone = nil
+	}
+
+	out.mu.Lock()
+	defer out.mu.Unlock()
+
+	err := out.writer.Close()
+	if out.writers != nil {
+		if closeErr := out.writers.closeAll(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// flushLoop flushes all open files until Close is called. The time between
+// flushes is randomized to within [interval/2, interval), so outputs started
+// together don't flush at the same time, while written data is still
+// synced within interval.
+func (out *fileOutput) flushLoop(interval time.Duration) {
+	defer close(out.flushStopped)
+
+	timer := time.NewTimer(flushDelay(interval))
+	defer timer.Stop()
+	for {
+		select {
+		case <-out.flushDone:
+			return
+		case <-timer.C:
+		}
+
+		if err := out.flushAll(); err != nil {
+			out.log.Errorf("Flushing file output failed with: %+v", err)
+		}
+		timer.Reset(flushDelay(interval))
+	}
+}
+
+func flushDelay(interval time.Duration) time.Duration {
+	half := interval / 2
+	if half <= 0 {
+		return interval
+	}
+	return half + time.Duration(rand.Int63n(int64(half))) //nolint:gosec // Jitter does not need a secure random source.
+}
+
+// flushAll commits all open files to stable storage.
+func (out *fileOutput) flushAll() error {
+	out.mu.Lock()
+	defer out.mu.Unlock()
+
+	err := out.writer.flush()
+	if out.writers != nil {
+		if flushErr := out.writers.flushAll(); err == nil {
+			err = flushErr
+		}
+	}
+	if err != nil {
+		out.observer.WriteError(err)
+		return err
+	}
+	out.observer.Flushed(time.Now())
+	return nil
+}
+
+// eventWriter returns the writer for the file the event is written to. If the
+// file name can not be formatted from the event, for example because a field
+// is missing, the event is written to the configured file.
+func (out *fileOutput) eventWriter(event *beat.Event) (*fileWriter, error) {
+	if out.pathFormat == nil {
+		return out.writer, nil
+	}
+
+	name, err := out.pathFormat.Run(event)
+	if err != nil || !validFileName(name) {
+		return out.writer, nil
+	}
+
+	path := filepath.Join(out.dir, name)
+	if w := out.writers.get(path); w != nil {
+		return w, nil
+	}
+
+	w, err := newFileWriter(path, out.rotation, out.flush, out.rotateOnStartup && !modifiedSince(path, out.started))
+	if err != nil {
+		return nil, err
+	}
+	for _, evicted := range out.writers.put(w) {
+		if err := evicted.Close(); err != nil {
+			out.log.Warnf("Failed to close file %s: %+v", evicted.filePath, err)
+		}
+	}
+	return w, nil
+}
+
+// modifiedSince reports whether the file at path was modified at or after t.
+func modifiedSince(path string, t time.Time) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.ModTime().Before(t)
+}
+
+// validFileName reports whether name can be used as the name of a file in
+// the output directory. Names must not reference other directories.
+func validFileName(name string) bool {
+	return name != "" && name != "." && name != ".." &&
+		!strings.ContainsAny(name, `/\`)
 }
 
 func (out *fileOutput) Publish(_ context.Context, batch publisher.Batch) error {
@@ -122,6 +283,10 @@ func (out *fileOutput) Publish(_ context.Context, batch publisher.Batch) error {
 	st.NewBatch(len(events))
 
 	dropped := 0
+	encodeFailed := 0
+
+	out.mu.Lock()
+	defer out.mu.Unlock()
 
 	for i := range events {
 		event := &events[i]
@@ -136,12 +301,22 @@ func (out *fileOutput) Publish(_ context.Context, batch publisher.Batch) error {
 			out.log.Debug("Failed event logged to event log file")
 			out.log.Debugw(fmt.Sprintf("Failed event: %v", event), logp.TypeKey, logp.EventType)
 
-			dropped++
+			encodeFailed++
 			continue
 		}
 
 		begin := time.Now()
-		if _, err = out.rotator.Write(append(serializedEvent, '\n')); err != nil {
+		line := append(serializedEvent, '\n')
+		w, err := out.eventWriter(&event.Content)
+		if err == nil {
+			if w.managedRotation {
+				if rotateErr := w.rotateIfNeeded(begin, len(line)); rotateErr != nil {
+					out.log.Errorf("Rotating file failed with: %+v", rotateErr)
+				}
+			}
+			err = w.write(line)
+		}
+		if err != nil {
 			st.WriteError(err)
 
 			if event.Guaranteed() {
@@ -159,9 +334,10 @@ func (out *fileOutput) Publish(_ context.Context, batch publisher.Batch) error {
 		st.ReportLatency(took)
 	}
 
+	st.EncodeErrors(encodeFailed)
 	st.PermanentErrors(dropped)
 
-	st.AckedEvents(len(events) - dropped)
+	st.AckedEvents(len(events) - dropped - encodeFailed)
 
 	return nil
 }
@@ -18,3 +18,292 @@
 //go:build !integration
 
 package fileout
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/outputs"
+	_ "github.com/njcx/libbeat_v8/outputs/codec/json"
+	"github.com/njcx/libbeat_v8/outputs/outest"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func newTestFileOutput(t *testing.T, settings mapstr.M) *fileOutput {
+	t.Helper()
+
+	foConfig, err := readConfig(config.MustNewConfigFrom(settings))
+	require.NoError(t, err)
+
+	fo := &fileOutput{
+		log:      logp.NewLogger("file"),
+		beat:     beat.Info{Beat: "test"},
+		observer: outputs.NewNilObserver(),
+	}
+	require.NoError(t, fo.init(fo.beat, *foConfig))
+	t.Cleanup(func() { fo.Close() })
+	return fo
+}
+
+func TestFileOutputCompressOnRotate(t *testing.T) {
+	dir := t.TempDir()
+	fo := newTestFileOutput(t, mapstr.M{
+		"path":               dir,
+		"filename":           "out",
+		"compress":           true,
+		"rotate_on_interval": "1h",
+	})
+
+	batch := outest.NewBatch(beat.Event{Fields: mapstr.M{"message": "hello"}})
+	require.NoError(t, fo.Publish(context.Background(), batch))
+
+	require.NoError(t, fo.writer.rotate(time.Now()))
+	active, err := outputFiles(filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Len(t, active, 1)
+
+	files, err := filepath.Glob(filepath.Join(dir, "out-*.gz"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"message":"hello"`)
+
+	// The new file is empty, so there is nothing to rotate.
+	require.NoError(t, fo.writer.rotate(time.Now()))
+	files, err = filepath.Glob(filepath.Join(dir, "out-*.gz"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestFileOutputResumeWithinInterval(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0600))
+
+	fo := newTestFileOutput(t, mapstr.M{
+		"path":               dir,
+		"filename":           "out",
+		"rotate_on_interval": "24h",
+	})
+	assert.Equal(t, uint(len("existing\n")), fo.writer.size)
+	files, err := outputFiles(path)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.True(t, fo.writer.nextRotation.After(time.Now()))
+}
+
+func TestFileOutputRotateOutsideInterval(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0600))
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	// With a rotation interval rotate_on_startup is ignored, a file written
+	// before the current interval is always rotated.
+	fo := newTestFileOutput(t, mapstr.M{
+		"path":               dir,
+		"filename":           "out",
+		"rotate_on_interval": "24h",
+		"rotate_on_startup":  false,
+	})
+	assert.Zero(t, fo.writer.size)
+	files, err := outputFiles(path)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func TestOutputFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out")
+	for _, name := range []string{"out", "out.1", "out.12", "out.log", "out.1.bak", "output", "out-20240102-030000.000.gz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+
+	files, err := outputFiles(path)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f.path))
+	}
+	assert.ElementsMatch(t, []string{"out", "out.1", "out.12"}, names)
+}
+
+func TestRemoveOldCompressedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out")
+
+	start := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		name := compressedFileName(path, start.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, os.WriteFile(name, nil, 0600))
+	}
+	other := path + "-other.gz"
+	require.NoError(t, os.WriteFile(other, nil, 0600))
+
+	require.NoError(t, removeOldCompressedFiles(path, 2))
+	files, err := filepath.Glob(path + "-*.gz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		compressedFileName(path, start.Add(2*time.Hour)),
+		compressedFileName(path, start.Add(3*time.Hour)),
+		other,
+	}, files)
+}
+
+func TestFileOutputPathFormat(t *testing.T) {
+	dir := t.TempDir()
+	fo := newTestFileOutput(t, mapstr.M{
+		"path":           dir,
+		"filename":       "out",
+		"path_format":    "%{[event.dataset]}.ndjson",
+		"max_open_files": 1,
+	})
+
+	batch := outest.NewBatch(
+		beat.Event{Fields: mapstr.M{"event": mapstr.M{"dataset": "a"}, "message": "first"}},
+		beat.Event{Fields: mapstr.M{"event": mapstr.M{"dataset": "b"}, "message": "second"}},
+		beat.Event{Fields: mapstr.M{"event": mapstr.M{"dataset": "a"}, "message": "third"}},
+		beat.Event{Fields: mapstr.M{"event": mapstr.M{"dataset": "../escape"}, "message": "fourth"}},
+		beat.Event{Fields: mapstr.M{"message": "fifth"}},
+	)
+	require.NoError(t, fo.Publish(context.Background(), batch))
+
+	// Only the most recently used file is kept open.
+	assert.Equal(t, 1, fo.writers.lru.Len())
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.ndjson"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"message":"first"`)
+	assert.Contains(t, string(content), `"message":"third"`)
+
+	content, err = os.ReadFile(filepath.Join(dir, "b.ndjson"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"message":"second"`)
+
+	content, err = os.ReadFile(filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"message":"fourth"`)
+	assert.Contains(t, string(content), `"message":"fifth"`)
+}
+
+func TestFileOutputPathFormatRotateOnStartup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0600))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	fo := newTestFileOutput(t, mapstr.M{
+		"path":           dir,
+		"filename":       "out",
+		"path_format":    "%{[event.dataset]}.ndjson",
+		"max_open_files": 1,
+	})
+
+	batch := outest.NewBatch(
+		beat.Event{Fields: mapstr.M{"event": mapstr.M{"dataset": "a"}, "message": "first"}},
+		beat.Event{Fields: mapstr.M{"event": mapstr.M{"dataset": "b"}, "message": "second"}},
+		beat.Event{Fields: mapstr.M{"event": mapstr.M{"dataset": "a"}, "message": "third"}},
+	)
+	require.NoError(t, fo.Publish(context.Background(), batch))
+
+	// The file written before startup is rotated when it is first opened,
+	// but not when it is reopened after being closed.
+	content, err := os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "existing\n", string(content))
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"message":"first"`)
+	assert.Contains(t, string(content), `"message":"third"`)
+}
+
+// flushObserver counts the flushes reported by the output.
+type flushObserver struct {
+	outputs.Observer
+	flushes atomic.Int64
+}
+
+func (o *flushObserver) Flushed(time.Time) { o.flushes.Add(1) }
+
+func TestFileOutputFlushInterval(t *testing.T) {
+	t.Run("data is flushed periodically", func(t *testing.T) {
+		dir := t.TempDir()
+		fo := newTestFileOutput(t, mapstr.M{
+			"path":           dir,
+			"filename":       "out",
+			"flush_interval": "20ms",
+			"fsync":          true,
+		})
+		observer := &flushObserver{Observer: outputs.NewNilObserver()}
+		fo.mu.Lock()
+		fo.observer = observer
+		fo.mu.Unlock()
+
+		batch := outest.NewBatch(beat.Event{Fields: mapstr.M{"message": "hello"}})
+		require.NoError(t, fo.Publish(context.Background(), batch))
+
+		require.Eventually(t, func() bool {
+			content, err := os.ReadFile(filepath.Join(dir, "out"))
+			return err == nil && strings.Contains(string(content), `"message":"hello"`)
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Eventually(t, func() bool { return observer.flushes.Load() > 0 }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("events are written without buffering", func(t *testing.T) {
+		dir := t.TempDir()
+		fo := newTestFileOutput(t, mapstr.M{
+			"path":           dir,
+			"filename":       "out",
+			"flush_interval": "1h",
+			"fsync":          true,
+		})
+
+		batch := outest.NewBatch(beat.Event{Fields: mapstr.M{"message": "hello"}})
+		require.NoError(t, fo.Publish(context.Background(), batch))
+
+		// The batch is acknowledged on Publish, so the events must already
+		// be in the file.
+		content, err := os.ReadFile(filepath.Join(dir, "out"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `"message":"hello"`)
+	})
+
+	t.Run("flush_interval requires fsync", func(t *testing.T) {
+		_, err := readConfig(config.MustNewConfigFrom(mapstr.M{
+			"path":           t.TempDir(),
+			"flush_interval": "1s",
+		}))
+		assert.Error(t, err)
+	})
+}
+
+func TestFlushDelay(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := flushDelay(time.Second)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.Less(t, d, time.Second)
+	}
+}
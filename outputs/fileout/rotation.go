@@ -0,0 +1,212 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package fileout
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// compressedTimestampLayout is the layout of the rotation timestamp in the
+// names of compressed files.
+const compressedTimestampLayout = "20060102-150405.000"
+
+// rotateIfNeeded rotates the current file when the rotation interval has
+// elapsed, or when writing n more bytes would exceed the maximum file size.
+// It is only used if time based rotation or compression is configured,
+// otherwise the rotator handles size based rotation by itself.
+func (w *fileWriter) rotateIfNeeded(now time.Time, n int) error {
+	switch {
+	case w.rotateInterval > 0 && !now.Before(w.nextRotation):
+	case w.size > 0 && w.size+uint(n) > w.maxSizeBytes:
+	default:
+		return nil
+	}
+	return w.rotate(now)
+}
+
+// rotate rotates the current file, compressing the rotated file if
+// configured. Empty files are not rotated.
+func (w *fileWriter) rotate(now time.Time) error {
+	if w.rotateInterval > 0 {
+		w.nextRotation = now.Truncate(w.rotateInterval).Add(w.rotateInterval)
+	}
+	if w.size == 0 {
+		return nil
+	}
+
+	if err := w.rotator.Rotate(); err != nil {
+		return fmt.Errorf("failed to rotate file: %w", err)
+	}
+	w.size = 0
+
+	if !w.compress {
+		return nil
+	}
+	return w.compressRotatedFiles()
+}
+
+// compressRotatedFiles compresses all uncompressed files written by the
+// writer except the active one, and removes the oldest compressed files
+// exceeding maxBackups.
+func (w *fileWriter) compressRotatedFiles() error {
+	files, err := outputFiles(w.filePath)
+	if err != nil {
+		return err
+	}
+	if len(files) < 2 {
+		return nil
+	}
+
+	// The active file is the most recently modified one.
+	for _, f := range files[:len(files)-1] {
+		if err := compressFile(f.path, compressedFileName(w.filePath, f.modTime), w.permissions); err != nil {
+			return err
+		}
+	}
+	return removeOldCompressedFiles(w.filePath, w.maxBackups)
+}
+
+type outputFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// outputFiles returns the uncompressed files written by the rotator for
+// path, ordered by modification time. These are path itself and the rotated
+// files path.1, path.2, and so on.
+func outputFiles(path string) ([]outputFile, error) {
+	matches, err := filepath.Glob(path + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []outputFile
+	for _, m := range matches {
+		if !isOutputFile(path, m) {
+			continue
+		}
+		info, err := os.Stat(m)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, outputFile{path: m, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	return files, nil
+}
+
+// isOutputFile reports whether name is path or one of its rotated files.
+func isOutputFile(path, name string) bool {
+	if name == path {
+		return true
+	}
+	n, found := strings.CutPrefix(name, path+".")
+	if !found {
+		return false
+	}
+	_, err := strconv.ParseUint(n, 10, 64)
+	return err == nil
+}
+
+// resumeCurrentFile reports whether the active file for path was written in
+// the current rotation interval, and returns its size.
+func resumeCurrentFile(path string, now time.Time, interval time.Duration) (bool, uint) {
+	files, err := outputFiles(path)
+	if err != nil || len(files) == 0 {
+		return false, 0
+	}
+	active := files[len(files)-1]
+	if active.modTime.Before(now.Truncate(interval)) {
+		return false, uint(active.size)
+	}
+	return true, uint(active.size)
+}
+
+func compressedFileName(path string, ts time.Time) string {
+	return path + "-" + ts.UTC().Format(compressedTimestampLayout) + ".gz"
+}
+
+// compressFile writes a gzip compressed copy of src to dst and removes src.
+func compressFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open rotated file: %w", err)
+	}
+	defer in.Close()
+
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create compressed file: %w", err)
+	}
+
+	gz := gzip.NewWriter(f)
+	_, err = io.Copy(gz, in)
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to compress rotated file %s: %w", src, err)
+	}
+
+	in.Close()
+	return os.Remove(src)
+}
+
+// removeOldCompressedFiles keeps the newest maxBackups compressed files of
+// path and removes the others.
+func removeOldCompressedFiles(path string, maxBackups uint) error {
+	matches, err := filepath.Glob(path + "-*.gz")
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	for _, m := range matches {
+		ts := strings.TrimSuffix(strings.TrimPrefix(m, path+"-"), ".gz")
+		if _, err := time.Parse(compressedTimestampLayout, ts); err == nil {
+			files = append(files, m)
+		}
+	}
+	if uint(len(files)) <= maxBackups {
+		return nil
+	}
+
+	// The timestamp layout sorts lexicographically.
+	sort.Strings(files)
+	for _, f := range files[:uint(len(files))-maxBackups] {
+		if err := os.Remove(f); err != nil {
+			return fmt.Errorf("failed to remove old compressed file: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package fileout
+
+import (
+	"container/list"
+	"os"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/file"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// rotationSettings configure how the files written by the output are
+// rotated.
+type rotationSettings struct {
+	// managedRotation is set if time based rotation or compression is
+	// configured. The output then decides on rotation itself, tracking the
+	// size of the current file.
+	managedRotation bool
+	rotateInterval  time.Duration
+	compress        bool
+	maxSizeBytes    uint
+	maxBackups      uint
+	permissions     os.FileMode
+}
+
+// flushSettings configure how the files written by the output are committed
+// to stable storage.
+type flushSettings struct {
+	// fsync is set if the files are synced on every flush interval. Writes
+	// are never buffered by the output, so events are in the file once they
+	// are acknowledged.
+	fsync bool
+}
+
+// fileWriter writes events to a single file and its rotated backups.
+type fileWriter struct {
+	rotationSettings
+	flushSettings
+
+	filePath     string
+	rotator      *file.Rotator
+	nextRotation time.Time
+	size         uint
+}
+
+// newFileWriter opens the file at path for writing.
+func newFileWriter(path string, settings rotationSettings, flush flushSettings, rotateOnStartup bool) (*fileWriter, error) {
+	w := &fileWriter{
+		rotationSettings: settings,
+		flushSettings:    flush,
+		filePath:         path,
+	}
+
+	// With managed rotation the rotator must not rotate on startup, as the
+	// rotated file would not be compressed. If a rotation interval is set,
+	// the current file is resumed if it was written in the current interval,
+	// and rotated otherwise, independent of rotateOnStartup.
+	now := time.Now()
+	if w.managedRotation {
+		resume, size := resumeCurrentFile(path, now, w.rotateInterval)
+		if w.rotateInterval > 0 {
+			rotateOnStartup = !resume
+		}
+		w.size = size
+	}
+
+	var err error
+	w.rotator, err = file.NewFileRotator(
+		path,
+		file.MaxSizeBytes(w.maxSizeBytes),
+		file.MaxBackups(w.maxBackups),
+		file.Permissions(w.permissions),
+		file.RotateOnStartup(rotateOnStartup && !w.managedRotation),
+		file.WithLogger(logp.NewLogger("rotator").With(logp.Namespace("rotator"))),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.managedRotation {
+		if rotateOnStartup {
+			if err := w.rotate(now); err != nil {
+				w.rotator.Close()
+				return nil, err
+			}
+		} else if w.rotateInterval > 0 {
+			w.nextRotation = now.Truncate(w.rotateInterval).Add(w.rotateInterval)
+		}
+	}
+	return w, nil
+}
+
+// write appends line to the file.
+func (w *fileWriter) write(line []byte) error {
+	if _, err := w.rotator.Write(line); err != nil {
+		return err
+	}
+	w.size += uint(len(line))
+	return nil
+}
+
+// flush commits the file to stable storage if fsync is configured.
+func (w *fileWriter) flush() error {
+	if w.fsync {
+		return w.rotator.Sync()
+	}
+	return nil
+}
+
+// Close flushes and closes the file.
+func (w *fileWriter) Close() error {
+	err := w.flush()
+	if closeErr := w.rotator.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writerCache keeps the per event files of the output open, closing the
+// least recently used file when more than size files are open.
+type writerCache struct {
+	size  int
+	lru   *list.List // Front is most recently used.
+	items map[string]*list.Element
+}
+
+func newWriterCache(size int) *writerCache {
+	return &writerCache{
+		size:  size,
+		lru:   list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+// get returns the open writer for path, or nil if the file is not open.
+func (c *writerCache) get(path string) *fileWriter {
+	elem, found := c.items[path]
+	if !found {
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*fileWriter)
+}
+
+// put adds an open writer, and returns the least recently used writers that
+// have to be closed to stay within the size limit.
+func (c *writerCache) put(w *fileWriter) []*fileWriter {
+	c.items[w.filePath] = c.lru.PushFront(w)
+
+	var evicted []*fileWriter
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		ow := oldest.Value.(*fileWriter)
+		delete(c.items, ow.filePath)
+		evicted = append(evicted, ow)
+	}
+	return evicted
+}
+
+// flushAll flushes all writers, returning the first error.
+func (c *writerCache) flushAll() error {
+	var firstErr error
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		if err := elem.Value.(*fileWriter).flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// closeAll closes and removes all writers.
+func (c *writerCache) closeAll() error {
+	var firstErr error
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		if err := elem.Value.(*fileWriter).Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.lru.Init()
+	c.items = map[string]*list.Element{}
+	return firstErr
+}
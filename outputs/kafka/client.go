@@ -70,6 +70,7 @@ type msgRef struct {
 
 var (
 	errNoTopicsSelected = errors.New("no topic could be selected")
+	errEncodeEvent      = errors.New("failed to encode event")
 )
 
 func newKafkaClient(
@@ -171,7 +172,11 @@ func (c *client) Publish(_ context.Context, batch publisher.Batch) error {
 		if err != nil {
 			c.log.Errorf("Dropping event: %+v", err)
 			ref.done()
-			c.observer.PermanentErrors(1)
+			if errors.Is(err, errEncodeEvent) {
+				c.observer.EncodeErrors(1)
+			} else {
+				c.observer.PermanentErrors(1)
+			}
 			continue
 		}
 
@@ -231,7 +236,7 @@ func (c *client) getEventMessage(data *publisher.Event) (*message, error) {
 			c.log.Debug("failed event logged to event log file")
 			c.log.Debugw(fmt.Sprintf("failed event: %v", event), logp.TypeKey, logp.EventType)
 		}
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", errEncodeEvent, err)
 	}
 
 	buf := make([]byte, len(serializedEvent))
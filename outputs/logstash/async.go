@@ -42,6 +42,8 @@ type asyncClient struct {
 
 	connect func() error
 
+	splitAfter int
+
 	mutex sync.Mutex
 }
 
@@ -67,6 +69,8 @@ func newAsyncClient(
 		log:      log,
 		Client:   conn,
 		observer: observer,
+
+		splitAfter: config.SplitAfter,
 	}
 
 	if config.SlowStart {
@@ -214,6 +218,7 @@ func (c *asyncClient) sendEvents(ref *msgRef, events []publisher.Event) error {
 	if client == nil {
 		return errors.New("connection closed")
 	}
+	markSent(c.observer, events)
 	window := make([]interface{}, len(events))
 	for i := range events {
 		window[i] = &events[i].Content
@@ -276,6 +281,7 @@ func (r *msgRef) dec() {
 		return
 	}
 
-	r.batch.RetryEvents(r.slice)
+	retryFailed(r.client.log, r.client.observer, r.batch, r.slice,
+		len(r.slice) < r.batchSize, r.client.splitAfter)
 	r.client.log.Errorf("Failed to publish events caused by: %+v", err)
 }
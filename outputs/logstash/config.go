@@ -25,6 +25,7 @@ import (
 	"github.com/elastic/elastic-agent-libs/config"
 
 	"github.com/njcx/libbeat_v8/common/cfgwarn"
+	"github.com/njcx/libbeat_v8/common/transport/tlsreload"
 	"github.com/elastic/elastic-agent-libs/transport"
 	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
 )
@@ -32,6 +33,7 @@ import (
 type Config struct {
 	Index            string                `config:"index"`
 	LoadBalance      bool                  `config:"loadbalance"`
+	PartitionField   string                `config:"partition_field"`
 	BulkMaxSize      int                   `config:"bulk_max_size"`
 	SlowStart        bool                  `config:"slow_start"`
 	Timeout          time.Duration         `config:"timeout"`
@@ -39,11 +41,16 @@ type Config struct {
 	Pipelining       int                   `config:"pipelining"        validate:"min=0"`
 	CompressionLevel int                   `config:"compression_level" validate:"min=0, max=9"`
 	MaxRetries       int                   `config:"max_retries"       validate:"min=-1"`
+	SplitAfter       int                   `config:"split_after_failures" validate:"min=0"`
 	TLS              *tlscommon.Config     `config:"ssl"`
 	Proxy            transport.ProxyConfig `config:",inline"`
 	Backoff          Backoff               `config:"backoff"`
 	EscapeHTML       bool                  `config:"escape_html"`
 	Queue            config.Namespace      `config:"queue"`
+
+	// SSLReloadInterval is the minimum time between two checks of the TLS
+	// client certificate and key files for changes. Zero disables reloading.
+	SSLReloadInterval time.Duration `config:"ssl_reload_interval" validate:"min=0"`
 }
 
 type Backoff struct {
@@ -65,7 +72,8 @@ func defaultConfig() Config {
 			Init: 1 * time.Second,
 			Max:  60 * time.Second,
 		},
-		EscapeHTML: false,
+		EscapeHTML:        false,
+		SSLReloadInterval: tlsreload.DefaultInterval,
 	}
 }
 
@@ -51,8 +51,9 @@ func TestConfig(t *testing.T) {
 					Init: 1 * time.Second,
 					Max:  60 * time.Second,
 				},
-				EscapeHTML: false,
-				Index:      "bar",
+				EscapeHTML:        false,
+				Index:             "bar",
+				SSLReloadInterval: time.Minute,
 			},
 		},
 		"config given": {
@@ -75,8 +76,9 @@ func TestConfig(t *testing.T) {
 					Init: 1 * time.Second,
 					Max:  60 * time.Second,
 				},
-				EscapeHTML: false,
-				Index:      "beat-index",
+				EscapeHTML:        false,
+				Index:             "beat-index",
+				SSLReloadInterval: time.Minute,
 			},
 		},
 		"removed config setting": {
@@ -0,0 +1,93 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logstash
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/njcx/libbeat_v8/common/transport/tlsreload"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/transport"
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+// reloadingDialer reloads the TLS configuration when the client certificate
+// files change. Established connections keep the certificate they were
+// created with, only new connections use the reloaded one.
+type reloadingDialer struct {
+	watcher    *tlsreload.Watcher
+	tlsConfig  *tlscommon.Config
+	makeDialer func(*tlscommon.TLSConfig) (transport.Dialer, error)
+	log        *logp.Logger
+
+	mu     sync.Mutex
+	dialer transport.Dialer
+}
+
+func newReloadingDialer(
+	d transport.Dialer,
+	watcher *tlsreload.Watcher,
+	tlsConfig *tlscommon.Config,
+	makeDialer func(*tlscommon.TLSConfig) (transport.Dialer, error),
+) *reloadingDialer {
+	return &reloadingDialer{
+		watcher:    watcher,
+		tlsConfig:  tlsConfig,
+		makeDialer: makeDialer,
+		log:        logp.NewLogger("logstash"),
+		dialer:     d,
+	}
+}
+
+func (d *reloadingDialer) Dial(network, address string) (net.Conn, error) {
+	return d.current().Dial(network, address)
+}
+
+func (d *reloadingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d.current().DialContext(ctx, network, address)
+}
+
+// current returns the dialer to use for a new connection, reloading the TLS
+// configuration first if the certificate files changed. If the new
+// certificate can't be loaded, the previous dialer is kept.
+func (d *reloadingDialer) current() transport.Dialer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	reloaded, err := d.watcher.Reload(func() error {
+		tls, err := tlscommon.LoadTLSConfig(d.tlsConfig)
+		if err != nil {
+			return err
+		}
+		dialer, err := d.makeDialer(tls)
+		if err != nil {
+			return err
+		}
+		d.dialer = dialer
+		return nil
+	})
+	if err != nil {
+		d.log.Errorf("Failed to reload the TLS client certificate, keeping the current one: %v", err)
+	}
+	if reloaded {
+		d.log.Info("TLS client certificate reloaded")
+	}
+	return d.dialer
+}
@@ -19,6 +19,8 @@ package logstash
 
 import (
 	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/common/transport/tlsreload"
+	"github.com/njcx/libbeat_v8/common/transport/unixsock"
 	"github.com/njcx/libbeat_v8/outputs"
 	conf "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/transport"
@@ -67,7 +69,7 @@ func makeLogstash(
 	for i, host := range hosts {
 		var client outputs.NetworkClient
 
-		conn, err := transport.NewClient(transp, "tcp", host, defaultPort)
+		conn, err := newTransportClient(transp, host, lsConfig)
 		if err != nil {
 			return outputs.Fail(err)
 		}
@@ -81,9 +83,54 @@ func makeLogstash(
 			return outputs.Fail(err)
 		}
 
-		client = outputs.WithBackoff(client, lsConfig.Backoff.Init, lsConfig.Backoff.Max)
+		if lsConfig.PartitionField == "" {
+			client = outputs.WithBackoff(client, lsConfig.Backoff.Init, lsConfig.Backoff.Max)
+		}
 		clients[i] = client
 	}
 
+	if lsConfig.PartitionField != "" {
+		// A single client distributes the events of each batch over all hosts.
+		client := outputs.WithBackoff(newPartitionClient(lsConfig.PartitionField, clients),
+			lsConfig.Backoff.Init, lsConfig.Backoff.Max)
+		return outputs.SuccessNet(lsConfig.Queue, false, lsConfig.BulkMaxSize, lsConfig.MaxRetries, nil,
+			[]outputs.NetworkClient{client})
+	}
+
 	return outputs.SuccessNet(lsConfig.Queue, lsConfig.LoadBalance, lsConfig.BulkMaxSize, lsConfig.MaxRetries, nil, clients)
 }
+
+// newTransportClient creates the connection to a Logstash host. Hosts like
+// `unix:///var/run/logstash.sock` are connected to over a unix socket, with
+// the configured TLS settings but without proxy. If the TLS client
+// certificate is read from files, new connections pick up changes to them.
+func newTransportClient(transp transport.Config, host string, lsConfig *Config) (*transport.Client, error) {
+	makeDialer := func(tls *tlscommon.TLSConfig) (transport.Dialer, error) {
+		c := transp
+		c.TLS = tls
+		return transport.MakeDialer(c)
+	}
+	address := host
+	if unixsock.IsUnixSocket(host) {
+		address = "localhost"
+		makeDialer = func(tls *tlscommon.TLSConfig) (transport.Dialer, error) {
+			d := unixsock.Dialer(unixsock.Path(host), transp.Timeout)
+			if transp.Stats != nil {
+				d = transport.StatsDialer(d, transp.Stats)
+			}
+			if tls != nil {
+				d = transport.TLSDialer(d, tls, transp.Timeout)
+			}
+			return d, nil
+		}
+	}
+
+	d, err := makeDialer(transp.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if watcher := tlsreload.NewWatcher(lsConfig.TLS, lsConfig.SSLReloadInterval); watcher != nil {
+		d = newReloadingDialer(d, watcher, lsConfig.TLS, makeDialer)
+	}
+	return transport.NewClientWithDialer(d, transp, "tcp", address, defaultPort)
+}
@@ -0,0 +1,237 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logstash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	"github.com/njcx/libbeat_v8/outputs"
+	"github.com/njcx/libbeat_v8/publisher"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/testing"
+)
+
+// partitionClient combines a set of NetworkClients into one NetworkClient.
+// Events are assigned to a connection by the hash of the configured field,
+// such that events with the same key are sent to the same Logstash host as
+// long as the set of hosts connected by the last Connect does not change.
+// Events missing the field are distributed round-robin.
+type partitionClient struct {
+	log     *logp.Logger
+	field   string
+	clients []outputs.NetworkClient
+
+	// active holds the indices of the currently connected clients.
+	active []int
+	next   int
+}
+
+var errNoActivePartition = errors.New("no active connection")
+
+func newPartitionClient(field string, clients []outputs.NetworkClient) *partitionClient {
+	return &partitionClient{
+		log:     logp.NewLogger("logstash"),
+		field:   field,
+		clients: clients,
+	}
+}
+
+// Connect connects all clients. It succeeds if at least one client could be
+// connected. Events are only assigned to connected clients, so a host being
+// unavailable rebalances its keys to the remaining hosts until the next
+// Connect, which is only called after publishing failed.
+func (p *partitionClient) Connect(ctx context.Context) error {
+	if len(p.clients) == 0 {
+		return outputs.ErrNoConnectionConfigured
+	}
+
+	var errs []error
+	p.active = p.active[:0]
+	for i, client := range p.clients {
+		if err := client.Connect(ctx); err != nil {
+			p.log.Warnf("Failed to connect to %v, excluding it from partitioning: %v", client, err)
+			errs = append(errs, err)
+			continue
+		}
+		p.active = append(p.active, i)
+	}
+
+	if len(p.active) == 0 {
+		return errors.Join(errs...)
+	}
+	if len(p.active) < len(p.clients) {
+		p.log.Infof("Partitioning events over %d of %d hosts", len(p.active), len(p.clients))
+	}
+	return nil
+}
+
+func (p *partitionClient) Close() error {
+	var errs []error
+	for _, i := range p.active {
+		if err := p.clients[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	p.active = p.active[:0]
+	return errors.Join(errs...)
+}
+
+// Publish splits the batch by partition and publishes each part to its
+// connection. The batch is signaled once all parts have been handled.
+func (p *partitionClient) Publish(ctx context.Context, batch publisher.Batch) error {
+	if len(p.active) == 0 {
+		batch.Retry()
+		return errNoActivePartition
+	}
+
+	events := batch.Events()
+	parts := make([][]publisher.Event, len(p.active))
+	for _, event := range events {
+		i := p.partition(&event)
+		parts[i] = append(parts[i], event)
+	}
+
+	tracker := &partitionBatch{parent: batch, total: len(events)}
+	for _, part := range parts {
+		if len(part) > 0 {
+			tracker.pending++
+		}
+	}
+
+	var errs []error
+	for i, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		client := p.clients[p.active[i]]
+		if err := client.Publish(ctx, &partitionSubBatch{events: part, parent: tracker}); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", client, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// partition returns the index into active to publish the event to.
+func (p *partitionClient) partition(event *publisher.Event) int {
+	n := len(p.active)
+	if v, err := event.Content.Fields.GetValue(p.field); err == nil {
+		h := fnv.New32a()
+		switch s := v.(type) {
+		case string:
+			_, _ = h.Write([]byte(s))
+		case []byte:
+			_, _ = h.Write(s)
+		default:
+			fmt.Fprint(h, v)
+		}
+		return int(h.Sum32() % uint32(n))
+	}
+
+	p.next = (p.next + 1) % n
+	return p.next
+}
+
+func (p *partitionClient) Test(d testing.Driver) {
+	for i, client := range p.clients {
+		c, ok := client.(testing.Testable)
+		d.Run(fmt.Sprintf("Client %d", i), func(d testing.Driver) {
+			if !ok {
+				d.Fatal("output", errors.New("client doesn't support testing"))
+			}
+			c.Test(d)
+		})
+	}
+}
+
+func (p *partitionClient) String() string {
+	names := make([]string, len(p.clients))
+	for i, client := range p.clients {
+		names[i] = client.String()
+	}
+	return "partition(" + p.field + ": " + strings.Join(names, ",") + ")"
+}
+
+// partitionBatch collects the signals of the parts of a batch split by
+// partitionClient, and signals the original batch once all parts are done.
+type partitionBatch struct {
+	parent publisher.Batch
+	total  int
+
+	mutex     sync.Mutex
+	pending   int
+	retry     []publisher.Event
+	dropped   int
+	cancelled int
+}
+
+func (b *partitionBatch) done(retry []publisher.Event, dropped, cancelled int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.retry = append(b.retry, retry...)
+	b.dropped += dropped
+	b.cancelled += cancelled
+	b.pending--
+	if b.pending > 0 {
+		return
+	}
+
+	switch {
+	case b.cancelled == b.total:
+		b.parent.Cancelled()
+	case b.dropped == b.total:
+		b.parent.Drop()
+	case len(b.retry) == 0:
+		b.parent.ACK()
+	default:
+		b.parent.RetryEvents(b.retry)
+	}
+}
+
+// partitionSubBatch is the part of a batch published to a single connection.
+type partitionSubBatch struct {
+	events []publisher.Event
+	parent *partitionBatch
+	once   sync.Once
+}
+
+func (b *partitionSubBatch) signal(retry []publisher.Event, dropped, cancelled int) {
+	b.once.Do(func() { b.parent.done(retry, dropped, cancelled) })
+}
+
+func (b *partitionSubBatch) Events() []publisher.Event { return b.events }
+func (b *partitionSubBatch) ACK()                      { b.signal(nil, 0, 0) }
+func (b *partitionSubBatch) Drop()                     { b.signal(nil, len(b.events), 0) }
+func (b *partitionSubBatch) Retry()                    { b.signal(b.events, 0, 0) }
+func (b *partitionSubBatch) Cancelled()                { b.signal(b.events, 0, len(b.events)) }
+
+func (b *partitionSubBatch) RetryEvents(events []publisher.Event) {
+	b.signal(events, 0, 0)
+}
+
+// SplitRetry hands the events back to the original batch to be retried as a
+// whole, as the sub batch can not be split on its own.
+func (b *partitionSubBatch) SplitRetry() bool {
+	b.signal(b.events, 0, 0)
+	return true
+}
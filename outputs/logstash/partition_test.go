@@ -0,0 +1,143 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package logstash
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/outputs"
+	"github.com/njcx/libbeat_v8/outputs/outest"
+	"github.com/njcx/libbeat_v8/publisher"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type mockPartitionTarget struct {
+	name       string
+	connectErr error
+	retry      bool
+	published  []publisher.Event
+}
+
+func (m *mockPartitionTarget) Connect(context.Context) error { return m.connectErr }
+func (m *mockPartitionTarget) Close() error                  { return nil }
+func (m *mockPartitionTarget) String() string                { return m.name }
+
+func (m *mockPartitionTarget) Publish(_ context.Context, batch publisher.Batch) error {
+	m.published = append(m.published, batch.Events()...)
+	if m.retry {
+		batch.Retry()
+	} else {
+		batch.ACK()
+	}
+	return nil
+}
+
+func makePartitionTargets(n int) ([]*mockPartitionTarget, []outputs.NetworkClient) {
+	targets := make([]*mockPartitionTarget, n)
+	clients := make([]outputs.NetworkClient, n)
+	for i := range targets {
+		targets[i] = &mockPartitionTarget{name: string(rune('a' + i))}
+		clients[i] = targets[i]
+	}
+	return targets, clients
+}
+
+func hostEvents(hosts ...string) []beat.Event {
+	events := make([]beat.Event, len(hosts))
+	for i, host := range hosts {
+		events[i] = beat.Event{Fields: mapstr.M{"host": mapstr.M{"name": host}}}
+	}
+	return events
+}
+
+func TestPartitionClientStickyByField(t *testing.T) {
+	targets, clients := makePartitionTargets(3)
+	client := newPartitionClient("host.name", clients)
+	require.NoError(t, client.Connect(context.Background()))
+
+	batch := outest.NewBatch(hostEvents("x", "y", "x", "z", "y", "x")...)
+	require.NoError(t, client.Publish(context.Background(), batch))
+
+	require.Len(t, batch.Signals, 1)
+	assert.Equal(t, outest.BatchACK, batch.Signals[0].Tag)
+
+	seen := map[string]string{}
+	total := 0
+	for _, target := range targets {
+		for _, event := range target.published {
+			host, err := event.Content.Fields.GetValue("host.name")
+			require.NoError(t, err)
+			if prev, ok := seen[host.(string)]; ok {
+				assert.Equal(t, prev, target.name, "events for host %v sent to different targets", host)
+			}
+			seen[host.(string)] = target.name
+			total++
+		}
+	}
+	assert.Equal(t, 6, total)
+}
+
+func TestPartitionClientMissingFieldRoundRobin(t *testing.T) {
+	targets, clients := makePartitionTargets(2)
+	client := newPartitionClient("host.name", clients)
+	require.NoError(t, client.Connect(context.Background()))
+
+	batch := outest.NewBatch(beat.Event{}, beat.Event{}, beat.Event{}, beat.Event{})
+	require.NoError(t, client.Publish(context.Background(), batch))
+
+	assert.Len(t, targets[0].published, 2)
+	assert.Len(t, targets[1].published, 2)
+}
+
+func TestPartitionClientExcludesUnavailableHosts(t *testing.T) {
+	targets, clients := makePartitionTargets(2)
+	targets[0].connectErr = errors.New("connection refused")
+	client := newPartitionClient("host.name", clients)
+	require.NoError(t, client.Connect(context.Background()))
+
+	batch := outest.NewBatch(hostEvents("x", "y", "z")...)
+	require.NoError(t, client.Publish(context.Background(), batch))
+
+	assert.Empty(t, targets[0].published)
+	assert.Len(t, targets[1].published, 3)
+
+	targets[1].connectErr = errors.New("connection refused")
+	assert.Error(t, client.Connect(context.Background()))
+}
+
+func TestPartitionClientRetriesFailedParts(t *testing.T) {
+	targets, clients := makePartitionTargets(2)
+	targets[1].retry = true
+	client := newPartitionClient("host.name", clients)
+	require.NoError(t, client.Connect(context.Background()))
+
+	batch := outest.NewBatch(beat.Event{}, beat.Event{}, beat.Event{}, beat.Event{})
+	require.NoError(t, client.Publish(context.Background(), batch))
+
+	require.Len(t, batch.Signals, 1)
+	assert.Equal(t, outest.BatchRetryEvents, batch.Signals[0].Tag)
+	assert.Len(t, batch.Signals[0].Events, 2)
+}
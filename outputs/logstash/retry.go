@@ -0,0 +1,80 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logstash
+
+import (
+	"github.com/njcx/libbeat_v8/outputs"
+	"github.com/njcx/libbeat_v8/publisher"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// sendAttemptsKey is the event cache key counting how often an event was sent
+// to Logstash. The events of a batch persist between retries, so the count
+// survives the batch being retried or split.
+const sendAttemptsKey = "send_attempts"
+
+// markSent increments the send attempts of events, and reports the events
+// that were sent before as retransmitted.
+func markSent(observer outputs.Observer, events []publisher.Event) {
+	retransmitted := 0
+	for i := range events {
+		attempts := sendAttempts(&events[i])
+		if attempts > 0 {
+			retransmitted++
+		}
+		_, _ = events[i].Cache.Put(sendAttemptsKey, attempts+1)
+	}
+	if retransmitted > 0 {
+		observer.RetransmittedEvents(retransmitted)
+	}
+}
+
+func sendAttempts(event *publisher.Event) int {
+	v, err := event.Cache.GetValue(sendAttemptsKey)
+	if err != nil {
+		return 0
+	}
+	n, _ := v.(int)
+	return n
+}
+
+// retryFailed returns the events Logstash did not ACK to the pipeline. Logstash
+// ACKs events in order, so these are the events following the last ACKed one,
+// and events ACKed before the failure are not sent again.
+//
+// If none of the batch's events were ACKed and the first failed event has been
+// sent at least splitAfter times, the batch is split instead, so an event
+// Logstash keeps failing on ends up isolated in a batch of its own.
+func retryFailed(
+	log *logp.Logger,
+	observer outputs.Observer,
+	batch publisher.Batch,
+	failed []publisher.Event,
+	progress bool,
+	splitAfter int,
+) {
+	if !progress && splitAfter > 0 && len(failed) > 1 && sendAttempts(&failed[0]) >= splitAfter {
+		if batch.SplitRetry() {
+			log.Warnf("Splitting batch of %v events after %v failed attempts to publish it",
+				len(failed), sendAttempts(&failed[0]))
+			observer.FailureSplit()
+			return
+		}
+	}
+	batch.RetryEvents(failed)
+}
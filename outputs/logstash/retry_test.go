@@ -0,0 +1,97 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logstash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/outputs"
+	"github.com/njcx/libbeat_v8/outputs/outest"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func TestMarkSent(t *testing.T) {
+	reg := monitoring.NewRegistry()
+	observer := outputs.NewStats(reg)
+
+	batch := outest.NewBatch(beat.Event{Fields: mapstr.M{"n": 1}}, beat.Event{Fields: mapstr.M{"n": 2}})
+	events := batch.Events()
+
+	markSent(observer, events)
+	assert.Equal(t, 1, sendAttempts(&events[0]))
+	assert.Equal(t, uint64(0), reg.Get("events.retransmitted").(*monitoring.Uint).Get())
+
+	// Only the second event is sent again, after the first one was ACKed.
+	markSent(observer, events[1:])
+	assert.Equal(t, 1, sendAttempts(&batch.Events()[0]))
+	assert.Equal(t, 2, sendAttempts(&batch.Events()[1]))
+	assert.Equal(t, uint64(1), reg.Get("events.retransmitted").(*monitoring.Uint).Get())
+}
+
+func TestRetryFailed(t *testing.T) {
+	log := logp.NewLogger("test")
+	observer := outputs.NewNilObserver()
+
+	newBatch := func(attempts int) *outest.Batch {
+		batch := outest.NewBatch(beat.Event{Fields: mapstr.M{"n": 1}}, beat.Event{Fields: mapstr.M{"n": 2}})
+		for i := 0; i < attempts; i++ {
+			markSent(observer, batch.Events())
+		}
+		return batch
+	}
+
+	t.Run("partial ACK retries the failed events", func(t *testing.T) {
+		batch := newBatch(3)
+		failed := batch.Events()[1:]
+		retryFailed(log, observer, batch, failed, true, 3)
+		require.Len(t, batch.Signals, 1)
+		assert.Equal(t, outest.BatchRetryEvents, batch.Signals[0].Tag)
+		assert.Equal(t, failed, batch.Signals[0].Events)
+	})
+
+	t.Run("failures below the threshold retry the batch", func(t *testing.T) {
+		batch := newBatch(2)
+		retryFailed(log, observer, batch, batch.Events(), false, 3)
+		require.Len(t, batch.Signals, 1)
+		assert.Equal(t, outest.BatchRetryEvents, batch.Signals[0].Tag)
+	})
+
+	t.Run("repeated failures split the batch", func(t *testing.T) {
+		reg := monitoring.NewRegistry()
+		batch := newBatch(3)
+		retryFailed(log, outputs.NewStats(reg), batch, batch.Events(), false, 3)
+		require.Len(t, batch.Signals, 1)
+		assert.Equal(t, outest.BatchSplitRetry, batch.Signals[0].Tag)
+		assert.Equal(t, uint64(1), reg.Get("batches.failure_split").(*monitoring.Uint).Get())
+		assert.Equal(t, uint64(0), reg.Get("batches.split").(*monitoring.Uint).Get(),
+			"Batches split after failures must not be reported as too large")
+	})
+
+	t.Run("splitting can be disabled", func(t *testing.T) {
+		batch := newBatch(3)
+		retryFailed(log, observer, batch, batch.Events(), false, 0)
+		require.Len(t, batch.Signals, 1)
+		assert.Equal(t, outest.BatchRetryEvents, batch.Signals[0].Tag)
+	})
+}
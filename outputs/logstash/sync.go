@@ -37,6 +37,8 @@ type syncClient struct {
 	win      *window
 	ttl      time.Duration
 	ticker   *time.Ticker
+
+	splitAfter int
 }
 
 func newSyncClient(
@@ -51,6 +53,8 @@ func newSyncClient(
 		Client:   conn,
 		observer: observer,
 		ttl:      config.TTL,
+
+		splitAfter: config.SplitAfter,
 	}
 
 	if config.SlowStart {
@@ -104,9 +108,10 @@ func (c *syncClient) reconnect() error {
 
 func (c *syncClient) Publish(_ context.Context, batch publisher.Batch) error {
 	events := batch.Events()
+	batchSize := len(events)
 	st := c.observer
 
-	st.NewBatch(len(events))
+	st.NewBatch(batchSize)
 
 	if len(events) == 0 {
 		batch.ACK()
@@ -152,7 +157,7 @@ func (c *syncClient) Publish(_ context.Context, batch publisher.Batch) error {
 		st.AckedEvents(n)
 		if err != nil {
 			// return batch to pipeline before reporting/counting error
-			batch.RetryEvents(events)
+			retryFailed(c.log, st, batch, events, len(events) < batchSize, c.splitAfter)
 
 			if c.win != nil {
 				c.win.shrinkWindow()
@@ -194,6 +199,7 @@ func (c *syncClient) publishWindowed(events []publisher.Event) (int, error) {
 }
 
 func (c *syncClient) sendEvents(events []publisher.Event) (int, error) {
+	markSent(c.observer, events)
 	window := make([]interface{}, len(events))
 	for i := range events {
 		window[i] = &events[i].Content
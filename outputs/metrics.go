@@ -22,6 +22,7 @@ import (
 
 	"github.com/rcrowley/go-metrics"
 
+	"github.com/njcx/libbeat_v8/publisher"
 	"github.com/elastic/elastic-agent-libs/monitoring"
 	"github.com/elastic/elastic-agent-libs/monitoring/adapter"
 )
@@ -63,11 +64,22 @@ type Stats struct {
 	// These events are also included in eventsFailed.
 	eventsTooMany *monitoring.Uint
 
+	// Number of events sent again after a failed attempt to publish them.
+	// Events are only counted by outputs that track send attempts.
+	eventsRetransmitted *monitoring.Uint
+
 	// Output batch stats
 
 	// Number of times a batch was split for being too large
 	batchesSplit *monitoring.Uint
 
+	// Number of batches sent in multiple requests to respect the output's
+	// request size limit
+	batchesBulkSplit *monitoring.Uint
+
+	// Number of times a batch was split after repeatedly failing to publish
+	batchesFailureSplit *monitoring.Uint
+
 	//
 	// Output network connection stats
 	//
@@ -77,7 +89,17 @@ type Stats struct {
 	readBytes  *monitoring.Uint // total amount of bytes read
 	readErrors *monitoring.Uint // total number of errors while waiting for response on output
 
+	flushTotal *monitoring.Uint // total number of flushes to stable storage
+	flushLast  *monitoring.Int  // time of the last flush, in milliseconds since the epoch
+
 	sendLatencyMillis metrics.Sample
+
+	// Total time in milliseconds spent in backoff before retrying to connect
+	// or publish.
+	backoffMillis *monitoring.Uint
+
+	// Pipeline-wide drop counters, see SetDropCounters. Optional.
+	drops *publisher.DropCounters
 }
 
 // NewStats creates a new Stats instance using a backing monitoring registry.
@@ -95,7 +117,12 @@ func NewStats(reg *monitoring.Registry) *Stats {
 		eventsActive:     monitoring.NewUint(reg, "events.active"),
 		eventsTooMany:    monitoring.NewUint(reg, "events.toomany"),
 
-		batchesSplit: monitoring.NewUint(reg, "batches.split"),
+		eventsRetransmitted: monitoring.NewUint(reg, "events.retransmitted"),
+
+		batchesSplit:     monitoring.NewUint(reg, "batches.split"),
+		batchesBulkSplit: monitoring.NewUint(reg, "batches.bulk_split"),
+
+		batchesFailureSplit: monitoring.NewUint(reg, "batches.failure_split"),
 
 		writeBytes:  monitoring.NewUint(reg, "write.bytes"),
 		writeErrors: monitoring.NewUint(reg, "write.errors"),
@@ -103,12 +130,25 @@ func NewStats(reg *monitoring.Registry) *Stats {
 		readBytes:  monitoring.NewUint(reg, "read.bytes"),
 		readErrors: monitoring.NewUint(reg, "read.errors"),
 
+		flushTotal: monitoring.NewUint(reg, "flush.total"),
+		flushLast:  monitoring.NewInt(reg, "flush.last_ms"),
+
 		sendLatencyMillis: metrics.NewUniformSample(1024),
+
+		backoffMillis: monitoring.NewUint(reg, "backoff.time.ms"),
 	}
 	_ = adapter.NewGoMetrics(reg, "write.latency", adapter.Accept).Register("histogram", metrics.NewHistogram(obj.sendLatencyMillis))
 	return obj
 }
 
+// SetDropCounters makes the Stats report events dropped by the output into
+// the pipeline-wide drop counters, in addition to the output metrics.
+func (s *Stats) SetDropCounters(drops *publisher.DropCounters) {
+	if s != nil {
+		s.drops = drops
+	}
+}
+
 // NewBatch updates active batch and event metrics.
 func (s *Stats) NewBatch(n int) {
 	if s != nil {
@@ -122,6 +162,13 @@ func (s *Stats) ReportLatency(time time.Duration) {
 	s.sendLatencyMillis.Update(time.Milliseconds())
 }
 
+// BackoffTime updates the total backoff time metric.
+func (s *Stats) BackoffTime(d time.Duration) {
+	if s != nil {
+		s.backoffMillis.Add(uint64(d.Milliseconds()))
+	}
+}
+
 // AckedEvents updates active and acked event metrics.
 func (s *Stats) AckedEvents(n int) {
 	if s != nil {
@@ -165,12 +212,38 @@ func (s *Stats) PermanentErrors(n int) {
 	}
 }
 
+// EncodeErrors updates the event drop metrics for events the output could
+// not encode. They are counted as permanent errors, and reported to the
+// pipeline drop counters as encode_error.
+func (s *Stats) EncodeErrors(n int) {
+	if s != nil {
+		s.PermanentErrors(n)
+		s.drops.Add(publisher.DropEncodeError, n)
+	}
+}
+
 func (s *Stats) BatchSplit() {
 	if s != nil {
 		s.batchesSplit.Inc()
 	}
 }
 
+// BulkSplit updates the number of batches that were sent in multiple
+// requests because they exceeded the output's request size limit.
+func (s *Stats) BulkSplit() {
+	if s != nil {
+		s.batchesBulkSplit.Inc()
+	}
+}
+
+// FailureSplit updates the number of batches that were split because
+// publishing them failed repeatedly.
+func (s *Stats) FailureSplit() {
+	if s != nil {
+		s.batchesFailureSplit.Inc()
+	}
+}
+
 // ErrTooMany updates the number of Too Many Requests responses reported by the output.
 func (s *Stats) ErrTooMany(n int) {
 	if s != nil {
@@ -178,6 +251,22 @@ func (s *Stats) ErrTooMany(n int) {
 	}
 }
 
+// RetransmittedEvents updates the number of events sent again after a failed
+// attempt.
+func (s *Stats) RetransmittedEvents(n int) {
+	if s != nil {
+		s.eventsRetransmitted.Add(uint64(n))
+	}
+}
+
+// Flushed updates the flush metrics after written data was flushed at t.
+func (s *Stats) Flushed(t time.Time) {
+	if s != nil {
+		s.flushTotal.Inc()
+		s.flushLast.Set(t.UnixMilli())
+	}
+}
+
 // WriteError increases the write I/O error metrics.
 func (s *Stats) WriteError(err error) {
 	if s != nil {
@@ -0,0 +1,43 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package outputs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/njcx/libbeat_v8/publisher"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func TestStatsEncodeErrors(t *testing.T) {
+	reg := monitoring.NewRegistry()
+	stats := NewStats(reg)
+	drops := publisher.NewDropCounters(monitoring.NewRegistry())
+	stats.SetDropCounters(drops)
+	stats.NewBatch(3)
+
+	stats.EncodeErrors(2)
+	stats.PermanentErrors(1)
+
+	assert.Equal(t, uint64(3), reg.Get("events.dropped").(*monitoring.Uint).Get(),
+		"Encode errors should be counted as permanent errors")
+	assert.Equal(t, uint64(2), drops.Get(publisher.DropEncodeError))
+	assert.Equal(t, uint64(2), drops.Total(), "Only encode errors should be reported as drops")
+}
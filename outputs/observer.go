@@ -26,19 +26,28 @@ type Observer interface {
 
 	RetryableErrors(int)  // report number of events with retryable errors
 	PermanentErrors(int)  // report number of events dropped due to permanent errors
+	EncodeErrors(int)     // report number of events dropped because they couldn't be encoded
 	DuplicateEvents(int)  // report number of events detected as duplicates (e.g. on resends)
 	DeadLetterEvents(int) // report number of failed events ingested to dead letter index
 	AckedEvents(int)      // report number of acked events
 	ErrTooMany(int)       // report too many requests response
 
+	RetransmittedEvents(int) // report number of events sent again after a failed attempt
+
 	BatchSplit() // report a batch was split for being too large to ingest
+	BulkSplit()  // report a batch was sent in multiple requests to respect a size limit
+
+	FailureSplit() // report a batch was split after repeatedly failing to publish
 
 	WriteError(error) // report an I/O error on write
 	WriteBytes(int)   // report number of bytes being written
 	ReadError(error)  // report an I/O error on read
 	ReadBytes(int)    // report number of bytes being read
 
+	Flushed(time.Time) // report written data was flushed to storage at the given time
+
 	ReportLatency(time.Duration) // report the duration a send to the output takes
+	BackoffTime(time.Duration)   // report the duration spent in backoff before a retry
 }
 
 type emptyObserver struct{}
@@ -52,14 +61,20 @@ func NewNilObserver() Observer {
 
 func (*emptyObserver) NewBatch(int)                  {}
 func (*emptyObserver) ReportLatency(_ time.Duration) {}
+func (*emptyObserver) BackoffTime(_ time.Duration)   {}
 func (*emptyObserver) AckedEvents(int)               {}
 func (*emptyObserver) DeadLetterEvents(int)          {}
 func (*emptyObserver) DuplicateEvents(int)           {}
 func (*emptyObserver) RetryableErrors(int)           {}
 func (*emptyObserver) PermanentErrors(int)           {}
+func (*emptyObserver) EncodeErrors(int)              {}
 func (*emptyObserver) BatchSplit()                   {}
+func (*emptyObserver) BulkSplit()                    {}
+func (*emptyObserver) FailureSplit()                 {}
 func (*emptyObserver) WriteError(error)              {}
 func (*emptyObserver) WriteBytes(int)                {}
 func (*emptyObserver) ReadError(error)               {}
 func (*emptyObserver) ReadBytes(int)                 {}
 func (*emptyObserver) ErrTooMany(int)                {}
+func (*emptyObserver) RetransmittedEvents(int)       {}
+func (*emptyObserver) Flushed(time.Time)             {}
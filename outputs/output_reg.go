@@ -64,7 +64,9 @@ type Group struct {
 	// output-serialized form before entering the queue) it should provide an
 	// encoder factory here. Events will be processed using the resulting encoders
 	// before being returned from the queue. This can provide significant cpu and
-	// memory savings for outputs that support it.
+	// memory savings for outputs that support it. Early encoding is opt-in:
+	// outputs that leave this nil receive the unencoded events. See
+	// queue.EncoderFactory for the tradeoffs.
 	// - Each encoder will be accessed from only one goroutine at a time.
 	// - Encoders should add the event's output-serialized form, along with any
 	//   metadata needed to handle a Publish call, to the EncodedEvent field of
@@ -106,5 +108,9 @@ func Load(
 	if stats == nil {
 		stats = NewNilObserver()
 	}
-	return factory(im, info, stats, config)
+	group, err := factory(im, info, stats, config)
+	if err != nil {
+		return group, err
+	}
+	return withDeadLetter(im, info, config, group)
 }
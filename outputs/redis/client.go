@@ -229,7 +229,7 @@ func (c *client) publishEventsBulk(conn redis.Conn, command string) publishFn {
 		args[0] = dest
 
 		okEvents, args := serializeEvents(c.log, args, 1, data, c.index, c.codec)
-		c.observer.PermanentErrors(len(data) - len(okEvents))
+		c.observer.EncodeErrors(len(data) - len(okEvents))
 		if (len(args) - 1) == 0 {
 			return nil, nil
 		}
@@ -255,7 +255,7 @@ func (c *client) publishEventsPipeline(conn redis.Conn, command string) publishF
 		var okEvents []publisher.Event
 		serialized := make([]interface{}, 0, len(data))
 		okEvents, serialized = serializeEvents(c.log, serialized, 0, data, c.index, c.codec)
-		c.observer.PermanentErrors(len(data) - len(okEvents))
+		c.observer.EncodeErrors(len(data) - len(okEvents))
 		if len(serialized) == 0 {
 			return nil, nil
 		}
@@ -102,3 +102,20 @@ func TestDiskQueueUnderAgent(t *testing.T) {
 		})
 	}
 }
+
+func TestSuccessEncoderFactory(t *testing.T) {
+	group, err := Success(config.Namespace{}, 10, 3, nil)
+	require.NoError(t, err)
+	assert.Nil(t, group.EncoderFactory, "outputs without an encoder should not enable early encoding")
+
+	called := false
+	factory := func() queue.Encoder {
+		called = true
+		return nil
+	}
+	group, err = SuccessNet(config.Namespace{}, true, 10, 3, factory, nil)
+	require.NoError(t, err)
+	require.NotNil(t, group.EncoderFactory, "the output's encoder factory should be passed to the pipeline")
+	group.EncoderFactory()
+	assert.True(t, called)
+}
@@ -18,12 +18,19 @@
 package actions
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+
 	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/common/cfgtype"
 	"github.com/njcx/libbeat_v8/processors"
 	"github.com/njcx/libbeat_v8/processors/checks"
 	jsprocessor "github.com/njcx/libbeat_v8/processors/script/javascript/module/processor"
@@ -36,22 +43,44 @@ const (
 	processorName = "decode_base64_field"
 )
 
+// defaultBase64MaxBytes limits the size of decompressed values, unless
+// max_bytes is configured.
+const defaultBase64MaxBytes = 10 * 1024 * 1024
+
 type decodeBase64Field struct {
-	config base64Config
-	log    *logp.Logger
+	config   base64Config
+	charset  encoding.Encoding
+	maxBytes int64
+	log      *logp.Logger
 }
 
 type base64Config struct {
-	Field         fromTo `config:"field"`
-	IgnoreMissing bool   `config:"ignore_missing"`
-	FailOnError   bool   `config:"fail_on_error"`
+	Field         fromTo            `config:"field"`
+	IgnoreMissing bool              `config:"ignore_missing"`
+	FailOnError   bool              `config:"fail_on_error"`
+	Decompress    string            `config:"decompress"`
+	Charset       string            `config:"charset"`
+	MaxBytes      *cfgtype.ByteSize `config:"max_bytes"`
+}
+
+func (c *base64Config) Validate() error {
+	switch c.Decompress {
+	case "", "gzip":
+	default:
+		return fmt.Errorf("unsupported decompress value %q, only gzip is supported", c.Decompress)
+	}
+	if c.MaxBytes != nil && *c.MaxBytes < 0 {
+		return errors.New("max_bytes must not be negative")
+	}
+	return nil
 }
 
 func init() {
 	processors.RegisterPlugin(processorName,
 		checks.ConfigChecked(NewDecodeBase64Field,
 			checks.RequireFields("field"),
-			checks.AllowedFields("field", "when", "ignore_missing", "fail_on_error")))
+			checks.AllowedFields("field", "when", "ignore_missing", "fail_on_error",
+				"decompress", "charset", "max_bytes")))
 	jsprocessor.RegisterPlugin("DecodeBase64Field", NewDecodeBase64Field)
 }
 
@@ -67,9 +96,29 @@ func NewDecodeBase64Field(c *cfg.C) (beat.Processor, error) {
 		return nil, fmt.Errorf("fail to unpack the %s configuration: %w", processorName, err)
 	}
 
+	var charset encoding.Encoding
+	if config.Charset != "" {
+		charset, err = htmlindex.Get(config.Charset)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported charset %q in %s configuration: %w", config.Charset, processorName, err)
+		}
+	}
+
+	// Without decompression the decoded value is smaller than the encoded
+	// one, so it is only limited if max_bytes is configured.
+	var maxBytes int64
+	switch {
+	case config.MaxBytes != nil:
+		maxBytes = int64(*config.MaxBytes)
+	case config.Decompress != "":
+		maxBytes = defaultBase64MaxBytes
+	}
+
 	return &decodeBase64Field{
-		config: config,
-		log:    logp.NewLogger(processorName),
+		config:   config,
+		charset:  charset,
+		maxBytes: maxBytes,
+		log:      logp.NewLogger(processorName),
 	}, nil
 }
 
@@ -117,6 +166,23 @@ func (f *decodeBase64Field) decodeField(event *beat.Event) error {
 		return fmt.Errorf("error trying to decode %s: %w", base64String, err)
 	}
 
+	maxBytes := f.maxBytes
+	if f.config.Decompress == "gzip" {
+		decodedData, err = gunzip(decodedData, maxBytes)
+		if err != nil {
+			return err
+		}
+	} else if maxBytes > 0 && int64(len(decodedData)) > maxBytes {
+		return fmt.Errorf("decoded value of %d bytes exceeds max_bytes of %d", len(decodedData), maxBytes)
+	}
+
+	if f.charset != nil {
+		decodedData, err = f.charset.NewDecoder().Bytes(decodedData)
+		if err != nil {
+			return fmt.Errorf("error decoding value as %s: %w", f.config.Charset, err)
+		}
+	}
+
 	target := f.config.Field.To
 	// If to is empty
 	if f.config.Field.To == "" || f.config.Field.From == f.config.Field.To {
@@ -129,3 +195,29 @@ func (f *decodeBase64Field) decodeField(event *beat.Event) error {
 
 	return nil
 }
+
+// gunzip decompresses data, failing if the decompressed data is larger than
+// maxBytes. A maxBytes of 0 disables the limit.
+func gunzip(data []byte, maxBytes int64) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing value: %w", err)
+	}
+	defer r.Close()
+
+	var src io.Reader = r
+	if maxBytes > 0 {
+		// Read one byte more than allowed to detect oversized data.
+		src = io.LimitReader(r, maxBytes+1)
+	}
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, src)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing value: %w", err)
+	}
+	if maxBytes > 0 && n > maxBytes {
+		return nil, fmt.Errorf("decompressed value exceeds max_bytes of %d", maxBytes)
+	}
+	return buf.Bytes(), nil
+}
@@ -18,11 +18,17 @@
 package actions
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/njcx/libbeat_v8/beat"
+	conf "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
@@ -255,3 +261,102 @@ func TestDecodeBase64Run(t *testing.T) {
 		assert.Equal(t, expectedMeta, newEvent.Meta)
 	})
 }
+
+func TestDecodeBase64Options(t *testing.T) {
+	gzipped := func(s string) string {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write([]byte(s))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return base64.StdEncoding.EncodeToString(buf.Bytes())
+	}
+
+	testCases := []struct {
+		description string
+		config      mapstr.M
+		input       string
+		output      string
+		error       bool
+	}{
+		{
+			description: "gzip decompression",
+			config:      mapstr.M{"decompress": "gzip"},
+			input:       gzipped("compressed data"),
+			output:      "compressed data",
+		},
+		{
+			description: "gzip decompression of invalid data",
+			config:      mapstr.M{"decompress": "gzip"},
+			input:       "Y29ycmVjdCBkYXRh",
+			error:       true,
+		},
+		{
+			description: "decompressed data exceeding max_bytes",
+			config:      mapstr.M{"decompress": "gzip", "max_bytes": "1KiB"},
+			input:       gzipped(strings.Repeat("a", 2048)),
+			error:       true,
+		},
+		{
+			description: "decoded data exceeding max_bytes",
+			config:      mapstr.M{"max_bytes": "4"},
+			input:       "Y29ycmVjdCBkYXRh",
+			error:       true,
+		},
+		{
+			description: "charset",
+			config:      mapstr.M{"charset": "iso-8859-1"},
+			input:       base64.StdEncoding.EncodeToString([]byte{'c', 'a', 'f', 0xe9}),
+			output:      "café",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			config := mapstr.M{"field": mapstr.M{"from": "field1", "to": "field2"}}
+			config.DeepUpdate(test.config)
+			p, err := NewDecodeBase64Field(conf.MustNewConfigFrom(config))
+			require.NoError(t, err)
+
+			event, err := p.Run(&beat.Event{Fields: mapstr.M{"field1": test.input}})
+			if test.error {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			decoded, err := event.GetValue("field2")
+			require.NoError(t, err)
+			assert.Equal(t, test.output, decoded)
+		})
+	}
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := NewDecodeBase64Field(conf.MustNewConfigFrom(mapstr.M{
+			"field": mapstr.M{"from": "field1"}, "decompress": "zstd",
+		}))
+		assert.Error(t, err)
+
+		_, err = NewDecodeBase64Field(conf.MustNewConfigFrom(mapstr.M{
+			"field": mapstr.M{"from": "field1"}, "charset": "no-such-charset",
+		}))
+		assert.Error(t, err)
+	})
+
+	t.Run("default max_bytes", func(t *testing.T) {
+		for _, test := range []struct {
+			config   mapstr.M
+			maxBytes int64
+		}{
+			{config: mapstr.M{}, maxBytes: 0},
+			{config: mapstr.M{"decompress": "gzip"}, maxBytes: defaultBase64MaxBytes},
+			{config: mapstr.M{"max_bytes": "1KiB"}, maxBytes: 1024},
+			{config: mapstr.M{"decompress": "gzip", "max_bytes": "0"}, maxBytes: 0},
+		} {
+			config := mapstr.M{"field": mapstr.M{"from": "field1"}}
+			config.DeepUpdate(test.config)
+			p, err := NewDecodeBase64Field(conf.MustNewConfigFrom(config))
+			require.NoError(t, err)
+			assert.Equal(t, test.maxBytes, p.(*decodeBase64Field).maxBytes, test.config)
+		}
+	})
+}
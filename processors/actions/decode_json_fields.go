@@ -41,27 +41,34 @@ type decodeJSONFields struct {
 	expandKeys    bool
 	overwriteKeys bool
 	addErrorKey   bool
+	errorTarget   string
+	keepNull      bool
 	processArray  bool
+	dropDupFields bool
 	documentID    string
 	target        *string
 	logger        *logp.Logger
 }
 
 type config struct {
-	Fields        []string `config:"fields"`
-	MaxDepth      int      `config:"max_depth" validate:"min=1"`
-	ExpandKeys    bool     `config:"expand_keys"`
-	OverwriteKeys bool     `config:"overwrite_keys"`
-	AddErrorKey   bool     `config:"add_error_key"`
-	ProcessArray  bool     `config:"process_array"`
-	Target        *string  `config:"target"`
-	DocumentID    string   `config:"document_id"`
+	Fields              []string `config:"fields"`
+	MaxDepth            int      `config:"max_depth" validate:"min=1"`
+	ExpandKeys          bool     `config:"expand_keys"`
+	OverwriteKeys       bool     `config:"overwrite_keys"`
+	AddErrorKey         bool     `config:"add_error_key"`
+	ErrorTarget         string   `config:"error_target"`
+	KeepNull            bool     `config:"keep_null"`
+	ProcessArray        bool     `config:"process_array"`
+	DropDuplicateFields bool     `config:"drop_duplicate_fields"`
+	Target              *string  `config:"target"`
+	DocumentID          string   `config:"document_id"`
 }
 
 var (
 	defaultConfig = config{
 		MaxDepth:     1,
 		ProcessArray: false,
+		KeepNull:     true,
 	}
 	errProcessingSkipped = errors.New("processing skipped")
 )
@@ -70,7 +77,7 @@ func init() {
 	processors.RegisterPlugin("decode_json_fields",
 		checks.ConfigChecked(NewDecodeJSONFields,
 			checks.RequireFields("fields"),
-			checks.AllowedFields("fields", "max_depth", "overwrite_keys", "add_error_key", "process_array", "target", "when", "document_id", "expand_keys")))
+			checks.AllowedFields("fields", "max_depth", "overwrite_keys", "add_error_key", "error_target", "keep_null", "process_array", "target", "when", "document_id", "expand_keys", "drop_duplicate_fields")))
 
 	jsprocessor.RegisterPlugin("DecodeJSONFields", NewDecodeJSONFields)
 }
@@ -92,7 +99,10 @@ func NewDecodeJSONFields(c *cfg.C) (beat.Processor, error) {
 		expandKeys:    config.ExpandKeys,
 		overwriteKeys: config.OverwriteKeys,
 		addErrorKey:   config.AddErrorKey,
+		errorTarget:   config.ErrorTarget,
+		keepNull:      config.KeepNull,
 		processArray:  config.ProcessArray,
+		dropDupFields: config.DropDuplicateFields,
 		documentID:    config.DocumentID,
 		target:        config.Target,
 		logger:        logger,
@@ -122,7 +132,7 @@ func (f *decodeJSONFields) Run(event *beat.Event) (*beat.Event, error) {
 		if err != nil {
 			f.logger.Debugf("Error trying to unmarshal %s", text)
 			errs = append(errs, err.Error())
-			event.SetErrorWithOption(fmt.Sprintf("parsing input as JSON: %s", err.Error()), f.addErrorKey, text, field)
+			jsontransform.SetError(event, f.errorOptions(), fmt.Sprintf("parsing input as JSON: %s", err.Error()), text, field)
 			continue
 		}
 
@@ -144,10 +154,13 @@ func (f *decodeJSONFields) Run(event *beat.Event) (*beat.Event, error) {
 		}
 
 		if target != "" {
+			if !f.keepNull {
+				jsontransform.RemoveNulls(output)
+			}
 			if f.expandKeys {
 				switch t := output.(type) {
 				case map[string]interface{}:
-					jsontransform.ExpandFields(f.logger, event, t, f.addErrorKey)
+					jsontransform.ExpandFieldsWithOptions(f.logger, event, t, f.errorOptions())
 				default:
 					errs = append(errs, "failed to expand keys")
 				}
@@ -156,7 +169,11 @@ func (f *decodeJSONFields) Run(event *beat.Event) (*beat.Event, error) {
 		} else {
 			switch t := output.(type) {
 			case map[string]interface{}:
-				jsontransform.WriteJSONKeys(event, t, f.expandKeys, f.overwriteKeys, f.addErrorKey)
+				opts := f.errorOptions()
+				opts.ExpandKeys = f.expandKeys
+				opts.OverwriteKeys = f.overwriteKeys
+				opts.DropNull = !f.keepNull
+				jsontransform.WriteJSONKeysWithOptions(event, t, opts)
 			default:
 				errs = append(errs, "failed to add target to root")
 			}
@@ -168,6 +185,18 @@ func (f *decodeJSONFields) Run(event *beat.Event) (*beat.Event, error) {
 			continue
 		}
 
+		if f.dropDupFields {
+			dups, err := jsontransform.FindDuplicateKeys([]byte(text))
+			if err != nil {
+				f.logger.Debugf("Error trying to find duplicate keys in %s", text)
+			}
+			// Duplicates are always recorded, enabling the option is the
+			// request to report them whether add_error_key is set or not.
+			opts := f.errorOptions()
+			opts.AddErrorKey = true
+			jsontransform.WriteDuplicateKeyErrorsWithOptions(event, dups, opts)
+		}
+
 		if id != "" {
 			if event.Meta == nil {
 				event.Meta = mapstr.M{}
@@ -182,6 +211,15 @@ func (f *decodeJSONFields) Run(event *beat.Event) (*beat.Event, error) {
 	return event, nil
 }
 
+// errorOptions returns the options controlling how errors are written to
+// the event.
+func (f *decodeJSONFields) errorOptions() jsontransform.Options {
+	return jsontransform.Options{
+		AddErrorKey: f.addErrorKey,
+		ErrorTarget: f.errorTarget,
+	}
+}
+
 func unmarshal(maxDepth int, text string, fields *interface{}, processArray bool) error {
 	if err := decodeJSON(text, fields); err != nil {
 		return err
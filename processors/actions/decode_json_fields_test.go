@@ -465,6 +465,91 @@ func TestAddErrKeyOption(t *testing.T) {
 	}
 }
 
+func TestErrorTarget(t *testing.T) {
+	testConfig := conf.MustNewConfigFrom(map[string]interface{}{
+		"fields":         fields,
+		"add_error_key":  true,
+		"error_target":   "json.error",
+		"overwrite_keys": true,
+		"target":         "",
+	})
+
+	t.Run("decoding error", func(t *testing.T) {
+		input := mapstr.M{
+			"msg":   `{"log": "broken"`,
+			"error": mapstr.M{"message": "application error"},
+		}
+		actual := getActualValue(t, testConfig, input)
+
+		assert.Equal(t, mapstr.M{"message": "application error"}, actual["error"])
+		message, err := actual.GetValue("json.error.message")
+		require.NoError(t, err)
+		assert.Contains(t, message, "parsing input as JSON")
+		field, _ := actual.GetValue("json.error.field")
+		assert.Equal(t, "msg", field)
+	})
+
+	t.Run("error while merging keys", func(t *testing.T) {
+		input := mapstr.M{"msg": `{"@timestamp": "{}", "error": {"code": 42}}`}
+		actual := getActualValue(t, testConfig, input)
+
+		expected := mapstr.M{
+			"msg":   `{"@timestamp": "{}", "error": {"code": 42}}`,
+			"error": map[string]interface{}{"code": float64(42)},
+			"json": mapstr.M{
+				"error": mapstr.M{"message": "@timestamp not overwritten (parse error on {})", "type": "json"},
+			},
+		}
+		assert.Equal(t, expected.String(), actual.String())
+	})
+}
+
+func TestKeepNull(t *testing.T) {
+	const msg = `{"a": null, "b": {"c": null, "d": 1}, "e": [null, {"f": null}]}`
+
+	tests := map[string]struct {
+		config   map[string]interface{}
+		expected mapstr.M
+	}{
+		"keep nulls by default": {
+			config: map[string]interface{}{"fields": fields, "target": "json"},
+			expected: mapstr.M{
+				"msg": msg,
+				"json": map[string]interface{}{
+					"a": nil,
+					"b": map[string]interface{}{"c": nil, "d": float64(1)},
+					"e": []interface{}{nil, map[string]interface{}{"f": nil}},
+				},
+			},
+		},
+		"drop nulls in target": {
+			config: map[string]interface{}{"fields": fields, "target": "json", "keep_null": false},
+			expected: mapstr.M{
+				"msg": msg,
+				"json": map[string]interface{}{
+					"b": map[string]interface{}{"d": float64(1)},
+					"e": []interface{}{nil, map[string]interface{}{}},
+				},
+			},
+		},
+		"drop nulls at root": {
+			config: map[string]interface{}{"fields": fields, "target": "", "keep_null": false},
+			expected: mapstr.M{
+				"msg": msg,
+				"b":   map[string]interface{}{"d": float64(1)},
+				"e":   []interface{}{nil, map[string]interface{}{}},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := getActualValue(t, conf.MustNewConfigFrom(test.config), mapstr.M{"msg": msg})
+			assert.Equal(t, test.expected.String(), actual.String())
+		})
+	}
+}
+
 func TestExpandKeys(t *testing.T) {
 	testConfig := conf.MustNewConfigFrom(map[string]interface{}{
 		"fields":      fields,
@@ -579,6 +664,31 @@ func TestAddErrorToEventOnUnmarshalError(t *testing.T) {
 	assert.NotNil(t, errObj["message"])
 }
 
+func TestDropDuplicateFields(t *testing.T) {
+	for _, addErrorKey := range []bool{true, false} {
+		t.Run(fmt.Sprintf("add_error_key set to %v", addErrorKey), func(t *testing.T) {
+			testConfig := conf.MustNewConfigFrom(map[string]interface{}{
+				"fields":                fields,
+				"drop_duplicate_fields": true,
+				"add_error_key":         addErrorKey,
+				"target":                "",
+			})
+			input := mapstr.M{"msg": `{"a": 1, "b": {"c": 2, "c": 3}, "a": 4}`}
+			expected := mapstr.M{
+				"msg": `{"a": 1, "b": {"c": 2, "c": 3}, "a": 4}`,
+				"a":   int64(4),
+				"b":   map[string]interface{}{"c": int64(3)},
+				"error": mapstr.M{
+					"message": "duplicate JSON keys found, only the last value was kept: b.c, a",
+					"type":    "json",
+				},
+			}
+			actual := getActualValue(t, testConfig, input)
+			assert.Equal(t, expected.String(), actual.String())
+		})
+	}
+}
+
 func getActualValue(t *testing.T, config *conf.C, input mapstr.M) mapstr.M {
 	log := logp.NewLogger("decode_json_fields_test")
 
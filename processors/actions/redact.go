@@ -0,0 +1,176 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package actions
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/processors"
+	"github.com/njcx/libbeat_v8/processors/checks"
+	jsprocessor "github.com/njcx/libbeat_v8/processors/script/javascript/module/processor"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type redact struct {
+	config redactConfig
+	log    *logp.Logger
+}
+
+type redactConfig struct {
+	Fields        []string         `config:"fields" validate:"required"`
+	Patterns      []*regexp.Regexp `config:"patterns" validate:"required"`
+	Replacement   string           `config:"replacement"`
+	Hash          bool             `config:"hash"`
+	HashKey       string           `config:"hash_key"`
+	IgnoreMissing bool             `config:"ignore_missing"`
+	FailOnError   bool             `config:"fail_on_error"`
+}
+
+func (c *redactConfig) Validate() error {
+	if c.Hash && c.HashKey == "" {
+		return errors.New("hash_key is required when hash is enabled")
+	}
+	return nil
+}
+
+func init() {
+	processors.RegisterPlugin("redact",
+		checks.ConfigChecked(NewRedact,
+			checks.RequireFields("fields", "patterns"),
+			checks.AllowedFields("fields", "patterns", "replacement", "hash", "hash_key",
+				"ignore_missing", "fail_on_error", "when")))
+
+	jsprocessor.RegisterPlugin("Redact", NewRedact)
+}
+
+// NewRedact returns a new redact processor.
+func NewRedact(c *conf.C) (beat.Processor, error) {
+	config := redactConfig{
+		Replacement:   "[REDACTED]",
+		IgnoreMissing: false,
+		FailOnError:   true,
+	}
+	err := c.Unpack(&config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack the redact configuration: %w", err)
+	}
+
+	f := &redact{
+		config: config,
+		log:    logp.NewLogger("redact"),
+	}
+	return f, nil
+}
+
+func (f *redact) Run(event *beat.Event) (*beat.Event, error) {
+	var backup *beat.Event
+	// Creates a copy of the event to revert in case of failure
+	if f.config.FailOnError {
+		backup = event.Clone()
+	}
+
+	for _, field := range f.config.Fields {
+		err := f.redactField(field, event)
+		if err != nil {
+			errMsg := fmt.Errorf("Failed to redact fields in processor: %w", err)
+			f.log.Debugw(errMsg.Error(), logp.TypeKey, logp.EventType)
+
+			if f.config.FailOnError {
+				event = backup
+				_, _ = event.PutValue("error.message", errMsg.Error())
+				return event, err
+			}
+		}
+	}
+
+	return event, nil
+}
+
+func (f *redact) redactField(field string, event *beat.Event) error {
+	value, err := event.GetValue(field)
+	if err != nil {
+		// Ignore ErrKeyNotFound errors
+		if f.config.IgnoreMissing && errors.Is(err, mapstr.ErrKeyNotFound) {
+			return nil
+		}
+		return fmt.Errorf("could not fetch value for key: %s, Error: %w", field, err)
+	}
+
+	var redacted interface{}
+	switch v := value.(type) {
+	case string:
+		redacted = f.redactString(v)
+	case []string:
+		out := make([]string, len(v))
+		for i, s := range v {
+			out[i] = f.redactString(s)
+		}
+		redacted = out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			s, ok := elem.(string)
+			if !ok {
+				return fmt.Errorf("value of key %s at index %d is not a string: %T", field, i, elem)
+			}
+			out[i] = f.redactString(s)
+		}
+		redacted = out
+	default:
+		return fmt.Errorf("value of key %s is not a string or an array of strings: %T", field, value)
+	}
+
+	if _, err = event.PutValue(field, redacted); err != nil {
+		return fmt.Errorf("could not put value for key: %s, Error: %w", field, err)
+	}
+	return nil
+}
+
+func (f *redact) redactString(s string) string {
+	for _, pattern := range f.config.Patterns {
+		if f.config.Hash {
+			s = pattern.ReplaceAllStringFunc(s, f.hashMatch)
+		} else {
+			s = pattern.ReplaceAllLiteralString(s, f.config.Replacement)
+		}
+	}
+	return s
+}
+
+// hashMatch replaces a matched substring with the hex encoded HMAC-SHA256 of
+// it, so that redacted values can still be correlated across events. The
+// configured key prevents recovering low entropy values, like card numbers,
+// by hashing all candidates.
+func (f *redact) hashMatch(match string) string {
+	mac := hmac.New(sha256.New, []byte(f.config.HashKey))
+	mac.Write([]byte(match))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (f *redact) String() string {
+	return fmt.Sprintf("redact=[fields=%v, patterns=%v, hash=%v]",
+		f.config.Fields, f.config.Patterns, f.config.Hash)
+}
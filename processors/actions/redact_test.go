@@ -0,0 +1,176 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package actions
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/njcx/libbeat_v8/beat"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestRedactRun(t *testing.T) {
+	cardNumber := regexp.MustCompile(`\b\d{16}\b`)
+	token := regexp.MustCompile(`token=\S+`)
+
+	var tests = []struct {
+		description   string
+		Fields        []string
+		Patterns      []*regexp.Regexp
+		Hash          bool
+		HashKey       string
+		IgnoreMissing bool
+		FailOnError   bool
+		Input         mapstr.M
+		Output        mapstr.M
+		error         bool
+	}{
+		{
+			description: "replace matches",
+			Fields:      []string{"message"},
+			Patterns:    []*regexp.Regexp{cardNumber, token},
+			Input: mapstr.M{
+				"message": "card 4111111111111111 used with token=abc123",
+			},
+			Output: mapstr.M{
+				"message": "card [REDACTED] used with [REDACTED]",
+			},
+			FailOnError: true,
+		},
+		{
+			description: "hash matches",
+			Fields:      []string{"message"},
+			Patterns:    []*regexp.Regexp{cardNumber},
+			Hash:        true,
+			HashKey:     "test-key",
+			Input: mapstr.M{
+				"message": "card 4111111111111111",
+			},
+			Output: mapstr.M{
+				"message": "card b9a296a413d12fc678c4027233b1926dccb9bc80f18f07061a9f429d8d8a09e7",
+			},
+			FailOnError: true,
+		},
+		{
+			description: "array of strings",
+			Fields:      []string{"a", "b"},
+			Patterns:    []*regexp.Regexp{token},
+			Input: mapstr.M{
+				"a": []string{"token=x", "none"},
+				"b": []interface{}{"token=y"},
+			},
+			Output: mapstr.M{
+				"a": []string{"[REDACTED]", "none"},
+				"b": []interface{}{"[REDACTED]"},
+			},
+			FailOnError: true,
+		},
+		{
+			description: "ignore missing",
+			Fields:      []string{"missing", "message"},
+			Patterns:    []*regexp.Regexp{token},
+			Input: mapstr.M{
+				"message": "token=x",
+			},
+			Output: mapstr.M{
+				"message": "[REDACTED]",
+			},
+			IgnoreMissing: true,
+			FailOnError:   true,
+		},
+		{
+			description: "missing field reverts the event",
+			Fields:      []string{"message", "missing"},
+			Patterns:    []*regexp.Regexp{token},
+			Input: mapstr.M{
+				"message": "token=x",
+			},
+			Output: mapstr.M{
+				"message": "token=x",
+				"error": mapstr.M{
+					"message": "Failed to redact fields in processor: could not fetch value for key: missing, Error: key not found",
+				},
+			},
+			FailOnError: true,
+			error:       true,
+		},
+		{
+			description: "non string value without fail_on_error",
+			Fields:      []string{"n", "message"},
+			Patterns:    []*regexp.Regexp{token},
+			Input: mapstr.M{
+				"n":       1,
+				"message": "token=x",
+			},
+			Output: mapstr.M{
+				"n":       1,
+				"message": "[REDACTED]",
+			},
+			FailOnError: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			f := &redact{
+				log: logp.NewLogger("redact"),
+				config: redactConfig{
+					Fields:        test.Fields,
+					Patterns:      test.Patterns,
+					Replacement:   "[REDACTED]",
+					Hash:          test.Hash,
+					HashKey:       test.HashKey,
+					IgnoreMissing: test.IgnoreMissing,
+					FailOnError:   test.FailOnError,
+				},
+			}
+			event := &beat.Event{
+				Fields: test.Input,
+			}
+
+			newEvent, err := f.Run(event)
+			if !test.error {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			assert.Equal(t, test.Output, newEvent.Fields)
+		})
+	}
+}
+
+func TestRedactHashRequiresKey(t *testing.T) {
+	settings := map[string]interface{}{
+		"fields":   []string{"message"},
+		"patterns": []string{`\d+`},
+		"hash":     true,
+	}
+	_, err := NewRedact(conf.MustNewConfigFrom(settings))
+	assert.ErrorContains(t, err, "hash_key")
+
+	settings["hash_key"] = "test-key"
+	_, err = NewRedact(conf.MustNewConfigFrom(settings))
+	assert.NoError(t, err)
+}
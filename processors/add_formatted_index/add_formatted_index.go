@@ -36,14 +36,19 @@ func init() {
 // with a given TimestampFormatString. The elasticsearch output interprets
 // that field as specifying the (raw string) index the event should be sent to;
 // in other outputs it is just included in the metadata.
+//
+// If a default index is configured, it is used whenever the format string
+// cannot be expanded (for example because a referenced field is missing).
 type AddFormattedIndex struct {
 	formatString *fmtstr.TimestampFormatString
 	fullEvent    bool
+	defaultIndex string
+	tagOnFailure []string
 }
 
 // New returns a new AddFormattedIndex processor.
 func New(formatString *fmtstr.TimestampFormatString) *AddFormattedIndex {
-	return &AddFormattedIndex{formatString, false}
+	return &AddFormattedIndex{formatString: formatString}
 }
 
 // NewC constructs a new AddFormattedIndex processor from configuration
@@ -53,7 +58,12 @@ func NewC(cfg *conf.C) (beat.Processor, error) {
 		return nil, err
 	}
 
-	return &AddFormattedIndex{c.Index, true}, nil
+	return &AddFormattedIndex{
+		formatString: c.Index,
+		fullEvent:    true,
+		defaultIndex: c.Default,
+		tagOnFailure: c.TagOnFailure,
+	}, nil
 }
 
 // Run runs the processor.
@@ -66,7 +76,13 @@ func (p *AddFormattedIndex) Run(event *beat.Event) (*beat.Event, error) {
 		index, err = p.formatString.Run(event.Timestamp)
 	}
 	if err != nil {
-		return nil, err
+		if p.defaultIndex == "" {
+			return nil, err
+		}
+		index = p.defaultIndex
+		if len(p.tagOnFailure) > 0 {
+			_ = mapstr.AddTags(event.Fields, p.tagOnFailure)
+		}
 	}
 
 	if event.Meta == nil {
@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package add_formatted_index
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/beat/events"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestAddFormattedIndex(t *testing.T) {
+	ts := time.Date(2022, 11, 18, 0, 0, 0, 0, time.UTC)
+
+	p, err := NewC(conf.MustNewConfigFrom(map[string]interface{}{
+		"index": "%{[fields.log_type]}-%{+yyyy.MM.dd}",
+	}))
+	require.NoError(t, err)
+
+	event, err := p.Run(&beat.Event{
+		Timestamp: ts,
+		Fields:    mapstr.M{"fields": mapstr.M{"log_type": "normal"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "normal-2022.11.18", event.Meta[events.FieldMetaRawIndex])
+}
+
+func TestAddFormattedIndexMissingField(t *testing.T) {
+	ts := time.Date(2022, 11, 18, 0, 0, 0, 0, time.UTC)
+
+	t.Run("without default", func(t *testing.T) {
+		p, err := NewC(conf.MustNewConfigFrom(map[string]interface{}{
+			"index": "%{[fields.log_type]}-%{+yyyy.MM.dd}",
+		}))
+		require.NoError(t, err)
+
+		event, err := p.Run(&beat.Event{Timestamp: ts, Fields: mapstr.M{}})
+		assert.Error(t, err)
+		assert.Nil(t, event)
+	})
+
+	t.Run("with default", func(t *testing.T) {
+		p, err := NewC(conf.MustNewConfigFrom(map[string]interface{}{
+			"index":          "%{[fields.log_type]}-%{+yyyy.MM.dd}",
+			"default":        "fallback",
+			"tag_on_failure": []string{"_index_fallback"},
+		}))
+		require.NoError(t, err)
+
+		event, err := p.Run(&beat.Event{Timestamp: ts, Fields: mapstr.M{}})
+		require.NoError(t, err)
+		assert.Equal(t, "fallback", event.Meta[events.FieldMetaRawIndex])
+		assert.Equal(t, []string{"_index_fallback"}, event.Fields["tags"])
+	})
+}
+
+func TestAddFormattedIndexConfigValidate(t *testing.T) {
+	_, err := NewC(conf.MustNewConfigFrom(map[string]interface{}{
+		"index":          "%{[fields.log_type]}",
+		"tag_on_failure": []string{"_index_fallback"},
+	}))
+	assert.Error(t, err)
+}
@@ -25,7 +25,9 @@ import (
 
 // configuration for AddFormattedIndex processor.
 type config struct {
-	Index *fmtstr.TimestampFormatString `config:"index"` // Index formatted string value
+	Index        *fmtstr.TimestampFormatString `config:"index"`          // Index formatted string value
+	Default      string                        `config:"default"`        // Index used when the format string cannot be expanded
+	TagOnFailure []string                      `config:"tag_on_failure"` // Tags to append when falling back to the default index
 }
 
 // Validate ensures that the configuration is valid.
@@ -35,5 +37,9 @@ func (c *config) Validate() error {
 		return errors.New("index field is required")
 	}
 
+	if len(c.TagOnFailure) > 0 && c.Default == "" {
+		return errors.New("tag_on_failure requires a default index")
+	}
+
 	return nil
 }
@@ -195,6 +195,17 @@ func TestConfigGeoEnabled(t *testing.T) {
 	assert.Len(t, eventGeoField, len(config))
 }
 
+func TestConfigGeoDatabasePathUnsupported(t *testing.T) {
+	testConfig, err := conf.NewConfigFrom(map[string]interface{}{
+		"geo.name":          "yerevan-am",
+		"geo.database_path": "/tmp/GeoLite2-City.mmdb",
+	})
+	require.NoError(t, err)
+
+	_, err = New(testConfig)
+	assert.ErrorContains(t, err, "geo.database_path is not supported")
+}
+
 func TestConfigGeoDisabled(t *testing.T) {
 	event := &beat.Event{
 		Fields:    mapstr.M{},
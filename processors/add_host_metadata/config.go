@@ -18,6 +18,7 @@
 package add_host_metadata
 
 import (
+	"errors"
 	"os"
 	"time"
 
@@ -34,6 +35,14 @@ type Config struct {
 	ReplaceFields       bool            `config:"replace_fields"` // replace existing host fields with add_host_metadata
 }
 
+// Validate checks the configuration.
+func (c *Config) Validate() error {
+	if c.Geo != nil && c.Geo.DatabasePath != "" {
+		return errors.New("geo.database_path is not supported by add_host_metadata")
+	}
+	return nil
+}
+
 func defaultConfig() Config {
 	// Setting environmental variable ELASTIC_NETINFO:false in Elastic Agent pod will disable the netinfo.enabled option of add_host_metadata processor
 	// This will result to events not being enhanced with host.ip and host.mac
@@ -21,8 +21,10 @@ import (
 	"fmt"
 
 	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/common"
 	"github.com/njcx/libbeat_v8/processors"
 	"github.com/njcx/libbeat_v8/processors/add_id/generator"
+	"github.com/njcx/libbeat_v8/processors/fingerprint"
 	jsprocessor "github.com/njcx/libbeat_v8/processors/script/javascript/module/processor"
 	conf "github.com/elastic/elastic-agent-libs/config"
 )
@@ -37,6 +39,7 @@ const processorName = "add_id"
 type addID struct {
 	config config
 	gen    generator.IDGenerator
+	hash   *hashID
 }
 
 // New constructs a new Add ID processor.
@@ -46,14 +49,28 @@ func New(cfg *conf.C) (beat.Processor, error) {
 		return nil, makeErrConfigUnpack(err)
 	}
 
+	if config.isHash() {
+		// The fields must be sorted so that the same set of configured
+		// fields always produces the same ID.
+		encode, _ := fingerprint.Encoding(config.Encoding)
+		return &addID{
+			config: config,
+			hash: &hashID{
+				fields:        common.MakeStringSet(config.Fields...).ToSlice(),
+				encode:        encode,
+				ignoreMissing: config.IgnoreMissing,
+			},
+		}, nil
+	}
+
 	gen, err := generator.Factory(config.Type)
 	if err != nil {
 		return nil, makeErrComputeID(err)
 	}
 
 	p := &addID{
-		config,
-		gen,
+		config: config,
+		gen:    gen,
 	}
 
 	return p, nil
@@ -61,7 +78,15 @@ func New(cfg *conf.C) (beat.Processor, error) {
 
 // Run enriches the given event with an ID
 func (p *addID) Run(event *beat.Event) (*beat.Event, error) {
-	id := p.gen.NextID()
+	var id string
+	if p.hash != nil {
+		var err error
+		if id, err = p.hash.id(event); err != nil {
+			return nil, makeErrComputeID(err)
+		}
+	} else {
+		id = p.gen.NextID()
+	}
 
 	if _, err := event.PutValue(p.config.TargetField, id); err != nil {
 		return nil, makeErrComputeID(err)
@@ -71,5 +96,9 @@ func (p *addID) Run(event *beat.Event) (*beat.Event, error) {
 }
 
 func (p *addID) String() string {
+	if p.hash != nil {
+		return fmt.Sprintf("%v=[target_field=[%v], type=[%v], fields=%v, encoding=[%v]]",
+			processorName, p.config.TargetField, hashType, p.hash.fields, p.config.Encoding)
+	}
 	return fmt.Sprintf("%v=[target_field=[%v]]", processorName, p.config.TargetField)
 }
@@ -86,3 +86,95 @@ func TestNonDefaultMetadataTarget(t *testing.T) {
 	v, err = newEvent.GetValue("@metadata._id")
 	assert.Error(t, err)
 }
+
+func TestHashType(t *testing.T) {
+	cfg := conf.MustNewConfigFrom(mapstr.M{
+		"type":   "hash",
+		"fields": []string{"message", "host.name"},
+	})
+	p, err := New(cfg)
+	assert.NoError(t, err)
+
+	newEvent := func(message string) *beat.Event {
+		return &beat.Event{
+			Fields: mapstr.M{
+				"message": message,
+				"host":    mapstr.M{"name": "web-1"},
+			},
+		}
+	}
+
+	first, err := p.Run(newEvent("hello"))
+	assert.NoError(t, err)
+	firstID, err := first.GetValue("@metadata._id")
+	assert.NoError(t, err)
+	// sha256("|host.name|web-1|message|hello|") in base64url.
+	assert.Equal(t, "s176VY9SbPoaW_W0obhhrIsfUBoGDf0RkYModUjkEGM", firstID)
+
+	retry, err := p.Run(newEvent("hello"))
+	assert.NoError(t, err)
+	retryID, _ := retry.GetValue("@metadata._id")
+	assert.Equal(t, firstID, retryID)
+
+	other, err := p.Run(newEvent("bye"))
+	assert.NoError(t, err)
+	otherID, _ := other.GetValue("@metadata._id")
+	assert.NotEqual(t, firstID, otherID)
+}
+
+func TestHashTypeEncoding(t *testing.T) {
+	cfg := conf.MustNewConfigFrom(mapstr.M{
+		"type":         "hash",
+		"fields":       []string{"message"},
+		"encoding":     "hex",
+		"target_field": "event.id",
+	})
+	p, err := New(cfg)
+	assert.NoError(t, err)
+
+	newEvent, err := p.Run(&beat.Event{Fields: mapstr.M{"message": "hello"}})
+	assert.NoError(t, err)
+
+	v, err := newEvent.GetValue("event.id")
+	assert.NoError(t, err)
+	assert.Len(t, v, 64)
+}
+
+func TestHashTypeMissingField(t *testing.T) {
+	cfg := conf.MustNewConfigFrom(mapstr.M{
+		"type":   "hash",
+		"fields": []string{"message"},
+	})
+	p, err := New(cfg)
+	assert.NoError(t, err)
+
+	_, err = p.Run(&beat.Event{Fields: mapstr.M{}})
+	assert.ErrorIs(t, err, mapstr.ErrKeyNotFound)
+
+	cfg = conf.MustNewConfigFrom(mapstr.M{
+		"type":           "hash",
+		"fields":         []string{"message"},
+		"ignore_missing": true,
+	})
+	p, err = New(cfg)
+	assert.NoError(t, err)
+
+	_, err = p.Run(&beat.Event{Fields: mapstr.M{}})
+	assert.NoError(t, err)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := map[string]mapstr.M{
+		"hash without fields": {"type": "hash"},
+		"unknown encoding":    {"type": "hash", "fields": []string{"a"}, "encoding": "base58"},
+		"fields without hash": {"type": "random", "fields": []string{"a"}},
+		"unknown type":        {"type": "uuid"},
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(conf.MustNewConfigFrom(cfg))
+			assert.Error(t, err)
+		})
+	}
+}
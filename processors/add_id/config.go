@@ -18,27 +18,55 @@
 package add_id
 
 import (
+	"strings"
+
 	"github.com/njcx/libbeat_v8/processors/add_id/generator"
+	"github.com/njcx/libbeat_v8/processors/fingerprint"
 )
 
+// hashType is the type of the deterministic ID computed from event fields.
+const hashType = "hash"
+
 // configuration for Add ID processor.
 type config struct {
-	TargetField string `config:"target_field"` // Target field for the ID
-	Type        string `config:"type"`         // Type of ID
+	TargetField   string   `config:"target_field"`   // Target field for the ID
+	Type          string   `config:"type"`           // Type of ID
+	Fields        []string `config:"fields"`         // Fields hashed by the hash type
+	Encoding      string   `config:"encoding"`       // Encoding of the hash type
+	IgnoreMissing bool     `config:"ignore_missing"` // Skip missing fields in the hash type
 }
 
 func defaultConfig() config {
 	return config{
 		TargetField: "@metadata._id",
 		Type:        "elasticsearch",
+		Encoding:    "base64url",
 	}
 }
 
 func (c *config) Validate() error {
+	if c.isHash() {
+		if len(c.Fields) == 0 {
+			return makeErrMissingFields()
+		}
+		if _, found := fingerprint.Encoding(c.Encoding); !found {
+			return makeErrUnknownEncoding(c.Encoding)
+		}
+		return nil
+	}
+
 	// Validate type of ID generator
 	if !generator.Exists(c.Type) {
 		return makeErrUnknownType(c.Type)
 	}
 
+	if len(c.Fields) != 0 {
+		return makeErrFieldsNotAllowed(c.Type)
+	}
+
 	return nil
 }
+
+func (c *config) isHash() bool {
+	return strings.ToLower(c.Type) == hashType
+}
@@ -22,9 +22,12 @@ import (
 )
 
 type (
-	errConfigUnpack struct{ cause error }
-	errComputeID    struct{ cause error }
-	errUnknownType  struct{ typ string }
+	errConfigUnpack     struct{ cause error }
+	errComputeID        struct{ cause error }
+	errUnknownType      struct{ typ string }
+	errUnknownEncoding  struct{ encoding string }
+	errMissingFields    struct{}
+	errFieldsNotAllowed struct{ typ string }
 )
 
 func makeErrConfigUnpack(cause error) errConfigUnpack {
@@ -53,3 +56,24 @@ func makeErrUnknownType(typ string) errUnknownType {
 func (e errUnknownType) Error() string {
 	return fmt.Sprintf("invalid type [%s]", e.typ)
 }
+
+func makeErrUnknownEncoding(encoding string) errUnknownEncoding {
+	return errUnknownEncoding{encoding}
+}
+func (e errUnknownEncoding) Error() string {
+	return fmt.Sprintf("invalid encoding [%s]", e.encoding)
+}
+
+func makeErrMissingFields() errMissingFields {
+	return errMissingFields{}
+}
+func (e errMissingFields) Error() string {
+	return fmt.Sprintf("type [%s] requires at least one field in fields", hashType)
+}
+
+func makeErrFieldsNotAllowed(typ string) errFieldsNotAllowed {
+	return errFieldsNotAllowed{typ}
+}
+func (e errFieldsNotAllowed) Error() string {
+	return fmt.Sprintf("fields can only be used with type [%s], not [%s]", hashType, e.typ)
+}
@@ -21,6 +21,7 @@ import "strings"
 
 var generators = map[string]IDGenerator{
 	"elasticsearch": ESTimeBasedUUIDGenerator(),
+	"random":        ESTimeBasedUUIDGenerator(),
 }
 
 // IDGenerator implementors know how to generate and return a new ID
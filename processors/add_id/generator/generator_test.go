@@ -32,6 +32,10 @@ func TestFactory(t *testing.T) {
 			ESTimeBasedUUIDGenerator(),
 			nil,
 		},
+		"random": {
+			ESTimeBasedUUIDGenerator(),
+			nil,
+		},
 		"foobar": {
 			nil,
 			makeErrUnknownType("foobar"),
@@ -0,0 +1,42 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package add_id
+
+import (
+	"crypto/sha256"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/processors/fingerprint"
+)
+
+// hashID computes a deterministic ID from the values of a sorted list of
+// fields, so that the same event always gets the same ID. The fields are
+// hashed like the fingerprint processor does with the sha256 method.
+type hashID struct {
+	fields        []string
+	encode        func([]byte) string
+	ignoreMissing bool
+}
+
+func (h *hashID) id(event *beat.Event) (string, error) {
+	hash := sha256.New()
+	if err := fingerprint.WriteFields(hash, event, h.fields, h.ignoreMissing); err != nil {
+		return "", err
+	}
+	return h.encode(hash.Sum(nil)), nil
+}
@@ -72,6 +72,13 @@ func New(cfg *config.C) (beat.Processor, error) {
 			return nil, err
 		}
 
+		if config.Geo.DatabasePath != "" {
+			// Values from the configuration take precedence over the database.
+			dbFields := lookupObserverGeo(config.Geo.DatabasePath, p.logger).Clone()
+			dbFields.DeepUpdate(geoFields)
+			geoFields = dbFields
+		}
+
 		p.geoData = mapstr.M{"observer": mapstr.M{"geo": geoFields}}
 	}
 
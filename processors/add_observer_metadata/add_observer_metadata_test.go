@@ -26,6 +26,7 @@ import (
 
 	"github.com/njcx/libbeat_v8/beat"
 	cfg "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
 
@@ -164,3 +165,67 @@ func TestConfigGeoDisabled(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, nil, eventGeoField)
 }
+
+func TestConfigGeoDatabaseMissing(t *testing.T) {
+	event := &beat.Event{
+		Fields:    mapstr.M{},
+		Timestamp: time.Now(),
+	}
+
+	testConfig, err := cfg.NewConfigFrom(map[string]interface{}{
+		"geo.name":          "yerevan-am",
+		"geo.database_path": "/does/not/exist/GeoLite2-City.mmdb",
+	})
+	require.NoError(t, err)
+
+	p, err := New(testConfig)
+	require.NoError(t, err)
+
+	newEvent, err := p.Run(event)
+	require.NoError(t, err)
+
+	eventGeoField, err := newEvent.GetValue("observer.geo")
+	require.NoError(t, err)
+	assert.Equal(t, mapstr.M{"name": "yerevan-am"}, eventGeoField)
+}
+
+func TestLookupObserverGeoRetriesFailures(t *testing.T) {
+	const path = "/does/not/exist/GeoLite2-City.mmdb"
+	log := logp.NewLogger("test")
+
+	assert.Nil(t, lookupObserverGeo(path, log))
+	entry := geoCache.results[path]
+	assert.False(t, entry.expires.IsZero(), "failed lookups must expire")
+
+	// An expired failure is looked up again.
+	geoCache.results[path] = geoCacheEntry{
+		geo:     mapstr.M{"city_name": "stale"},
+		expires: time.Now().Add(-time.Second),
+	}
+	assert.Nil(t, lookupObserverGeo(path, log))
+	assert.True(t, geoCache.results[path].expires.After(time.Now()))
+}
+
+func TestPublicIP(t *testing.T) {
+	tests := map[string]struct {
+		ipList   []string
+		expected string
+	}{
+		"none":          {nil, ""},
+		"private only":  {[]string{"192.168.1.251", "10.0.0.1", "fe80::64b2:c3ff:fe5b:b974"}, ""},
+		"first public":  {[]string{"192.168.1.251", "81.2.69.160", "175.16.199.1"}, "81.2.69.160"},
+		"public ipv6":   {[]string{"fd00::1", "2001:db8::1"}, "2001:db8::1"},
+		"invalid entry": {[]string{"not-an-ip", "81.2.69.160"}, "81.2.69.160"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ip := publicIP(test.ipList)
+			if test.expected == "" {
+				assert.Nil(t, ip)
+			} else {
+				assert.Equal(t, test.expected, ip.String())
+			}
+		})
+	}
+}
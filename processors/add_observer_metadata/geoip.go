@@ -0,0 +1,165 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package add_observer_metadata
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/njcx/libbeat_v8/processors/util"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// geoFailureTTL is how long a failed lookup is cached before it is retried,
+// for example once the database has been downloaded.
+const geoFailureTTL = 5 * time.Minute
+
+// geoCache holds the result of the observer geo lookup per database. The
+// observer IP rarely changes, so a successful lookup is kept for the
+// lifetime of the process.
+var geoCache = struct {
+	sync.Mutex
+	results map[string]geoCacheEntry
+}{results: map[string]geoCacheEntry{}}
+
+type geoCacheEntry struct {
+	geo mapstr.M
+
+	// expires is zero for successful lookups, which never expire.
+	expires time.Time
+}
+
+// geoRecord is the subset of a GeoIP2/GeoLite2 City record used to populate
+// observer.geo.
+type geoRecord struct {
+	Continent struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"continent"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Location struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+// lookupObserverGeo returns the geo fields of the first public IP of the
+// observer found in the database at path. Errors are logged and result in an
+// empty map, so a missing database does not prevent the processor from
+// running. Failed lookups are retried after geoFailureTTL.
+func lookupObserverGeo(path string, log *logp.Logger) mapstr.M {
+	geoCache.Lock()
+	defer geoCache.Unlock()
+
+	now := time.Now()
+	if entry, found := geoCache.results[path]; found {
+		if entry.expires.IsZero() || now.Before(entry.expires) {
+			return entry.geo
+		}
+	}
+
+	geo, err := lookupGeo(path)
+	if err != nil {
+		log.Warnf("Failed to geolocate the observer using %v: %v", path, err)
+	}
+	entry := geoCacheEntry{geo: geo}
+	if err != nil || geo == nil {
+		entry.expires = now.Add(geoFailureTTL)
+	}
+	geoCache.results[path] = entry
+	return geo
+}
+
+func lookupGeo(path string) (mapstr.M, error) {
+	ipList, _, err := util.GetNetInfo()
+	if err != nil && len(ipList) == 0 {
+		return nil, fmt.Errorf("failed to get observer IP addresses: %w", err)
+	}
+
+	ip := publicIP(ipList)
+	if ip == nil {
+		return nil, nil
+	}
+
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var record geoRecord
+	if err := db.Lookup(ip, &record); err != nil {
+		return nil, fmt.Errorf("failed to lookup %v: %w", ip, err)
+	}
+
+	return record.toMapStr(), nil
+}
+
+// publicIP returns the first global unicast, non private address from
+// ipList or nil if there is none.
+func publicIP(ipList []string) net.IP {
+	for _, s := range ipList {
+		ip := net.ParseIP(s)
+		if ip != nil && ip.IsGlobalUnicast() && !ip.IsPrivate() {
+			return ip
+		}
+	}
+	return nil
+}
+
+func (r geoRecord) toMapStr() mapstr.M {
+	geo := mapstr.M{}
+	add := func(key, value string) {
+		if value != "" {
+			geo[key] = value
+		}
+	}
+
+	add("continent_name", r.Continent.Names["en"])
+	add("country_name", r.Country.Names["en"])
+	add("country_iso_code", r.Country.ISOCode)
+	if len(r.Subdivisions) > 0 {
+		add("region_name", r.Subdivisions[0].Names["en"])
+		if r.Country.ISOCode != "" && r.Subdivisions[0].ISOCode != "" {
+			add("region_iso_code", r.Country.ISOCode+"-"+r.Subdivisions[0].ISOCode)
+		}
+	}
+	add("city_name", r.City.Names["en"])
+	if r.Location.Latitude != nil && r.Location.Longitude != nil {
+		geo["location"] = mapstr.M{
+			"lat": *r.Location.Latitude,
+			"lon": *r.Location.Longitude,
+		}
+	}
+
+	return geo
+}
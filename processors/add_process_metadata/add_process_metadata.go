@@ -89,7 +89,7 @@ type processMetadataProvider interface {
 }
 
 type cidProvider interface {
-	GetCid(pid int) (string, error)
+	GetContainer(pid int) (containerInfo, error)
 }
 
 func init() {
@@ -171,7 +171,8 @@ func newProcessMetadataProcessorWithProvider(config config, provider processMeta
 	}
 
 	// don't use cgroup.ProcessCgroupPaths to save it from doing the work when container id disabled
-	if ok := containsValue(mappings, "container.id"); ok {
+	if containsValue(mappings, "container.id") || (config.ContainerRuntime && containsValue(mappings, "container.runtime")) {
+		var cids gosigarCidProvider
 		if withCache && config.CgroupCacheExpireTime != 0 {
 			p.log.Debug("Initializing cgroup cache")
 			evictionListener := func(k common.Key, v common.Value) {
@@ -180,10 +181,12 @@ func newProcessMetadataProcessorWithProvider(config config, provider processMeta
 
 			p.cgroupsCache = common.NewCacheWithRemovalListener(config.CgroupCacheExpireTime, 100, evictionListener)
 			p.cgroupsCache.StartJanitor(config.CgroupCacheExpireTime)
-			p.cidProvider = newCidProvider(config.CgroupPrefixes, config.CgroupRegex, reader, p.cgroupsCache)
+			cids = newCidProvider(config.CgroupPrefixes, config.CgroupRegex, reader, p.cgroupsCache)
 		} else {
-			p.cidProvider = newCidProvider(config.CgroupPrefixes, config.CgroupRegex, reader, nil)
+			cids = newCidProvider(config.CgroupPrefixes, config.CgroupRegex, reader, nil)
 		}
+		cids.hostPath = config.HostPath
+		p.cidProvider = cids
 	}
 
 	if withCache {
@@ -275,11 +278,15 @@ func (p *addProcessMetadata) enrich(event *beat.Event, pidField string) (result
 		meta = metaPtr.fields
 	}
 
-	cid, err := p.getContainerID(pid)
-	if cid == "" || err != nil {
+	container, err := p.getContainer(pid)
+	if container.id == "" || err != nil {
 		p.log.Debugf("failed to get container id for PID=%d: %v", pid, err)
 	} else {
-		if _, err = meta.Put("container", mapstr.M{"id": cid}); err != nil {
+		fields := mapstr.M{"id": container.id}
+		if p.config.ContainerRuntime && container.runtime != "" {
+			fields["runtime"] = container.runtime
+		}
+		if _, err = meta.Put("container", fields); err != nil {
 			return nil, err
 		}
 	}
@@ -316,15 +323,11 @@ func (p *addProcessMetadata) enrich(event *beat.Event, pidField string) (result
 	return result, nil
 }
 
-func (p *addProcessMetadata) getContainerID(pid int) (string, error) {
+func (p *addProcessMetadata) getContainer(pid int) (containerInfo, error) {
 	if p.cidProvider == nil {
-		return "", nil
-	}
-	cid, err := p.cidProvider.GetCid(pid)
-	if err != nil {
-		return "", err
+		return containerInfo{}, nil
 	}
-	return cid, nil
+	return p.cidProvider.GetContainer(pid)
 }
 
 type addProcessMetadataCloser struct {
@@ -31,6 +31,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/common"
 	"github.com/njcx/libbeat_v8/common/capabilities"
 	"github.com/njcx/libbeat_v8/processors"
 	conf "github.com/elastic/elastic-agent-libs/config"
@@ -1312,3 +1313,115 @@ func TestDefaultCgroupRegex(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerRuntime(t *testing.T) {
+	testCases := map[string]string{
+		"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod69349abe_d645_11ea_9c4c_08002709c05c.slice/docker-80d85a3a585f1575028ebe468d83093c301eda20d37d1671ff2a0be50fc0e460.scope":         "docker",
+		"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod2d5133c0_65f3_40b2_b375_c04866d418e1.slice/cri-containerd-e01a26336924e2fb8089bcf4cf943954fd9ea616cc5678f38f65928307979459.scope": "containerd",
+		"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod69349abe_d645_11ea_9c4c_08002709c05c.slice/crio-80d85a3a585f1575028ebe468d83093c301eda20d37d1671ff2a0be50fc0e460.scope":           "cri-o",
+		"/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-conmon-ee059a097566fdc5ac9141bfcdfbed0c972163da891de076e0849d7b53597aac.scope":                                                   "podman",
+		"/docker/485776c9f6f2c22e2b44a2239b65471d6a02701b54d1cb5e1c55a09108a1b5b9":                                                                                                                        "docker",
+		"/system.slice/containerd.service": "",
+		"/user.slice":                      "",
+	}
+
+	for path, expected := range testCases {
+		assert.Equal(t, expected, containerRuntime(path), path)
+	}
+}
+
+func TestContainerRuntimeField(t *testing.T) {
+	const cid = "2dcbab615aebfa9313feffc5cfdacd381543cfa04c6be3f39ac656e55ef34805"
+	initCgroupPaths = newCGHandlerBuilder(testCGRsolver{res: func(_ int) (cgroup.PathList, error) {
+		return cgroup.PathList{
+			V2: map[string]cgroup.ControllerPath{
+				"cgroup": {IsV2: true, ControllerPath: "/system.slice/docker-" + cid + ".scope"},
+			},
+		}, nil
+	}})
+
+	for _, enabled := range []bool{false, true} {
+		config, err := conf.NewConfigFrom(mapstr.M{
+			"match_pids":        []string{"ppid"},
+			"include_fields":    []string{"container"},
+			"container_runtime": enabled,
+		})
+		require.NoError(t, err)
+
+		proc, err := New(config)
+		require.NoError(t, err)
+
+		result, err := proc.Run(&beat.Event{Fields: mapstr.M{"ppid": 1}})
+		require.NoError(t, err)
+
+		expected := mapstr.M{"id": cid}
+		if enabled {
+			expected["runtime"] = "docker"
+		}
+		container, err := result.GetValue("container")
+		require.NoError(t, err)
+		assert.Equal(t, expected, container, "container_runtime: %v", enabled)
+	}
+}
+
+func TestCidCachePIDReuse(t *testing.T) {
+	hostPath := t.TempDir()
+	writeStat := func(startTime string) {
+		t.Helper()
+		dir := hostPath + "/proc/42"
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		stat := "42 (my (odd) cmd) S 1 42 42 0 -1 4194560 100 0 0 0 1 2 0 0 20 0 1 0 " + startTime + " 1000 100 18446744073709551615"
+		require.NoError(t, os.WriteFile(dir+"/stat", []byte(stat), 0o644))
+	}
+
+	calls := 0
+	cids := []string{
+		"2dcbab615aebfa9313feffc5cfdacd381543cfa04c6be3f39ac656e55ef34805",
+		"80d85a3a585f1575028ebe468d83093c301eda20d37d1671ff2a0be50fc0e460",
+	}
+	reader := testCGRsolver{res: func(_ int) (cgroup.PathList, error) {
+		cid := cids[calls%len(cids)]
+		calls++
+		return cgroup.PathList{
+			V2: map[string]cgroup.ControllerPath{
+				"cgroup": {IsV2: true, ControllerPath: "/docker/" + cid},
+			},
+		}, nil
+	}}
+
+	cache := common.NewCache(time.Minute, 10)
+	provider := newCidProvider(nil, defaultCgroupRegex, reader, cache)
+	provider.hostPath = hostPath
+
+	writeStat("1000")
+	c, err := provider.GetContainer(42)
+	require.NoError(t, err)
+	assert.Equal(t, containerInfo{id: cids[0], runtime: "docker"}, c)
+
+	// Same process, the cached value is used.
+	c, err = provider.GetContainer(42)
+	require.NoError(t, err)
+	assert.Equal(t, cids[0], c.id)
+	assert.Equal(t, 1, calls)
+
+	// The PID has been reused by a new process.
+	writeStat("2000")
+	c, err = provider.GetContainer(42)
+	require.NoError(t, err)
+	assert.Equal(t, cids[1], c.id)
+	assert.Equal(t, 2, calls)
+}
+
+func TestProcStartTime(t *testing.T) {
+	hostPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(hostPath+"/proc/7", 0o755))
+	require.NoError(t, os.WriteFile(hostPath+"/proc/7/stat",
+		[]byte("7 (cmd with spaces) S 1 7 7 0 -1 4194560 100 0 0 0 1 2 0 0 20 0 1 0 123456 1000 100"), 0o644))
+
+	startTime, err := procStartTime(hostPath, 7)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(123456), startTime)
+
+	_, err = procStartTime(hostPath, 8)
+	assert.Error(t, err)
+}
@@ -60,6 +60,9 @@ type config struct {
 	// CgroupCacheExpireTime is the length of time before cgroup cache elements expire in seconds,
 	// set to 0 to disable the cgroup cache
 	CgroupCacheExpireTime time.Duration `config:"cgroup_cache_expire_time"`
+
+	// ContainerRuntime adds the container runtime derived from the cgroup path.
+	ContainerRuntime bool `config:"container_runtime"`
 }
 
 func (c *config) Validate() error {
@@ -98,7 +101,8 @@ var defaultFields = mapstr.M{
 		},
 	},
 	"container": mapstr.M{
-		"id": nil,
+		"id":      nil,
+		"runtime": nil,
 	},
 }
 
@@ -18,9 +18,12 @@
 package add_process_metadata
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/njcx/libbeat_v8/common"
@@ -39,32 +42,61 @@ type gosigarCidProvider struct {
 	cgroupRegex        *regexp.Regexp
 	processCgroupPaths processors.CGReader
 	pidCidCache        *common.Cache
+
+	// hostPath is the root of the /proc filesystem used to read the
+	// start time of processes.
+	hostPath string
+}
+
+// containerInfo is the container a process is running in.
+type containerInfo struct {
+	id      string
+	runtime string
+}
+
+// cachedContainer is the cache entry of a PID. The start time of the process
+// is used to detect PID reuse.
+type cachedContainer struct {
+	containerInfo
+	startTime uint64
 }
 
-func (p gosigarCidProvider) GetCid(pid int) (result string, err error) {
-	var cid string
-	var ok bool
+// GetCid returns the ID of the container the process is running in.
+func (p gosigarCidProvider) GetCid(pid int) (string, error) {
+	c, err := p.GetContainer(pid)
+	return c.id, err
+}
+
+// GetContainer returns the ID and runtime of the container the process is
+// running in.
+func (p gosigarCidProvider) GetContainer(pid int) (containerInfo, error) {
+	// The start time is zero when it cannot be read, in this case cached
+	// entries are only invalidated by the cache expiration.
+	startTime, _ := procStartTime(p.hostPath, pid)
 
 	// check from cache
 	if p.pidCidCache != nil {
-		if cid, ok = p.pidCidCache.Get(pid).(string); ok {
-			p.log.Debugf("Using cached container id for pid=%v", pid)
-			return cid, nil
+		if cached, ok := p.pidCidCache.Get(pid).(cachedContainer); ok {
+			if cached.startTime == startTime {
+				p.log.Debugf("Using cached container id for pid=%v", pid)
+				return cached.containerInfo, nil
+			}
+			p.log.Debugf("Invalidating cached container id for reused pid=%v", pid)
 		}
 	}
 
 	cgroups, err := p.getProcessCgroups(pid)
 	if err != nil {
-		return "", fmt.Errorf("failed to get cgroups for pid=%v: %w", pid, err)
+		return containerInfo{}, fmt.Errorf("failed to get cgroups for pid=%v: %w", pid, err)
 	}
 
-	cid = p.getContainerID(cgroups)
+	c := p.getContainer(cgroups)
 
-	// add pid and cid to cache
+	// add pid and container to cache
 	if p.pidCidCache != nil {
-		p.pidCidCache.Put(pid, cid)
+		p.pidCidCache.Put(pid, cachedContainer{containerInfo: c, startTime: startTime})
 	}
-	return cid, nil
+	return c, nil
 }
 
 func newCidProvider(cgroupPrefixes []string, cgroupRegex *regexp.Regexp, processCgroupPaths processors.CGReader, pidCidCache *common.Cache) gosigarCidProvider {
@@ -74,6 +106,7 @@ func newCidProvider(cgroupPrefixes []string, cgroupRegex *regexp.Regexp, process
 		cgroupRegex:        cgroupRegex,
 		processCgroupPaths: processCgroupPaths,
 		pidCidCache:        pidCidCache,
+		hostPath:           "/",
 	}
 }
 
@@ -89,27 +122,74 @@ func (p gosigarCidProvider) getProcessCgroups(pid int) (cgroup.PathList, error)
 	return pathList, nil
 }
 
-// getContainerID checks all the processes' cgroup paths to see if any match the
+// getContainer checks all the processes' cgroup paths to see if any match the
 // configured cgroup_regex or cgroup_prefixes. If there is a match, then the
-// container ID is returned. Otherwise, an empty string is returned.
-func (p gosigarCidProvider) getContainerID(cgroups cgroup.PathList) string {
+// container ID and the runtime derived from the matching path are returned.
+// Otherwise, an empty containerInfo is returned.
+func (p gosigarCidProvider) getContainer(cgroups cgroup.PathList) containerInfo {
 	if p.cgroupRegex != nil {
 		for _, path := range cgroups.Flatten() {
 			rs := p.cgroupRegex.FindStringSubmatch(path.ControllerPath)
 			if len(rs) > 1 {
-				return rs[1]
+				return containerInfo{id: rs[1], runtime: containerRuntime(path.ControllerPath)}
 			}
 		}
-		return ""
+		return containerInfo{}
 	}
 
 	// Try cgroup_prefixes.
 	for _, path := range cgroups.Flatten() {
 		for _, prefix := range p.cgroupPrefixes {
 			if strings.HasPrefix(path.ControllerPath, prefix) {
-				return filepath.Base(path.ControllerPath)
+				return containerInfo{
+					id:      filepath.Base(path.ControllerPath),
+					runtime: containerRuntime(path.ControllerPath),
+				}
 			}
 		}
 	}
+	return containerInfo{}
+}
+
+// containerRuntime derives the container runtime from the naming conventions
+// of the cgroup path, e.g. `docker-<id>.scope`, `/docker/<id>`,
+// `cri-containerd-<id>.scope` or `crio-<id>.scope`. It returns an empty string
+// if the runtime is unknown.
+func containerRuntime(path string) string {
+	base := filepath.Base(path)
+	dir := filepath.Base(filepath.Dir(path))
+	switch {
+	case strings.HasPrefix(base, "cri-containerd-"), dir == "containerd":
+		return "containerd"
+	case strings.HasPrefix(base, "crio-"), dir == "crio":
+		return "cri-o"
+	case strings.HasPrefix(base, "docker-"), dir == "docker":
+		return "docker"
+	case strings.HasPrefix(base, "libpod-"), dir == "libpod":
+		return "podman"
+	}
 	return ""
 }
+
+// procStartTime returns the start time of the process in clock ticks since
+// boot as reported by /proc/<pid>/stat.
+func procStartTime(hostPath string, pid int) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(hostPath, "proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, err
+	}
+
+	// The command name can contain spaces and parentheses, the fields
+	// following it start after the last closing parenthesis.
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return 0, fmt.Errorf("invalid stat file for pid=%v", pid)
+	}
+
+	// starttime is the 22nd field, the 20th after the command name.
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("invalid stat file for pid=%v", pid)
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
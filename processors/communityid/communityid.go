@@ -145,6 +145,12 @@ func (p *processor) buildFlow(event *beat.Event) *flowhash.Flow {
 		return nil
 	}
 
+	// Some sources (e.g. Zeek) report ICMPv6 as "icmp". The protocol
+	// number must match the IP version for the hash to be correct.
+	if flow.Protocol == icmpProtocol && flow.SourceIP.To4() == nil {
+		flow.Protocol = icmpIPv6Protocol
+	}
+
 	switch flow.Protocol {
 	case tcpProtocol, udpProtocol, sctpProtocol:
 		// source port
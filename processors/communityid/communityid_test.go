@@ -106,6 +106,19 @@ func TestRun(t *testing.T) {
 		testProcessor(t, 0, e, "1:PAE85ZfR4SbNXl5URZwWYyDehwU=")
 	})
 
+	t.Run("icmpv6", func(t *testing.T) {
+		// 1:GpbEQrKqfWtsfsFiqg8fufoZe5Y= | 3ffe:507::1:200:86ff:fe05:80da 3ffe:507::1:260:97ff:fe07:69ea 58 128 0
+		e := evt()
+		e.Put("source.ip", "3ffe:507::1:200:86ff:fe05:80da")
+		e.Put("destination.ip", "3ffe:507::1:260:97ff:fe07:69ea")
+		e.Put("icmp.type", 128)
+		e.Put("icmp.code", 0)
+		for _, transport := range []string{"ipv6-icmp", "icmpv6", "icmp"} {
+			e.Put("network.transport", transport)
+			testProcessor(t, 0, e.Clone(), "1:GpbEQrKqfWtsfsFiqg8fufoZe5Y=")
+		}
+	})
+
 	t.Run("igmp", func(t *testing.T) {
 		e := evt()
 		e.Delete("source.port")
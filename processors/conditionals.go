@@ -84,6 +84,15 @@ func (r *WhenProcessor) Run(event *beat.Event) (*beat.Event, error) {
 	return r.p.Run(event)
 }
 
+// RunMulti executes this WhenProcessor, allowing the processor to emit
+// multiple events.
+func (r *WhenProcessor) RunMulti(event *beat.Event) ([]*beat.Event, error) {
+	if !(r.condition).Check(event) {
+		return []*beat.Event{event}, nil
+	}
+	return beat.RunMulti(r.p, event)
+}
+
 func (r *WhenProcessor) String() string {
 	return fmt.Sprintf("%v, condition=%v", r.p.String(), r.condition.String())
 }
@@ -171,6 +180,16 @@ func (p *IfThenElseProcessor) Run(event *beat.Event) (*beat.Event, error) {
 	return event, nil
 }
 
+// RunMulti is like Run, allowing the processors to emit multiple events.
+func (p *IfThenElseProcessor) RunMulti(event *beat.Event) ([]*beat.Event, error) {
+	if p.cond.Check(event) {
+		return p.then.RunMulti(event)
+	} else if p.els != nil {
+		return p.els.RunMulti(event)
+	}
+	return []*beat.Event{event}, nil
+}
+
 func (p *IfThenElseProcessor) String() string {
 	var sb strings.Builder
 	sb.WriteString("if ")
@@ -124,6 +124,10 @@ func (p *processor) convertField(event *beat.Event, conversion field) (interface
 	}
 
 	if conversion.Type > unset {
+		if values, ok := toArray(v); ok {
+			return p.convertArray(conversion, values)
+		}
+
 		t, err := transformType(conversion.Type, v)
 		if err != nil {
 			return nil, newConvertError(conversion, err, p.Tag, "unable to convert value [%v]", v)
@@ -134,6 +138,49 @@ func (p *processor) convertField(event *beat.Event, conversion field) (interface
 	return v, nil
 }
 
+// convertArray converts each element of an array, preserving the structure
+// of nested arrays. If FailOnError is false, elements that can not be
+// converted are kept as is.
+func (p *processor) convertArray(conversion field, values []interface{}) ([]interface{}, error) {
+	converted := make([]interface{}, len(values))
+	for i, v := range values {
+		if nested, ok := toArray(v); ok {
+			t, err := p.convertArray(conversion, nested)
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = t
+			continue
+		}
+
+		t, err := transformType(conversion.Type, v)
+		if err != nil {
+			if p.FailOnError {
+				return nil, newConvertError(conversion, err, p.Tag, "unable to convert value [%v] at index %d", v, i)
+			}
+			t = v
+		}
+		converted[i] = t
+	}
+	return converted, nil
+}
+
+// toArray returns value as a slice of values if it is an array.
+func toArray(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		values := make([]interface{}, len(v))
+		for i, s := range v {
+			values[i] = s
+		}
+		return values, true
+	default:
+		return nil, false
+	}
+}
+
 func (p *processor) writeToEvent(event *beat.Event, converted []interface{}) error {
 	for i, conversion := range p.Fields {
 		v := converted[i]
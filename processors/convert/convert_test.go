@@ -239,6 +239,64 @@ func TestConvertRun(t *testing.T) {
 			},
 			fail: true,
 		},
+		"array": {
+			config: mapstr.M{
+				"fields": []mapstr.M{
+					{"from": "ports", "type": "integer"},
+					{"from": "addresses", "to": "ips", "type": "ip"},
+				},
+			},
+			input: beat.Event{
+				Fields: mapstr.M{
+					"ports":     []interface{}{"80", "443", []string{"8080"}},
+					"addresses": []string{"127.0.0.1", "::1"},
+				},
+			},
+			expected: beat.Event{
+				Fields: mapstr.M{
+					"ports":     []interface{}{int32(80), int32(443), []interface{}{int32(8080)}},
+					"addresses": []string{"127.0.0.1", "::1"},
+					"ips":       []interface{}{"127.0.0.1", "::1"},
+				},
+			},
+		},
+		"array with invalid element": {
+			config: mapstr.M{
+				"fields": []mapstr.M{
+					{"from": "ports", "type": "integer"},
+				},
+			},
+			input: beat.Event{
+				Fields: mapstr.M{
+					"ports": []interface{}{"80", "http"},
+				},
+			},
+			expected: beat.Event{
+				Fields: mapstr.M{
+					"ports": []interface{}{"80", "http"},
+				},
+			},
+			fail:        true,
+			errContains: "unable to convert value [http] at index 1",
+		},
+		"array with invalid element and fail_on_error disabled": {
+			config: mapstr.M{
+				"fields": []mapstr.M{
+					{"from": "ports", "type": "integer"},
+				},
+				"fail_on_error": false,
+			},
+			input: beat.Event{
+				Fields: mapstr.M{
+					"ports": []interface{}{"80", "http"},
+				},
+			},
+			expected: beat.Event{
+				Fields: mapstr.M{
+					"ports": []interface{}{int32(80), "http"},
+				},
+			},
+		},
 		"invalid conversion": {
 			config: mapstr.M{
 				"fields": []mapstr.M{
@@ -36,6 +36,9 @@ type decodeCSVFields struct {
 	csvConfig
 	fields    map[string]string
 	separator rune
+	// quote is swapped with '"' before and after parsing when set, as
+	// encoding/csv only supports double quotes.
+	quote *strings.Replacer
 }
 
 type csvConfig struct {
@@ -45,11 +48,14 @@ type csvConfig struct {
 	OverwriteKeys    bool     `config:"overwrite_keys"`
 	FailOnError      bool     `config:"fail_on_error"`
 	Separator        string   `config:"separator"`
+	Quote            string   `config:"quote"`
+	Columns          []string `config:"columns"`
 }
 
 var (
 	defaultCSVConfig = csvConfig{
 		Separator:   ",",
+		Quote:       `"`,
 		FailOnError: true,
 	}
 )
@@ -58,7 +64,7 @@ func init() {
 	processors.RegisterPlugin("decode_csv_fields",
 		checks.ConfigChecked(NewDecodeCSVField,
 			checks.RequireFields("fields"),
-			checks.AllowedFields("fields", "ignore_missing", "overwrite_keys", "separator", "trim_leading_space", "overwrite_keys", "fail_on_error", "when")))
+			checks.AllowedFields("fields", "ignore_missing", "overwrite_keys", "separator", "trim_leading_space", "overwrite_keys", "fail_on_error", "quote", "columns", "when")))
 
 	jsprocessor.RegisterPlugin("DecodeCSVField", NewDecodeCSVField)
 }
@@ -84,6 +90,15 @@ func NewDecodeCSVField(c *config.C) (beat.Processor, error) {
 	default:
 		return nil, fmt.Errorf("separator must be a single character, got %d in string '%s'", len(runes), config.Separator)
 	}
+	// Set quote
+	switch runes := []rune(config.Quote); {
+	case len(runes) != 1:
+		return nil, fmt.Errorf("quote must be a single character, got %d in string '%s'", len(runes), config.Quote)
+	case runes[0] == f.separator:
+		return nil, fmt.Errorf("quote and separator must be different characters, got '%s'", config.Quote)
+	case runes[0] != '"':
+		f.quote = strings.NewReplacer(config.Quote, `"`, `"`, config.Quote)
+	}
 	// Set fields as string -> string
 	f.fields = make(map[string]string, len(config.Fields))
 	for src, dstIf := range config.Fields.Flatten() {
@@ -124,6 +139,9 @@ func (f *decodeCSVFields) decodeCSVField(src, dest string, event *beat.Event) er
 		return fmt.Errorf("field %s is not of string type", src)
 	}
 
+	if f.quote != nil {
+		text = f.quote.Replace(text)
+	}
 	reader := csv.NewReader(strings.NewReader(text))
 	reader.Comma = f.separator
 	reader.TrimLeadingSpace = f.TrimLeadingSpace
@@ -134,18 +152,45 @@ func (f *decodeCSVFields) decodeCSVField(src, dest string, event *beat.Event) er
 	if err != nil {
 		return fmt.Errorf("error decoding CSV from field %s: %w", src, err)
 	}
+	if f.quote != nil {
+		for i, v := range record {
+			record[i] = f.quote.Replace(v)
+		}
+	}
+
+	var value interface{} = record
+	if len(f.Columns) > 0 {
+		if len(record) != len(f.Columns) && f.FailOnError {
+			return fmt.Errorf("error decoding CSV from field %s: got %d values, expected %d columns", src, len(record), len(f.Columns))
+		}
+		value = f.toColumns(record)
+	}
 
 	if src != dest && !f.OverwriteKeys {
 		if _, err = event.GetValue(dest); err == nil {
 			return fmt.Errorf("target field %s already has a value. Set the overwrite_keys flag or drop/rename the field first", dest)
 		}
 	}
-	if _, err = event.PutValue(dest, record); err != nil {
+	if _, err = event.PutValue(dest, value); err != nil {
 		return fmt.Errorf("failed setting field %s: %w", dest, err)
 	}
 	return nil
 }
 
+// toColumns maps the values of record to the configured column names.
+// Columns without a value are omitted and values without a column are
+// dropped.
+func (f *decodeCSVFields) toColumns(record []string) mapstr.M {
+	m := make(mapstr.M, len(f.Columns))
+	for i, column := range f.Columns {
+		if i >= len(record) {
+			break
+		}
+		_, _ = m.Put(column, record[i])
+	}
+	return m
+}
+
 // String returns a string representation of this processor.
 func (f decodeCSVFields) String() string {
 	json, _ := json.Marshal(f.csvConfig)
@@ -317,6 +317,89 @@ func TestDecodeCSVField(t *testing.T) {
 			},
 			fail: true,
 		},
+
+		"custom quote": {
+			config: mapstr.M{
+				"fields": mapstr.M{
+					"message": "message",
+				},
+				"quote": "'",
+			},
+			input: beat.Event{
+				Fields: mapstr.M{
+					"message": `a,'b,c','it''s',say "hi"`,
+				},
+			},
+			expected: beat.Event{
+				Fields: mapstr.M{
+					"message": []string{"a", "b,c", "it's", `say "hi"`},
+				},
+			},
+		},
+
+		"columns": {
+			config: mapstr.M{
+				"fields": mapstr.M{
+					"message": "flow",
+				},
+				"columns": []string{"proto", "source.ip", "destination.ip"},
+			},
+			input: beat.Event{
+				Fields: mapstr.M{
+					"message": "17,192.168.33.1,8.8.8.8",
+				},
+			},
+			expected: beat.Event{
+				Fields: mapstr.M{
+					"message":             "17,192.168.33.1,8.8.8.8",
+					"flow.proto":          "17",
+					"flow.source.ip":      "192.168.33.1",
+					"flow.destination.ip": "8.8.8.8",
+				},
+			},
+		},
+
+		"ragged row": {
+			config: mapstr.M{
+				"fields": mapstr.M{
+					"message": "flow",
+				},
+				"columns": []string{"proto", "source.ip"},
+			},
+			input: beat.Event{
+				Fields: mapstr.M{
+					"message": "17,192.168.33.1,8.8.8.8",
+				},
+			},
+			expected: beat.Event{
+				Fields: mapstr.M{
+					"message": "17,192.168.33.1,8.8.8.8",
+				},
+			},
+			fail: true,
+		},
+
+		"ragged row without fail_on_error": {
+			config: mapstr.M{
+				"fields": mapstr.M{
+					"message": "flow",
+				},
+				"columns":       []string{"proto", "source.ip", "destination.ip"},
+				"fail_on_error": false,
+			},
+			input: beat.Event{
+				Fields: mapstr.M{
+					"message": "17,192.168.33.1",
+				},
+			},
+			expected: beat.Event{
+				Fields: mapstr.M{
+					"message":        "17,192.168.33.1",
+					"flow.proto":     "17",
+					"flow.source.ip": "192.168.33.1",
+				},
+			},
+		},
 	}
 
 	for title, tt := range tests {
@@ -384,5 +467,17 @@ func TestDecodeCSVField_String(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assert.Equal(t, "decode_csv_field={\"Fields\":{\"a\":\"csv.a\",\"b\":\"csv.b\"},\"IgnoreMissing\":true,\"TrimLeadingSpace\":false,\"OverwriteKeys\":false,\"FailOnError\":true,\"Separator\":\"#\"}", p.String())
+	assert.Equal(t, "decode_csv_field={\"Fields\":{\"a\":\"csv.a\",\"b\":\"csv.b\"},\"IgnoreMissing\":true,\"TrimLeadingSpace\":false,\"OverwriteKeys\":false,\"FailOnError\":true,\"Separator\":\"#\",\"Quote\":\"\\\"\",\"Columns\":null}", p.String())
+}
+
+func TestDecodeCSVField_BadQuote(t *testing.T) {
+	for _, quote := range []string{"", "''", ","} {
+		_, err := NewDecodeCSVField(cfg.MustNewConfigFrom(mapstr.M{
+			"fields": mapstr.M{
+				"message": "message",
+			},
+			"quote": quote,
+		}))
+		assert.Error(t, err, "quote %q", quote)
+	}
 }
@@ -19,6 +19,7 @@ package decode_duration
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/njcx/libbeat_v8/beat"
@@ -40,6 +41,25 @@ type decodeDurationConfig struct {
 	Format string `config:"format"`
 }
 
+const (
+	formatNanoseconds  = "nanoseconds"
+	formatMilliseconds = "milliseconds"
+	formatSeconds      = "seconds"
+	formatMinutes      = "minutes"
+	formatHours        = "hours"
+	formatHuman        = "human"
+)
+
+func (c decodeDurationConfig) Validate() error {
+	switch c.Format {
+	case "", formatNanoseconds, formatMilliseconds, formatSeconds, formatMinutes, formatHours, formatHuman:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format '%s', supported formats are %s, %s, %s, %s, %s and %s",
+			c.Format, formatNanoseconds, formatMilliseconds, formatSeconds, formatMinutes, formatHours, formatHuman)
+	}
+}
+
 type decodeDuration struct {
 	config decodeDurationConfig
 }
@@ -60,15 +80,19 @@ func (u decodeDuration) Run(event *beat.Event) (*beat.Event, error) {
 		return event, fmt.Errorf("couldn't parse field '%s' as duration: %w", fieldName, err)
 	}
 	switch u.config.Format {
-	case "milliseconds":
+	case formatNanoseconds:
+		x = d.Nanoseconds()
+	case formatMilliseconds:
 		// keep the result is type float64
 		x = float64(d.Milliseconds())
-	case "seconds":
+	case formatSeconds:
 		x = d.Seconds()
-	case "minutes":
+	case formatMinutes:
 		x = d.Minutes()
-	case "hours":
+	case formatHours:
 		x = d.Hours()
+	case formatHuman:
+		x = humanDuration(d)
 	default:
 		x = float64(d.Milliseconds())
 	}
@@ -78,6 +102,19 @@ func (u decodeDuration) Run(event *beat.Event) (*beat.Event, error) {
 	return event, nil
 }
 
+// humanDuration formats d like time.Duration.String but without trailing
+// zero units, e.g. "1h2m" instead of "1h2m0s".
+func humanDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
 func (u decodeDuration) String() string {
 	return "decode_duration"
 }
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/njcx/libbeat_v8/beat"
+	"github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
 
@@ -71,3 +72,52 @@ func TestDecodeDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeDurationFormats(t *testing.T) {
+	cases := []struct {
+		Duration string
+		Format   string
+		Result   interface{}
+	}{
+		{"1s1ms", "nanoseconds", int64(1001000000)},
+		{"1h2m", "human", "1h2m"},
+		{"1h0m0s", "human", "1h"},
+		{"90s", "human", "1m30s"},
+		{"2m", "human", "2m"},
+		{"1.5ms", "human", "1.5ms"},
+		{"0s", "human", "0s"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(fmt.Sprintf("%s format as %s", testCase.Duration, testCase.Format), func(t *testing.T) {
+			evt := &beat.Event{Fields: mapstr.M{"duration": testCase.Duration}}
+			c := &decodeDuration{
+				config: decodeDurationConfig{
+					Field:  "duration",
+					Format: testCase.Format,
+				},
+			}
+			evt, err := c.Run(evt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err := evt.GetValue("duration")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d != testCase.Result {
+				t.Fatalf("test case except: %#v, actual: %#v", testCase.Result, d)
+			}
+		})
+	}
+}
+
+func TestDecodeDurationInvalidFormat(t *testing.T) {
+	_, err := NewDecodeDuration(config.MustNewConfigFrom(mapstr.M{
+		"field":  "duration",
+		"format": "fortnights",
+	}))
+	if err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,233 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package decode_kv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/processors"
+	"github.com/njcx/libbeat_v8/processors/checks"
+	jsprocessor "github.com/njcx/libbeat_v8/processors/script/javascript/module/processor"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const procName = "decode_kv"
+
+func init() {
+	processors.RegisterPlugin(procName,
+		checks.ConfigChecked(New,
+			checks.RequireFields("field"),
+			checks.AllowedFields("field", "field_split", "value_split", "target", "trim",
+				"convert_numbers", "ignore_missing", "fail_on_error", "when")))
+	jsprocessor.RegisterPlugin("DecodeKV", New)
+}
+
+type config struct {
+	Field          string `config:"field" validate:"required"`
+	FieldSplit     string `config:"field_split" validate:"required"`
+	ValueSplit     string `config:"value_split" validate:"required"`
+	Target         string `config:"target"`
+	Trim           string `config:"trim"`
+	ConvertNumbers bool   `config:"convert_numbers"`
+	IgnoreMissing  bool   `config:"ignore_missing"`
+	FailOnError    bool   `config:"fail_on_error"`
+}
+
+func defaultConfig() config {
+	return config{
+		FieldSplit:  " ",
+		ValueSplit:  "=",
+		FailOnError: true,
+	}
+}
+
+type processor struct {
+	config
+	log *logp.Logger
+}
+
+// New constructs a processor that parses key-value pairs like
+// `user=alice action=login` from a string field.
+func New(cfg *conf.C) (beat.Processor, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, fmt.Errorf("fail to unpack the %v processor configuration: %w", procName, err)
+	}
+	if c.FieldSplit == c.ValueSplit {
+		return nil, fmt.Errorf("%v field_split and value_split must be different", procName)
+	}
+	return &processor{config: c, log: logp.NewLogger(procName)}, nil
+}
+
+func (p *processor) String() string {
+	json, _ := json.Marshal(p.config)
+	return procName + "=" + string(json)
+}
+
+func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
+	var backup *beat.Event
+	if p.FailOnError {
+		backup = event.Clone()
+	}
+
+	err := p.decodeField(event)
+	if err != nil {
+		errMsg := fmt.Errorf("failed to decode key-value pairs in %v processor: %w", procName, err)
+		p.log.Debugw(errMsg.Error(), logp.TypeKey, logp.EventType)
+
+		if p.FailOnError {
+			event = backup
+			_, _ = event.PutValue("error.message", errMsg.Error())
+			return event, err
+		}
+	}
+	return event, nil
+}
+
+func (p *processor) decodeField(event *beat.Event) error {
+	v, err := event.GetValue(p.Field)
+	if err != nil {
+		if p.IgnoreMissing && errors.Is(err, mapstr.ErrKeyNotFound) {
+			return nil
+		}
+		return fmt.Errorf("could not fetch value for key: %s, Error: %w", p.Field, err)
+	}
+
+	text, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("invalid type for `field`, expecting a string received %T", v)
+	}
+
+	pairs, err := p.parse(text)
+	if err != nil {
+		return err
+	}
+
+	for _, kv := range pairs {
+		key := kv.key
+		if p.Target != "" {
+			key = p.Target + "." + key
+		}
+		var value interface{} = kv.value
+		if p.ConvertNumbers && !kv.quoted {
+			value = convertNumber(kv.value)
+		}
+		if _, err := event.PutValue(key, value); err != nil {
+			return fmt.Errorf("could not put value: %v: %v, %w", value, key, err)
+		}
+	}
+	return nil
+}
+
+type keyValue struct {
+	key, value string
+	quoted     bool
+}
+
+// parse splits s into key-value pairs. Values enclosed in double or single
+// quotes may contain the separators; within double quotes a backslash
+// escapes the next character.
+func (p *processor) parse(s string) ([]keyValue, error) {
+	var pairs []keyValue
+	for len(s) > 0 {
+		// Skip repeated field separators, e.g. multiple spaces.
+		if strings.HasPrefix(s, p.FieldSplit) {
+			s = s[len(p.FieldSplit):]
+			continue
+		}
+
+		valueStart := strings.Index(s, p.ValueSplit)
+		fieldEnd := strings.Index(s, p.FieldSplit)
+		if valueStart < 0 || (fieldEnd >= 0 && fieldEnd < valueStart) {
+			token := s
+			if fieldEnd >= 0 {
+				token = s[:fieldEnd]
+			}
+			return nil, fmt.Errorf("missing value separator %q in %q", p.ValueSplit, token)
+		}
+
+		key := strings.Trim(s[:valueStart], p.Trim)
+		if key == "" {
+			return nil, fmt.Errorf("empty key before %q", s)
+		}
+		s = s[valueStart+len(p.ValueSplit):]
+
+		var kv keyValue
+		if len(s) > 0 && (s[0] == '"' || s[0] == '\'') {
+			value, rest, err := unquote(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for key %q: %w", key, err)
+			}
+			if rest != "" && !strings.HasPrefix(rest, p.FieldSplit) {
+				return nil, fmt.Errorf("unexpected characters after quoted value for key %q", key)
+			}
+			kv = keyValue{key: key, value: value, quoted: true}
+			s = rest
+		} else {
+			end := strings.Index(s, p.FieldSplit)
+			if end < 0 {
+				end = len(s)
+			}
+			kv = keyValue{key: key, value: strings.Trim(s[:end], p.Trim)}
+			s = s[end:]
+		}
+		pairs = append(pairs, kv)
+	}
+	return pairs, nil
+}
+
+// unquote reads the quoted value at the start of s and returns it without
+// quotes, along with the remainder of s.
+func unquote(s string) (value, rest string, err error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && quote == '"' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case c == quote:
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("missing closing quote %q", quote)
+}
+
+// convertNumber returns s as int64 or float64 if it is a number, and as is
+// otherwise.
+func convertNumber(s string) interface{} {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	// NaN and infinity can't be represented in JSON, keep them as strings.
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f
+	}
+	return s
+}
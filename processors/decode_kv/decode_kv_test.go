@@ -0,0 +1,142 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package decode_kv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestDecodeKV(t *testing.T) {
+	tests := map[string]struct {
+		config   mapstr.M
+		input    mapstr.M
+		expected mapstr.M
+		wantErr  bool
+	}{
+		"simple pairs": {
+			config: mapstr.M{"field": "message"},
+			input:  mapstr.M{"message": "user=alice action=login status=ok"},
+			expected: mapstr.M{
+				"message": "user=alice action=login status=ok",
+				"user":    "alice",
+				"action":  "login",
+				"status":  "ok",
+			},
+		},
+		"target and dotted keys": {
+			config: mapstr.M{"field": "message", "target": "kv"},
+			input:  mapstr.M{"message": "user.name=alice  http.status=200"},
+			expected: mapstr.M{
+				"message": "user.name=alice  http.status=200",
+				"kv": mapstr.M{
+					"user": mapstr.M{"name": "alice"},
+					"http": mapstr.M{"status": "200"},
+				},
+			},
+		},
+		"quoted values": {
+			config: mapstr.M{"field": "message", "target": "kv"},
+			input:  mapstr.M{"message": `msg="hello world" path='a b=c' q="say \"hi\""`},
+			expected: mapstr.M{
+				"message": `msg="hello world" path='a b=c' q="say \"hi\""`,
+				"kv": mapstr.M{
+					"msg":  "hello world",
+					"path": "a b=c",
+					"q":    `say "hi"`,
+				},
+			},
+		},
+		"custom separators and trim": {
+			config: mapstr.M{"field": "message", "target": "kv", "field_split": ";", "value_split": ":", "trim": " "},
+			input:  mapstr.M{"message": "user: alice ; action : login"},
+			expected: mapstr.M{
+				"message": "user: alice ; action : login",
+				"kv":      mapstr.M{"user": "alice", "action": "login"},
+			},
+		},
+		"convert numbers": {
+			config: mapstr.M{"field": "message", "target": "kv", "convert_numbers": true},
+			input:  mapstr.M{"message": `bytes=1024 took=0.25 id="42" name=inf`},
+			expected: mapstr.M{
+				"message": `bytes=1024 took=0.25 id="42" name=inf`,
+				"kv": mapstr.M{
+					"bytes": int64(1024),
+					"took":  0.25,
+					"id":    "42",
+					"name":  "inf",
+				},
+			},
+		},
+		"missing field": {
+			config:   mapstr.M{"field": "message"},
+			input:    mapstr.M{"other": "a=b"},
+			wantErr:  true,
+			expected: mapstr.M{"other": "a=b"},
+		},
+		"ignore missing": {
+			config:   mapstr.M{"field": "message", "ignore_missing": true},
+			input:    mapstr.M{"other": "a=b"},
+			expected: mapstr.M{"other": "a=b"},
+		},
+		"malformed pair leaves the event unchanged": {
+			config:   mapstr.M{"field": "message", "target": "kv"},
+			input:    mapstr.M{"message": "a=1 broken c=3"},
+			wantErr:  true,
+			expected: mapstr.M{"message": "a=1 broken c=3"},
+		},
+		"unterminated quote": {
+			config:   mapstr.M{"field": "message", "target": "kv", "fail_on_error": false},
+			input:    mapstr.M{"message": `a="open`},
+			expected: mapstr.M{"message": `a="open`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := New(conf.MustNewConfigFrom(test.config))
+			require.NoError(t, err)
+
+			event, err := p.Run(&beat.Event{Fields: test.input.Clone()})
+			if test.wantErr {
+				assert.Error(t, err)
+				// The original event is returned with the error message.
+				msg, _ := event.GetValue("error.message")
+				assert.Contains(t, msg, "failed to decode key-value pairs")
+				_ = event.Delete("error")
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, event.Fields)
+		})
+	}
+}
+
+func TestDecodeKVConfig(t *testing.T) {
+	_, err := New(conf.MustNewConfigFrom(mapstr.M{"field": "message", "field_split": "=", "value_split": "="}))
+	assert.Error(t, err, "identical separators must be rejected")
+
+	_, err = New(conf.MustNewConfigFrom(mapstr.M{"field": "message", "field_split": ""}))
+	assert.Error(t, err, "an empty field separator must be rejected")
+}
@@ -0,0 +1,170 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package decode_traceparent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/processors"
+	"github.com/njcx/libbeat_v8/processors/checks"
+	jsprocessor "github.com/njcx/libbeat_v8/processors/script/javascript/module/processor"
+	conf "github.com/elastic/elastic-agent-libs/config"
+)
+
+const procName = "decode_traceparent"
+
+func init() {
+	processors.RegisterPlugin(procName,
+		checks.ConfigChecked(New,
+			checks.RequireFields("field")))
+	jsprocessor.RegisterPlugin("DecodeTraceparent", New)
+}
+
+type config struct {
+	Field         string `config:"field"`
+	TargetField   string `config:"target_field"`
+	IgnoreMissing bool   `config:"ignore_missing"`
+	IgnoreFailure bool   `config:"ignore_failure"`
+}
+
+func defaultConfig() config {
+	return config{
+		TargetField: "trace",
+	}
+}
+
+type processor struct {
+	config
+}
+
+// New constructs a processor that decodes a W3C traceparent header value
+// into the trace id, span id and trace flags of the event.
+func New(cfg *conf.C) (beat.Processor, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, fmt.Errorf("fail to unpack the %v processor configuration: %w", procName, err)
+	}
+	return &processor{config: c}, nil
+}
+
+func (p *processor) String() string {
+	json, _ := json.Marshal(p.config)
+	return procName + "=" + string(json)
+}
+
+func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
+	v, err := event.GetValue(p.Field)
+	if err != nil {
+		if p.IgnoreMissing || p.IgnoreFailure {
+			return event, nil
+		}
+		return event, fmt.Errorf("%s source field [%v] not found: %w", procName, p.Field, err)
+	}
+
+	header, ok := v.(string)
+	if !ok {
+		if p.IgnoreFailure {
+			return event, nil
+		}
+		return event, fmt.Errorf("%s source field [%v] is not a string", procName, p.Field)
+	}
+
+	tp, err := parseTraceparent(header)
+	if err != nil {
+		if p.IgnoreFailure {
+			return event, nil
+		}
+		return event, fmt.Errorf("failed to decode traceparent from field [%v]: %w", p.Field, err)
+	}
+
+	// span.id is a top level ECS field, the trace id and flags are written
+	// to the target field.
+	for key, value := range map[string]string{
+		p.TargetField + ".id":    tp.traceID,
+		p.TargetField + ".flags": tp.flags,
+		"span.id":                tp.parentID,
+	} {
+		if _, err := event.PutValue(key, value); err != nil {
+			if p.IgnoreFailure {
+				return event, nil
+			}
+			return event, fmt.Errorf("failed to write traceparent to field [%v]: %w", key, err)
+		}
+	}
+	return event, nil
+}
+
+// traceparent holds the fields of a W3C traceparent header, see
+// https://www.w3.org/TR/trace-context/#traceparent-header.
+type traceparent struct {
+	version  string
+	traceID  string
+	parentID string
+	flags    string
+}
+
+var (
+	errInvalidFormat  = errors.New("traceparent must have the format version-trace_id-parent_id-flags")
+	errInvalidVersion = errors.New("invalid traceparent version")
+	errZeroTraceID    = errors.New("traceparent trace id must not be all zeros")
+	errZeroParentID   = errors.New("traceparent parent id must not be all zeros")
+)
+
+// parseTraceparent validates and splits a traceparent header value. Values
+// of version 00 must consist of exactly the four fields. Later versions may
+// append more fields, which are ignored.
+func parseTraceparent(s string) (traceparent, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return traceparent{}, errInvalidFormat
+	}
+
+	tp := traceparent{version: parts[0], traceID: parts[1], parentID: parts[2], flags: parts[3]}
+	switch {
+	case !isLowerHex(tp.version, 2):
+		return traceparent{}, errInvalidVersion
+	case tp.version == "ff":
+		return traceparent{}, errInvalidVersion
+	case tp.version == "00" && len(parts) != 4:
+		return traceparent{}, errInvalidFormat
+	case !isLowerHex(tp.traceID, 32), !isLowerHex(tp.parentID, 16), !isLowerHex(tp.flags, 2):
+		return traceparent{}, errInvalidFormat
+	case strings.Trim(tp.traceID, "0") == "":
+		return traceparent{}, errZeroTraceID
+	case strings.Trim(tp.parentID, "0") == "":
+		return traceparent{}, errZeroParentID
+	}
+	return tp, nil
+}
+
+// isLowerHex reports whether s consists of n lowercase hex digits.
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package decode_traceparent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	valid := map[string]traceparent{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": {
+			version: "00", traceID: "4bf92f3577b34da6a3ce929d0e0e4736", parentID: "00f067aa0ba902b7", flags: "01",
+		},
+		"cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future": {
+			version: "cc", traceID: "4bf92f3577b34da6a3ce929d0e0e4736", parentID: "00f067aa0ba902b7", flags: "00",
+		},
+	}
+	for header, expected := range valid {
+		tp, err := parseTraceparent(header)
+		require.NoError(t, err, header)
+		assert.Equal(t, expected, tp, header)
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"0-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g",
+	}
+	for _, header := range invalid {
+		_, err := parseTraceparent(header)
+		assert.Error(t, err, header)
+	}
+}
+
+func TestDecodeTraceparent(t *testing.T) {
+	p, err := New(conf.MustNewConfigFrom(mapstr.M{"field": "http.request.headers.traceparent"}))
+	require.NoError(t, err)
+
+	event := &beat.Event{Fields: mapstr.M{
+		"http": mapstr.M{"request": mapstr.M{"headers": mapstr.M{
+			"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		}}},
+	}}
+	event, err = p.Run(event)
+	require.NoError(t, err)
+
+	traceID, _ := event.GetValue("trace.id")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	spanID, _ := event.GetValue("span.id")
+	assert.Equal(t, "00f067aa0ba902b7", spanID)
+	flags, _ := event.GetValue("trace.flags")
+	assert.Equal(t, "01", flags)
+}
+
+func TestDecodeTraceparentFailures(t *testing.T) {
+	malformed := func() *beat.Event {
+		return &beat.Event{Fields: mapstr.M{"traceparent": "not-a-traceparent"}}
+	}
+
+	p, err := New(conf.MustNewConfigFrom(mapstr.M{"field": "traceparent"}))
+	require.NoError(t, err)
+	_, err = p.Run(malformed())
+	assert.Error(t, err)
+	_, err = p.Run(&beat.Event{Fields: mapstr.M{}})
+	assert.Error(t, err)
+
+	p, err = New(conf.MustNewConfigFrom(mapstr.M{"field": "traceparent", "ignore_failure": true}))
+	require.NoError(t, err)
+	event, err := p.Run(malformed())
+	assert.NoError(t, err)
+	assert.Equal(t, mapstr.M{"traceparent": "not-a-traceparent"}, event.Fields)
+
+	p, err = New(conf.MustNewConfigFrom(mapstr.M{"field": "traceparent", "ignore_missing": true}))
+	require.NoError(t, err)
+	_, err = p.Run(&beat.Event{Fields: mapstr.M{}})
+	assert.NoError(t, err)
+}
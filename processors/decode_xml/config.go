@@ -18,13 +18,15 @@
 package decode_xml
 
 type decodeXMLConfig struct {
-	Field         string  `config:"field" validate:"required"`
-	Target        *string `config:"target_field"`
-	OverwriteKeys bool    `config:"overwrite_keys"`
-	DocumentID    string  `config:"document_id"`
-	ToLower       bool    `config:"to_lower"`
-	IgnoreMissing bool    `config:"ignore_missing"`
-	IgnoreFailure bool    `config:"ignore_failure"`
+	Field              string  `config:"field" validate:"required"`
+	Target             *string `config:"target_field"`
+	OverwriteKeys      bool    `config:"overwrite_keys"`
+	DocumentID         string  `config:"document_id"`
+	ToLower            bool    `config:"to_lower"`
+	PreserveNamespaces bool    `config:"preserve_namespaces"`
+	AttributesKey      string  `config:"attributes_key"`
+	IgnoreMissing      bool    `config:"ignore_missing"`
+	IgnoreFailure      bool    `config:"ignore_failure"`
 }
 
 func defaultConfig() decodeXMLConfig {
@@ -56,7 +56,8 @@ func init() {
 			checks.AllowedFields(
 				"field", "target_field",
 				"overwrite_keys", "document_id",
-				"to_lower", "ignore_missing",
+				"to_lower", "preserve_namespaces",
+				"attributes_key", "ignore_missing",
 				"ignore_failure", "when",
 			)))
 	jsprocessor.RegisterPlugin("DecodeXML", New)
@@ -141,6 +142,12 @@ func (x *decodeXML) decode(p []byte) (mapstr.M, error) {
 	if x.ToLower {
 		dec.LowercaseKeys()
 	}
+	if x.PreserveNamespaces {
+		dec.PreserveNamespaces()
+	}
+	if x.AttributesKey != "" {
+		dec.AttributesKey(x.AttributesKey)
+	}
 
 	out, err := dec.Decode()
 	if err != nil {
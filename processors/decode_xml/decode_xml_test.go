@@ -479,6 +479,108 @@ func TestDecodeXML(t *testing.T) {
 	})
 }
 
+func TestDecodeXMLNamespaces(t *testing.T) {
+	const soap = `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">
+		<soap:Body>
+			<m:GetPrice xmlns:m="http://www.example.org" m:currency="EUR">
+				<m:Item>Apples</m:Item>
+			</m:GetPrice>
+		</soap:Body>
+	</soap:Envelope>`
+
+	var testCases = []struct {
+		description string
+		config      decodeXMLConfig
+		Output      mapstr.M
+	}{
+		{
+			description: "namespaces are dropped by default",
+			config: decodeXMLConfig{
+				Field:   "message",
+				Target:  &testXMLTargetField,
+				ToLower: true,
+			},
+			Output: mapstr.M{
+				"envelope": map[string]interface{}{
+					"soap": "http://www.w3.org/2003/05/soap-envelope",
+					"body": map[string]interface{}{
+						"getprice": map[string]interface{}{
+							"m":        "http://www.example.org",
+							"currency": "EUR",
+							"item":     "Apples",
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "preserve namespaces",
+			config: decodeXMLConfig{
+				Field:              "message",
+				Target:             &testXMLTargetField,
+				PreserveNamespaces: true,
+			},
+			Output: mapstr.M{
+				"soap:Envelope": map[string]interface{}{
+					"xmlns:soap": "http://www.w3.org/2003/05/soap-envelope",
+					"soap:Body": map[string]interface{}{
+						"m:GetPrice": map[string]interface{}{
+							"xmlns:m":    "http://www.example.org",
+							"m:currency": "EUR",
+							"m:Item":     "Apples",
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "preserve namespaces with attributes key",
+			config: decodeXMLConfig{
+				Field:              "message",
+				Target:             &testXMLTargetField,
+				PreserveNamespaces: true,
+				AttributesKey:      "@attributes",
+			},
+			Output: mapstr.M{
+				"soap:Envelope": map[string]interface{}{
+					"@attributes": map[string]interface{}{
+						"xmlns:soap": "http://www.w3.org/2003/05/soap-envelope",
+					},
+					"soap:Body": map[string]interface{}{
+						"m:GetPrice": map[string]interface{}{
+							"@attributes": map[string]interface{}{
+								"xmlns:m":    "http://www.example.org",
+								"m:currency": "EUR",
+							},
+							"m:Item": "Apples",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := newDecodeXML(test.config)
+			require.NoError(t, err)
+
+			event := &beat.Event{
+				Fields: mapstr.M{"message": soap},
+			}
+			newEvent, err := f.Run(event)
+			require.NoError(t, err)
+
+			xml, err := newEvent.GetValue(testXMLTargetField)
+			require.NoError(t, err)
+			assert.Equal(t, test.Output, xml)
+		})
+	}
+}
+
 func BenchmarkProcessor_Run(b *testing.B) {
 	c := defaultConfig()
 	target := "xml"
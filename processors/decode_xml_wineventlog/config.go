@@ -20,6 +20,8 @@ package decode_xml_wineventlog
 type config struct {
 	Field         string `config:"field" validate:"required"`
 	Target        string `config:"target_field"`
+	TargetArray   string `config:"target_array"`
+	KeepEventData bool   `config:"keep_event_data"`
 	OverwriteKeys bool   `config:"overwrite_keys"`
 	MapECSFields  bool   `config:"map_ecs_fields"`
 	IgnoreMissing bool   `config:"ignore_missing"`
@@ -32,5 +34,6 @@ func defaultConfig() config {
 		OverwriteKeys: true,
 		MapECSFields:  true,
 		Target:        "winlog",
+		KeepEventData: true,
 	}
 }
@@ -21,7 +21,6 @@ package decode_xml_wineventlog
 
 import (
 	"github.com/njcx/libbeat_v8/sys/winevent"
-	"github.com/elastic/elastic-agent-libs/mapstr"
 )
 
 type nonWinDecoder struct{}
@@ -30,12 +29,11 @@ func newDecoder() decoder {
 	return nonWinDecoder{}
 }
 
-func (nonWinDecoder) decode(data []byte) (mapstr.M, mapstr.M, error) {
+func (nonWinDecoder) decode(data []byte) (winevent.Event, error) {
 	evt, err := winevent.UnmarshalXML(data)
 	if err != nil {
-		return nil, nil, err
+		return evt, err
 	}
 	winevent.EnrichRawValuesWithNames(nil, &evt)
-	win, ecs := fields(evt)
-	return win, ecs, nil
+	return evt, nil
 }
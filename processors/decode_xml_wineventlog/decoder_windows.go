@@ -25,7 +25,6 @@ import (
 	"github.com/njcx/libbeat_v8/sys/winevent"
 	"github.com/njcx/libbeat_v8/sys/wineventlog"
 	"github.com/elastic/elastic-agent-libs/logp"
-	"github.com/elastic/elastic-agent-libs/mapstr"
 )
 
 type winDecoder struct {
@@ -41,15 +40,14 @@ func newDecoder() decoder {
 	}
 }
 
-func (dec *winDecoder) decode(data []byte) (mapstr.M, mapstr.M, error) {
+func (dec *winDecoder) decode(data []byte) (winevent.Event, error) {
 	evt, err := winevent.UnmarshalXML(data)
 	if err != nil {
-		return nil, nil, err
+		return evt, err
 	}
 	md := dec.cache.getPublisherMetadata(evt.Provider.Name)
 	winevent.EnrichRawValuesWithNames(md, &evt)
-	win, ecs := fields(evt)
-	return win, ecs, nil
+	return evt, nil
 }
 
 type metadataCache struct {
@@ -28,6 +28,7 @@ import (
 	"github.com/njcx/libbeat_v8/processors"
 	"github.com/njcx/libbeat_v8/processors/checks"
 	jsprocessor "github.com/njcx/libbeat_v8/processors/script/javascript/module/processor"
+	"github.com/njcx/libbeat_v8/sys"
 	"github.com/njcx/libbeat_v8/sys/winevent"
 	conf "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
@@ -49,6 +50,7 @@ func init() {
 			checks.RequireFields("field", "target_field"),
 			checks.AllowedFields(
 				"field", "target_field",
+				"target_array", "keep_event_data",
 				"overwrite_keys", "map_ecs_fields",
 				"ignore_missing", "ignore_failure",
 				"when",
@@ -64,7 +66,7 @@ type processor struct {
 }
 
 type decoder interface {
-	decode(data []byte) (win, ecs mapstr.M, err error)
+	decode(data []byte) (winevent.Event, error)
 }
 
 // New constructs a new decode_xml processor.
@@ -111,11 +113,21 @@ func (p *processor) run(event *beat.Event) error {
 		return errFieldIsNotString
 	}
 
-	win, ecs, err := p.decoder.decode([]byte(text))
+	evt, err := p.decoder.decode([]byte(text))
 	if err != nil {
 		return fmt.Errorf("error decoding XML field: %w", err)
 	}
 
+	win, ecs := fields(evt)
+	if p.TargetArray != "" {
+		if values := eventDataValues(evt); len(values) > 0 {
+			_, _ = win.Put(p.TargetArray, values)
+		}
+		if !p.KeepEventData {
+			_ = win.Delete("event_data")
+		}
+	}
+
 	if p.Target != "" {
 		if _, err = event.PutValue(p.Target, win); err != nil {
 			return fmt.Errorf("failed to put value %v into field %q: %w", win, p.Target, err)
@@ -156,6 +168,20 @@ func fields(evt winevent.Event) (mapstr.M, mapstr.M) {
 	return win, ecs
 }
 
+// eventDataValues returns the values of all EventData elements in document
+// order, including duplicated and unnamed ones.
+func eventDataValues(evt winevent.Event) []string {
+	if len(evt.EventData.Pairs) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(evt.EventData.Pairs))
+	for _, kv := range evt.EventData.Pairs {
+		values = append(values, sys.RemoveWindowsLineEndings(kv.Value))
+	}
+	return values
+}
+
 func getValue(m mapstr.M, key string) interface{} {
 	v, _ := m.GetValue(key)
 	return v
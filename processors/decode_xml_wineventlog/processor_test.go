@@ -175,3 +175,61 @@ func TestProcessor(t *testing.T) {
 		assert.Equal(t, event.Fields, newEvent.Fields)
 	})
 }
+
+func TestProcessorTargetArray(t *testing.T) {
+	const message = "<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='Test-Provider'/>" +
+		"<EventID>1</EventID><Computer>vagrant</Computer></System><EventData><Data>first</Data><Data>second</Data>" +
+		"<Data Name='param'>a</Data><Data Name='param'>b</Data></EventData></Event>"
+
+	testCases := []struct {
+		description string
+		keep        bool
+		eventData   interface{}
+	}{
+		{
+			description: "keeps event_data",
+			keep:        true,
+			eventData: mapstr.M{
+				"param1": "first",
+				"param2": "second",
+				"param":  "a",
+			},
+		},
+		{
+			description: "drops event_data",
+			keep:        false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+
+			config := defaultConfig()
+			config.TargetArray = "event_data_values"
+			config.KeepEventData = test.keep
+
+			f, err := newProcessor(config)
+			require.NoError(t, err)
+
+			event := &beat.Event{
+				Fields: mapstr.M{"message": message},
+			}
+			newEvent, err := f.Run(event)
+			require.NoError(t, err)
+
+			values, err := newEvent.GetValue("winlog.event_data_values")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"first", "second", "a", "b"}, values)
+
+			eventData, err := newEvent.GetValue("winlog.event_data")
+			if test.eventData == nil {
+				assert.ErrorIs(t, err, mapstr.ErrKeyNotFound)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.eventData, eventData)
+			}
+		})
+	}
+}
@@ -39,6 +39,7 @@ type config struct {
 	OverwriteKeys bool       `config:"overwrite_keys"`
 	TrimValues    trimMode   `config:"trim_values"`
 	TrimChars     string     `config:"trim_chars"`
+	StrictConvert bool       `config:"strict_convert"`
 }
 
 var defaultConfig = config{
@@ -45,6 +45,10 @@ type Dissector struct {
 	raw     string
 	parser  *parser
 	trimmer trimmer
+
+	// strictConvert makes DissectConvert fail if a value can't be converted
+	// to its data type, instead of keeping the value as a string.
+	strictConvert bool
 }
 
 // Dissect takes the raw string and will use the defined tokenizer to return a map with the
@@ -90,7 +94,7 @@ func (d *Dissector) DissectConvert(s string) (MapConverted, error) {
 		return nil, errParsingFailure
 	}
 
-	return d.resolveConvert(s, positions), nil
+	return d.resolveConvert(s, positions)
 }
 
 // Raw returns the raw tokenizer used to generate the actual parser.
@@ -193,7 +197,7 @@ func (d *Dissector) resolve(s string, p positions) Map {
 	return m
 }
 
-func (d *Dissector) resolveConvert(s string, p positions) MapConverted {
+func (d *Dissector) resolveConvert(s string, p positions) (MapConverted, error) {
 	lookup := make(mapstr.M, len(p))
 	m := make(Map, len(p))
 	mc := make(MapConverted, len(p))
@@ -209,7 +213,14 @@ func (d *Dissector) resolveConvert(s string, p positions) MapConverted {
 			}
 			v, _ := m[key]
 			if f.DataType() != "" {
-				mc[key] = convertData(f.DataType(), v)
+				value, err := convertData(f.DataType(), v)
+				if err != nil {
+					if d.strictConvert {
+						return nil, fmt.Errorf("cannot convert value of key '%s' to %s: %w", key, f.DataType(), err)
+					}
+					value = v
+				}
+				mc[key] = value
 			} else {
 				mc[key] = v
 			}
@@ -219,7 +230,7 @@ func (d *Dissector) resolveConvert(s string, p positions) MapConverted {
 	for _, f := range d.parser.referenceFields {
 		delete(mc, f.Key())
 	}
-	return mc
+	return mc, nil
 }
 
 // New creates a new Dissector from a tokenized string.
@@ -274,12 +285,10 @@ func transformType(typ dataType, value string) (interface{}, error) {
 	}
 }
 
-func convertData(typ string, b string) interface{} {
-	if dt, ok := dataTypeNames[typ]; ok {
-		value, err := transformType(dt, b)
-		if err == nil {
-			return value
-		}
+func convertData(typ string, b string) (interface{}, error) {
+	dt, ok := dataTypeNames[typ]
+	if !ok {
+		return nil, errInvalidDatatype
 	}
-	return b
+	return transformType(dt, b)
 }
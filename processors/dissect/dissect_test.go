@@ -39,6 +39,7 @@ func TestDissectConversion(t *testing.T) {
 		Tok      string
 		Msg      string
 		Expected map[string]interface{}
+		Strict   bool
 		Fail     bool
 	}{
 		{
@@ -87,6 +88,33 @@ func TestDissectConversion(t *testing.T) {
 			},
 			Fail: false,
 		},
+		{
+			Name: "Convert with short type names",
+			Tok:  "bytes=%{bytes|int} ok=%{ok|bool}",
+			Msg:  "bytes=1024 ok=false",
+			Expected: map[string]interface{}{
+				"bytes": int32(1024),
+				"ok":    false,
+			},
+			Fail: false,
+		},
+		{
+			Name: "Keep value that can't be converted as string",
+			Tok:  "id=%{id|integer} msg=\"%{message}\"",
+			Msg:  "id=abc msg=\"Not a number\"}",
+			Expected: map[string]interface{}{
+				"id":      "abc",
+				"message": "Not a number",
+			},
+			Fail: false,
+		},
+		{
+			Name:   "Fail to convert value with strict conversion",
+			Tok:    "id=%{id|integer} msg=\"%{message}\"",
+			Msg:    "id=abc msg=\"Not a number\"}",
+			Strict: true,
+			Fail:   true,
+		},
 	}
 
 	for _, test := range tests {
@@ -95,6 +123,7 @@ func TestDissectConversion(t *testing.T) {
 			if !assert.NoError(t, err) {
 				return
 			}
+			d.strictConvert = test.Strict
 
 			if test.Fail {
 				_, err := d.DissectConvert(test.Msg)
@@ -61,11 +61,13 @@ const (
 
 var dataTypeNames = map[string]dataType{
 	"integer": Integer,
+	"int":     Integer,
 	"long":    Long,
 	"float":   Float,
 	"double":  Double,
 	"string":  String,
 	"boolean": Boolean,
+	"bool":    Boolean,
 	"ip":      IP,
 }
 
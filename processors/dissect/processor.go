@@ -54,6 +54,7 @@ func NewProcessor(c *cfg.C) (beat.Processor, error) {
 			return nil, err
 		}
 	}
+	config.Tokenizer.strictConvert = config.StrictConvert
 	p := &processor{config: config}
 
 	return p, nil
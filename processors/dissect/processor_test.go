@@ -19,9 +19,11 @@ package dissect
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/njcx/libbeat_v8/beat"
 	conf "github.com/elastic/elastic-agent-libs/config"
@@ -453,6 +455,12 @@ func TestProcessorConvert(t *testing.T) {
 			fields: mapstr.M{"message": "userid=7736"},
 			values: map[string]interface{}{"dissect.user_id": int32(7736)},
 		},
+		{
+			name:   "extract int and float",
+			c:      map[string]interface{}{"tokenizer": "%{bytes|int} %{latency|float}"},
+			fields: mapstr.M{"message": "512 0.25"},
+			values: map[string]interface{}{"dissect.bytes": int32(512), "dissect.latency": float32(0.25)},
+		},
 	}
 
 	for _, test := range tests {
@@ -484,3 +492,50 @@ func TestProcessorConvert(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessorConvertFallback(t *testing.T) {
+	c, err := conf.NewConfigFrom(map[string]interface{}{
+		"tokenizer": "%{bytes|int} %{latency|float}",
+	})
+	require.NoError(t, err)
+
+	processor, err := NewProcessor(c)
+	require.NoError(t, err)
+
+	e := beat.Event{Fields: mapstr.M{"message": "512 slow"}}
+	newEvent, err := processor.Run(&e)
+	require.NoError(t, err)
+
+	assert.Equal(t, mapstr.M{"bytes": int32(512), "latency": "slow"}, newEvent.Fields["dissect"])
+}
+
+func TestProcessorConvertFailure(t *testing.T) {
+	for _, ignoreFailure := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ignore_failure=%v", ignoreFailure), func(t *testing.T) {
+			c, err := conf.NewConfigFrom(map[string]interface{}{
+				"tokenizer":      "%{bytes|int} %{latency|float}",
+				"ignore_failure": ignoreFailure,
+				"strict_convert": true,
+			})
+			require.NoError(t, err)
+
+			processor, err := NewProcessor(c)
+			require.NoError(t, err)
+
+			e := beat.Event{Fields: mapstr.M{"message": "512 slow"}}
+			newEvent, err := processor.Run(&e)
+			if ignoreFailure {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			flags, err := newEvent.GetValue(beat.FlagField)
+			require.NoError(t, err)
+			assert.Contains(t, flags, flagParsingError)
+
+			_, err = newEvent.GetValue("dissect")
+			assert.ErrorIs(t, err, mapstr.ErrKeyNotFound)
+		})
+	}
+}
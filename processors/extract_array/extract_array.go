@@ -35,6 +35,7 @@ import (
 type config struct {
 	Field         string   `config:"field"`
 	Mappings      mapstr.M `config:"mappings"`
+	Defaults      mapstr.M `config:"defaults"`
 	IgnoreMissing bool     `config:"ignore_missing"`
 	OmitEmpty     bool     `config:"omit_empty"`
 	OverwriteKeys bool     `config:"overwrite_keys"`
@@ -44,6 +45,10 @@ type config struct {
 type fieldMapping struct {
 	from int
 	to   string
+
+	// def is the value used when the array has no element at index from.
+	def        interface{}
+	hasDefault bool
 }
 
 type extractArrayProcessor struct {
@@ -62,7 +67,7 @@ func init() {
 	processors.RegisterPlugin("extract_array",
 		checks.ConfigChecked(New,
 			checks.RequireFields("field", "mappings"),
-			checks.AllowedFields("field", "mappings", "ignore_missing", "overwrite_keys", "fail_on_error", "when", "omit_empty")))
+			checks.AllowedFields("field", "mappings", "ignore_missing", "overwrite_keys", "fail_on_error", "when", "omit_empty", "defaults")))
 
 	jsprocessor.RegisterPlugin("ExtractArray", New)
 }
@@ -75,12 +80,26 @@ func (f *extractArrayProcessor) Unpack(from *conf.C) error {
 		return fmt.Errorf("failed to unpack the extract_array configuration: %w", err)
 	}
 	f.config = tmp
+	defaults := f.Defaults.Flatten()
 	for field, column := range f.Mappings.Flatten() {
 		colIdx, ok := common.TryToInt(column)
 		if !ok || colIdx < 0 {
 			return fmt.Errorf("bad extract_array mapping for field %s: %+v is not a positive integer", field, column)
 		}
-		f.mappings = append(f.mappings, fieldMapping{from: colIdx, to: field})
+		mapping := fieldMapping{from: colIdx, to: field}
+		if def, found := defaults[field]; found {
+			mapping.def, mapping.hasDefault = def, true
+			delete(defaults, field)
+		}
+		f.mappings = append(f.mappings, mapping)
+	}
+	if len(defaults) > 0 {
+		unmapped := make([]string, 0, len(defaults))
+		for field := range defaults {
+			unmapped = append(unmapped, field)
+		}
+		sort.Strings(unmapped)
+		return fmt.Errorf("bad extract_array defaults: fields %v are not mapped", unmapped)
 	}
 	sort.Slice(f.mappings, func(i, j int) bool {
 		return f.mappings[i].from < f.mappings[j].from
@@ -137,18 +156,24 @@ func (f *extractArrayProcessor) Run(event *beat.Event) (*beat.Event, error) {
 
 	n := array.Len()
 	for _, mapping := range f.mappings {
+		var value interface{}
 		if mapping.from >= n {
-			if !f.config.FailOnError {
+			if !mapping.hasDefault {
+				if !f.config.FailOnError {
+					continue
+				}
+				return saved, fmt.Errorf("index %d exceeds length of %d when processing mapping for field %s", mapping.from, n, mapping.to)
+			}
+			value = mapping.def
+		} else {
+			cell := array.Index(mapping.from)
+			// checking for CanInterface() here is done to prevent .Interface() from
+			// panicking, but it can only happen when value points to a private
+			// field inside a struct.
+			if !cell.IsValid() || !cell.CanInterface() || (f.config.OmitEmpty && isEmpty(cell)) {
 				continue
 			}
-			return saved, fmt.Errorf("index %d exceeds length of %d when processing mapping for field %s", mapping.from, n, mapping.to)
-		}
-		cell := array.Index(mapping.from)
-		// checking for CanInterface() here is done to prevent .Interface() from
-		// panicking, but it can only happen when value points to a private
-		// field inside a struct.
-		if !cell.IsValid() || !cell.CanInterface() || (f.config.OmitEmpty && isEmpty(cell)) {
-			continue
+			value = cell.Interface()
 		}
 		if !f.config.OverwriteKeys {
 			if _, err = event.GetValue(mapping.to); err == nil {
@@ -158,7 +183,7 @@ func (f *extractArrayProcessor) Run(event *beat.Event) (*beat.Event, error) {
 				return saved, fmt.Errorf("target field %s already has a value. Set the overwrite_keys flag or drop/rename the field first", mapping.to)
 			}
 		}
-		if _, err = event.PutValue(mapping.to, clone(cell.Interface())); err != nil {
+		if _, err = event.PutValue(mapping.to, clone(value)); err != nil {
 			if !f.config.FailOnError {
 				continue
 			}
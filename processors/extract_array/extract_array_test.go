@@ -42,6 +42,19 @@ func TestExtractArrayProcessor_String(t *testing.T) {
 	assert.Equal(t, "extract_array={field=csv, mappings=[{0 source.ip} {2 network.transport} {99 destination.ip}]}", p.String())
 }
 
+func TestExtractArrayProcessor_UnmappedDefault(t *testing.T) {
+	_, err := New(conf.MustNewConfigFrom(mapstr.M{
+		"field": "csv",
+		"mappings": mapstr.M{
+			"source.ip": 0,
+		},
+		"defaults": mapstr.M{
+			"destination.ip": "-",
+		},
+	}))
+	assert.EqualError(t, err, "bad extract_array defaults: fields [destination.ip] are not mapped")
+}
+
 func TestExtractArrayProcessor_Run(t *testing.T) {
 	tests := map[string]struct {
 		config   mapstr.M
@@ -218,6 +231,91 @@ func TestExtractArrayProcessor_Run(t *testing.T) {
 			},
 		},
 
+		"nested targets": {
+			config: mapstr.M{
+				"field": "array",
+				"mappings": mapstr.M{
+					"source": mapstr.M{
+						"ip":   0,
+						"port": 1,
+					},
+					"destination.ip": 2,
+				},
+			},
+			input: beat.Event{
+				Fields: mapstr.M{
+					"array": []interface{}{"10.0.0.1", 5000, "10.0.0.2"},
+				},
+			},
+			expected: beat.Event{
+				Fields: mapstr.M{
+					"array": []interface{}{"10.0.0.1", 5000, "10.0.0.2"},
+					"source": mapstr.M{
+						"ip":   "10.0.0.1",
+						"port": 5000,
+					},
+					"destination": mapstr.M{
+						"ip": "10.0.0.2",
+					},
+				},
+			},
+		},
+
+		"defaults": {
+			config: mapstr.M{
+				"field": "array",
+				"mappings": mapstr.M{
+					"a":   0,
+					"b":   1,
+					"c.d": 2,
+				},
+				"defaults": mapstr.M{
+					"b":   "unused",
+					"c.d": "none",
+				},
+			},
+			input: beat.Event{
+				Fields: mapstr.M{
+					"array": []interface{}{"zero", "one"},
+				},
+			},
+			expected: beat.Event{
+				Fields: mapstr.M{
+					"array": []interface{}{"zero", "one"},
+					"a":     "zero",
+					"b":     "one",
+					"c.d":   "none",
+				},
+			},
+		},
+
+		"omit_empty with defaults": {
+			config: mapstr.M{
+				"field": "array",
+				"mappings": mapstr.M{
+					"a": 0,
+					"b": 1,
+					"c": 2,
+				},
+				"defaults": mapstr.M{
+					"c": "missing",
+				},
+				"omit_empty": true,
+			},
+			input: beat.Event{
+				Fields: mapstr.M{
+					"array": []interface{}{"", "one"},
+				},
+			},
+			expected: beat.Event{
+				Fields: mapstr.M{
+					"array": []interface{}{"", "one"},
+					"b":     "one",
+					"c":     "missing",
+				},
+			},
+		},
+
 		"nil values": {
 			config: mapstr.M{
 				"field": "array",
@@ -0,0 +1,161 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package processors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// failureOptions lists the options handled for all registered processors.
+// They are removed from the configuration before it is passed to the
+// processor's constructor.
+var failureOptions = []string{"on_failure_tags", "on_failure_error_message"}
+
+type failureConfig struct {
+	Tags         []string `config:"on_failure_tags"`
+	ErrorMessage bool     `config:"on_failure_error_message"`
+}
+
+// FailureProcessor runs a processor and handles its errors by tagging the
+// event instead of reporting them. Processing continues with the next
+// processor.
+type FailureProcessor struct {
+	p            beat.Processor
+	tags         []string
+	errorMessage bool
+}
+
+// NewFailureHandler returns a constructor that configures failure handling
+// for the processors created by constructor if the configuration contains
+// `on_failure_tags` or `on_failure_error_message`.
+func NewFailureHandler(constructor Constructor) Constructor {
+	return func(cfg *config.C) (beat.Processor, error) {
+		if !hasFailureOptions(cfg) {
+			return constructor(cfg)
+		}
+
+		var fc failureConfig
+		if err := cfg.Unpack(&fc); err != nil {
+			return nil, fmt.Errorf("failed to unpack failure options: %w", err)
+		}
+
+		procCfg, err := withoutFields(cfg, failureOptions...)
+		if err != nil {
+			return nil, err
+		}
+		p, err := constructor(procCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return &FailureProcessor{
+			p:            p,
+			tags:         fc.Tags,
+			errorMessage: fc.ErrorMessage,
+		}, nil
+	}
+}
+
+func hasFailureOptions(cfg *config.C) bool {
+	for _, name := range failureOptions {
+		if cfg.HasField(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutFields returns a copy of cfg without the given fields, leaving cfg
+// unmodified so it can be used to construct processors again.
+func withoutFields(cfg *config.C, fields ...string) (*config.C, error) {
+	c := config.NewConfig()
+	if err := c.Merge(cfg); err != nil {
+		return nil, err
+	}
+	for _, name := range fields {
+		if _, err := c.Remove(name, -1); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Run executes the processor. If it fails, the configured tags are added to
+// the event and, if enabled, the error is stored in `error.message` unless
+// the processor set it already.
+func (p *FailureProcessor) Run(event *beat.Event) (*beat.Event, error) {
+	out, err := p.p.Run(event)
+	if err == nil || errors.Is(err, ErrClosed) {
+		return out, err
+	}
+	if out == nil {
+		out = event
+	}
+	return out, p.markFailed(out, err)
+}
+
+// RunMulti is like Run, allowing the processor to emit multiple events. On
+// failure, all returned events are marked.
+func (p *FailureProcessor) RunMulti(event *beat.Event) ([]*beat.Event, error) {
+	out, err := beat.RunMulti(p.p, event)
+	if err == nil || errors.Is(err, ErrClosed) {
+		return out, err
+	}
+	if len(out) == 0 {
+		out = []*beat.Event{event}
+	}
+	for _, e := range out {
+		if markErr := p.markFailed(e, err); markErr != nil {
+			return out, markErr
+		}
+	}
+	return out, nil
+}
+
+// markFailed adds the configured tags and error message for err to event.
+func (p *FailureProcessor) markFailed(event *beat.Event, err error) error {
+	if event.Fields == nil {
+		event.Fields = mapstr.M{}
+	}
+
+	if len(p.tags) > 0 {
+		if tagErr := mapstr.AddTags(event.Fields, p.tags); tagErr != nil {
+			return fmt.Errorf("failed to add failure tags after %w", err)
+		}
+	}
+	if p.errorMessage {
+		if has, _ := event.Fields.HasKey("error.message"); !has {
+			_, _ = event.PutValue("error.message", err.Error())
+		}
+	}
+	return nil
+}
+
+// Close closes the wrapped processor.
+func (p *FailureProcessor) Close() error {
+	return Close(p.p)
+}
+
+func (p *FailureProcessor) String() string {
+	return fmt.Sprintf("%v, on_failure_tags=%v", p.p.String(), p.tags)
+}
@@ -0,0 +1,132 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package processors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type failingProcessor struct {
+	fail bool
+}
+
+func newFailingProcessor(cfg *config.C) (beat.Processor, error) {
+	// Reject unknown options like most processors do.
+	for _, field := range cfg.GetFields() {
+		if field != "fail" && field != "when" {
+			return nil, errors.New("unexpected " + field + " option")
+		}
+	}
+	var c struct {
+		Fail bool `config:"fail"`
+	}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	return &failingProcessor{fail: c.Fail}, nil
+}
+
+func (p *failingProcessor) Run(event *beat.Event) (*beat.Event, error) {
+	if p.fail {
+		return event, errors.New("processing failed")
+	}
+	_, _ = event.PutValue("processed", true)
+	return event, nil
+}
+
+func (p *failingProcessor) String() string { return "failing" }
+
+func TestFailureHandler(t *testing.T) {
+	tests := map[string]struct {
+		config   mapstr.M
+		fields   mapstr.M
+		wantErr  bool
+		expected mapstr.M
+	}{
+		"no failure options": {
+			config:   mapstr.M{"fail": true},
+			fields:   mapstr.M{},
+			wantErr:  true,
+			expected: mapstr.M{},
+		},
+		"success is not tagged": {
+			config:   mapstr.M{"on_failure_tags": []string{"failed"}},
+			fields:   mapstr.M{},
+			expected: mapstr.M{"processed": true},
+		},
+		"failure adds tags": {
+			config:   mapstr.M{"fail": true, "on_failure_tags": []string{"failed", "parse"}},
+			fields:   mapstr.M{"tags": []string{"existing"}},
+			expected: mapstr.M{"tags": []string{"existing", "failed", "parse"}},
+		},
+		"failure adds error message": {
+			config: mapstr.M{"fail": true, "on_failure_tags": []string{"failed"}, "on_failure_error_message": true},
+			fields: mapstr.M{},
+			expected: mapstr.M{
+				"tags":  []string{"failed"},
+				"error": mapstr.M{"message": "processing failed"},
+			},
+		},
+		"existing error message is kept": {
+			config: mapstr.M{"fail": true, "on_failure_error_message": true},
+			fields: mapstr.M{"error": mapstr.M{"message": "detailed error"}},
+			expected: mapstr.M{
+				"error": mapstr.M{"message": "detailed error"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ns := NewNamespace()
+			require.NoError(t, ns.Register("failing", newFailingProcessor))
+
+			cfg := config.MustNewConfigFrom(mapstr.M{"failing": test.config})
+			p, err := ns.Plugin()(cfg)
+			require.NoError(t, err)
+
+			event, err := p.Run(&beat.Event{Fields: test.fields})
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, event.Fields)
+		})
+	}
+}
+
+func TestFailureHandlerKeepsConfig(t *testing.T) {
+	cfg := config.MustNewConfigFrom(mapstr.M{"fail": true, "on_failure_tags": []string{"failed"}})
+
+	constructor := NewFailureHandler(newFailingProcessor)
+	for i := 0; i < 2; i++ {
+		p, err := constructor(cfg)
+		require.NoError(t, err)
+		assert.IsType(t, &FailureProcessor{}, p)
+	}
+	assert.True(t, cfg.HasField("on_failure_tags"))
+}
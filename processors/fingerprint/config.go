@@ -37,6 +37,20 @@ func defaultConfig() Config {
 	}
 }
 
+// Validate checks that the fields, hash method and encoding are set.
+func (c *Config) Validate() error {
+	if len(c.Fields) == 0 {
+		return errNoFields
+	}
+	if c.Method.Hash == nil {
+		return makeErrUnknownMethod(c.Method.Name)
+	}
+	if c.Encoding.Encode == nil {
+		return makeErrUnknownEncoding(c.Encoding.Name)
+	}
+	return nil
+}
+
 func (c *Config) MarshalJSON() ([]byte, error) {
 	type Alias Config
 	return json.Marshal(&struct {
@@ -37,6 +37,7 @@ func init() {
 		{Name: "hex", Encode: hex.EncodeToString},
 		{Name: "base32", Encode: base32.StdEncoding.EncodeToString},
 		{Name: "base64", Encode: base64.StdEncoding.EncodeToString},
+		{Name: "base64url", Encode: base64.RawURLEncoding.EncodeToString},
 	} {
 		encodings[e.Name] = e
 	}
@@ -54,3 +55,10 @@ func (e *namedEncodingMethod) Unpack(str string) error {
 	*e = m
 	return nil
 }
+
+// Encoding returns the encoding function registered under the given name.
+// Names are case insensitive.
+func Encoding(name string) (func([]byte) string, bool) {
+	m, found := encodings[strings.ToLower(name)]
+	return m.Encode, found
+}
@@ -70,6 +70,9 @@ func makeErrMissingField(field string, cause error) errMissingField {
 func (e errMissingField) Error() string {
 	return fmt.Sprintf("failed to find field [%v] in event: %v", e.field, e.cause)
 }
+func (e errMissingField) Unwrap() error {
+	return e.cause
+}
 
 func makeErrNonScalarField(field string) errNonScalarField {
 	return errNonScalarField{field}
@@ -71,7 +71,7 @@ func New(cfg *config.C) (beat.Processor, error) {
 func (p *fingerprint) Run(event *beat.Event) (*beat.Event, error) {
 	hashFn := p.hash()
 
-	if err := p.writeFields(hashFn, event); err != nil {
+	if err := WriteFields(hashFn, event, p.fields, p.config.IgnoreMissing); err != nil {
 		return nil, makeErrComputeFingerprint(err)
 	}
 
@@ -89,11 +89,15 @@ func (p *fingerprint) String() string {
 	return procName + "=" + string(json)
 }
 
-func (p *fingerprint) writeFields(to io.Writer, event *beat.Event) error {
-	for _, k := range p.fields {
+// WriteFields writes the names and values of the given fields of the event
+// in the format hashed by the fingerprint processor. The fields must be
+// sorted to always get the same output for a similar set of fields. Missing
+// fields are skipped if ignoreMissing is set.
+func WriteFields(to io.Writer, event *beat.Event, fields []string, ignoreMissing bool) error {
+	for _, k := range fields {
 		v, err := event.GetValue(k)
 		if err != nil {
-			if p.config.IgnoreMissing {
+			if ignoreMissing {
 				continue
 			}
 			return makeErrMissingField(k, err)
@@ -19,6 +19,8 @@ package fingerprint
 
 import (
 	"fmt"
+	"hash"
+	"io"
 	"math/rand"
 	"strconv"
 	"testing"
@@ -121,12 +123,13 @@ func TestHashMethods(t *testing.T) {
 	tests := map[string]struct {
 		expected string
 	}{
-		"md5":    {"4c45df4792f3ef850c928ec5f5232538"},
-		"sha1":   {"22f76427d626516d3f7a05785165b99617683b22"},
-		"sha256": {"1208288932231e313b369bae587ff574cd3016a408e52e7128d7bee752674003"},
-		"sha384": {"295adfe0bc03908948e4b0b6a54f441767867e426dda590430459c8a147fbba242a38cba282adee78335b9e08877b86c"},
-		"sha512": {"f50ad51b63c92a0ed0c910527119b81806f3110f0afaa1dcb93506a78371ea761e50c0fc09b08c441d832dd2da1b45e5d8361adfb240e1fffc2695122a23e183"},
-		"xxhash": {"37bc50682fba6686"},
+		"md5":     {"4c45df4792f3ef850c928ec5f5232538"},
+		"sha1":    {"22f76427d626516d3f7a05785165b99617683b22"},
+		"sha256":  {"1208288932231e313b369bae587ff574cd3016a408e52e7128d7bee752674003"},
+		"sha384":  {"295adfe0bc03908948e4b0b6a54f441767867e426dda590430459c8a147fbba242a38cba282adee78335b9e08877b86c"},
+		"sha512":  {"f50ad51b63c92a0ed0c910527119b81806f3110f0afaa1dcb93506a78371ea761e50c0fc09b08c441d832dd2da1b45e5d8361adfb240e1fffc2695122a23e183"},
+		"xxhash":  {"37bc50682fba6686"},
+		"murmur3": {"c6915bc6"},
 	}
 
 	for method, test := range tests {
@@ -155,6 +158,29 @@ func TestHashMethods(t *testing.T) {
 	}
 }
 
+func TestMurmur3(t *testing.T) {
+	tests := map[string]uint32{
+		"":      0,
+		"hello": 0x248bfa47,
+		"The quick brown fox jumps over the lazy dog": 0x2e4ff723,
+	}
+
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			h := newMurmur3().(hash.Hash32)
+			_, _ = io.WriteString(h, input)
+			assert.Equal(t, expected, h.Sum32())
+
+			// Writing byte by byte must give the same hash.
+			h.Reset()
+			for i := 0; i < len(input); i++ {
+				_, _ = h.Write([]byte{input[i]})
+			}
+			assert.Equal(t, expected, h.Sum32())
+		})
+	}
+}
+
 func TestSourceFields(t *testing.T) {
 	testFields := mapstr.M{
 		"field1": "foo",
@@ -212,9 +238,10 @@ func TestEncoding(t *testing.T) {
 	tests := map[string]struct {
 		expectedFingerprint string
 	}{
-		"hex":    {"8934ca639027aab1ee9f3944d4d6bd1e"},
-		"base32": {"RE2MUY4QE6VLD3U7HFCNJVV5DY======"},
-		"base64": {"iTTKY5AnqrHunzlE1Na9Hg=="},
+		"hex":       {"8934ca639027aab1ee9f3944d4d6bd1e"},
+		"base32":    {"RE2MUY4QE6VLD3U7HFCNJVV5DY======"},
+		"base64":    {"iTTKY5AnqrHunzlE1Na9Hg=="},
+		"base64url": {"iTTKY5AnqrHunzlE1Na9Hg"},
 	}
 
 	for encoding, test := range tests {
@@ -44,6 +44,7 @@ func init() {
 		{Name: "sha384", Hash: sha512.New384},
 		{Name: "sha512", Hash: sha512.New},
 		{Name: "xxhash", Hash: newXxHash},
+		{Name: "murmur3", Hash: newMurmur3},
 	} {
 		hashes[h.Name] = h
 	}
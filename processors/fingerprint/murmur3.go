@@ -0,0 +1,117 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package fingerprint
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	murmur3C1 = 0xcc9e2d51
+	murmur3C2 = 0x1b873593
+)
+
+// murmur3 implements the 32-bit x86 variant of MurmurHash3 with a zero seed.
+type murmur3 struct {
+	h    uint32
+	tail []byte
+	n    int
+}
+
+var _ hash.Hash32 = (*murmur3)(nil)
+
+func newMurmur3() hash.Hash {
+	return &murmur3{tail: make([]byte, 0, 4)}
+}
+
+func (m *murmur3) Write(p []byte) (int, error) {
+	n := len(p)
+	m.n += n
+
+	if len(m.tail) > 0 {
+		missing := 4 - len(m.tail)
+		if len(p) < missing {
+			m.tail = append(m.tail, p...)
+			return n, nil
+		}
+		m.tail = append(m.tail, p[:missing]...)
+		m.block(binary.LittleEndian.Uint32(m.tail))
+		m.tail = m.tail[:0]
+		p = p[missing:]
+	}
+
+	for ; len(p) >= 4; p = p[4:] {
+		m.block(binary.LittleEndian.Uint32(p))
+	}
+	m.tail = append(m.tail, p...)
+	return n, nil
+}
+
+func (m *murmur3) block(k uint32) {
+	k *= murmur3C1
+	k = bits.RotateLeft32(k, 15)
+	k *= murmur3C2
+
+	m.h ^= k
+	m.h = bits.RotateLeft32(m.h, 13)
+	m.h = m.h*5 + 0xe6546b64
+}
+
+func (m *murmur3) Sum32() uint32 {
+	h := m.h
+
+	var k uint32
+	switch len(m.tail) {
+	case 3:
+		k ^= uint32(m.tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(m.tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(m.tail[0])
+		k *= murmur3C1
+		k = bits.RotateLeft32(k, 15)
+		k *= murmur3C2
+		h ^= k
+	}
+
+	h ^= uint32(m.n)
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+func (m *murmur3) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint32(b, m.Sum32())
+}
+
+func (m *murmur3) Reset() {
+	m.h = 0
+	m.tail = m.tail[:0]
+	m.n = 0
+}
+
+func (m *murmur3) Size() int { return 4 }
+
+func (m *murmur3) BlockSize() int { return 4 }
@@ -20,6 +20,8 @@ package move_fields
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 
 	"github.com/njcx/libbeat_v8/beat"
 	"github.com/njcx/libbeat_v8/processors"
@@ -41,6 +43,19 @@ type moveFieldsConfig struct {
 	From          string   `config:"from"`
 	To            string   `config:"to"`
 	IgnoreMissing bool     `config:"ignore_missing"`
+
+	// Pattern selects the keys to move by regular expression. When
+	// Replacement is set the matched part of each key is rewritten with it,
+	// so capture groups can be used to rename the keys.
+	Pattern     *regexp.Regexp `config:"pattern"`
+	Replacement *string        `config:"replacement"`
+}
+
+// reservedKeys are never moved from the event root unless they are
+// explicitly listed in fields.
+var reservedKeys = map[string]struct{}{
+	"@metadata":  {},
+	"@timestamp": {},
 }
 
 type moveFields struct {
@@ -67,14 +82,23 @@ func (u moveFields) Run(event *beat.Event) (*beat.Event, error) {
 	if len(keys) == 0 {
 		keys = make([]string, 0, len(parent))
 		for k := range parent {
+			if _, ok := reservedKeys[k]; ok && u.config.From == "" {
+				continue
+			}
 			keys = append(keys, k)
 		}
+		// Sort the keys so that conflicting destinations are always
+		// resolved in the same order.
+		sort.Strings(keys)
 	}
 
 	for _, k := range keys {
 		if _, ok := u.excludeMap[k]; ok {
 			continue
 		}
+		if u.config.Pattern != nil && !u.config.Pattern.MatchString(k) {
+			continue
+		}
 		v, err := parent.GetValue(k)
 		if u.config.IgnoreMissing && errors.Is(err, mapstr.ErrKeyNotFound) {
 			continue
@@ -85,7 +109,7 @@ func (u moveFields) Run(event *beat.Event) (*beat.Event, error) {
 		if err = parent.Delete(k); err != nil {
 			return nil, fmt.Errorf("move field delete field from parent sub key: %s, failed: %w", k, err)
 		}
-		newKey := fmt.Sprintf("%s%s", u.config.To, k)
+		newKey := fmt.Sprintf("%s%s", u.config.To, u.rename(k))
 		if _, err = root.Put(newKey, v); err != nil {
 			return nil, fmt.Errorf("move field write field to sub key: %s, new key: %s, failed: %w", k, newKey, err)
 		}
@@ -95,6 +119,13 @@ func (u moveFields) Run(event *beat.Event) (*beat.Event, error) {
 	return event, nil
 }
 
+func (u moveFields) rename(k string) string {
+	if u.config.Pattern == nil || u.config.Replacement == nil {
+		return k
+	}
+	return u.config.Pattern.ReplaceAllString(k, *u.config.Replacement)
+}
+
 func (u moveFields) String() string {
 	return "move_fields"
 }
@@ -105,6 +136,9 @@ func NewMoveFields(c *config.C) (beat.Processor, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to unpack move fields config: %w", err)
 	}
+	if fc.Replacement != nil && fc.Pattern == nil {
+		return nil, errors.New("move fields replacement requires a pattern")
+	}
 
 	p := &moveFields{
 		config:     fc,
@@ -19,6 +19,7 @@ package move_fields
 
 import (
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/njcx/libbeat_v8/beat"
@@ -115,6 +116,54 @@ func TestMoveFields(t *testing.T) {
 				excludeMap: nil,
 			},
 		},
+		{
+			"move keys matching a pattern",
+			mapstr.M{"labels": mapstr.M{"app_name": "a", "app_version": 1, "team": "b"}},
+			mapstr.M{"labels": mapstr.M{"team": "b"}, "app": mapstr.M{"app_name": "a", "app_version": 1}},
+			&moveFields{
+				config: moveFieldsConfig{
+					From:    "labels",
+					To:      "app.",
+					Pattern: regexp.MustCompile(`^app_`),
+				},
+			},
+		},
+		{
+			"rename keys using capture groups",
+			mapstr.M{"labels": mapstr.M{"app_name": "a", "app_version": 1, "team": "b"}},
+			mapstr.M{"labels": mapstr.M{"team": "b"}, "app": mapstr.M{"name": "a", "version": 1}},
+			&moveFields{
+				config: moveFieldsConfig{
+					From:        "labels",
+					To:          "app.",
+					Pattern:     regexp.MustCompile(`^app_(.*)$`),
+					Replacement: ptr("$1"),
+				},
+			},
+		},
+		{
+			"reserved keys are not moved from the event root",
+			mapstr.M{"@timestamp": "now", "@metadata": mapstr.M{"a": 1}, "app_name": "a"},
+			mapstr.M{"@timestamp": "now", "@metadata": mapstr.M{"a": 1}, "app": mapstr.M{"name": "a"}},
+			&moveFields{
+				config: moveFieldsConfig{
+					To:          "app.",
+					Pattern:     regexp.MustCompile(`^@?(app_)?`),
+					Replacement: ptr(""),
+				},
+			},
+		},
+		{
+			"reserved keys are moved when listed",
+			mapstr.M{"@timestamp": "now", "other": 1},
+			mapstr.M{"orig": mapstr.M{"@timestamp": "now"}, "other": 1},
+			&moveFields{
+				config: moveFieldsConfig{
+					Fields: []string{"@timestamp"},
+					To:     "orig.",
+				},
+			},
+		},
 	}
 
 	for idx, c := range cases {
@@ -132,3 +181,5 @@ func TestMoveFields(t *testing.T) {
 		})
 	}
 }
+
+func ptr[T any](v T) *T { return &v }
@@ -45,7 +45,7 @@ func NewNamespace() *Namespace {
 }
 
 func (ns *Namespace) Register(name string, factory Constructor) error {
-	p := plugin{NewConditional(factory)}
+	p := plugin{NewConditional(NewFailureHandler(factory))}
 	names := strings.Split(name, ".")
 	if err := ns.add(names, p); err != nil {
 		return fmt.Errorf("plugin %s registration fail %w", name, err)
@@ -88,22 +88,9 @@ func (ns *Namespace) add(names []string, p pluginer) error {
 
 func (ns *Namespace) Plugin() Constructor {
 	return NewConditional(func(cfg *config.C) (beat.Processor, error) {
-		var section string
-		for _, name := range cfg.GetFields() {
-			if name == "when" { // TODO: remove check for "when" once fields are filtered
-				continue
-			}
-
-			if section != "" {
-				return nil, fmt.Errorf("too many lookup modules "+
-					"configured (%v, %v)", section, name)
-			}
-
-			section = name
-		}
-
-		if section == "" {
-			return nil, errors.New("no lookup module configured")
+		section, err := lookupSection(cfg)
+		if err != nil {
+			return nil, err
 		}
 
 		backend, found := ns.reg[section]
@@ -121,6 +108,69 @@ func (ns *Namespace) Plugin() Constructor {
 	})
 }
 
+// lookupSection returns the name of the single lookup module configured in
+// the namespace configuration cfg.
+func lookupSection(cfg *config.C) (string, error) {
+	var section string
+	for _, name := range cfg.GetFields() {
+		if name == "when" { // TODO: remove check for "when" once fields are filtered
+			continue
+		}
+
+		if section != "" {
+			return "", fmt.Errorf("too many lookup modules "+
+				"configured (%v, %v)", section, name)
+		}
+
+		section = name
+	}
+
+	if section == "" {
+		return "", errors.New("no lookup module configured")
+	}
+	return section, nil
+}
+
+// resolveName returns the full name of the processor configured by cfg for
+// the plugin p registered under name. If p is a namespace, the names of the
+// configured lookup modules are appended, joined by dots.
+func resolveName(name string, p pluginer, cfg *config.C) string {
+	ns, ok := p.(*Namespace)
+	if !ok {
+		return name
+	}
+
+	section, err := lookupSection(cfg)
+	if err != nil {
+		return name
+	}
+	backend, found := ns.reg[section]
+	if !found {
+		return name
+	}
+	sub, err := cfg.Child(section, -1)
+	if err != nil {
+		return name
+	}
+	return resolveName(name+"."+section, backend, sub)
+}
+
+// Names returns the names of all registered processors, including the
+// processors of nested namespaces prefixed with the namespace name.
+func (ns *Namespace) Names() []string {
+	var names []string
+	for name, p := range ns.reg {
+		if sub, ok := p.(*Namespace); ok {
+			for _, subName := range sub.Names() {
+				names = append(names, name+"."+subName)
+			}
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
 // Constructors returns all registered processor constructors and its names.
 func (ns *Namespace) Constructors() map[string]Constructor {
 	c := make(map[string]Constructor, len(ns.reg))
@@ -59,6 +59,27 @@ func TestNamespace(t *testing.T) {
 	}
 }
 
+func TestNamespaceResolveName(t *testing.T) {
+	ns := NewNamespace()
+	fatalError(t, ns.Register("abc.def.test", newTestFilterRule))
+	fatalError(t, ns.Register("test", newTestFilterRule))
+
+	cfg, err := config.NewConfigFrom(map[string]interface{}{
+		"def": map[string]interface{}{
+			"test": nil,
+		},
+		"when": map[string]interface{}{
+			"has_fields": []string{"a"},
+		},
+	})
+	fatalError(t, err)
+	assert.Equal(t, "abc.def.test", resolveName("abc", ns.reg["abc"], cfg))
+
+	cfg, err = config.NewConfigFrom(map[string]interface{}{})
+	fatalError(t, err)
+	assert.Equal(t, "test", resolveName("test", ns.reg["test"], cfg))
+}
+
 func TestNamespaceRegisterFail(t *testing.T) {
 	ns := NewNamespace()
 	err := ns.Register("test", newTestFilterRule)
@@ -108,6 +108,7 @@ func New(config PluginConfig) (*Processors, error) {
 		}
 
 		procs.AddProcessor(plugin)
+		markUsed(resolveName(actionName, gen, actionCfg))
 	}
 
 	if len(procs.List) > 0 {
@@ -176,6 +177,30 @@ func (procs *Processors) Run(event *beat.Event) (*beat.Event, error) {
 	return event, nil
 }
 
+// RunMulti executes all processors serially like Run, but allows processors
+// implementing beat.MultiEventProcessor to emit multiple events. Each event
+// is passed on to the remaining processors. If a processor fails, the events
+// processed so far are returned with the error.
+func (procs *Processors) RunMulti(event *beat.Event) ([]*beat.Event, error) {
+	events := []*beat.Event{event}
+	for _, p := range procs.List {
+		next := make([]*beat.Event, 0, len(events))
+		for i, e := range events {
+			out, err := beat.RunMulti(p, e)
+			next = append(next, out...)
+			if err != nil {
+				return append(next, events[i+1:]...), fmt.Errorf("failed applying processor %v: %w", p, err)
+			}
+		}
+		if len(next) == 0 {
+			// Drop.
+			return nil, nil
+		}
+		events = next
+	}
+	return events, nil
+}
+
 func (procs Processors) String() string {
 	var s []string
 	for _, p := range procs.List {
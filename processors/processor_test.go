@@ -19,6 +19,7 @@ package processors_test
 
 import (
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
@@ -34,6 +35,7 @@ import (
 	_ "github.com/njcx/libbeat_v8/processors/decode_csv_fields"
 	_ "github.com/njcx/libbeat_v8/processors/dissect"
 	_ "github.com/njcx/libbeat_v8/processors/extract_array"
+	_ "github.com/njcx/libbeat_v8/processors/split"
 	_ "github.com/njcx/libbeat_v8/processors/urldecode"
 	conf "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
@@ -787,3 +789,45 @@ func generateFields(t require.TestingT, m mapstr.M, count, nesting int) {
 		require.NoError(t, err)
 	}
 }
+
+func TestRegisteredProcessors(t *testing.T) {
+	names := processors.RegisteredProcessors()
+	assert.Contains(t, names, "add_fields")
+	assert.Contains(t, names, "convert")
+	assert.True(t, sort.StringsAreSorted(names), "names must be sorted")
+
+	GetProcessors(t, []map[string]interface{}{
+		{"urldecode": map[string]interface{}{"fields": []map[string]interface{}{{"from": "a", "to": "b"}}}},
+	})
+	assert.True(t, processors.IsUsed("urldecode"))
+	assert.False(t, processors.IsUsed("no_such_processor"))
+}
+
+func TestRunMulti(t *testing.T) {
+	procs := GetProcessors(t, []map[string]interface{}{
+		{"split": map[string]interface{}{"field": "records", "target": ""}},
+		{"add_fields": map[string]interface{}{"target": "", "fields": map[string]interface{}{"split": true}}},
+		{"drop_event": map[string]interface{}{"when": map[string]interface{}{"equals": map[string]interface{}{"id": 2}}}},
+		{"if": map[string]interface{}{"equals": map[string]interface{}{"id": 3}},
+			"then": []map[string]interface{}{{"split": map[string]interface{}{"field": "tags"}}}},
+	})
+
+	events, err := procs.RunMulti(&beat.Event{Fields: mapstr.M{
+		"records": []interface{}{
+			mapstr.M{"id": 1},
+			mapstr.M{"id": 2},
+			mapstr.M{"id": 3, "tags": []interface{}{"a", "b"}},
+		},
+	}})
+	require.NoError(t, err)
+
+	var fields []mapstr.M
+	for _, e := range events {
+		fields = append(fields, e.Fields)
+	}
+	assert.Equal(t, []mapstr.M{
+		{"id": 1, "split": true},
+		{"id": 3, "split": true, "tags": "a"},
+		{"id": 3, "split": true, "tags": "b"},
+	}, fields)
+}
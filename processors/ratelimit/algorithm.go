@@ -30,6 +30,10 @@ type algoConfig struct {
 	// limit is the rate limit to be enforced by the algorithm.
 	limit rate
 
+	// maxKeys is the maximum number of keys tracked by the algorithm.
+	// Zero means no limit.
+	maxKeys int
+
 	// config is any algorithm-specific additional configuration.
 	config cfg.C
 }
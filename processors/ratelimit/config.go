@@ -23,14 +23,35 @@ import (
 	cfg "github.com/elastic/elastic-agent-libs/config"
 )
 
+const (
+	actionDrop = "drop"
+	actionTag  = "tag"
+)
+
 // config for rate limit processor.
 type config struct {
 	Limit     rate          `config:"limit" validate:"required"`
 	Fields    []string      `config:"fields"`
+	MaxKeys   int           `config:"max_keys" validate:"min=0"`
+	Action    string        `config:"action"`
 	Algorithm cfg.Namespace `config:"algorithm"`
 }
 
+func (c *config) Validate() error {
+	switch c.Action {
+	case "", actionDrop, actionTag:
+	default:
+		return fmt.Errorf("invalid action '%v', must be one of: %v, %v", c.Action, actionDrop, actionTag)
+	}
+
+	return nil
+}
+
 func (c *config) setDefaults() error {
+	if c.Action == "" {
+		c.Action = actionDrop
+	}
+
 	if c.Algorithm.Name() == "" {
 		cfg, err := cfg.NewConfigFrom(map[string]interface{}{
 			"token_bucket": map[string]interface{}{},
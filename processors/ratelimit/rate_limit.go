@@ -41,12 +41,17 @@ var instanceID = atomic.MakeUint32(0)
 const processorName = "rate_limit"
 const logName = "processor." + processorName
 
+// rateLimitedTag is added to the tags of events exceeding the rate limit
+// when the action is set to tag.
+const rateLimitedTag = "rate_limited"
+
 func init() {
 	processors.RegisterPlugin(processorName, new)
 }
 
 type metrics struct {
 	Dropped *monitoring.Int
+	Tagged  *monitoring.Int
 }
 
 type rateLimit struct {
@@ -69,8 +74,9 @@ func new(cfg *c.C) (beat.Processor, error) {
 	}
 
 	algoConfig := algoConfig{
-		limit:  config.Limit,
-		config: *config.Algorithm.Config(),
+		limit:   config.Limit,
+		maxKeys: config.MaxKeys,
+		config:  *config.Algorithm.Config(),
 	}
 	algo, err := factory(config.Algorithm.Name(), algoConfig)
 	if err != nil {
@@ -90,6 +96,7 @@ func new(cfg *c.C) (beat.Processor, error) {
 		logger:    log,
 		metrics: metrics{
 			Dropped: monitoring.NewInt(reg, "dropped"),
+			Tagged:  monitoring.NewInt(reg, "tagged"),
 		},
 	}
 
@@ -99,7 +106,8 @@ func new(cfg *c.C) (beat.Processor, error) {
 }
 
 // Run applies the configured rate limit to the given event. If the event is within the
-// configured rate limit, it is returned as-is. If not, nil is returned.
+// configured rate limit, it is returned as-is. If not, nil is returned, or the event
+// is returned tagged with rate_limited if the action is set to tag.
 func (p *rateLimit) Run(event *beat.Event) (*beat.Event, error) {
 	key, err := p.makeKey(event)
 	if err != nil {
@@ -110,6 +118,12 @@ func (p *rateLimit) Run(event *beat.Event) (*beat.Event, error) {
 		return event, nil
 	}
 
+	if p.config.Action == actionTag {
+		p.metrics.Tagged.Inc()
+		_ = mapstr.AddTags(event.Fields, []string{rateLimitedTag})
+		return event, nil
+	}
+
 	p.logger.Debugf("event [%v] dropped by rate_limit processor", event)
 	p.metrics.Dropped.Inc()
 	return nil, nil
@@ -117,8 +131,8 @@ func (p *rateLimit) Run(event *beat.Event) (*beat.Event, error) {
 
 func (p *rateLimit) String() string {
 	return fmt.Sprintf(
-		"%v=[limit=[%v],fields=[%v],algorithm=[%v]]",
-		processorName, p.config.Limit, p.config.Fields, p.config.Algorithm.Name(),
+		"%v=[limit=[%v],fields=[%v],max_keys=[%v],action=[%v],algorithm=[%v]]",
+		processorName, p.config.Limit, p.config.Fields, p.config.MaxKeys, p.config.Action, p.config.Algorithm.Name(),
 	)
 }
 
@@ -18,6 +18,8 @@
 package ratelimit
 
 import (
+	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -38,6 +40,20 @@ func TestNew(t *testing.T) {
 			mapstr.M{},
 			"",
 		},
+		"max_keys": {
+			mapstr.M{
+				"fields":   []string{"host.name"},
+				"max_keys": 100,
+				"action":   "tag",
+			},
+			"",
+		},
+		"invalid_action": {
+			mapstr.M{
+				"action": "foobar",
+			},
+			"invalid action 'foobar'",
+		},
 		"unknown_algo": {
 			mapstr.M{
 				"algorithm": mapstr.M{
@@ -139,6 +155,35 @@ func TestRateLimit(t *testing.T) {
 				withField(inEvents[3], "foo", "seger"),
 			},
 		},
+		"with_fields_evicted": {
+			config: mapstr.M{
+				"limit":    "1/m",
+				"fields":   []string{"foo"},
+				"max_keys": 1,
+			},
+			inEvents: []beat.Event{
+				withField(inEvents[0], "foo", "bar"),
+				withField(inEvents[1], "foo", "bar"),
+				withField(inEvents[2], "foo", "seger"),
+				withField(inEvents[3], "foo", "bar"),
+			},
+			outEvents: []beat.Event{
+				withField(inEvents[0], "foo", "bar"),
+				withField(inEvents[2], "foo", "seger"),
+				withField(inEvents[3], "foo", "bar"),
+			},
+		},
+		"with_tag_action": {
+			config: mapstr.M{
+				"limit":  "1/m",
+				"action": "tag",
+			},
+			inEvents: inEvents[0:2],
+			outEvents: []beat.Event{
+				inEvents[0],
+				withField(inEvents[1], "tags", []string{"rate_limited"}),
+			},
+		},
 		"with_burst": {
 			config: mapstr.M{
 				"limit":            "2/s",
@@ -176,3 +221,33 @@ func TestRateLimit(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkRateLimitMaxKeys(b *testing.B) {
+	for _, numKeys := range []int{10, 10000, 100000} {
+		b.Run(strconv.Itoa(numKeys), func(b *testing.B) {
+			p, err := new(conf.MustNewConfigFrom(mapstr.M{
+				"limit":    "100/s",
+				"fields":   []string{"host.name"},
+				"max_keys": 1000,
+			}))
+			require.NoError(b, err)
+
+			var counter atomic.Uint64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					i := counter.Add(1)
+					event := beat.Event{
+						Fields: mapstr.M{
+							"host": mapstr.M{"name": "host-" + strconv.Itoa(int(i)%numKeys)},
+						},
+					}
+					_, err := p.Run(&event)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
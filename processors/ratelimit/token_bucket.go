@@ -18,6 +18,7 @@
 package ratelimit
 
 import (
+	"container/list"
 	"fmt"
 	"sync"
 	"time"
@@ -39,12 +40,25 @@ type bucket struct {
 	lastReplenish time.Time
 }
 
+// keyedBucket is a bucket stored in the LRU list of a tokenBucket.
+type keyedBucket struct {
+	key uint64
+	bucket
+}
+
 type tokenBucket struct {
 	mu unison.Mutex
 
 	limit   rate
 	depth   float64
-	buckets sync.Map
+	maxKeys int
+
+	// bucketsMu protects buckets and lru. lru orders the buckets from the
+	// most to the least recently used, so the least recently used bucket
+	// can be evicted once maxKeys is reached.
+	bucketsMu sync.Mutex
+	buckets   map[uint64]*list.Element
+	lru       *list.List
 
 	// GC thresholds and metrics
 	gc struct {
@@ -89,7 +103,9 @@ func newTokenBucket(config algoConfig) (algorithm, error) {
 	return &tokenBucket{
 		limit:   config.limit,
 		depth:   config.limit.value * cfg.BurstMultiplier,
-		buckets: sync.Map{},
+		maxKeys: config.maxKeys,
+		buckets: make(map[uint64]*list.Element),
+		lru:     list.New(),
 		gc: struct {
 			thresholds tokenBucketGCConfig
 			metrics    struct {
@@ -109,8 +125,9 @@ func newTokenBucket(config algoConfig) (algorithm, error) {
 func (t *tokenBucket) IsAllowed(key uint64) bool {
 	t.runGC()
 
-	b := t.getBucket(key)
-	allowed := b.withdraw()
+	t.bucketsMu.Lock()
+	allowed := t.getBucket(key).withdraw()
+	t.bucketsMu.Unlock()
 
 	t.gc.metrics.numCalls.Inc()
 	return allowed
@@ -121,19 +138,38 @@ func (t *tokenBucket) setClock(c clockwork.Clock) {
 	t.clock = c
 }
 
+// getBucket returns the bucket of key, creating it if needed. If maxKeys is
+// reached, the least recently used bucket is evicted. An evicted key starts
+// over with a full bucket the next time it is seen. The caller must hold
+// bucketsMu.
 func (t *tokenBucket) getBucket(key uint64) *bucket {
-	v, exists := t.buckets.LoadOrStore(key, &bucket{
-		tokens:        t.depth,
-		lastReplenish: t.clock.Now(),
-	})
-	b := v.(*bucket)
-
-	if exists {
+	if elem, exists := t.buckets[key]; exists {
+		t.lru.MoveToFront(elem)
+		b := &elem.Value.(*keyedBucket).bucket
 		b.replenish(t.limit, t.clock)
 		return b
 	}
 
-	return b
+	if t.maxKeys > 0 && t.lru.Len() >= t.maxKeys {
+		t.removeBucket(t.lru.Back())
+	}
+
+	kb := &keyedBucket{
+		key: key,
+		bucket: bucket{
+			tokens:        t.depth,
+			lastReplenish: t.clock.Now(),
+		},
+	}
+	t.buckets[key] = t.lru.PushFront(kb)
+	return &kb.bucket
+}
+
+// removeBucket removes the bucket stored in elem. The caller must hold
+// bucketsMu.
+func (t *tokenBucket) removeBucket(elem *list.Element) {
+	t.lru.Remove(elem)
+	delete(t.buckets, elem.Value.(*keyedBucket).key)
 }
 
 func (b *bucket) withdraw() bool {
@@ -168,26 +204,24 @@ func (t *tokenBucket) runGC() {
 
 		// Add tokens to all buckets according to the rate limit
 		// and flag full buckets for deletion.
-		toDelete := make([]uint64, 0)
-		numBucketsBefore := 0
-		t.buckets.Range(func(k, v interface{}) bool {
-			key := k.(uint64)
-			b := v.(*bucket)
+		t.bucketsMu.Lock()
+		toDelete := make([]*list.Element, 0)
+		numBucketsBefore := t.lru.Len()
+		for elem := t.lru.Front(); elem != nil; elem = elem.Next() {
+			b := &elem.Value.(*keyedBucket).bucket
 
 			b.replenish(t.limit, t.clock)
 
 			if b.tokens >= t.depth {
-				toDelete = append(toDelete, key)
+				toDelete = append(toDelete, elem)
 			}
-
-			numBucketsBefore++
-			return true
-		})
+		}
 
 		// Cleanup full buckets to free up memory
-		for _, key := range toDelete {
-			t.buckets.Delete(key)
+		for _, elem := range toDelete {
+			t.removeBucket(elem)
 		}
+		t.bucketsMu.Unlock()
 
 		// Reset GC metrics
 		t.gc.metrics.numCalls = atomic.MakeUint(0)
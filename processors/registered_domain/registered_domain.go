@@ -20,6 +20,7 @@ package registered_domain
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"strings"
 
 	"golang.org/x/net/publicsuffix"
@@ -90,7 +91,7 @@ func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
 		return event, fmt.Errorf("registered_domain source field [%v] is not a string", p.Field)
 	}
 
-	rd, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	rd, etld, err := registeredDomain(domain)
 	if err != nil {
 		if p.IgnoreFailure {
 			return event, nil
@@ -107,9 +108,8 @@ func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
 	}
 
 	if p.TargetETLDField != "" {
-		tld, _ := publicsuffix.PublicSuffix(domain)
-		if tld != "" {
-			if _, err = event.PutValue(p.TargetETLDField, tld); err != nil && !p.IgnoreFailure {
+		if etld != "" {
+			if _, err = event.PutValue(p.TargetETLDField, etld); err != nil && !p.IgnoreFailure {
 				return event, fmt.Errorf("failed to write effective top-level domain to target field [%v]: %w", p.TargetETLDField, err)
 			}
 		}
@@ -130,3 +130,26 @@ func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
 
 	return event, nil
 }
+
+// registeredDomain returns the eTLD+1 and the effective top-level domain of
+// domain using a single Public Suffix List lookup. It mirrors
+// publicsuffix.EffectiveTLDPlusOne, which does not expose the suffix it
+// computes, and additionally rejects IP addresses.
+func registeredDomain(domain string) (rd, etld string, err error) {
+	if net.ParseIP(strings.Trim(domain, "[]")) != nil {
+		return "", "", fmt.Errorf("cannot derive eTLD+1 for IP address %q", domain)
+	}
+	if strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") || strings.Contains(domain, "..") {
+		return "", "", fmt.Errorf("empty label in domain %q", domain)
+	}
+
+	etld, _ = publicsuffix.PublicSuffix(domain)
+	if len(domain) <= len(etld) {
+		return "", "", fmt.Errorf("cannot derive eTLD+1 for domain %q", domain)
+	}
+	i := len(domain) - len(etld) - 1
+	if domain[i] != '.' {
+		return "", "", fmt.Errorf("invalid public suffix %q for domain %q", etld, domain)
+	}
+	return domain[1+strings.LastIndex(domain[:i], "."):], etld, nil
+}
@@ -45,6 +45,9 @@ func TestProcessorRun(t *testing.T) {
 		{true, ".", ".", "", ""},
 		{true, "", "", "", ""},
 		{true, "localhost", "", "", ""},
+		{true, "192.168.0.1", "", "", ""},
+		{true, "2001:db8::1", "", "", ""},
+		{true, "[::1]", "", "", ""},
 	}
 
 	c := defaultConfig()
@@ -120,3 +123,22 @@ func TestProcessorRun(t *testing.T) {
 		assert.Equal(t, evt.Fields, newEvt.Fields)
 	})
 }
+
+func TestProcessorIgnoreFailure(t *testing.T) {
+	c := defaultConfig()
+	c.Field = "domain"
+	c.TargetField = "registered_domain"
+	c.TargetSubdomainField = "subdomain"
+	c.TargetETLDField = "etld"
+	c.IgnoreFailure = true
+	p, err := newRegisteredDomain(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, domain := range []string{"com", "co.uk", "10.0.0.1", "::1"} {
+		evt, err := p.Run(&beat.Event{Fields: mapstr.M{"domain": domain}})
+		assert.NoError(t, err, domain)
+		assert.Equal(t, mapstr.M{"domain": domain}, evt.Fields, domain)
+	}
+}
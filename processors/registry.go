@@ -19,6 +19,8 @@ package processors
 
 import (
 	"errors"
+	"sort"
+	"sync"
 
 	"github.com/njcx/libbeat_v8/beat"
 	p "github.com/njcx/libbeat_v8/plugin"
@@ -60,3 +62,31 @@ func RegisterPlugin(name string, constructor Constructor) {
 		panic(err)
 	}
 }
+
+// RegisteredProcessors returns the sorted names of all processors registered
+// in this build. Names of namespaced processors are joined by dots.
+func RegisteredProcessors() []string {
+	names := registry.Names()
+	sort.Strings(names)
+	return names
+}
+
+// usedProcessors records the names of the processors configured through New.
+var usedProcessors = struct {
+	sync.Mutex
+	names map[string]bool
+}{names: map[string]bool{}}
+
+func markUsed(name string) {
+	usedProcessors.Lock()
+	defer usedProcessors.Unlock()
+	usedProcessors.names[name] = true
+}
+
+// IsUsed returns true if a processor with the given name was configured
+// since the Beat started.
+func IsUsed(name string) bool {
+	usedProcessors.Lock()
+	defer usedProcessors.Unlock()
+	return usedProcessors.names[name]
+}
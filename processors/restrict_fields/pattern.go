@@ -0,0 +1,131 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package restrict_fields
+
+import "strings"
+
+// patternNode is a node in a tree of field patterns split on dots. Each edge
+// is a pattern segment, which may contain `*` wildcards matching any
+// characters within a single segment.
+type patternNode struct {
+	// terminal is set if a pattern ends at this node. The matched field is
+	// selected including all of its subfields.
+	terminal bool
+	literals map[string]*patternNode
+	globs    []globEdge
+}
+
+type globEdge struct {
+	segment string
+	node    *patternNode
+}
+
+func compilePatterns(patterns []string) *patternNode {
+	root := &patternNode{}
+	for _, pattern := range patterns {
+		node := root
+		for _, segment := range strings.Split(pattern, ".") {
+			node = node.child(segment)
+		}
+		node.terminal = true
+	}
+	return root
+}
+
+func (n *patternNode) child(segment string) *patternNode {
+	if strings.Contains(segment, "*") {
+		for _, g := range n.globs {
+			if g.segment == segment {
+				return g.node
+			}
+		}
+		child := &patternNode{}
+		n.globs = append(n.globs, globEdge{segment: segment, node: child})
+		return child
+	}
+
+	if n.literals == nil {
+		n.literals = map[string]*patternNode{}
+	}
+	child, ok := n.literals[segment]
+	if !ok {
+		child = &patternNode{}
+		n.literals[segment] = child
+	}
+	return child
+}
+
+// advance returns the nodes reached from nodes by following the segments of
+// key, which may itself contain dots. It reports whether a pattern matches
+// the key completely, in which case the returned nodes are not relevant.
+func advance(nodes []*patternNode, key string) ([]*patternNode, bool) {
+	for len(key) > 0 && len(nodes) > 0 {
+		segment := key
+		if i := strings.IndexByte(key, '.'); i >= 0 {
+			segment, key = key[:i], key[i+1:]
+		} else {
+			key = ""
+		}
+
+		var next []*patternNode
+		for _, n := range nodes {
+			if child, ok := n.literals[segment]; ok {
+				if child.terminal {
+					return nil, true
+				}
+				next = append(next, child)
+			}
+			for _, g := range n.globs {
+				if matchSegment(g.segment, segment) {
+					if g.node.terminal {
+						return nil, true
+					}
+					next = append(next, g.node)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes, false
+}
+
+// matchSegment reports whether s matches pattern, where `*` in pattern
+// matches any sequence of characters.
+func matchSegment(pattern, s string) bool {
+	star, backtrack := -1, 0
+	p, i := 0, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, backtrack = p, i
+			p++
+		case p < len(pattern) && pattern[p] == s[i]:
+			p++
+			i++
+		case star >= 0:
+			backtrack++
+			p, i = star+1, backtrack
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,138 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package restrict_fields
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/processors"
+	"github.com/njcx/libbeat_v8/processors/checks"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const procName = "restrict_fields"
+
+func init() {
+	processors.RegisterPlugin(procName,
+		checks.ConfigChecked(New,
+			checks.AllowedFields("include", "exclude", "when")))
+}
+
+type config struct {
+	Include []string `config:"include"`
+	Exclude []string `config:"exclude"`
+}
+
+func (c *config) Validate() error {
+	if len(c.Include) == 0 && len(c.Exclude) == 0 {
+		return errors.New("at least one of include or exclude must be set")
+	}
+	for _, pattern := range append(c.Include, c.Exclude...) {
+		if pattern == "" || strings.HasPrefix(pattern, ".") || strings.HasSuffix(pattern, ".") || strings.Contains(pattern, "..") {
+			return fmt.Errorf("invalid field pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+type processor struct {
+	config
+	include *patternNode
+	exclude *patternNode
+}
+
+// New constructs a processor that removes all fields not matching one of the
+// include patterns, if any, and then all fields matching one of the exclude
+// patterns. @timestamp and @metadata are never removed.
+func New(cfg *conf.C) (beat.Processor, error) {
+	var c config
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, fmt.Errorf("fail to unpack the %v processor configuration: %w", procName, err)
+	}
+
+	p := &processor{config: c}
+	if len(c.Include) > 0 {
+		p.include = compilePatterns(append(c.Include, processors.MandatoryExportedFields...))
+	}
+	if len(c.Exclude) > 0 {
+		p.exclude = compilePatterns(c.Exclude)
+	}
+	return p, nil
+}
+
+func (p *processor) String() string {
+	json, _ := json.Marshal(p.config)
+	return procName + "=" + string(json)
+}
+
+func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
+	if p.include != nil {
+		keepMatching(event.Fields, []*patternNode{p.include})
+	}
+	if p.exclude != nil {
+		removeMatching(event.Fields, []*patternNode{p.exclude})
+	}
+	return event, nil
+}
+
+// keepMatching removes the keys of m that neither match a pattern nor lead to
+// a field matching a pattern. Objects left empty are removed.
+func keepMatching(m map[string]interface{}, nodes []*patternNode) {
+	for k, v := range m {
+		next, matched := advance(nodes, k)
+		if matched {
+			continue
+		}
+		if sub, ok := toMap(v); ok && len(next) > 0 {
+			keepMatching(sub, next)
+			if len(sub) > 0 {
+				continue
+			}
+		}
+		delete(m, k)
+	}
+}
+
+// removeMatching removes the keys of m that match a pattern.
+func removeMatching(m map[string]interface{}, nodes []*patternNode) {
+	for k, v := range m {
+		next, matched := advance(nodes, k)
+		if matched {
+			delete(m, k)
+			continue
+		}
+		if sub, ok := toMap(v); ok && len(next) > 0 {
+			removeMatching(sub, next)
+		}
+	}
+}
+
+func toMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case mapstr.M:
+		return m, true
+	case map[string]interface{}:
+		return m, true
+	}
+	return nil, false
+}
@@ -0,0 +1,244 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package restrict_fields
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func testEvent() *beat.Event {
+	return &beat.Event{
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Meta:      mapstr.M{"_id": "abc"},
+		Fields: mapstr.M{
+			"message": "hello",
+			"type":    "log",
+			"user":    mapstr.M{"name": "alice", "email": "alice@example.com"},
+			"http": mapstr.M{
+				"request": mapstr.M{
+					"method": "GET",
+					"headers": mapstr.M{
+						"user-agent":    "curl",
+						"authorization": "Bearer secret",
+						"x-api-key":     "secret",
+					},
+				},
+				"response": mapstr.M{"status_code": 200},
+			},
+			"host.name": "server",
+			"labels":    map[string]interface{}{"env": "prod", "team": "a"},
+		},
+	}
+}
+
+func TestRestrictFields(t *testing.T) {
+	tests := map[string]struct {
+		config   mapstr.M
+		expected mapstr.M
+	}{
+		"include": {
+			config: mapstr.M{"include": []string{"message", "user.name"}},
+			expected: mapstr.M{
+				"message": "hello",
+				"type":    "log",
+				"user":    mapstr.M{"name": "alice"},
+			},
+		},
+		"include wildcard segment": {
+			config: mapstr.M{"include": []string{"http.request.headers.*", "labels.env"}},
+			expected: mapstr.M{
+				"type": "log",
+				"http": mapstr.M{
+					"request": mapstr.M{
+						"headers": mapstr.M{
+							"user-agent":    "curl",
+							"authorization": "Bearer secret",
+							"x-api-key":     "secret",
+						},
+					},
+				},
+				"labels": map[string]interface{}{"env": "prod"},
+			},
+		},
+		"include partial wildcard": {
+			config: mapstr.M{"include": []string{"http.*.status_*", "http.request.headers.x-*"}},
+			expected: mapstr.M{
+				"type": "log",
+				"http": mapstr.M{
+					"request":  mapstr.M{"headers": mapstr.M{"x-api-key": "secret"}},
+					"response": mapstr.M{"status_code": 200},
+				},
+			},
+		},
+		"include dotted keys": {
+			config: mapstr.M{"include": []string{"host.name"}},
+			expected: mapstr.M{
+				"type":      "log",
+				"host.name": "server",
+			},
+		},
+		"include parent of dotted key": {
+			config: mapstr.M{"include": []string{"host"}},
+			expected: mapstr.M{
+				"type":      "log",
+				"host.name": "server",
+			},
+		},
+		"include drops leaves on the path": {
+			config:   mapstr.M{"include": []string{"message.text"}},
+			expected: mapstr.M{"type": "log"},
+		},
+		"exclude": {
+			config: mapstr.M{"exclude": []string{"http.request.headers.authorization", "user.email", "host.*"}},
+			expected: mapstr.M{
+				"message": "hello",
+				"type":    "log",
+				"user":    mapstr.M{"name": "alice"},
+				"http": mapstr.M{
+					"request": mapstr.M{
+						"method": "GET",
+						"headers": mapstr.M{
+							"user-agent": "curl",
+							"x-api-key":  "secret",
+						},
+					},
+					"response": mapstr.M{"status_code": 200},
+				},
+				"labels": map[string]interface{}{"env": "prod", "team": "a"},
+			},
+		},
+		"include and exclude": {
+			config: mapstr.M{
+				"include": []string{"http.request.*"},
+				"exclude": []string{"http.request.headers.authorization", "http.request.headers.x-*"},
+			},
+			expected: mapstr.M{
+				"type": "log",
+				"http": mapstr.M{
+					"request": mapstr.M{
+						"method":  "GET",
+						"headers": mapstr.M{"user-agent": "curl"},
+					},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := New(conf.MustNewConfigFrom(test.config))
+			require.NoError(t, err)
+
+			in := testEvent()
+			out, err := p.Run(in)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, out.Fields)
+			// @timestamp and @metadata are never removed.
+			assert.Equal(t, testEvent().Timestamp, out.Timestamp)
+			assert.Equal(t, testEvent().Meta, out.Meta)
+		})
+	}
+}
+
+func TestRestrictFieldsConfig(t *testing.T) {
+	for name, config := range map[string]mapstr.M{
+		"no patterns":   {},
+		"empty pattern": {"include": []string{""}},
+		"empty segment": {"exclude": []string{"http..headers"}},
+		"trailing dot":  {"include": []string{"http."}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(conf.MustNewConfigFrom(config))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMatchSegment(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		match      bool
+	}{
+		{"*", "", true},
+		{"*", "anything", true},
+		{"x-*", "x-api-key", true},
+		{"x-*", "y-api-key", false},
+		{"*-key", "x-api-key", true},
+		{"*api*", "x-api-key", true},
+		{"a*b*c", "aXbYbZc", true},
+		{"a*b*c", "aXbYbZ", false},
+		{"abc", "abc", true},
+		{"abc", "abcd", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.match, matchSegment(test.pattern, test.s), "%q %q", test.pattern, test.s)
+	}
+}
+
+// deepEvent returns an event with width keys per level, depth levels deep.
+func deepEvent(width, depth int) mapstr.M {
+	m := mapstr.M{}
+	for i := 0; i < width; i++ {
+		key := fmt.Sprintf("field%d", i)
+		if depth > 1 {
+			m[key] = deepEvent(width, depth-1)
+		} else {
+			m[key] = "value"
+		}
+	}
+	return m
+}
+
+func BenchmarkRestrictFields(b *testing.B) {
+	benchmarks := map[string]mapstr.M{
+		"include literal": {"include": []string{"field0.field1.field2.field3", "field1.field2"}},
+		"include wildcard": {
+			"include": []string{"field*.field1.*.field3", "*.*.field2.*"},
+		},
+		"exclude wildcard": {
+			"exclude": []string{"field*.field1.*.field3", "*.*.field2.*"},
+		},
+	}
+
+	for name, config := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			p, err := New(conf.MustNewConfigFrom(config))
+			require.NoError(b, err)
+
+			events := make([]*beat.Event, b.N)
+			for i := range events {
+				events[i] = &beat.Event{Fields: deepEvent(5, 5)}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = p.Run(events[i])
+			}
+		})
+	}
+}
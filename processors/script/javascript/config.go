@@ -28,9 +28,11 @@ type Config struct {
 	Source             string                 `config:"source"`                               // Inline script to execute.
 	File               string                 `config:"file"`                                 // Source file.
 	Files              []string               `config:"files"`                                // Multiple source files.
+	IncludeFiles       []string               `config:"include_files"`                        // Shared helper files loaded before the script.
 	Params             map[string]interface{} `config:"params"`                               // Parameters to pass to script.
 	Timeout            time.Duration          `config:"timeout" validate:"min=0"`             // Execution timeout.
 	TagOnException     string                 `config:"tag_on_exception"`                     // Tag to add to events when an exception happens.
+	IgnoreFailure      bool                   `config:"ignore_failure"`                       // Do not return an error when the script fails.
 	MaxCachedSessions  int                    `config:"max_cached_sessions" validate:"min=0"` // Max. number of cached VM sessions.
 	OnlyCachedSessions bool                   `config:"only_cached_sessions"`                 // Only use cached VM sessions.
 }
@@ -43,6 +43,7 @@ type jsProcessor struct {
 	sessionPool *sessionPool
 	sourceProg  *goja.Program
 	sourceFile  string
+	includes    []string
 	stats       *processorStats
 }
 
@@ -86,7 +87,12 @@ func NewFromConfig(c Config, reg *monitoring.Registry) (beat.Processor, error) {
 		return nil, err
 	}
 
-	pool, err := newSessionPool(prog, c)
+	includeFiles, includeProgs, err := compileIncludes(c.IncludeFiles)
+	if err != nil {
+		return nil, annotateError(c.Tag, err)
+	}
+
+	pool, err := newSessionPool(prog, includeProgs, c)
 	if err != nil {
 		return nil, annotateError(c.Tag, err)
 	}
@@ -96,41 +102,69 @@ func NewFromConfig(c Config, reg *monitoring.Registry) (beat.Processor, error) {
 		sessionPool: pool,
 		sourceProg:  prog,
 		sourceFile:  sourceFile,
+		includes:    includeFiles,
 		stats:       getStats(c.Tag, reg),
 	}, nil
 }
 
 // loadSources loads javascript source from files.
 func loadSources(files ...string) (string, []byte, error) {
-	var sources []string
-	buf := new(bytes.Buffer)
+	sources, err := resolveSources(files)
+	if err != nil {
+		return "", nil, err
+	}
 
-	readFile := func(path string) error {
-		if common.IsStrictPerms() {
-			if err := common.OwnerHasExclusiveWritePerms(path); err != nil {
-				return err
-			}
+	buf := new(bytes.Buffer)
+	for _, name := range sources {
+		if err := readSource(buf, name); err != nil {
+			return "", nil, err
 		}
+	}
 
-		f, err := os.Open(path)
-		if err != nil {
-			return fmt.Errorf("failed to open file %v: %w", path, err)
+	return strings.Join(sources, ";"), buf.Bytes(), nil
+}
+
+// compileIncludes loads and compiles each include file separately so that
+// errors reference the file they come from. The files are only read here, the
+// scripts themselves have no access to the file system.
+func compileIncludes(files []string) ([]string, []*goja.Program, error) {
+	if len(files) == 0 {
+		return nil, nil, nil
+	}
+
+	sources, err := resolveSources(files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	progs := make([]*goja.Program, 0, len(sources))
+	for _, name := range sources {
+		buf := new(bytes.Buffer)
+		if err := readSource(buf, name); err != nil {
+			return nil, nil, err
 		}
-		defer f.Close()
 
-		if _, err = io.Copy(buf, f); err != nil {
-			return fmt.Errorf("failed to read file %v: %w", path, err)
+		prog, err := goja.Compile(name, buf.String(), true)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compile include file %v: %w", name, err)
 		}
-		return nil
+		progs = append(progs, prog)
 	}
 
+	return sources, progs, nil
+}
+
+// resolveSources resolves the given paths relative to the config directory
+// and expands globs.
+func resolveSources(files []string) ([]string, error) {
+	var sources []string
 	for _, filePath := range files {
 		filePath = paths.Resolve(paths.Config, filePath)
 
 		if hasMeta(filePath) {
 			matches, err := filepath.Glob(filePath)
 			if err != nil {
-				return "", nil, err
+				return nil, err
 			}
 			sources = append(sources, matches...)
 		} else {
@@ -139,17 +173,29 @@ func loadSources(files ...string) (string, []byte, error) {
 	}
 
 	if len(sources) == 0 {
-		return "", nil, fmt.Errorf("no sources were found in %v",
+		return nil, fmt.Errorf("no sources were found in %v",
 			strings.Join(files, ", "))
 	}
+	return sources, nil
+}
 
-	for _, name := range sources {
-		if err := readFile(name); err != nil {
-			return "", nil, err
+func readSource(buf *bytes.Buffer, path string) error {
+	if common.IsStrictPerms() {
+		if err := common.OwnerHasExclusiveWritePerms(path); err != nil {
+			return err
 		}
 	}
 
-	return strings.Join(sources, ";"), buf.Bytes(), nil
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file %v: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err = io.Copy(buf, f); err != nil {
+		return fmt.Errorf("failed to read file %v: %w", path, err)
+	}
+	return nil
 }
 
 func annotateError(id string, err error) error {
@@ -176,6 +222,10 @@ func (p *jsProcessor) Run(event *beat.Event) (*beat.Event, error) {
 	} else {
 		rtn, err = p.runWithStats(s, event)
 	}
+	if err != nil && p.IgnoreFailure {
+		// The event has already been tagged and annotated with the error.
+		return rtn, nil
+	}
 	return rtn, annotateError(p.Tag, err)
 }
 
@@ -187,11 +237,18 @@ func (p *jsProcessor) runWithStats(s *session, event *beat.Event) (*beat.Event,
 	p.stats.processTime.Update(int64(elapsed))
 	if err != nil {
 		p.stats.exceptions.Inc()
+		if isTimeout(err) {
+			p.stats.timeouts.Inc()
+		}
 	}
 	return event, err
 }
 
 func (p *jsProcessor) String() string {
+	if len(p.includes) > 0 {
+		return "script=[type=javascript, id=" + p.Tag + ", sources=" + p.sourceFile +
+			", includes=" + strings.Join(p.includes, ";") + "]"
+	}
 	return "script=[type=javascript, id=" + p.Tag + ", sources=" + p.sourceFile + "]"
 }
 
@@ -207,6 +264,7 @@ func hasMeta(path string) bool {
 
 type processorStats struct {
 	exceptions  *monitoring.Int
+	timeouts    *monitoring.Int
 	processTime metrics.Sample
 }
 
@@ -226,6 +284,7 @@ func getStats(id string, reg *monitoring.Registry) *processorStats {
 
 	stats := &processorStats{
 		exceptions:  monitoring.NewInt(processorReg, "exceptions"),
+		timeouts:    monitoring.NewInt(processorReg, "timeouts"),
 		processTime: metrics.NewUniformSample(2048),
 	}
 	_ = adapter.NewGoMetrics(processorReg, "histogram", adapter.Accept).
@@ -82,7 +82,7 @@ type session struct {
 	tagOnException string
 }
 
-func newSession(p *goja.Program, conf Config, test bool) (*session, error) {
+func newSession(p *goja.Program, includes []*goja.Program, conf Config, test bool) (*session, error) {
 	// Create a logger
 	logger := logp.NewLogger(logName)
 	if conf.Tag != "" {
@@ -111,6 +111,14 @@ func newSession(p *goja.Program, conf Config, test bool) (*session, error) {
 	// Register constructor for 'new Event' to enable test() to create events.
 	s.vm.Set("Event", newBeatEventV0Constructor(s))
 
+	// Run the include files first so that the functions they define are
+	// available to the script.
+	for _, inc := range includes {
+		if _, err := s.vm.RunProgram(inc); err != nil {
+			return nil, fmt.Errorf("failed to load include file: %w", err)
+		}
+	}
+
 	_, err := s.vm.RunProgram(p)
 	if err != nil {
 		return nil, err
@@ -135,6 +143,13 @@ func newSession(p *goja.Program, conf Config, test bool) (*session, error) {
 	return s, nil
 }
 
+// isTimeout returns true if err was caused by the execution timeout
+// interrupting the script.
+func isTimeout(err error) bool {
+	var interrupted *goja.InterruptedError
+	return errors.As(err, &interrupted) && interrupted.Value() == timeoutError
+}
+
 // setProcessFunction validates that the process() function exists and stores
 // the handle.
 func (s *session) setProcessFunction() error {
@@ -229,14 +244,21 @@ func (s *session) runProcessFunc(b *beat.Event) (out *beat.Event, err error) {
 	}
 
 	// Interrupt the JS code if execution exceeds timeout.
+	var timer *time.Timer
 	if s.timeout > 0 {
-		t := time.AfterFunc(s.timeout, func() {
+		timer = time.AfterFunc(s.timeout, func() {
 			s.vm.Interrupt(timeoutError)
 		})
-		defer t.Stop()
 	}
 
-	if _, err = s.processFunc(goja.Undefined(), s.evt.JSObject()); err != nil {
+	_, err = s.processFunc(goja.Undefined(), s.evt.JSObject())
+	// Stop the timer before clearing the interrupt, so it can't fire after
+	// the interrupt was cleared and abort the next invocation.
+	if timer != nil {
+		timer.Stop()
+	}
+	s.vm.ClearInterrupt()
+	if err != nil {
 		if s.tagOnException != "" {
 			_ = mapstr.AddTags(b.Fields, []string{s.tagOnException})
 		}
@@ -278,15 +300,15 @@ type sessionPool struct {
 	NewSessionsAllowed bool
 }
 
-func newSessionPool(p *goja.Program, c Config) (*sessionPool, error) {
-	s, err := newSession(p, c, true)
+func newSessionPool(p *goja.Program, includes []*goja.Program, c Config) (*sessionPool, error) {
+	s, err := newSession(p, includes, c, true)
 	if err != nil {
 		return nil, err
 	}
 
 	pool := sessionPool{
 		New: func() *session {
-			s, _ := newSession(p, c, false)
+			s, _ := newSession(p, includes, c, false)
 			return s
 		},
 		C:                  make(chan *session, c.MaxCachedSessions),
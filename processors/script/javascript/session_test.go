@@ -19,6 +19,8 @@ package javascript
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -26,6 +28,7 @@ import (
 	"github.com/njcx/libbeat_v8/beat"
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -90,6 +93,83 @@ func TestSessionScriptParams(t *testing.T) {
 	})
 }
 
+func TestSessionIncludeFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeScript := func(name, src string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	strs := writeScript("strings.js", `
+		function shout(s) {
+			return s.toUpperCase() + "!";
+		}
+	`)
+	nums := writeScript("numbers.js", `
+		var ANSWER = 42;
+	`)
+
+	t.Run("functions are available to the script", func(t *testing.T) {
+		const script = `
+			function process(event) {
+				event.Put("message", shout(event.Get("message")));
+				event.Put("answer", ANSWER);
+			}
+		`
+		p, err := NewFromConfig(Config{
+			Source:       script,
+			IncludeFiles: []string{strs, nums},
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		evt, err := p.Run(&beat.Event{Fields: mapstr.M{"message": "hello"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg, _ := evt.GetValue("message")
+		assert.Equal(t, "HELLO!", msg)
+		answer, _ := evt.GetValue("answer")
+		assert.EqualValues(t, 42, answer)
+	})
+
+	t.Run("globs are expanded", func(t *testing.T) {
+		p, err := NewFromConfig(Config{
+			Source:       `function process(event) { event.Put("answer", ANSWER); }`,
+			IncludeFiles: []string{filepath.Join(dir, "*.js")},
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, p.String(), "includes=")
+	})
+
+	t.Run("compile errors reference the include file", func(t *testing.T) {
+		broken := writeScript("broken.js", `function broken( {`)
+
+		_, err := NewFromConfig(Config{
+			Source:       header + footer,
+			IncludeFiles: []string{strs, broken},
+		}, nil)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "failed to compile include file "+broken)
+		}
+	})
+
+	t.Run("missing include file", func(t *testing.T) {
+		_, err := NewFromConfig(Config{
+			Source:       header + footer,
+			IncludeFiles: []string{filepath.Join(dir, "missing.js")},
+		}, nil)
+		assert.Error(t, err)
+	})
+}
+
 func TestSessionTestFunction(t *testing.T) {
 	const script = `
 		var fail = false;
@@ -187,6 +267,59 @@ func TestSessionTimeout(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSessionTimeoutIgnoreFailure(t *testing.T) {
+	logp.TestingSetup()
+
+	const infiniteLoop = `
+		if (evt.Get("spin")) {
+			while (true) {}
+		}
+		evt.Put("processed", true);
+	`
+
+	reg := monitoring.NewRegistry()
+	p, err := NewFromConfig(Config{
+		Tag:            "timeout",
+		Source:         header + infiniteLoop + footer,
+		Timeout:        100 * time.Millisecond,
+		TagOnException: "_js_exception",
+		IgnoreFailure:  true,
+	}, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		stuck := &beat.Event{Fields: mapstr.M{"spin": true}}
+		var runErr error
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			stuck, runErr = p.Run(stuck)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout did not interrupt the script")
+		}
+		assert.NoError(t, runErr)
+		tags, _ := stuck.GetValue("tags")
+		assert.Equal(t, []string{"_js_exception"}, tags)
+
+		// The following event must be processed normally.
+		next, err := p.Run(&beat.Event{Fields: mapstr.M{"spin": false}})
+		assert.NoError(t, err)
+		processed, _ := next.GetValue("processed")
+		assert.Equal(t, true, processed)
+	}
+
+	timeouts := reg.Get("processor.javascript.timeout.timeouts").(*monitoring.Int)
+	assert.EqualValues(t, 3, timeouts.Get())
+	exceptions := reg.Get("processor.javascript.timeout.exceptions").(*monitoring.Int)
+	assert.EqualValues(t, 3, exceptions.Get())
+}
+
 func TestSessionParallel(t *testing.T) {
 	const script = `
 		evt.Put("host.name", "workstation");			
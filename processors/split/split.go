@@ -0,0 +1,179 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package split
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/processors"
+	"github.com/njcx/libbeat_v8/processors/checks"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const procName = "split"
+
+// errMultiEventUnsupported is returned by Run, as a single event can't hold
+// the result of the split.
+var errMultiEventUnsupported = errors.New("the split processor must run in a processor list that supports multiple events")
+
+func init() {
+	processors.RegisterPlugin(procName,
+		checks.ConfigChecked(New,
+			checks.RequireFields("field"),
+			checks.AllowedFields("field", "target", "keep_parent", "when")))
+}
+
+type config struct {
+	Field      string  `config:"field" validate:"required"`
+	Target     *string `config:"target"`
+	KeepParent bool    `config:"keep_parent"`
+}
+
+type processor struct {
+	config
+}
+
+// New constructs a processor that splits an event into one event per
+// element of an array field.
+func New(cfg *conf.C) (beat.Processor, error) {
+	var c config
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, fmt.Errorf("fail to unpack the %v processor configuration: %w", procName, err)
+	}
+	return &processor{config: c}, nil
+}
+
+func (p *processor) String() string {
+	json, _ := json.Marshal(p.config)
+	return procName + "=" + string(json)
+}
+
+// Run returns the event unchanged, unless there is nothing to split.
+// Splitting requires RunMulti.
+func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
+	if len(p.elements(event)) == 0 {
+		return event, nil
+	}
+	return event, errMultiEventUnsupported
+}
+
+// RunMulti returns one event per element of the array field. Each event is a
+// copy of the input event without the array field, with the element written
+// to the target. Events without the field, or where the field is not an array
+// or is empty, are returned unchanged.
+func (p *processor) RunMulti(event *beat.Event) ([]*beat.Event, error) {
+	elements := p.elements(event)
+	if len(elements) == 0 {
+		return []*beat.Event{event}, nil
+	}
+
+	// Remove the array while copying the event, so it isn't copied for
+	// every element.
+	array, _ := event.GetValue(p.Field)
+	_ = event.Delete(p.Field)
+	defer func() { _, _ = event.PutValue(p.Field, array) }()
+
+	out := make([]*beat.Event, 0, len(elements)+1)
+	if p.KeepParent {
+		out = append(out, event)
+	}
+	for _, element := range elements {
+		child := event.Clone()
+		if err := p.putElement(child, element); err != nil {
+			return []*beat.Event{event}, fmt.Errorf("failed to split field %v: %w", p.Field, err)
+		}
+		out = append(out, child)
+	}
+	return out, nil
+}
+
+// elements returns the elements of the array field, or nil if the event has
+// no such field or it is not an array.
+func (p *processor) elements(event *beat.Event) []interface{} {
+	v, err := event.GetValue(p.Field)
+	if err != nil {
+		return nil
+	}
+	switch arr := v.(type) {
+	case []interface{}:
+		return arr
+	case []mapstr.M:
+		elements := make([]interface{}, len(arr))
+		for i, m := range arr {
+			elements[i] = m
+		}
+		return elements
+	case []map[string]interface{}:
+		elements := make([]interface{}, len(arr))
+		for i, m := range arr {
+			elements[i] = mapstr.M(m)
+		}
+		return elements
+	case []string:
+		elements := make([]interface{}, len(arr))
+		for i, s := range arr {
+			elements[i] = s
+		}
+		return elements
+	}
+	return nil
+}
+
+// putElement writes element to the target of child. With an empty target,
+// object elements are merged into the root of the event.
+func (p *processor) putElement(child *beat.Event, element interface{}) error {
+	target := p.Field
+	if p.Target != nil {
+		target = *p.Target
+	}
+	element = cloneValue(element)
+
+	if target != "" {
+		_, err := child.PutValue(target, element)
+		return err
+	}
+
+	fields, ok := toMap(element)
+	if !ok {
+		return fmt.Errorf("element of type %T can't be merged into the event without a target", element)
+	}
+	child.Fields.DeepUpdate(fields)
+	return nil
+}
+
+// cloneValue copies object elements, so the events don't share them.
+func cloneValue(v interface{}) interface{} {
+	if m, ok := toMap(v); ok {
+		return m.Clone()
+	}
+	return v
+}
+
+func toMap(v interface{}) (mapstr.M, bool) {
+	switch m := v.(type) {
+	case mapstr.M:
+		return m, true
+	case map[string]interface{}:
+		return mapstr.M(m), true
+	}
+	return nil, false
+}
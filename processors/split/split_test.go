@@ -0,0 +1,131 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package split
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestSplit(t *testing.T) {
+	tests := map[string]struct {
+		config   mapstr.M
+		input    mapstr.M
+		expected []mapstr.M
+		wantErr  bool
+	}{
+		"objects replace the array": {
+			config: mapstr.M{"field": "records"},
+			input: mapstr.M{
+				"host":    "a",
+				"records": []interface{}{mapstr.M{"id": 1}, mapstr.M{"id": 2}},
+			},
+			expected: []mapstr.M{
+				{"host": "a", "records": mapstr.M{"id": 1}},
+				{"host": "a", "records": mapstr.M{"id": 2}},
+			},
+		},
+		"objects merged into the root": {
+			config: mapstr.M{"field": "records", "target": ""},
+			input: mapstr.M{
+				"host":    "a",
+				"records": []interface{}{map[string]interface{}{"id": 1, "host": "b"}, mapstr.M{"id": 2}},
+			},
+			expected: []mapstr.M{
+				{"host": "b", "id": 1},
+				{"host": "a", "id": 2},
+			},
+		},
+		"scalars written to target": {
+			config: mapstr.M{"field": "batch.values", "target": "value"},
+			input: mapstr.M{
+				"batch": mapstr.M{"values": []string{"x", "y"}, "name": "n"},
+			},
+			expected: []mapstr.M{
+				{"batch": mapstr.M{"name": "n"}, "value": "x"},
+				{"batch": mapstr.M{"name": "n"}, "value": "y"},
+			},
+		},
+		"keep parent": {
+			config: mapstr.M{"field": "records", "keep_parent": true},
+			input:  mapstr.M{"records": []interface{}{1, 2}},
+			expected: []mapstr.M{
+				{"records": []interface{}{1, 2}},
+				{"records": 1},
+				{"records": 2},
+			},
+		},
+		"missing field": {
+			config:   mapstr.M{"field": "records"},
+			input:    mapstr.M{"message": "hello"},
+			expected: []mapstr.M{{"message": "hello"}},
+		},
+		"not an array": {
+			config:   mapstr.M{"field": "records"},
+			input:    mapstr.M{"records": "hello"},
+			expected: []mapstr.M{{"records": "hello"}},
+		},
+		"empty array": {
+			config:   mapstr.M{"field": "records"},
+			input:    mapstr.M{"records": []interface{}{}},
+			expected: []mapstr.M{{"records": []interface{}{}}},
+		},
+		"scalars can't be merged into the root": {
+			config:   mapstr.M{"field": "records", "target": ""},
+			input:    mapstr.M{"records": []interface{}{1}},
+			expected: []mapstr.M{{"records": []interface{}{1}}},
+			wantErr:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := New(conf.MustNewConfigFrom(test.config))
+			require.NoError(t, err)
+
+			events, err := beat.RunMulti(p, &beat.Event{Fields: test.input.Clone()})
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			var fields []mapstr.M
+			for _, e := range events {
+				fields = append(fields, e.Fields)
+			}
+			assert.Equal(t, test.expected, fields)
+		})
+	}
+}
+
+func TestSplitRun(t *testing.T) {
+	p, err := New(conf.MustNewConfigFrom(mapstr.M{"field": "records"}))
+	require.NoError(t, err)
+
+	input := mapstr.M{"records": []interface{}{1, 2}}
+	event, err := p.Run(&beat.Event{Fields: input.Clone()})
+	assert.ErrorIs(t, err, errMultiEventUnsupported)
+	assert.Equal(t, input, event.Fields, "the event must be returned unchanged")
+}
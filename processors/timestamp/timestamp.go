@@ -130,7 +130,7 @@ func (p *processor) parseValue(v interface{}) (time.Time, error) {
 	detailedErr := &parseError{}
 
 	for _, layout := range p.Layouts {
-		ts, err := p.parseValueByLayout(v, layout)
+		ts, err := ParseByLayout(v, layout, p.tz)
 		if err == nil {
 			return ts, nil
 		}
@@ -155,7 +155,10 @@ func (p *processor) parseValue(v interface{}) (time.Time, error) {
 	return time.Time{}, detailedErr
 }
 
-func (p *processor) parseValueByLayout(v interface{}, layout string) (time.Time, error) {
+// ParseByLayout parses v as a time using layout. Besides the Go time layouts,
+// layout can be UNIX or UNIX_MS to parse seconds or milliseconds since the
+// epoch. Times without a year are set to the current year in loc.
+func ParseByLayout(v interface{}, layout string, loc *time.Location) (time.Time, error) {
 	switch layout {
 	case "UNIX":
 		if sec, ok := common.TryToInt(v); ok {
@@ -177,7 +180,7 @@ func (p *processor) parseValueByLayout(v interface{}, layout string) (time.Time,
 			return time.Time{}, fmt.Errorf("unexpected type %T for time field", v)
 		}
 
-		ts, err := time.ParseInLocation(layout, str, p.tz)
+		ts, err := time.ParseInLocation(layout, str, loc)
 		if err == nil {
 			// Use current year if no year is zero.
 			if ts.Year() == 0 {
@@ -0,0 +1,76 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package timestamp_diff
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/njcx/libbeat_v8/common/cfgtype"
+)
+
+const (
+	unitNanoseconds  = "nanoseconds"
+	unitMicroseconds = "microseconds"
+	unitMilliseconds = "milliseconds"
+	unitSeconds      = "seconds"
+
+	negativeKeep  = "keep"
+	negativeClamp = "clamp"
+	negativeError = "error"
+)
+
+type config struct {
+	StartField    string            `config:"start_field" validate:"required"` // Field containing the start time.
+	EndField      string            `config:"end_field" validate:"required"`   // Field containing the end time.
+	Target        string            `config:"target"`                          // Field the difference is written to. Defaults to event.duration.
+	Unit          string            `config:"unit"`                            // Unit of the difference. Defaults to nanoseconds.
+	Layouts       []string          `config:"layouts"`                         // Layouts used to parse string values. UNIX and UNIX_MS are accepted for numeric values.
+	Timezone      *cfgtype.Timezone `config:"timezone"`                        // Time zone used when parsing times that do not contain a time zone.
+	OnNegative    string            `config:"on_negative"`                     // Handling of an end time before the start time: keep, clamp or error.
+	IgnoreMissing bool              `config:"ignore_missing"`                  // Ignore errors when a time field is missing.
+	IgnoreFailure bool              `config:"ignore_failure"`                  // Ignore all errors produced by the processor.
+}
+
+func defaultConfig() config {
+	return config{
+		Target:     "event.duration",
+		Unit:       unitNanoseconds,
+		Layouts:    []string{time.RFC3339Nano},
+		OnNegative: negativeKeep,
+	}
+}
+
+func (c config) Validate() error {
+	switch c.Unit {
+	case unitNanoseconds, unitMicroseconds, unitMilliseconds, unitSeconds:
+	default:
+		return fmt.Errorf("unsupported unit '%s', supported units are %s, %s, %s and %s",
+			c.Unit, unitNanoseconds, unitMicroseconds, unitMilliseconds, unitSeconds)
+	}
+	switch c.OnNegative {
+	case negativeKeep, negativeClamp, negativeError:
+	default:
+		return fmt.Errorf("unsupported on_negative value '%s', supported values are %s, %s and %s",
+			c.OnNegative, negativeKeep, negativeClamp, negativeError)
+	}
+	if len(c.Layouts) == 0 {
+		return fmt.Errorf("at least one layout is required")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package timestamp_diff
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/common"
+	"github.com/njcx/libbeat_v8/processors"
+	"github.com/njcx/libbeat_v8/processors/checks"
+	"github.com/njcx/libbeat_v8/processors/timestamp"
+	jsprocessor "github.com/njcx/libbeat_v8/processors/script/javascript/module/processor"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const procName = "timestamp_diff"
+
+func init() {
+	processors.RegisterPlugin(procName,
+		checks.ConfigChecked(New,
+			checks.RequireFields("start_field", "end_field"),
+			checks.AllowedFields("start_field", "end_field", "target", "unit", "layouts",
+				"timezone", "on_negative", "ignore_missing", "ignore_failure", "when")))
+	jsprocessor.RegisterPlugin("TimestampDiff", New)
+}
+
+type processor struct {
+	config
+	tz *time.Location
+}
+
+// New constructs a processor that computes the time elapsed between two
+// timestamp fields.
+func New(cfg *conf.C) (beat.Processor, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, fmt.Errorf("failed to unpack the %v configuration: %w", procName, err)
+	}
+
+	return &processor{
+		config: c,
+		tz:     c.Timezone.Location(),
+	}, nil
+}
+
+func (p *processor) String() string {
+	return fmt.Sprintf("%v=[start_field=%v, end_field=%v, target=%v, unit=%v, layouts=%v, timezone=%v, on_negative=%v]",
+		procName, p.StartField, p.EndField, p.Target, p.Unit, p.Layouts, p.tz, p.OnNegative)
+}
+
+func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
+	err := p.run(event)
+	if err != nil && !p.IgnoreFailure {
+		return event, err
+	}
+	return event, nil
+}
+
+func (p *processor) run(event *beat.Event) error {
+	start, found, err := p.getTime(event, p.StartField)
+	if err != nil || !found {
+		return err
+	}
+	end, found, err := p.getTime(event, p.EndField)
+	if err != nil || !found {
+		return err
+	}
+
+	d := end.Sub(start)
+	if d < 0 {
+		switch p.OnNegative {
+		case negativeClamp:
+			d = 0
+		case negativeError:
+			return fmt.Errorf("%v (%v) is before %v (%v)", p.EndField, end, p.StartField, start)
+		}
+	}
+
+	if _, err := event.PutValue(p.Target, p.convert(d)); err != nil {
+		return fmt.Errorf("failed to put the time difference into %v: %w", p.Target, err)
+	}
+	return nil
+}
+
+// getTime returns the time stored in field. found is false if the field is
+// missing and ignore_missing is set.
+func (p *processor) getTime(event *beat.Event, field string) (ts time.Time, found bool, err error) {
+	v, err := event.GetValue(field)
+	if err != nil {
+		if p.IgnoreMissing && errors.Is(err, mapstr.ErrKeyNotFound) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("failed to get time field %v: %w", field, err)
+	}
+
+	switch t := v.(type) {
+	case time.Time:
+		return t, true, nil
+	case common.Time:
+		return time.Time(t), true, nil
+	}
+
+	var errs []error
+	for _, layout := range p.Layouts {
+		ts, err := timestamp.ParseByLayout(v, layout, p.tz)
+		if err == nil {
+			return ts, true, nil
+		}
+		errs = append(errs, err)
+	}
+	return time.Time{}, false, fmt.Errorf("failed parsing time field %v='%v': %w", field, v, errors.Join(errs...))
+}
+
+// convert returns d in the configured unit. Nanoseconds and microseconds are
+// returned as integers, milliseconds and seconds as floats.
+func (p *processor) convert(d time.Duration) interface{} {
+	switch p.Unit {
+	case unitMicroseconds:
+		return d.Microseconds()
+	case unitMilliseconds:
+		return float64(d) / float64(time.Millisecond)
+	case unitSeconds:
+		return d.Seconds()
+	default:
+		return d.Nanoseconds()
+	}
+}
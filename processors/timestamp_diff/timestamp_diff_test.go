@@ -0,0 +1,167 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package timestamp_diff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestTimestampDiff(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		config   mapstr.M
+		fields   mapstr.M
+		expected interface{}
+		wantErr  bool
+	}{
+		"default layout in nanoseconds": {
+			config: mapstr.M{},
+			fields: mapstr.M{"event": mapstr.M{
+				"start": "2024-03-01T10:00:00Z",
+				"end":   "2024-03-01T10:00:01.5Z",
+			}},
+			expected: int64(1500 * time.Millisecond),
+		},
+		"time values": {
+			config: mapstr.M{"unit": "milliseconds"},
+			fields: mapstr.M{"event": mapstr.M{
+				"start": start,
+				"end":   common.Time(start.Add(250 * time.Microsecond)),
+			}},
+			expected: 0.25,
+		},
+		"custom layouts and timezone": {
+			config: mapstr.M{
+				"layouts":  []string{"2006-01-02 15:04:05", "UNIX_MS"},
+				"timezone": "+0200",
+				"unit":     "seconds",
+			},
+			fields: mapstr.M{"event": mapstr.M{
+				"start": "2024-03-01 12:00:00",
+				"end":   start.Add(90 * time.Second).UnixMilli(),
+			}},
+			expected: 90.0,
+		},
+		"microseconds": {
+			config: mapstr.M{"unit": "microseconds"},
+			fields: mapstr.M{"event": mapstr.M{
+				"start": "2024-03-01T10:00:00Z",
+				"end":   "2024-03-01T10:00:00.002Z",
+			}},
+			expected: int64(2000),
+		},
+		"negative kept": {
+			config: mapstr.M{},
+			fields: mapstr.M{"event": mapstr.M{
+				"start": "2024-03-01T10:00:01Z",
+				"end":   "2024-03-01T10:00:00Z",
+			}},
+			expected: -int64(time.Second),
+		},
+		"negative clamped": {
+			config: mapstr.M{"on_negative": "clamp"},
+			fields: mapstr.M{"event": mapstr.M{
+				"start": "2024-03-01T10:00:01Z",
+				"end":   "2024-03-01T10:00:00Z",
+			}},
+			expected: int64(0),
+		},
+		"negative error": {
+			config: mapstr.M{"on_negative": "error"},
+			fields: mapstr.M{"event": mapstr.M{
+				"start": "2024-03-01T10:00:01Z",
+				"end":   "2024-03-01T10:00:00Z",
+			}},
+			wantErr: true,
+		},
+		"missing field": {
+			config:  mapstr.M{},
+			fields:  mapstr.M{"event": mapstr.M{"start": "2024-03-01T10:00:01Z"}},
+			wantErr: true,
+		},
+		"ignore missing": {
+			config: mapstr.M{"ignore_missing": true},
+			fields: mapstr.M{"event": mapstr.M{"start": "2024-03-01T10:00:01Z"}},
+		},
+		"invalid value": {
+			config: mapstr.M{},
+			fields: mapstr.M{"event": mapstr.M{
+				"start": "yesterday",
+				"end":   "2024-03-01T10:00:00Z",
+			}},
+			wantErr: true,
+		},
+		"ignore failure": {
+			config: mapstr.M{"ignore_failure": true},
+			fields: mapstr.M{"event": mapstr.M{
+				"start": "yesterday",
+				"end":   "2024-03-01T10:00:00Z",
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := mapstr.M{"start_field": "event.start", "end_field": "event.end"}
+			cfg.Update(test.config)
+			p, err := New(conf.MustNewConfigFrom(cfg))
+			require.NoError(t, err)
+
+			event, err := p.Run(&beat.Event{Fields: test.fields})
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			v, err := event.GetValue("event.duration")
+			if test.expected == nil {
+				assert.ErrorIs(t, err, mapstr.ErrKeyNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, v)
+		})
+	}
+}
+
+func TestTimestampDiffConfig(t *testing.T) {
+	tests := map[string]mapstr.M{
+		"unknown unit":        {"unit": "days"},
+		"unknown on_negative": {"on_negative": "abs"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := mapstr.M{"start_field": "event.start", "end_field": "event.end"}
+			cfg.Update(test)
+			_, err := New(conf.MustNewConfigFrom(cfg))
+			assert.Error(t, err)
+		})
+	}
+}
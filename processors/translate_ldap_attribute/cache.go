@@ -0,0 +1,101 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package translate_ldap_attribute
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type attributeCacheEntry struct {
+	key     string
+	values  []string
+	expires time.Time
+}
+
+// attributeCache is an LRU cache of mapped attribute values keyed by the
+// search attribute value. Entries expire after a fixed TTL. It is safe for
+// concurrent use.
+type attributeCache struct {
+	mutex sync.Mutex
+	size  int
+	ttl   time.Duration
+	lru   *list.List // Front is most recently used.
+	items map[string]*list.Element
+	now   func() time.Time
+}
+
+func newAttributeCache(size int, ttl time.Duration) *attributeCache {
+	return &attributeCache{
+		size:  size,
+		ttl:   ttl,
+		lru:   list.New(),
+		items: map[string]*list.Element{},
+		now:   time.Now,
+	}
+}
+
+// get returns the cached values for key if they exist and have not expired.
+func (c *attributeCache) get(key string) ([]string, bool) {
+	if c.size <= 0 {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, found := c.items[key]
+	if !found {
+		return nil, false
+	}
+	entry := elem.Value.(*attributeCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.values, true
+}
+
+// put stores the values for key, evicting the least recently used entries
+// above the size limit.
+func (c *attributeCache) put(key string, values []string) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if elem, found := c.items[key]; found {
+		entry := elem.Value.(*attributeCacheEntry)
+		entry.values, entry.expires = values, expires
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.lru.PushFront(&attributeCacheEntry{key: key, values: values, expires: expires})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*attributeCacheEntry).key)
+	}
+}
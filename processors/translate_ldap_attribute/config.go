@@ -18,6 +18,8 @@
 package translate_ldap_attribute
 
 import (
+	"time"
+
 	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
 )
 
@@ -33,13 +35,34 @@ type config struct {
 	LDAPSearchTimeLimit int               `config:"ldap_search_time_limit"`
 	LDAPTLS             *tlscommon.Config `config:"ldap_ssl"`
 
-	IgnoreMissing bool `config:"ignore_missing"`
-	IgnoreFailure bool `config:"ignore_failure"`
+	LDAPPoolSize         int           `config:"ldap_pool_size" validate:"min=1"`
+	LDAPDialTimeout      time.Duration `config:"ldap_dial_timeout"`
+	LDAPReconnectBackoff time.Duration `config:"ldap_reconnect_backoff"`
+
+	Cache cacheConfig `config:"cache"`
+
+	IgnoreMissing bool     `config:"ignore_missing"`
+	IgnoreFailure bool     `config:"ignore_failure"`
+	TagOnFailure  []string `config:"tag_on_failure"` // Tags to append when a failure is ignored.
+}
+
+type cacheConfig struct {
+	Size int           `config:"size" validate:"min=0"`
+	TTL  time.Duration `config:"ttl"`
 }
 
 func defaultConfig() config {
 	return config{
-		LDAPSearchAttribute: "objectGUID",
-		LDAPMappedAttribute: "cn",
-		LDAPSearchTimeLimit: 30}
+		LDAPSearchAttribute:  "objectGUID",
+		LDAPMappedAttribute:  "cn",
+		LDAPSearchTimeLimit:  30,
+		LDAPPoolSize:         4,
+		LDAPDialTimeout:      10 * time.Second,
+		LDAPReconnectBackoff: 5 * time.Second,
+		Cache: cacheConfig{
+			Size: 1000,
+			TTL:  10 * time.Minute,
+		},
+		TagOnFailure: []string{"_ldap_lookup_failure"},
+	}
 }
@@ -19,17 +19,33 @@ package translate_ldap_attribute
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"sync"
+	"time"
 
 	"github.com/go-ldap/ldap/v3"
 )
 
-// ldapClient manages a single reusable LDAP connection
+// errConnectBackoff is returned while the client waits before reconnecting
+// after a failed connection attempt.
+var errConnectBackoff = errors.New("LDAP server unavailable, waiting before reconnecting")
+
+// ldapClient manages a pool of long-lived LDAP connections. Connections are
+// opened on demand and replaced when they fail.
 type ldapClient struct {
-	conn *ldap.Conn
-	mu   sync.Mutex
 	*ldapConfig
+
+	// pool holds one slot per connection. A nil slot has no open connection.
+	pool chan *ldap.Conn
+
+	mu      sync.Mutex
+	retryAt time.Time // No connection attempts are made before retryAt.
+	now     func() time.Time
+
+	// dial opens and binds a new connection. It can be replaced in tests.
+	dial func() (*ldap.Conn, error)
 }
 
 type ldapConfig struct {
@@ -41,33 +57,41 @@ type ldapConfig struct {
 	mappedAttr      string
 	searchTimeLimit int
 	tlsConfig       *tls.Config
+	poolSize        int
+	dialTimeout     time.Duration
+	backoff         time.Duration
 }
 
-// newLDAPClient initializes a new ldapClient with a single connection
-func newLDAPClient(config *ldapConfig) (*ldapClient, error) {
-	client := &ldapClient{ldapConfig: config}
-
-	// Establish initial connection
-	if err := client.connect(); err != nil {
-		return nil, err
+// newLDAPClient initializes a new ldapClient. Connections are established
+// lazily so that an unavailable server does not prevent the processor from
+// being created.
+func newLDAPClient(config *ldapConfig) *ldapClient {
+	size := config.poolSize
+	if size <= 0 {
+		size = 1
 	}
-
-	return client, nil
+	client := &ldapClient{
+		ldapConfig: config,
+		pool:       make(chan *ldap.Conn, size),
+		now:        time.Now,
+	}
+	client.dial = client.connect
+	for i := 0; i < size; i++ {
+		client.pool <- nil
+	}
+	return client
 }
 
 // connect establishes a new connection to the LDAP server
-func (client *ldapClient) connect() error {
-	client.mu.Lock()
-	defer client.mu.Unlock()
-
+func (client *ldapClient) connect() (*ldap.Conn, error) {
 	// Connect with or without TLS based on configuration
-	var opts []ldap.DialOpt
+	opts := []ldap.DialOpt{ldap.DialWithDialer(&net.Dialer{Timeout: client.dialTimeout})}
 	if client.tlsConfig != nil {
 		opts = append(opts, ldap.DialWithTLSConfig(client.tlsConfig))
 	}
 	conn, err := ldap.DialURL(client.address, opts...)
 	if err != nil {
-		return fmt.Errorf("failed to dial LDAP server: %w", err)
+		return nil, fmt.Errorf("failed to dial LDAP server: %w", err)
 	}
 
 	if client.password != "" {
@@ -78,35 +102,59 @@ func (client *ldapClient) connect() error {
 
 	if err != nil {
 		conn.Close()
-		return fmt.Errorf("failed to bind to LDAP server: %w", err)
+		return nil, fmt.Errorf("failed to bind to LDAP server: %w", err)
 	}
 
-	client.conn = conn
-	return nil
+	return conn, nil
 }
 
-// reconnect checks the connection's health and reconnects if necessary
-func (client *ldapClient) reconnect() error {
+// acquire takes a connection from the pool, opening a new one if the slot
+// is empty or its connection was closed. The connection, or nil on error,
+// must be given back with release.
+func (client *ldapClient) acquire() (*ldap.Conn, error) {
+	conn := <-client.pool
+	if conn != nil && !conn.IsClosing() {
+		return conn, nil
+	}
+	if conn != nil {
+		conn.Close()
+	}
+
 	client.mu.Lock()
-	defer client.mu.Unlock()
+	waiting := client.now().Before(client.retryAt)
+	client.mu.Unlock()
+	if waiting {
+		return nil, errConnectBackoff
+	}
 
-	// Check if the connection is still alive
-	if client.conn.IsClosing() {
-		return client.connect()
+	conn, err := client.dial()
+	if err != nil {
+		client.mu.Lock()
+		client.retryAt = client.now().Add(client.backoff)
+		client.mu.Unlock()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// release gives a connection back to the pool. Connections that failed with
+// a network error are closed and replaced on the next acquire.
+func (client *ldapClient) release(conn *ldap.Conn, err error) {
+	if conn != nil && err != nil && ldap.IsErrorWithCode(err, ldap.ErrorNetwork) {
+		conn.Close()
+		conn = nil
 	}
-	return nil
+	client.pool <- conn
 }
 
 // findObjectBy searches for an object and returns its mapped values.
 func (client *ldapClient) findObjectBy(searchBy string) ([]string, error) {
-	// Ensure the connection is alive or reconnect if necessary
-	if err := client.reconnect(); err != nil {
-		return nil, fmt.Errorf("failed to reconnect: %w", err)
+	conn, err := client.acquire()
+	if err != nil {
+		client.release(nil, nil)
+		return nil, &connectionError{err: err}
 	}
 
-	client.mu.Lock()
-	defer client.mu.Unlock()
-
 	// Format the filter and perform the search
 	filter := fmt.Sprintf("(%s=%s)", client.searchAttr, searchBy)
 	searchRequest := ldap.NewSearchRequest(
@@ -116,8 +164,12 @@ func (client *ldapClient) findObjectBy(searchBy string) ([]string, error) {
 	)
 
 	// Execute search
-	result, err := client.conn.Search(searchRequest)
+	result, err := conn.Search(searchRequest)
+	client.release(conn, err)
 	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.ErrorNetwork) {
+			return nil, &connectionError{err: err}
+		}
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 	if len(result.Entries) == 0 {
@@ -129,11 +181,24 @@ func (client *ldapClient) findObjectBy(searchBy string) ([]string, error) {
 	return cn, nil
 }
 
-// close closes the LDAP connection
+// close closes all pooled LDAP connections
 func (client *ldapClient) close() {
-	client.mu.Lock()
-	defer client.mu.Unlock()
-	if client.conn != nil {
-		client.conn.Close()
+	for i := 0; i < cap(client.pool); i++ {
+		if conn := <-client.pool; conn != nil {
+			conn.Close()
+		}
 	}
 }
+
+// connectionError reports that the LDAP server could not be reached.
+type connectionError struct {
+	err error
+}
+
+func (e *connectionError) Error() string {
+	return fmt.Sprintf("LDAP connection error: %v", e.err)
+}
+
+func (e *connectionError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,82 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package translate_ldap_attribute
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLDAPClientReconnectBackoff(t *testing.T) {
+	now := time.Unix(0, 0)
+	client := newLDAPClient(&ldapConfig{poolSize: 2, backoff: time.Minute})
+	client.now = func() time.Time { return now }
+
+	dials := 0
+	errDial := errors.New("connection refused")
+	client.dial = func() (*ldap.Conn, error) {
+		dials++
+		return nil, errDial
+	}
+
+	var connErr *connectionError
+	_, err := client.findObjectBy("guid")
+	assert.ErrorAs(t, err, &connErr)
+	assert.ErrorIs(t, err, errDial)
+
+	// No connection attempts are made until the backoff expires.
+	_, err = client.findObjectBy("guid")
+	assert.ErrorAs(t, err, &connErr)
+	assert.ErrorIs(t, err, errConnectBackoff)
+	assert.Equal(t, 1, dials)
+
+	now = now.Add(time.Minute)
+	_, err = client.findObjectBy("guid")
+	assert.ErrorIs(t, err, errDial)
+	assert.Equal(t, 2, dials)
+
+	// All pool slots are given back after failures.
+	assert.Len(t, client.pool, 2)
+	client.close()
+}
+
+func TestAttributeCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newAttributeCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.put("a", []string{"alice"})
+	c.put("b", []string{"bob"})
+
+	values, found := c.get("a")
+	assert.True(t, found)
+	assert.Equal(t, []string{"alice"}, values)
+
+	// "b" is the least recently used entry and gets evicted.
+	c.put("c", []string{"carol"})
+	_, found = c.get("b")
+	assert.False(t, found)
+
+	now = now.Add(time.Minute)
+	_, found = c.get("a")
+	assert.False(t, found)
+}
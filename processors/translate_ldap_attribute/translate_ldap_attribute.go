@@ -20,13 +20,16 @@ package translate_ldap_attribute
 import (
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/common/atomic"
 	"github.com/njcx/libbeat_v8/processors"
 	jsprocessor "github.com/njcx/libbeat_v8/processors/script/javascript/module/processor"
 	conf "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
 	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
 )
 
@@ -34,6 +37,9 @@ const logName = "processor.translate_ldap_attribute"
 
 var errInvalidType = errors.New("search attribute field value is not a string")
 
+// instanceID is used to assign each instance a unique monitoring namespace.
+var instanceID = atomic.MakeUint32(0)
+
 func init() {
 	processors.RegisterPlugin("translate_ldap_attribute", New)
 	jsprocessor.RegisterPlugin("TranslateLDAPAttribute", New)
@@ -42,9 +48,17 @@ func init() {
 type processor struct {
 	config
 	client *ldapClient
+	cache  *attributeCache
+	stats  processorStats
 	log    *logp.Logger
 }
 
+type processorStats struct {
+	Lookups          *monitoring.Int // Lookups sent to the LDAP server.
+	CacheHits        *monitoring.Int
+	ConnectionErrors *monitoring.Int
+}
+
 func New(cfg *conf.C) (beat.Processor, error) {
 	c := defaultConfig()
 	if err := cfg.Unpack(&c); err != nil {
@@ -63,6 +77,9 @@ func newFromConfig(c config) (*processor, error) {
 		searchAttr:      c.LDAPSearchAttribute,
 		mappedAttr:      c.LDAPMappedAttribute,
 		searchTimeLimit: c.LDAPSearchTimeLimit,
+		poolSize:        c.LDAPPoolSize,
+		dialTimeout:     c.LDAPDialTimeout,
+		backoff:         c.LDAPReconnectBackoff,
 	}
 	if c.LDAPTLS != nil {
 		tlsConfig, err := tlscommon.LoadTLSConfig(c.LDAPTLS)
@@ -71,14 +88,21 @@ func newFromConfig(c config) (*processor, error) {
 		}
 		ldapConfig.tlsConfig = tlsConfig.ToConfig()
 	}
-	client, err := newLDAPClient(ldapConfig)
-	if err != nil {
-		return nil, err
-	}
+
+	var (
+		id      = int(instanceID.Inc())
+		metrics = monitoring.Default.NewRegistry(logName+"."+strconv.Itoa(id), monitoring.DoNotReport)
+	)
 	return &processor{
 		config: c,
-		client: client,
-		log:    logp.NewLogger(logName),
+		client: newLDAPClient(ldapConfig),
+		cache:  newAttributeCache(c.Cache.Size, c.Cache.TTL),
+		stats: processorStats{
+			Lookups:          monitoring.NewInt(metrics, "lookups"),
+			CacheHits:        monitoring.NewInt(metrics, "cache_hits"),
+			ConnectionErrors: monitoring.NewInt(metrics, "connection_errors"),
+		},
+		log: logp.NewLogger(logName).With("instance_id", id),
 	}, nil
 }
 
@@ -89,7 +113,12 @@ func (p *processor) String() string {
 
 func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
 	err := p.translateLDAPAttr(event)
-	if err == nil || p.IgnoreFailure || (p.IgnoreMissing && errors.Is(err, mapstr.ErrKeyNotFound)) {
+	if err == nil || (p.IgnoreMissing && errors.Is(err, mapstr.ErrKeyNotFound)) {
+		return event, nil
+	}
+	if p.IgnoreFailure {
+		p.log.Debugf("translate_ldap_attribute failed: %v", err)
+		_ = mapstr.AddTags(event.Fields, p.TagOnFailure)
 		return event, nil
 	}
 	return event, err
@@ -106,9 +135,20 @@ func (p *processor) translateLDAPAttr(event *beat.Event) error {
 		return errInvalidType
 	}
 
-	cn, err := p.client.findObjectBy(guidString)
-	if err != nil {
-		return err
+	cn, found := p.cache.get(guidString)
+	if found {
+		p.stats.CacheHits.Inc()
+	} else {
+		p.stats.Lookups.Inc()
+		cn, err = p.client.findObjectBy(guidString)
+		if err != nil {
+			var connErr *connectionError
+			if errors.As(err, &connErr) {
+				p.stats.ConnectionErrors.Inc()
+			}
+			return err
+		}
+		p.cache.put(guidString, cn)
 	}
 
 	field := p.Field
@@ -0,0 +1,111 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package translate_sid
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lookupResult is the outcome of an account lookup. Lookups of values
+// without a mapping are cached too because resolving an unknown SID is as
+// slow as resolving a known one.
+type lookupResult struct {
+	sid         string
+	account     string
+	domain      string
+	accountType uint32
+	err         error
+}
+
+type lookupCacheEntry struct {
+	key     string
+	result  lookupResult
+	expires time.Time
+}
+
+// lookupCache is an LRU cache of lookup results whose entries expire after
+// a fixed TTL. It is safe for concurrent use.
+type lookupCache struct {
+	mutex sync.Mutex
+	size  int
+	ttl   time.Duration
+	lru   *list.List // Front is most recently used.
+	items map[string]*list.Element
+	now   func() time.Time
+}
+
+func newLookupCache(size int, ttl time.Duration) *lookupCache {
+	return &lookupCache{
+		size:  size,
+		ttl:   ttl,
+		lru:   list.New(),
+		items: map[string]*list.Element{},
+		now:   time.Now,
+	}
+}
+
+// get returns the cached result for key if it exists and has not expired.
+func (c *lookupCache) get(key string) (lookupResult, bool) {
+	if c.size <= 0 {
+		return lookupResult{}, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, found := c.items[key]
+	if !found {
+		return lookupResult{}, false
+	}
+	entry := elem.Value.(*lookupCacheEntry)
+	if c.ttl > 0 && !c.now().Before(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.items, key)
+		return lookupResult{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.result, true
+}
+
+// put stores the result for key, evicting the least recently used entries
+// above the size limit.
+func (c *lookupCache) put(key string, result lookupResult) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if elem, found := c.items[key]; found {
+		entry := elem.Value.(*lookupCacheEntry)
+		entry.result, entry.expires = result, expires
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.lru.PushFront(&lookupCacheEntry{key: key, result: result, expires: expires})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*lookupCacheEntry).key)
+	}
+}
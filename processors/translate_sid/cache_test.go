@@ -0,0 +1,64 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package translate_sid
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newLookupCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.put("S-1-1-0", lookupResult{account: "Everyone"})
+	c.put("S-1-5-7", lookupResult{account: "ANONYMOUS LOGON", domain: "NT AUTHORITY"})
+
+	r, found := c.get("S-1-1-0")
+	assert.True(t, found)
+	assert.Equal(t, "Everyone", r.account)
+
+	// S-1-5-7 is the least recently used entry and gets evicted.
+	errNoMapping := errors.New("no mapping")
+	c.put("S-1-5-2025429265-500", lookupResult{err: errNoMapping})
+	_, found = c.get("S-1-5-7")
+	assert.False(t, found)
+
+	r, found = c.get("S-1-5-2025429265-500")
+	assert.True(t, found)
+	assert.Equal(t, errNoMapping, r.err)
+
+	// All entries expire after the TTL.
+	now = now.Add(time.Minute)
+	_, found = c.get("S-1-1-0")
+	assert.False(t, found)
+	_, found = c.get("S-1-5-2025429265-500")
+	assert.False(t, found)
+	assert.Zero(t, c.lru.Len())
+}
+
+func TestLookupCacheDisabled(t *testing.T) {
+	c := newLookupCache(0, time.Minute)
+	c.put("S-1-1-0", lookupResult{account: "Everyone"})
+	_, found := c.get("S-1-1-0")
+	assert.False(t, found)
+}
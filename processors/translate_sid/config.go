@@ -17,7 +17,10 @@
 
 package translate_sid
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type config struct {
 	Field             string `config:"field"  validate:"required"`
@@ -26,9 +29,33 @@ type config struct {
 	DomainTarget      string `config:"domain_target"`
 	IgnoreMissing     bool   `config:"ignore_missing"`
 	IgnoreFailure     bool   `config:"ignore_failure"`
+
+	// Reverse looks up the SID of the account name contained in Field and
+	// writes it to SIDTarget.
+	Reverse   bool   `config:"reverse"`
+	SIDTarget string `config:"sid_target"`
+
+	Cache cacheConfig `config:"cache"`
+}
+
+type cacheConfig struct {
+	Size int           `config:"size" validate:"min=0"`
+	TTL  time.Duration `config:"ttl"`
 }
 
 func (c *config) Validate() error {
+	if c.Reverse {
+		if c.SIDTarget == "" {
+			return errors.New("sid_target must be configured in reverse mode")
+		}
+		if c.AccountNameTarget != "" {
+			return errors.New("account_name_target cannot be used in reverse mode")
+		}
+		return nil
+	}
+	if c.SIDTarget != "" {
+		return errors.New("sid_target can only be used in reverse mode")
+	}
 	if c.AccountNameTarget == "" && c.AccountTypeTarget == "" && c.DomainTarget == "" {
 		return errors.New("at least one target field must be configured " +
 			"(set account_name_target, account_type_target, and/or domain_target)")
@@ -37,5 +64,10 @@ func (c *config) Validate() error {
 }
 
 func defaultConfig() config {
-	return config{}
+	return config{
+		Cache: cacheConfig{
+			Size: 1024,
+			TTL:  10 * time.Minute,
+		},
+	}
 }
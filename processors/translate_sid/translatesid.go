@@ -38,7 +38,10 @@ import (
 
 const logName = "processor.translate_sid"
 
-var errInvalidType = errors.New("SID field value is not a string")
+var (
+	errInvalidType        = errors.New("SID field value is not a string")
+	errInvalidAccountType = errors.New("account name field value is not a string")
+)
 
 func init() {
 	processors.RegisterPlugin("translate_sid", New)
@@ -47,7 +50,8 @@ func init() {
 
 type processor struct {
 	config
-	log *logp.Logger
+	cache *lookupCache
+	log   *logp.Logger
 }
 
 // New returns a new translate_sid processor for converting windows SID values
@@ -64,13 +68,14 @@ func New(cfg *conf.C) (beat.Processor, error) {
 func newFromConfig(c config) (*processor, error) {
 	return &processor{
 		config: c,
+		cache:  newLookupCache(c.Cache.Size, c.Cache.TTL),
 		log:    logp.NewLogger(logName),
 	}, nil
 }
 
 func (p *processor) String() string {
-	return fmt.Sprintf("translate_sid=[field=%s, account_name_target=%s, account_type_target=%s, domain_target=%s]",
-		p.Field, p.AccountNameTarget, p.AccountTypeTarget, p.DomainTarget)
+	return fmt.Sprintf("translate_sid=[field=%s, reverse=%v, sid_target=%s, account_name_target=%s, account_type_target=%s, domain_target=%s]",
+		p.Field, p.Reverse, p.SIDTarget, p.AccountNameTarget, p.AccountTypeTarget, p.DomainTarget)
 }
 
 func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
@@ -86,46 +91,102 @@ func (p *processor) translateSID(event *beat.Event) error {
 	if err != nil {
 		return err
 	}
-	sidString, ok := v.(string)
+	value, ok := v.(string)
 	if !ok {
+		if p.Reverse {
+			return errInvalidAccountType
+		}
 		return errInvalidType
 	}
 
-	// All SIDs starting with S-1-15-3 are capability SIDs. Active Directory
-	// does not resolve them so don't try.
-	// Reference: https://support.microsoft.com/en-us/help/243330/well-known-security-identifiers-in-windows-operating-systems
-	if strings.HasPrefix(sidString, "S-1-15-3-") {
-		return windows.ERROR_NONE_MAPPED
-
-	}
-
-	sid, err := windows.StringToSid(sidString)
-	if err != nil {
-		return err
+	result, found := p.cache.get(value)
+	if !found {
+		if p.Reverse {
+			result = lookupSID(value)
+		} else {
+			result = lookupAccount(value)
+		}
+		if result.cacheable() {
+			p.cache.put(value, result)
+		}
 	}
-
-	// XXX: May want to introduce an in-memory cache if the lookups are time consuming.
-	account, domain, accountType, err := sid.LookupAccount("")
-	if err != nil {
-		return err
+	if result.err != nil {
+		return result.err
 	}
 
 	// Do all operations even if one fails.
 	var errs []error
+	if p.SIDTarget != "" {
+		if _, err = event.PutValue(p.SIDTarget, result.sid); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if p.AccountNameTarget != "" {
-		if _, err = event.PutValue(p.AccountNameTarget, account); err != nil {
+		if _, err = event.PutValue(p.AccountNameTarget, result.account); err != nil {
 			errs = append(errs, err)
 		}
 	}
 	if p.AccountTypeTarget != "" {
-		if _, err = event.PutValue(p.AccountTypeTarget, winevent.SIDType(accountType).String()); err != nil {
+		if _, err = event.PutValue(p.AccountTypeTarget, winevent.SIDType(result.accountType).String()); err != nil {
 			errs = append(errs, err)
 		}
 	}
 	if p.DomainTarget != "" {
-		if _, err = event.PutValue(p.DomainTarget, domain); err != nil {
+		if _, err = event.PutValue(p.DomainTarget, result.domain); err != nil {
 			errs = append(errs, err)
 		}
 	}
 	return multierr.Combine(errs...)
 }
+
+// cacheable returns true if the result can be cached. Only successful
+// lookups and lookups of values without a mapping are cached. Other errors
+// may be transient, like an unreachable domain controller.
+func (r lookupResult) cacheable() bool {
+	return r.err == nil || errors.Is(r.err, windows.ERROR_NONE_MAPPED)
+}
+
+// lookupAccount resolves the account associated with a SID.
+func lookupAccount(sidString string) lookupResult {
+	// All SIDs starting with S-1-15-3 are capability SIDs. Active Directory
+	// does not resolve them so don't try.
+	// Reference: https://support.microsoft.com/en-us/help/243330/well-known-security-identifiers-in-windows-operating-systems
+	if strings.HasPrefix(sidString, "S-1-15-3-") {
+		return lookupResult{err: windows.ERROR_NONE_MAPPED}
+	}
+
+	sid, err := windows.StringToSid(sidString)
+	if err != nil {
+		return lookupResult{err: err}
+	}
+
+	account, domain, accountType, err := sid.LookupAccount("")
+	if err != nil {
+		return lookupResult{err: err}
+	}
+	return lookupResult{
+		sid:         sidString,
+		account:     account,
+		domain:      domain,
+		accountType: accountType,
+	}
+}
+
+// lookupSID resolves the SID of an account name. The name may be qualified
+// with a domain (DOMAIN\name).
+func lookupSID(account string) lookupResult {
+	if account == "" {
+		return lookupResult{err: windows.ERROR_NONE_MAPPED}
+	}
+
+	sid, domain, accountType, err := windows.LookupSID("", account)
+	if err != nil {
+		return lookupResult{err: err}
+	}
+	return lookupResult{
+		sid:         sid.String(),
+		account:     account,
+		domain:      domain,
+		accountType: accountType,
+	}
+}
@@ -147,6 +147,89 @@ func TestTranslateSIDEmptyTarget(t *testing.T) {
 	}
 }
 
+func TestTranslateSIDReverse(t *testing.T) {
+	var tests = []struct {
+		Account     string
+		SID         string
+		AccountType winevent.SIDType
+		Domain      string
+	}{
+		{Account: `BUILTIN\Administrators`, SID: "S-1-5-32-544", Domain: "BUILTIN", AccountType: winevent.SidTypeAlias},
+		{Account: "Everyone", SID: "S-1-1-0"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Account, func(t *testing.T) {
+			p, err := newFromConfig(config{
+				Field:             "account",
+				Reverse:           true,
+				SIDTarget:         "sid",
+				DomainTarget:      "domain",
+				AccountTypeTarget: "type",
+			})
+			require.NoError(t, err)
+
+			evt, err := p.Run(&beat.Event{Fields: mapstr.M{"account": tc.Account}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.SID, evt.Fields["sid"])
+			if tc.Domain != "" {
+				assertEqualIgnoreCase(t, tc.Domain, evt.Fields["domain"])
+			}
+			if tc.AccountType > 0 {
+				assert.Equal(t, tc.AccountType.String(), evt.Fields["type"])
+			}
+		})
+	}
+
+	t.Run("unresolved", func(t *testing.T) {
+		p, err := newFromConfig(config{
+			Field:     "account",
+			Reverse:   true,
+			SIDTarget: "sid",
+		})
+		require.NoError(t, err)
+
+		evt, err := p.Run(&beat.Event{Fields: mapstr.M{"account": "no-such-account-f3b1c0"}})
+		assert.Equal(t, windows.ERROR_NONE_MAPPED, err)
+		assert.Nil(t, evt.Fields["sid"])
+
+		p.IgnoreFailure = true
+		_, err = p.Run(&beat.Event{Fields: mapstr.M{"account": "no-such-account-f3b1c0"}})
+		assert.NoError(t, err)
+	})
+}
+
+func TestTranslateSIDCache(t *testing.T) {
+	c := defaultConfig()
+	c.Field = "sid"
+	c.AccountNameTarget = "account"
+	p, err := newFromConfig(c)
+	require.NoError(t, err)
+
+	for _, sid := range []string{"S-1-1-0", "S-1-5-2025429265-500"} {
+		_, _ = p.Run(&beat.Event{Fields: mapstr.M{"sid": sid}})
+	}
+
+	result, found := p.cache.get("S-1-1-0")
+	require.True(t, found)
+	assertEqualIgnoreCase(t, "Everyone", result.account)
+
+	// Lookups without a mapping are cached and reported on every event.
+	result, found = p.cache.get("S-1-5-2025429265-500")
+	require.True(t, found)
+	assert.Equal(t, windows.ERROR_NONE_MAPPED, result.err)
+	_, err = p.Run(&beat.Event{Fields: mapstr.M{"sid": "S-1-5-2025429265-500"}})
+	assert.Equal(t, windows.ERROR_NONE_MAPPED, err)
+}
+
+func TestLookupResultCacheable(t *testing.T) {
+	assert.True(t, lookupResult{account: "Everyone"}.cacheable())
+	assert.True(t, lookupResult{err: windows.ERROR_NONE_MAPPED}.cacheable())
+	assert.True(t, lookupResult{err: fmt.Errorf("lookup failed: %w", windows.ERROR_NONE_MAPPED)}.cacheable())
+	assert.False(t, lookupResult{err: windows.ERROR_NO_SUCH_DOMAIN}.cacheable())
+	assert.False(t, lookupResult{err: windows.RPC_S_SERVER_UNAVAILABLE}.cacheable())
+}
+
 func BenchmarkProcessor_Run(b *testing.B) {
 	p, err := newFromConfig(config{
 		Field:             "sid",
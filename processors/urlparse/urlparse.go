@@ -0,0 +1,200 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package urlparse
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/processors"
+	"github.com/njcx/libbeat_v8/processors/checks"
+	jsprocessor "github.com/njcx/libbeat_v8/processors/script/javascript/module/processor"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type urlParse struct {
+	config urlParseConfig
+	log    *logp.Logger
+}
+
+type urlParseConfig struct {
+	Field         string `config:"field" validate:"required"`
+	TargetPrefix  string `config:"target_prefix"`
+	ParseQuery    bool   `config:"parse_query"`
+	IgnoreMissing bool   `config:"ignore_missing"`
+	FailOnError   bool   `config:"fail_on_error"`
+}
+
+func init() {
+	processors.RegisterPlugin("urlparse",
+		checks.ConfigChecked(New,
+			checks.RequireFields("field"),
+			checks.AllowedFields("field", "target_prefix", "parse_query", "ignore_missing", "fail_on_error")))
+	jsprocessor.RegisterPlugin("URLParse", New)
+}
+
+func New(c *config.C) (beat.Processor, error) {
+	config := urlParseConfig{
+		TargetPrefix:  "url",
+		IgnoreMissing: false,
+		FailOnError:   true,
+	}
+
+	if err := c.Unpack(&config); err != nil {
+		return nil, fmt.Errorf("failed to unpack the configuration of urlparse processor: %w", err)
+	}
+
+	return &urlParse{
+		config: config,
+		log:    logp.NewLogger("urlparse"),
+	}, nil
+}
+
+func (p *urlParse) Run(event *beat.Event) (*beat.Event, error) {
+	var backup *beat.Event
+	if p.config.FailOnError {
+		backup = event.Clone()
+	}
+
+	err := p.parseField(event)
+	if err != nil {
+		errMsg := fmt.Errorf("failed to parse field in urlparse processor: %w", err)
+		p.log.Debugw(errMsg.Error(), logp.TypeKey, logp.EventType)
+
+		if p.config.FailOnError {
+			event = backup
+			_, _ = event.PutValue("error.message", errMsg.Error())
+			return event, err
+		}
+	}
+
+	return event, nil
+}
+
+func (p *urlParse) parseField(event *beat.Event) error {
+	value, err := event.GetValue(p.config.Field)
+	if err != nil {
+		if p.config.IgnoreMissing && errors.Is(err, mapstr.ErrKeyNotFound) {
+			return nil
+		}
+		return fmt.Errorf("could not fetch value for key: %s, Error: %w", p.config.Field, err)
+	}
+
+	rawURL, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("invalid type for `field`, expecting a string received %T", value)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// The error returned by url.Parse embeds the input, which may contain
+		// credentials, so only its cause is reported.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("error trying to parse URL: %w", err)
+	}
+
+	fields, err := p.urlFields(u)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range fields {
+		target := k
+		if p.config.TargetPrefix != "" {
+			target = p.config.TargetPrefix + "." + k
+		}
+		if _, err := event.PutValue(target, v); err != nil {
+			return fmt.Errorf("could not put value: %v: %v, %w", v, target, err)
+		}
+	}
+
+	return nil
+}
+
+// urlFields returns the ECS url.* fields that are present in u. Relative URLs
+// only produce the components they contain. The password from the userinfo is
+// never returned.
+func (p *urlParse) urlFields(u *url.URL) (mapstr.M, error) {
+	fields := mapstr.M{}
+	if u.Scheme != "" {
+		fields["scheme"] = u.Scheme
+	}
+	if u.User != nil && u.User.Username() != "" {
+		fields["username"] = u.User.Username()
+	}
+	if host := u.Hostname(); host != "" {
+		fields["domain"] = host
+	}
+	if port := u.Port(); port != "" {
+		n, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q: %w", port, err)
+		}
+		fields["port"] = int(n)
+	}
+	if u.Path != "" {
+		fields["path"] = u.Path
+	} else if u.Opaque != "" {
+		fields["path"] = u.Opaque
+	}
+	if u.RawQuery != "" {
+		fields["query"] = u.RawQuery
+		if p.config.ParseQuery {
+			params, err := queryParams(u.RawQuery)
+			if err != nil {
+				return nil, err
+			}
+			fields["query_params"] = params
+		}
+	}
+	if u.Fragment != "" {
+		fields["fragment"] = u.Fragment
+	}
+	return fields, nil
+}
+
+// queryParams decodes a query string into a map. Parameters that appear once
+// are stored as a string and repeated parameters as a list of strings.
+func queryParams(rawQuery string) (mapstr.M, error) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to parse query %s: %w", rawQuery, err)
+	}
+
+	params := make(mapstr.M, len(values))
+	for k, v := range values {
+		if len(v) == 1 {
+			params[k] = v[0]
+		} else {
+			params[k] = v
+		}
+	}
+	return params, nil
+}
+
+func (p *urlParse) String() string {
+	return "urlparse=" + fmt.Sprintf("%+v", p.config)
+}
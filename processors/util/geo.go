@@ -34,6 +34,11 @@ type GeoConfig struct {
 	RegionName     string `config:"region_name"`
 	RegionISOCode  string `config:"region_iso_code"`
 	CityName       string `config:"city_name"`
+
+	// DatabasePath is the path of a MaxMind City database used to
+	// geolocate the host. Only supported by add_observer_metadata, other
+	// processors reject it.
+	DatabasePath string `config:"database_path"`
 }
 
 // GeoConfigToMap converts `geo` sections to a `mapstr.M`.
@@ -0,0 +1,139 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package processors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/njcx/libbeat_v8/conditions"
+	"github.com/elastic/elastic-agent-libs/config"
+)
+
+// Validate checks a list of processor configurations without constructing
+// the processors. It reports unknown actions, malformed if/then/else blocks
+// and invalid conditions. Settings of the actions themselves are not checked,
+// as some constructors connect to external services.
+func Validate(config PluginConfig) error {
+	for _, procConfig := range config {
+		if procConfig.HasField("if") {
+			if err := validateIfThenElse(procConfig); err != nil {
+				return fmt.Errorf("failed to make if/then/else processor: %w", err)
+			}
+			continue
+		}
+
+		if len(procConfig.GetFields()) != 1 {
+			return fmt.Errorf("each processor must have exactly one "+
+				"action, but found %d actions (%v)",
+				len(procConfig.GetFields()),
+				strings.Join(procConfig.GetFields(), ","))
+		}
+
+		actionName := procConfig.GetFields()[0]
+		actionCfg, err := procConfig.Child(actionName, -1)
+		if err != nil {
+			return err
+		}
+
+		gen, exists := registry.reg[actionName]
+		if !exists {
+			var validActions []string
+			for k := range registry.reg {
+				validActions = append(validActions, k)
+			}
+			return fmt.Errorf("the processor action %s does not exist. Valid actions: %v", actionName, strings.Join(validActions, ", "))
+		}
+
+		if err := validateAction(gen, actionCfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateIfThenElse(cfg *config.C) error {
+	var c ifThenElseConfig
+	if err := cfg.Unpack(&c); err != nil {
+		return err
+	}
+
+	if _, err := conditions.NewCondition(&c.Cond); err != nil {
+		return err
+	}
+
+	validateList := func(c *config.C) error {
+		if c == nil {
+			return nil
+		}
+		if !c.IsArray() {
+			return Validate([]*config.C{c})
+		}
+
+		var pc PluginConfig
+		if err := c.Unpack(&pc); err != nil {
+			return err
+		}
+		return Validate(pc)
+	}
+
+	if err := validateList(c.Then); err != nil {
+		return err
+	}
+	return validateList(c.Else)
+}
+
+// validateAction checks the condition of an action and resolves the
+// sub-section of namespaced actions, like the constructors built by
+// NewConditional and Namespace.Plugin do.
+func validateAction(p pluginer, cfg *config.C) error {
+	if cfg.HasField("when") {
+		sub, err := cfg.Child("when", -1)
+		if err != nil {
+			return err
+		}
+		condConfig := conditions.Config{}
+		if err := sub.Unpack(&condConfig); err != nil {
+			return err
+		}
+		if _, err := conditions.NewCondition(&condConfig); err != nil {
+			return fmt.Errorf("failed to initialize condition: %w", err)
+		}
+	}
+
+	ns, ok := p.(*Namespace)
+	if !ok {
+		return nil
+	}
+
+	section, err := lookupSection(cfg)
+	if err != nil {
+		return err
+	}
+
+	backend, found := ns.reg[section]
+	if !found {
+		return fmt.Errorf("unknown lookup module: %v", section)
+	}
+
+	sub, err := cfg.Child(section, -1)
+	if err != nil {
+		return err
+	}
+	return validateAction(backend, sub)
+}
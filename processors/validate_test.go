@@ -0,0 +1,100 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package processors_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/processors"
+	_ "github.com/njcx/libbeat_v8/processors/actions"
+	conf "github.com/elastic/elastic-agent-libs/config"
+)
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		processors []map[string]interface{}
+		valid      bool
+	}{
+		"known action": {
+			processors: []map[string]interface{}{
+				{"drop_fields": map[string]interface{}{"fields": []string{"a"}}},
+			},
+			valid: true,
+		},
+		"unknown action": {
+			processors: []map[string]interface{}{
+				{"not_a_processor": map[string]interface{}{}},
+			},
+		},
+		"too many actions": {
+			processors: []map[string]interface{}{
+				{
+					"drop_fields":    map[string]interface{}{"fields": []string{"a"}},
+					"include_fields": map[string]interface{}{"fields": []string{"b"}},
+				},
+			},
+		},
+		"invalid condition": {
+			processors: []map[string]interface{}{
+				{"drop_event": map[string]interface{}{
+					"when": map[string]interface{}{"fake": map[string]interface{}{"a": 1}},
+				}},
+			},
+		},
+		"if then else": {
+			processors: []map[string]interface{}{
+				{
+					"if":   map[string]interface{}{"equals": map[string]interface{}{"a": 1}},
+					"then": map[string]interface{}{"drop_event": nil},
+					"else": []map[string]interface{}{{"drop_fields": map[string]interface{}{"fields": []string{"a"}}}},
+				},
+			},
+			valid: true,
+		},
+		"unknown action in else": {
+			processors: []map[string]interface{}{
+				{
+					"if":   map[string]interface{}{"equals": map[string]interface{}{"a": 1}},
+					"then": map[string]interface{}{"drop_event": nil},
+					"else": []map[string]interface{}{{"not_a_processor": nil}},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var config processors.PluginConfig
+			for _, p := range test.processors {
+				c, err := conf.NewConfigFrom(p)
+				require.NoError(t, err)
+				config = append(config, c)
+			}
+
+			err := processors.Validate(config)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package publisher
+
+import (
+	"sync"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+// DropReason categorizes why an event was dropped on its way through the
+// publisher pipeline.
+type DropReason string
+
+const (
+	// DropQueueFull is reported when a client configured with DropIfFull
+	// could not publish an event because the queue was full.
+	DropQueueFull DropReason = "queue_full"
+
+	// DropTooBig is reported when an event exceeded the configured maximum
+	// event size.
+	DropTooBig DropReason = "too_big"
+
+	// DropProcessorError is reported when a processor failed and the event
+	// was not published.
+	DropProcessorError DropReason = "processor_error"
+
+	// DropEncodeError is reported when an output could not encode an event.
+	DropEncodeError DropReason = "encode_error"
+)
+
+// DropReasons lists all reasons tracked by DropCounters.
+var DropReasons = []DropReason{
+	DropQueueFull,
+	DropTooBig,
+	DropProcessorError,
+	DropEncodeError,
+}
+
+// dropsRegistryName is the name of the registry holding the drop counters,
+// relative to the metrics registry passed to NewDropCounters.
+const dropsRegistryName = "drops"
+
+// DropCounters counts dropped events by reason. The counters are exposed via
+// monitoring under "drops.<reason>", with "drops.total" summing up all
+// reasons. All methods are safe to call on a nil *DropCounters.
+type DropCounters struct {
+	total   *monitoring.Uint
+	reasons map[DropReason]*monitoring.Uint
+}
+
+var dropCountersMu sync.Mutex
+
+// NewDropCounters returns the drop counters registered in metrics, creating
+// them on first use. Pipeline components sharing a metrics registry report
+// into the same counters, so operators find all event loss in one place.
+// Returns nil if metrics is nil.
+func NewDropCounters(metrics *monitoring.Registry) *DropCounters {
+	if metrics == nil {
+		return nil
+	}
+
+	dropCountersMu.Lock()
+	defer dropCountersMu.Unlock()
+
+	reg := metrics.GetRegistry(dropsRegistryName)
+	if reg == nil {
+		reg = metrics.NewRegistry(dropsRegistryName)
+	}
+
+	d := &DropCounters{
+		total:   dropCounter(reg, "total"),
+		reasons: make(map[DropReason]*monitoring.Uint, len(DropReasons)),
+	}
+	for _, reason := range DropReasons {
+		d.reasons[reason] = dropCounter(reg, string(reason))
+	}
+	return d
+}
+
+// dropCounter returns the counter named name in reg, registering it if it
+// doesn't exist yet.
+func dropCounter(reg *monitoring.Registry, name string) *monitoring.Uint {
+	if v, ok := reg.Get(name).(*monitoring.Uint); ok {
+		return v
+	}
+	return monitoring.NewUint(reg, name)
+}
+
+// Add records n events dropped for the given reason.
+func (d *DropCounters) Add(reason DropReason, n int) {
+	if d == nil || n <= 0 {
+		return
+	}
+	counter, ok := d.reasons[reason]
+	if !ok {
+		return
+	}
+	counter.Add(uint64(n))
+	d.total.Add(uint64(n))
+}
+
+// Get returns the number of events dropped for the given reason.
+func (d *DropCounters) Get(reason DropReason) uint64 {
+	if d == nil {
+		return 0
+	}
+	if counter, ok := d.reasons[reason]; ok {
+		return counter.Get()
+	}
+	return 0
+}
+
+// Total returns the number of events dropped for any reason.
+func (d *DropCounters) Total() uint64 {
+	if d == nil {
+		return 0
+	}
+	return d.total.Get()
+}
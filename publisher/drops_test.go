@@ -0,0 +1,59 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package publisher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func TestDropCounters(t *testing.T) {
+	t.Run("counts by reason", func(t *testing.T) {
+		drops := NewDropCounters(monitoring.NewRegistry())
+		drops.Add(DropQueueFull, 2)
+		drops.Add(DropTooBig, 1)
+		drops.Add(DropEncodeError, 0)
+		drops.Add(DropReason("unknown"), 5)
+
+		assert.Equal(t, uint64(2), drops.Get(DropQueueFull))
+		assert.Equal(t, uint64(1), drops.Get(DropTooBig))
+		assert.Equal(t, uint64(0), drops.Get(DropEncodeError))
+		assert.Equal(t, uint64(3), drops.Total(), "Unknown reasons should not be counted")
+	})
+
+	t.Run("shared by registry", func(t *testing.T) {
+		metrics := monitoring.NewRegistry()
+		NewDropCounters(metrics).Add(DropProcessorError, 1)
+		NewDropCounters(metrics).Add(DropProcessorError, 1)
+
+		snapshot := monitoring.CollectFlatSnapshot(metrics, monitoring.Full, true)
+		assert.Equal(t, int64(2), snapshot.Ints["drops.processor_error"])
+		assert.Equal(t, int64(2), snapshot.Ints["drops.total"])
+	})
+
+	t.Run("nil counters", func(t *testing.T) {
+		drops := NewDropCounters(nil)
+		assert.Nil(t, drops)
+		drops.Add(DropQueueFull, 1)
+		assert.Zero(t, drops.Get(DropQueueFull))
+		assert.Zero(t, drops.Total())
+	})
+}
@@ -18,14 +18,18 @@
 package pipeline
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/beat/events"
 	"github.com/njcx/libbeat_v8/common/atomic"
+	"github.com/njcx/libbeat_v8/outputs/codec/json"
 	"github.com/njcx/libbeat_v8/processors"
 	"github.com/njcx/libbeat_v8/publisher"
 	"github.com/njcx/libbeat_v8/publisher/queue"
+	"github.com/njcx/libbeat_v8/publisher/queue/memqueue"
 	"github.com/elastic/elastic-agent-libs/logp"
 )
 
@@ -40,6 +44,24 @@ type client struct {
 	eventFlags publisher.EventFlags
 	canDrop    bool
 
+	// Events whose encoded size exceeds maxEventBytes are dropped before
+	// being published to the queue. The encoder is created on first use and
+	// protected by mutex.
+	maxEventBytes int
+	beatVersion   string
+	encoder       *json.Encoder
+
+	// If sampleRate is larger than 1, only every sampleRate-th event passing
+	// the processors is published. sampleCount is protected by mutex.
+	sampleRate  uint64
+	sampleCount uint64
+
+	// queuedEvents is the pipeline's count of events accepted by the queue.
+	queuedEvents *atomic.Int
+
+	// fillRatio reports the pipeline's queue fill ratio, see QueueFillRatio.
+	fillRatio func() float64
+
 	// Open state, signaling, and sync primitives for coordinating client Close.
 	isOpen    atomic.Bool // set to false during shutdown, such that no new events will be accepted anymore.
 	closeOnce sync.Once   // closeOnce ensure that the client shutdown sequence is only executed once
@@ -58,6 +80,14 @@ type clientCloseWaiter struct {
 	waitClose  time.Duration
 }
 
+// QueueFillRatio implements beat.QueueFillReporter.
+func (c *client) QueueFillRatio() float64 {
+	if c.fillRatio == nil {
+		return 0
+	}
+	return c.fillRatio()
+}
+
 func (c *client) PublishAll(events []beat.Event) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -75,11 +105,6 @@ func (c *client) Publish(e beat.Event) {
 }
 
 func (c *client) publish(e beat.Event) {
-	var (
-		event   = &e
-		publish = true
-	)
-
 	c.onNewEvent()
 
 	if !c.isOpen.Load() {
@@ -88,56 +113,137 @@ func (c *client) publish(e beat.Event) {
 		return
 	}
 
-	if c.processors != nil {
-		var err error
+	if c.processors == nil {
+		c.publishEvent(e)
+		return
+	}
+
+	// Processors implementing beat.MultiEventProcessor can turn the event
+	// into multiple events.
+	events, processorErr := beat.RunMulti(c.processors, &e)
+	if processorErr != nil {
+		// If we introduce a dead-letter queue, this is where we should
+		// route the event to it.
+		c.logger.Errorf("Failed to publish event: %v", processorErr)
+	}
+
+	if len(events) == 0 {
+		c.eventListener.AddEvent(e, false)
+		if processorErr != nil {
+			c.onProcessorError(e)
+		} else {
+			c.onFilteredOut(e)
+		}
+		return
+	}
 
-		event, err = c.processors.Run(event)
-		publish = event != nil
-		if err != nil {
-			// If we introduce a dead-letter queue, this is where we should
-			// route the event to it.
-			c.logger.Errorf("Failed to publish event: %v", err)
+	for i, event := range events {
+		if i > 0 {
+			// Count additional events as new events, so the active
+			// events are balanced once they are ACKed.
+			c.onNewEvent()
 		}
+		c.publishEvent(*event)
 	}
+}
 
-	if event != nil {
-		e = *event
+// publishEvent publishes a processed event to the queue.
+func (c *client) publishEvent(e beat.Event) {
+	if !c.sampled() {
+		c.eventListener.AddEvent(e, false)
+		c.onSampledOut(e)
+		return
 	}
 
-	c.eventListener.AddEvent(e, publish)
-	if !publish {
-		c.onFilteredOut(e)
+	if c.exceedsMaxSize(&e) {
+		c.eventListener.AddEvent(e, false)
+		c.onDroppedTooBig(e)
 		return
 	}
 
-	e = *event
+	c.eventListener.AddEvent(e, true)
+
 	pubEvent := publisher.Event{
 		Content: e,
 		Flags:   c.eventFlags,
 	}
 
-	var published bool
+	var published, timedOut bool
 	if c.canDrop {
 		_, published = c.producer.TryPublish(pubEvent)
 	} else {
-		_, published = c.producer.Publish(pubEvent)
+		published, timedOut = c.publishBlocking(pubEvent)
 	}
-
-	if published {
+	switch {
+	case published:
 		c.onPublished()
-	} else {
+	case (c.canDrop || timedOut) && c.isOpen.Load():
+		c.onDroppedQueueFull(e)
+	default:
 		c.onDroppedOnPublish(e)
 	}
 }
 
+// publishBlocking publishes an event, blocking until the queue accepts it
+// or, if the client has a publish timeout, until the timeout expires.
+func (c *client) publishBlocking(e publisher.Event) (published, timedOut bool) {
+	if p, ok := c.producer.(memqueue.ResultProducer); ok {
+		_, result := p.PublishWithResult(e)
+		return result == memqueue.Published, result == memqueue.PublishTimedOut
+	}
+	_, published = c.producer.Publish(e)
+	return published, false
+}
+
+// sampled reports whether the current event is kept by the client's sampling.
+// The first event of every sampleRate events is kept.
+func (c *client) sampled() bool {
+	if c.sampleRate <= 1 {
+		return true
+	}
+
+	keep := c.sampleCount%c.sampleRate == 0
+	c.sampleCount++
+	return keep
+}
+
+// exceedsMaxSize reports whether the encoded event is larger than the
+// configured maxEventBytes. Events that can not be encoded are passed on, so
+// the output can report the failure.
+func (c *client) exceedsMaxSize(e *beat.Event) bool {
+	if c.maxEventBytes <= 0 {
+		return false
+	}
+
+	if c.encoder == nil {
+		c.encoder = json.New(c.beatVersion, json.Config{})
+	}
+	serialized, err := c.encoder.Encode("", e)
+	if err != nil {
+		return false
+	}
+	return len(serialized) > c.maxEventBytes
+}
+
 func (c *client) Close() error {
+	return c.CloseWithContext(context.Background())
+}
+
+// CloseWithContext implements beat.ContextCloser. Once ctx is done, the client
+// stops waiting for pending events to be ACKed and closes the queue producer,
+// which cancels a Publish call blocked on a full queue.
+func (c *client) CloseWithContext(ctx context.Context) error {
+	var err error
 	if c.isOpen.Swap(false) {
 		// Only do shutdown handling the first time Close is called
 		c.onClosing()
 
 		c.logger.Debug("client: closing acker")
-		c.waiter.signalClose()
+		c.waiter.signalClose(ctx)
 		c.waiter.wait()
+		if err = ctx.Err(); err != nil {
+			c.logger.Debugf("client: stopped waiting for pending events: %v", err)
+		}
 
 		c.eventListener.ClientClosed()
 		c.logger.Debug("client: done closing acker")
@@ -156,7 +262,7 @@ func (c *client) Close() error {
 			c.logger.Debug("client: done closing processors")
 		}
 	}
-	return nil
+	return err
 }
 
 func (c *client) onClosing() {
@@ -177,6 +283,7 @@ func (c *client) onNewEvent() {
 }
 
 func (c *client) onPublished() {
+	c.queuedEvents.Inc()
 	c.observer.publishedEvent()
 	if c.clientListener != nil {
 		c.clientListener.Published()
@@ -187,6 +294,44 @@ func (c *client) onFilteredOut(e beat.Event) {
 	c.observer.filteredEvent()
 }
 
+func (c *client) onProcessorError(e beat.Event) {
+	c.observer.processorErrorEvent()
+}
+
+func (c *client) onSampledOut(e beat.Event) {
+	c.observer.sampledOutEvent()
+}
+
+func (c *client) onDroppedTooBig(e beat.Event) {
+	c.observer.droppedTooBigEvent()
+	if c.logger.IsDebug() {
+		c.logger.Debugf("Dropping event exceeding max size of %d bytes (index: '%s', dataset: '%s')",
+			c.maxEventBytes, eventIndex(e), eventDataset(e))
+	}
+}
+
+// eventIndex returns the index an event is routed to via its metadata, if set.
+func eventIndex(e beat.Event) string {
+	for _, key := range []string{events.FieldMetaIndex, events.FieldMetaRawIndex} {
+		if s, err := events.GetMetaStringValue(e, key); err == nil && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// eventDataset returns the data stream or ECS dataset of an event, if set.
+func eventDataset(e beat.Event) string {
+	for _, key := range []string{"data_stream.dataset", "event.dataset"} {
+		if v, err := e.Fields.GetValue(key); err == nil {
+			if s, ok := v.(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
 func (c *client) onDroppedOnPublish(e beat.Event) {
 	c.observer.failedPublishEvent()
 	if c.clientListener != nil {
@@ -194,6 +339,13 @@ func (c *client) onDroppedOnPublish(e beat.Event) {
 	}
 }
 
+func (c *client) onDroppedQueueFull(e beat.Event) {
+	c.observer.queueFullEvent()
+	if c.clientListener != nil {
+		c.clientListener.DroppedOnPublish(e)
+	}
+}
+
 func newClientCloseWaiter(timeout time.Duration) *clientCloseWaiter {
 	return &clientCloseWaiter{
 		signalAll:  make(chan struct{}, 1),
@@ -225,7 +377,7 @@ func (w *clientCloseWaiter) ACKEvents(n int) {
 // closing sequence.
 func (w *clientCloseWaiter) ClientClosed() {}
 
-func (w *clientCloseWaiter) signalClose() {
+func (w *clientCloseWaiter) signalClose(ctx context.Context) {
 	if w == nil {
 		return
 	}
@@ -244,6 +396,7 @@ func (w *clientCloseWaiter) signalClose() {
 		select {
 		case <-w.signalAll:
 		case <-time.After(w.waitClose):
+		case <-ctx.Done():
 		}
 	}()
 }
@@ -18,8 +18,10 @@
 package pipeline
 
 import (
+	"context"
 	"errors"
 	"io"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -28,6 +30,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/common/acker"
 	"github.com/njcx/libbeat_v8/outputs"
 	"github.com/njcx/libbeat_v8/processors"
 	"github.com/njcx/libbeat_v8/publisher"
@@ -275,6 +278,303 @@ func TestClientWaitClose(t *testing.T) {
 	})
 }
 
+func TestClientCloseWithContext(t *testing.T) {
+	logp.TestingSetup()
+
+	q := memqueue.NewQueue(logp.L(), nil, memqueue.Settings{Events: 1}, 0, nil)
+	pipeline := makePipeline(t, Settings{}, q)
+	defer pipeline.Close()
+
+	client, err := pipeline.ConnectWith(beat.ClientConfig{
+		WaitClose: time.Minute,
+	})
+	require.NoError(t, err)
+
+	// Fill the queue with an event which never gets acknowledged, and block
+	// another Publish call on the full queue.
+	client.Publish(beat.Event{})
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		client.Publish(beat.Event{})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- beat.CloseWithContext(ctx, client)
+	}()
+
+	select {
+	case err := <-closed:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected CloseWithContext to stop waiting once the context is done")
+	}
+
+	select {
+	case <-published:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected CloseWithContext to cancel the blocked Publish")
+	}
+}
+
+func TestClientMaxEventBytes(t *testing.T) {
+	logp.TestingSetup()
+
+	metrics := monitoring.NewRegistry()
+	pipeline, err := New(beat.Info{},
+		Monitors{Metrics: metrics},
+		conf.Namespace{},
+		outputs.Group{},
+		Settings{MaxEventBytes: 64},
+	)
+	require.NoError(t, err)
+	pipeline.outputController.queue = makeDiscardQueue()
+	defer pipeline.Close()
+
+	client, err := pipeline.ConnectWith(beat.ClientConfig{})
+	require.NoError(t, err)
+	defer client.Close()
+
+	client.Publish(beat.Event{Fields: mapstr.M{"msg": "small"}})
+	client.Publish(beat.Event{Fields: mapstr.M{"msg": strings.Repeat("x", 128)}})
+
+	snapshot := monitoring.CollectFlatSnapshot(metrics, monitoring.Full, true)
+	assert.Equal(t, int64(2), snapshot.Ints["pipeline.events.total"])
+	assert.Equal(t, int64(1), snapshot.Ints["pipeline.events.published"])
+	assert.Equal(t, int64(1), snapshot.Ints["pipeline.events.dropped_too_big"])
+	assert.Equal(t, int64(1), snapshot.Ints["drops.too_big"])
+	assert.Equal(t, int64(1), snapshot.Ints["drops.total"])
+}
+
+func TestClientMaxEventBytesACK(t *testing.T) {
+	logp.TestingSetup()
+
+	pipeline := makePipeline(t, Settings{MaxEventBytes: 64}, makeACKQueue())
+	defer pipeline.Close()
+
+	var acked int
+	var private []interface{}
+	client, err := pipeline.ConnectWith(beat.ClientConfig{
+		EventListener: acker.EventPrivateReporter(func(n int, data []interface{}) {
+			acked += n
+			private = append(private, data...)
+		}),
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	client.Publish(beat.Event{Fields: mapstr.M{"msg": "small"}, Private: 0})
+	client.Publish(beat.Event{Fields: mapstr.M{"msg": strings.Repeat("x", 128)}, Private: 1})
+	client.Publish(beat.Event{Fields: mapstr.M{"msg": "small"}, Private: 2})
+
+	// The dropped event is only registered once, so the private data of
+	// every event is reported in order.
+	assert.Equal(t, 2, acked)
+	assert.Equal(t, []interface{}{0, 1, 2}, private)
+}
+
+// makeACKQueue returns a queue that accepts and immediately ACKs all events.
+func makeACKQueue() queue.Queue {
+	return &testQueue{
+		producer: func(cfg queue.ProducerConfig) queue.Producer {
+			return &testProducer{
+				publish: func(_ bool, _ queue.Entry) (queue.EntryID, bool) {
+					if cfg.ACK != nil {
+						cfg.ACK(1)
+					}
+					return 0, true
+				},
+			}
+		},
+	}
+}
+
+func TestClientDropCounters(t *testing.T) {
+	logp.TestingSetup()
+
+	makeMetricsPipeline := func(t *testing.T, settings Settings, qu queue.Queue) (*Pipeline, *monitoring.Registry) {
+		t.Helper()
+		metrics := monitoring.NewRegistry()
+		pipeline, err := New(beat.Info{},
+			Monitors{Metrics: metrics},
+			conf.Namespace{},
+			outputs.Group{},
+			settings,
+		)
+		require.NoError(t, err)
+		pipeline.outputController.queue = qu
+		t.Cleanup(func() { pipeline.Close() })
+		return pipeline, metrics
+	}
+
+	t.Run("queue_full", func(t *testing.T) {
+		// The queue accepts the first event and is full afterwards.
+		accepted := 0
+		qu := &testQueue{
+			producer: func(queue.ProducerConfig) queue.Producer {
+				return &testProducer{
+					publish: func(_ bool, _ queue.Entry) (queue.EntryID, bool) {
+						accepted++
+						return 0, accepted == 1
+					},
+				}
+			},
+		}
+		pipeline, metrics := makeMetricsPipeline(t, Settings{}, qu)
+
+		client, err := pipeline.ConnectWith(beat.ClientConfig{PublishMode: beat.DropIfFull})
+		require.NoError(t, err)
+		defer client.Close()
+
+		client.Publish(beat.Event{Fields: mapstr.M{"n": 1}})
+		client.Publish(beat.Event{Fields: mapstr.M{"n": 2}})
+		client.Publish(beat.Event{Fields: mapstr.M{"n": 3}})
+
+		snapshot := monitoring.CollectFlatSnapshot(metrics, monitoring.Full, true)
+		assert.Equal(t, int64(2), snapshot.Ints["drops.queue_full"])
+		assert.Equal(t, int64(2), snapshot.Ints["drops.total"])
+	})
+
+	t.Run("processor_error", func(t *testing.T) {
+		ps := testProcessorSupporter{Processor: &testProcessor{error: true}}
+		pipeline, metrics := makeMetricsPipeline(t, Settings{Processors: ps}, makeDiscardQueue())
+
+		client, err := pipeline.ConnectWith(beat.ClientConfig{})
+		require.NoError(t, err)
+		defer client.Close()
+
+		client.Publish(beat.Event{Fields: mapstr.M{"n": 1}})
+
+		snapshot := monitoring.CollectFlatSnapshot(metrics, monitoring.Full, true)
+		assert.Equal(t, int64(1), snapshot.Ints["drops.processor_error"])
+		assert.Equal(t, int64(1), snapshot.Ints["drops.total"])
+	})
+}
+
+func TestClientSampling(t *testing.T) {
+	logp.TestingSetup()
+
+	metrics := monitoring.NewRegistry()
+	pipeline, err := New(beat.Info{},
+		Monitors{Metrics: metrics},
+		conf.Namespace{},
+		outputs.Group{},
+		Settings{},
+	)
+	require.NoError(t, err)
+	pipeline.outputController.queue = makeDiscardQueue()
+	defer pipeline.Close()
+
+	client, err := pipeline.ConnectWith(beat.ClientConfig{SampleRate: 4})
+	require.NoError(t, err)
+	defer client.Close()
+
+	for i := 0; i < 10; i++ {
+		client.Publish(beat.Event{Fields: mapstr.M{"count": i}})
+	}
+
+	snapshot := monitoring.CollectFlatSnapshot(metrics, monitoring.Full, true)
+	assert.Equal(t, int64(10), snapshot.Ints["pipeline.events.total"])
+	assert.Equal(t, int64(3), snapshot.Ints["pipeline.events.published"])
+	assert.Equal(t, int64(7), snapshot.Ints["pipeline.events.sampled_out"])
+}
+
+func TestClientSamplingACK(t *testing.T) {
+	logp.TestingSetup()
+
+	pipeline := makePipeline(t, Settings{}, makeACKQueue())
+	defer pipeline.Close()
+
+	var acked int
+	var private []interface{}
+	client, err := pipeline.ConnectWith(beat.ClientConfig{
+		SampleRate: 4,
+		EventListener: acker.EventPrivateReporter(func(n int, data []interface{}) {
+			acked += n
+			private = append(private, data...)
+		}),
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	expected := make([]interface{}, 10)
+	for i := range expected {
+		expected[i] = i
+		client.Publish(beat.Event{Fields: mapstr.M{"count": i}, Private: i})
+	}
+
+	// Only the sampled events are ACKed by the queue, the private data of
+	// the sampled out events is reported in between.
+	assert.Equal(t, 3, acked)
+	assert.Equal(t, expected, private)
+}
+
+func TestClientMultiEventProcessor(t *testing.T) {
+	logp.TestingSetup()
+
+	// The processor emits each event twice and drops events without fields.
+	p := &testMultiProcessor{}
+	metrics := monitoring.NewRegistry()
+	pipeline, err := New(beat.Info{},
+		Monitors{Metrics: metrics},
+		conf.Namespace{},
+		outputs.Group{},
+		Settings{Processors: testProcessorSupporter{Processor: p}},
+	)
+	require.NoError(t, err)
+	pipeline.outputController.queue = makeDiscardQueue()
+	defer pipeline.Close()
+
+	client, err := pipeline.ConnectWith(beat.ClientConfig{})
+	require.NoError(t, err)
+	defer client.Close()
+
+	client.Publish(beat.Event{Fields: mapstr.M{"msg": "split"}})
+	client.Publish(beat.Event{})
+
+	snapshot := monitoring.CollectFlatSnapshot(metrics, monitoring.Full, true)
+	assert.Equal(t, int64(3), snapshot.Ints["pipeline.events.total"])
+	assert.Equal(t, int64(2), snapshot.Ints["pipeline.events.published"])
+	assert.Equal(t, int64(1), snapshot.Ints["pipeline.events.filtered"])
+}
+
+func TestClientQueueFillRatio(t *testing.T) {
+	logp.TestingSetup()
+
+	pipeline, err := New(beat.Info{},
+		Monitors{Metrics: monitoring.NewRegistry()},
+		conf.Namespace{},
+		outputs.Group{},
+		Settings{},
+	)
+	require.NoError(t, err)
+	pipeline.outputController.queue = makeDiscardQueue()
+	defer pipeline.Close()
+
+	client, err := pipeline.ConnectWith(beat.ClientConfig{})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// No queue observer has been created yet.
+	ratio, ok := beat.QueueFillRatio(client)
+	require.True(t, ok, "pipeline clients should report the queue fill ratio")
+	assert.Zero(t, ratio)
+
+	observer := queue.NewQueueObserver(monitoring.NewRegistry())
+	observer.MaxEvents(10)
+	for i := 0; i < 4; i++ {
+		observer.AddEvent(0)
+	}
+	pipeline.outputController.queueObserver.Store(&observer)
+
+	ratio, _ = beat.QueueFillRatio(client)
+	assert.InDelta(t, 0.4, ratio, 1e-9)
+}
+
 func TestMonitoring(t *testing.T) {
 	const (
 		maxEvents  = 123
@@ -337,6 +637,23 @@ func (p *testProcessor) ErrorSwitch() {
 	p.error = !p.error
 }
 
+type testMultiProcessor struct{}
+
+func (p *testMultiProcessor) String() string {
+	return "testMultiProcessor"
+}
+
+func (p *testMultiProcessor) Run(in *beat.Event) (*beat.Event, error) {
+	return in, nil
+}
+
+func (p *testMultiProcessor) RunMulti(in *beat.Event) ([]*beat.Event, error) {
+	if len(in.Fields) == 0 {
+		return nil, nil
+	}
+	return []*beat.Event{in, in.Clone()}, nil
+}
+
 type testProcessorSupporter struct {
 	beat.Processor
 }
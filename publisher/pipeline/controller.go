@@ -19,6 +19,7 @@ package pipeline
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/njcx/libbeat_v8/beat"
@@ -49,6 +50,10 @@ type outputController struct {
 	queueLock       sync.Mutex
 	pendingRequests []producerRequest
 
+	// queueObserver is set when the queue is created. It is read without
+	// taking queueLock so that queueFillRatio never blocks.
+	queueObserver atomic.Pointer[queue.Observer]
+
 	// This factory will be used to create the queue when needed, unless
 	// it is overridden by output configuration when outputController.Set
 	// is called.
@@ -209,6 +214,15 @@ func (c *outputController) closeQueue(timeout time.Duration) {
 	}
 }
 
+// queueFillRatio returns the current queue fill ratio, or 0 if the queue has
+// not been created yet.
+func (c *outputController) queueFillRatio() float64 {
+	if ob := c.queueObserver.Load(); ob != nil {
+		return (*ob).FillRatio()
+	}
+	return 0
+}
+
 // queueProducer creates a queue producer with the given config, blocking
 // until the queue is created if it does not yet exist.
 func (c *outputController) queueProducer(config queue.ProducerConfig) queue.Producer {
@@ -271,6 +285,7 @@ func (c *outputController) createQueueIfNeeded(outGrp outputs.Group) {
 		}
 	}
 	queueObserver := queue.NewQueueObserver(pipelineMetrics)
+	c.queueObserver.Store(&queueObserver)
 
 	queue, err := factory(logger, queueObserver, c.inputQueueSize, outGrp.EncoderFactory)
 	if err != nil {
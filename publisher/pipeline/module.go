@@ -24,6 +24,7 @@ import (
 
 	"github.com/njcx/libbeat_v8/beat"
 	"github.com/njcx/libbeat_v8/outputs"
+	"github.com/njcx/libbeat_v8/publisher"
 	"github.com/njcx/libbeat_v8/publisher/processing"
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/monitoring"
@@ -133,7 +134,9 @@ func loadOutput(
 		} else {
 			metrics = monitors.Metrics.NewRegistry("output")
 		}
-		outStats = outputs.NewStats(metrics)
+		stats := outputs.NewStats(metrics)
+		stats.SetDropCounters(publisher.NewDropCounters(monitors.Metrics))
+		outStats = stats
 	}
 
 	outName, out, err := makeOutput(outStats)
@@ -18,6 +18,7 @@
 package pipeline
 
 import (
+	"github.com/njcx/libbeat_v8/publisher"
 	"github.com/elastic/elastic-agent-libs/monitoring"
 )
 
@@ -41,10 +42,18 @@ type clientObserver interface {
 	newEvent()
 	// An event was filtered by processors before being published
 	filteredEvent()
+	// An event was dropped because a processor failed
+	processorErrorEvent()
 	// An event was published to the queue
 	publishedEvent()
 	// An event was rejected by the queue
 	failedPublishEvent()
+	// An event was rejected by the queue because it was full (DropIfFull)
+	queueFullEvent()
+	// An event was dropped for exceeding the maximum event size
+	droppedTooBigEvent()
+	// An event was dropped by the client's sampling
+	sampledOutEvent()
 	eventsACKed(count int)
 }
 
@@ -64,6 +73,10 @@ type retryObserver interface {
 type metricsObserver struct {
 	metrics *monitoring.Registry
 	vars    metricsObserverVars
+
+	// drops are the drop counters shared by all pipeline components using
+	// the same metrics registry.
+	drops *publisher.DropCounters
 }
 
 type metricsObserverVars struct {
@@ -74,6 +87,7 @@ type metricsObserverVars struct {
 	eventsTotal, eventsFiltered, eventsPublished, eventsFailed *monitoring.Uint
 	eventsDropped, eventsRetry                                 *monitoring.Uint // (retryer) drop/retry counters
 	activeEvents                                               *monitoring.Uint
+	eventsDroppedTooBig, eventsSampledOut                      *monitoring.Uint
 }
 
 func newMetricsObserver(metrics *monitoring.Registry) *metricsObserver {
@@ -113,7 +127,17 @@ func newMetricsObserver(metrics *monitoring.Registry) *metricsObserver {
 			// events.dropped counts events that were dropped because errors from
 			// the output workers exceeded the configured maximum retry count.
 			eventsDropped: monitoring.NewUint(reg, "events.dropped"),
+
+			// events.dropped_too_big counts events that were dropped by a
+			// pipeline client because their encoded size exceeded the configured
+			// maximum event size.
+			eventsDroppedTooBig: monitoring.NewUint(reg, "events.dropped_too_big"),
+
+			// events.sampled_out counts events that were dropped by the sampling
+			// of a pipeline client before being sent to the queue.
+			eventsSampledOut: monitoring.NewUint(reg, "events.sampled_out"),
 		},
+		drops: publisher.NewDropCounters(metrics),
 	}
 }
 
@@ -149,6 +173,12 @@ func (o *metricsObserver) filteredEvent() {
 	o.vars.activeEvents.Dec()
 }
 
+// (client) event was dropped because a processor failed
+func (o *metricsObserver) processorErrorEvent() {
+	o.filteredEvent()
+	o.drops.Add(publisher.DropProcessorError, 1)
+}
+
 // (client) managed to push an event into the publisher pipeline
 func (o *metricsObserver) publishedEvent() {
 	o.vars.eventsPublished.Inc()
@@ -165,6 +195,25 @@ func (o *metricsObserver) failedPublishEvent() {
 	o.vars.activeEvents.Dec()
 }
 
+// (client) DropIfFull is set and the queue was full
+func (o *metricsObserver) queueFullEvent() {
+	o.failedPublishEvent()
+	o.drops.Add(publisher.DropQueueFull, 1)
+}
+
+// (client) event exceeded the maximum event size and was dropped
+func (o *metricsObserver) droppedTooBigEvent() {
+	o.vars.eventsDroppedTooBig.Inc()
+	o.vars.activeEvents.Dec()
+	o.drops.Add(publisher.DropTooBig, 1)
+}
+
+// (client) event was dropped by the client's sampling
+func (o *metricsObserver) sampledOutEvent() {
+	o.vars.eventsSampledOut.Inc()
+	o.vars.activeEvents.Dec()
+}
+
 //
 // pipeline output events
 //
@@ -183,13 +232,17 @@ type emptyObserver struct{}
 
 var nilObserver observer = (*emptyObserver)(nil)
 
-func (*emptyObserver) cleanup()            {}
-func (*emptyObserver) clientConnected()    {}
-func (*emptyObserver) clientClosed()       {}
-func (*emptyObserver) newEvent()           {}
-func (*emptyObserver) filteredEvent()      {}
-func (*emptyObserver) publishedEvent()     {}
-func (*emptyObserver) failedPublishEvent() {}
-func (*emptyObserver) eventsACKed(n int)   {}
-func (*emptyObserver) eventsDropped(int)   {}
-func (*emptyObserver) eventsRetry(int)     {}
+func (*emptyObserver) cleanup()             {}
+func (*emptyObserver) clientConnected()     {}
+func (*emptyObserver) clientClosed()        {}
+func (*emptyObserver) newEvent()            {}
+func (*emptyObserver) filteredEvent()       {}
+func (*emptyObserver) processorErrorEvent() {}
+func (*emptyObserver) publishedEvent()      {}
+func (*emptyObserver) failedPublishEvent()  {}
+func (*emptyObserver) queueFullEvent()      {}
+func (*emptyObserver) droppedTooBigEvent()  {}
+func (*emptyObserver) sampledOutEvent()     {}
+func (*emptyObserver) eventsACKed(n int)    {}
+func (*emptyObserver) eventsDropped(int)    {}
+func (*emptyObserver) eventsRetry(int)      {}
@@ -68,6 +68,14 @@ type Pipeline struct {
 	waitCloseTimeout time.Duration
 
 	processors processing.Supporter
+
+	// queuedEvents counts events accepted by the queue that have not been
+	// ACKed yet.
+	queuedEvents atomic.Int
+
+	// maxEventBytes is the maximum encoded size of an event accepted by
+	// pipeline clients. Zero disables the check.
+	maxEventBytes int
 }
 
 // Settings is used to pass additional settings to a newly created pipeline instance.
@@ -81,6 +89,12 @@ type Settings struct {
 	Processors processing.Supporter
 
 	InputQueueSize int
+
+	// MaxEventBytes sets the maximum JSON encoded size of an event after
+	// processing. Larger events are dropped before reaching the queue and
+	// counted in the pipeline's events.dropped_too_big metric.
+	// Zero means unlimited.
+	MaxEventBytes int
 }
 
 // WaitCloseMode enumerates the possible behaviors of WaitClose in a pipeline.
@@ -126,6 +140,7 @@ func New(
 		observer:         nilObserver,
 		waitCloseTimeout: settings.WaitClose,
 		processors:       settings.Processors,
+		maxEventBytes:    settings.MaxEventBytes,
 	}
 	if settings.WaitCloseMode == WaitOnPipelineClose && settings.WaitClose > 0 {
 		p.waitCloseTimeout = settings.WaitClose
@@ -160,6 +175,8 @@ func New(
 // Close stops the pipeline, outputs and queue.
 // If WaitClose with WaitOnPipelineClose mode is configured, Close will block
 // for a duration of WaitClose, if there are still active events in the pipeline.
+// The queue is closed as soon as it is drained, and the number of events
+// left in the queue after WaitClose has elapsed is logged.
 // Note: clients must be closed before calling Close.
 func (p *Pipeline) Close() error {
 	log := p.monitors.Logger
@@ -168,6 +185,11 @@ func (p *Pipeline) Close() error {
 
 	// Note: active clients are not closed / disconnected.
 	p.outputController.WaitClose(p.waitCloseTimeout)
+	if p.waitCloseTimeout > 0 {
+		if remaining := p.queuedEvents.Load(); remaining > 0 {
+			log.Warnf("Pipeline did not drain within %v, %d events left in the queue", p.waitCloseTimeout, remaining)
+		}
+	}
 
 	p.observer.cleanup()
 	return nil
@@ -217,6 +239,11 @@ func (p *Pipeline) ConnectWith(cfg beat.ClientConfig) (beat.Client, error) {
 		eventFlags:     eventFlags,
 		canDrop:        canDrop,
 		observer:       p.observer,
+		maxEventBytes:  p.maxEventBytes,
+		sampleRate:     uint64(cfg.SampleRate),
+		queuedEvents:   &p.queuedEvents,
+		fillRatio:      p.outputController.queueFillRatio,
+		beatVersion:    p.beatInfo.Version,
 	}
 
 	ackHandler := cfg.EventListener
@@ -233,11 +260,13 @@ func (p *Pipeline) ConnectWith(cfg beat.ClientConfig) (beat.Client, error) {
 
 	producerCfg := queue.ProducerConfig{
 		ACK: func(count int) {
+			p.queuedEvents.Sub(count)
 			client.observer.eventsACKed(count)
 			if ackHandler != nil {
 				ackHandler.ACKEvents(count)
 			}
 		},
+		PublishTimeout: cfg.PublishTimeout,
 	}
 
 	if ackHandler == nil {
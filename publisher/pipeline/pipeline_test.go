@@ -21,11 +21,17 @@ import (
 	"runtime"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/njcx/libbeat_v8/beat"
 	"github.com/njcx/libbeat_v8/common/atomic"
 	"github.com/njcx/libbeat_v8/publisher/queue"
+	"github.com/njcx/libbeat_v8/publisher/queue/memqueue"
 	"github.com/njcx/libbeat_v8/tests/resources"
+	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
 
@@ -74,6 +80,27 @@ func TestPipelineAcceptsAnyNumberOfClients(t *testing.T) {
 	}
 }
 
+func TestPipelineWaitCloseReportsRemainingEvents(t *testing.T) {
+	logp.TestingSetup()
+
+	const waitClose = 50 * time.Millisecond
+
+	// a memory queue without any output, so it never drains
+	q := memqueue.NewQueue(logp.L(), nil, memqueue.Settings{Events: 10}, 0, nil)
+	pipeline := makePipeline(t, Settings{WaitClose: waitClose, WaitCloseMode: WaitOnPipelineClose}, q)
+
+	client, err := pipeline.Connect()
+	require.NoError(t, err)
+	client.Publish(beat.Event{Fields: mapstr.M{"count": 1}})
+	client.Publish(beat.Event{Fields: mapstr.M{"count": 2}})
+	client.Close()
+
+	start := time.Now()
+	require.NoError(t, pipeline.Close())
+	assert.GreaterOrEqual(t, time.Since(start), waitClose)
+	assert.Equal(t, 2, pipeline.queuedEvents.Load())
+}
+
 // makeDiscardQueue returns a queue that always discards all events
 // the producers are assigned an unique incremental ID, when their
 // close method is called, this ID is returned
@@ -62,16 +62,26 @@ func generate(
 	config generateConfig,
 	id int,
 	errors func(err error),
+	v *validator,
 ) error {
 	settings := beat.ClientConfig{
 		WaitClose: config.WaitClose,
 	}
 
 	logger := logp.NewLogger("publisher_pipeline_stress_generate")
+	var listeners []beat.EventListener
 	if config.ACK {
-		settings.EventListener = acker.Counting(func(n int) {
+		listeners = append(listeners, acker.Counting(func(n int) {
 			logger.Infof("Pipeline client (%v) ACKS; %v", id, n)
-		})
+		}))
+	}
+	if v != nil {
+		listeners = append(listeners, acker.TrackingCounter(func(acked, _ int) {
+			v.producerACKed(id, acked)
+		}))
+	}
+	if len(listeners) > 0 {
+		settings.EventListener = acker.Combine(listeners...)
 	}
 
 	if m := config.PublishMode; m != "" {
@@ -142,11 +152,16 @@ func generate(
 	logger.Infof("start (%v) generator: %v", id, time.Now())
 	defer logger.Infof("stop (%v) generator: %v", id, time.Now())
 
+	if v != nil {
+		defer func() { v.published(id, count.Load()) }()
+	}
+
 	for cs.Active() {
 		event := beat.Event{
 			Timestamp: time.Now(),
 			Fields: mapstr.M{
 				"id":    id,
+				"seq":   count.Load(),
 				"hello": "world",
 				"count": count,
 
@@ -32,6 +32,9 @@ type testOutput struct {
 	config     testOutputConfig
 	observer   outputs.Observer
 	batchCount int
+
+	// validator records the ACKed events if validation is enabled.
+	validator *validator
 }
 
 type testOutputConfig struct {
@@ -56,14 +59,26 @@ func init() {
 }
 
 func makeTestOutput(_ outputs.IndexManager, beat beat.Info, observer outputs.Observer, cfg *conf.C) (outputs.Group, error) {
+	return makeValidatingTestOutput(observer, cfg, nil)
+}
+
+// makeValidatingTestOutput creates the test output, reporting all ACKed
+// events to v if v is not nil.
+func makeValidatingTestOutput(observer outputs.Observer, cfg *conf.C, v *validator) (outputs.Group, error) {
 	config := defaultTestOutputConfig
 	if err := cfg.Unpack(&config); err != nil {
 		return outputs.Fail(err)
 	}
 
+	if v != nil {
+		// Batches are only ACKed in order if a single worker publishes them
+		// and no batch is retried.
+		v.checkBatchOrder = config.Worker == 1 && config.Fail.EveryBatch <= 0
+	}
+
 	clients := make([]outputs.Client, config.Worker)
 	for i := range clients {
-		client := &testOutput{config: config, observer: observer}
+		client := &testOutput{config: config, observer: observer, validator: v}
 		clients[i] = client
 	}
 
@@ -103,6 +118,9 @@ func (t *testOutput) Publish(_ context.Context, batch publisher.Batch) error {
 	// TODO: add support to fail single events at end of batch or randomly
 
 	// ack complete batch
+	if t.validator != nil {
+		t.validator.outputACKed(batch.Events())
+	}
 	batch.ACK()
 	t.observer.AckedEvents(n)
 
@@ -48,12 +48,17 @@ var defaultConfig = config{
 // progress is only started if the `errors` callback is set.
 // RunTests returns and error if test setup failed, but without `errors` some
 // internal errors might not visible.
+// If validate is set, the generated events are numbered and checked at the
+// test output for loss, duplicates, and ordering once the pipeline has been
+// closed. Mismatches are returned as error. Validation requires the `test`
+// output.
 func RunTests(
 	info beat.Info,
 	duration time.Duration,
 	cfg *conf.C,
 	errors func(err error),
-) error {
+	validate bool,
+) (err error) {
 	config := defaultConfig
 	if err := cfg.Unpack(&config); err != nil {
 		return fmt.Errorf("unpacking config failed: %w", err)
@@ -61,6 +66,14 @@ func RunTests(
 
 	log := logp.L()
 
+	var v *validator
+	if validate {
+		if name := config.Output.Name(); name != "test" {
+			return fmt.Errorf("validation requires the test output, got '%v'", name)
+		}
+		v = newValidator()
+	}
+
 	processing, err := processing.MakeDefaultSupport(false, nil)(info, log, cfg)
 	if err != nil {
 		return err
@@ -76,6 +89,10 @@ func RunTests(
 		processing,
 		func(stat outputs.Observer) (string, outputs.Group, error) {
 			cfg := config.Output
+			if v != nil {
+				out, err := makeValidatingTestOutput(stat, cfg.Config(), v)
+				return cfg.Name(), out, err
+			}
 			out, err := outputs.Load(nil, info, stat, cfg.Name(), cfg.Config())
 			return cfg.Name(), out, err
 		},
@@ -87,6 +104,17 @@ func RunTests(
 		log.Info("Stop pipeline")
 		pipeline.Close()
 		log.Info("pipeline closed")
+
+		if v == nil {
+			return
+		}
+		if verr := v.validate(); verr != nil {
+			if err == nil {
+				err = verr
+			}
+		} else {
+			log.Info("validation passed")
+		}
 	}()
 
 	cs := newCloseSignaler()
@@ -98,7 +126,7 @@ func RunTests(
 	for i := 0; i < config.Generate.Worker; i++ {
 		i := i
 		withWG(&genWG, func() {
-			err := generate(cs, pipeline, config.Generate, i, errors)
+			err := generate(cs, pipeline, config.Generate, i, errors, v)
 			if err != nil {
 				log.Errorf("Generator failed with: %v", err)
 			}
@@ -38,10 +38,12 @@ import (
 // additional flags
 var (
 	duration time.Duration // -duration <dur>
+	validate bool          // -validate
 )
 
 func init() {
 	flag.DurationVar(&duration, "duration", 0, "configure max run duration")
+	flag.BoolVar(&validate, "validate", false, "check for lost, duplicate and out of order events")
 }
 
 func TestPipeline(t *testing.T) {
@@ -101,7 +103,7 @@ func TestPipeline(t *testing.T) {
 					t.Error(err)
 				}
 
-				if err := stress.RunTests(info, duration, config, onErr); err != nil {
+				if err := stress.RunTests(info, duration, config, onErr, validate); err != nil {
 					t.Error("Test failed with:", err)
 				}
 			})
@@ -0,0 +1,166 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package stress
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/njcx/libbeat_v8/publisher"
+)
+
+// validator checks the events received by the test output against the events
+// created by the generators. Every generated event carries the id of its
+// generator and a per-generator sequence number. The validator reports events
+// that were ACKed to a generator but never reached the output (loss), events
+// received more than once (duplicates), and events received out of order.
+//
+// Events are ordered within a batch. If the output runs a single worker and
+// never fails a batch, ordering is also checked across batches.
+type validator struct {
+	mu sync.Mutex
+
+	// checkBatchOrder enables the ordering check across batches.
+	checkBatchOrder bool
+
+	producers map[int]*producerState
+}
+
+type producerState struct {
+	// Number of events the generator published.
+	published uint64
+
+	// Number of events the pipeline ACKed to the generator.
+	acked int
+
+	// Sequence numbers received by the output.
+	received map[uint64]struct{}
+
+	// Sequence number of the last event received by the output.
+	last    uint64
+	hasLast bool
+
+	duplicates int
+	outOfOrder int
+	invalid    int
+}
+
+func newValidator() *validator {
+	return &validator{producers: map[int]*producerState{}}
+}
+
+func (v *validator) producer(id int) *producerState {
+	p := v.producers[id]
+	if p == nil {
+		p = &producerState{received: map[uint64]struct{}{}}
+		v.producers[id] = p
+	}
+	return p
+}
+
+// published records that generator id published n events in total.
+func (v *validator) published(id int, n uint64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.producer(id).published = n
+}
+
+// producerACKed records the number of events the pipeline ACKed to generator
+// id.
+func (v *validator) producerACKed(id int, n int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.producer(id).acked += n
+}
+
+// outputACKed records the events of a batch ACKed by the test output.
+func (v *validator) outputACKed(events []publisher.Event) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// last sequence number per generator within this batch
+	batchLast := map[int]uint64{}
+	for _, event := range events {
+		fields := event.Content.Fields
+		id, okID := fields["id"].(int)
+		seq, okSeq := fields["seq"].(uint64)
+		if !okID || !okSeq {
+			// Without generator id we can't attribute the event, count it
+			// against a pseudo-generator.
+			v.producer(-1).invalid++
+			continue
+		}
+
+		p := v.producer(id)
+		if _, exists := p.received[seq]; exists {
+			p.duplicates++
+			continue
+		}
+		p.received[seq] = struct{}{}
+
+		if last, ok := batchLast[id]; ok && seq <= last {
+			p.outOfOrder++
+		} else if v.checkBatchOrder && p.hasLast && seq <= p.last {
+			p.outOfOrder++
+		}
+		batchLast[id] = seq
+		p.last, p.hasLast = seq, true
+	}
+}
+
+// validate returns an error describing all mismatches found, or nil if no
+// event was lost, duplicated or reordered.
+func (v *validator) validate() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	ids := make([]int, 0, len(v.producers))
+	for id := range v.producers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var errs []string
+	for _, id := range ids {
+		p := v.producers[id]
+		if p.invalid > 0 {
+			errs = append(errs, fmt.Sprintf("%d events without generator id or sequence number", p.invalid))
+			continue
+		}
+
+		received := len(p.received)
+		if missing := p.acked - received; missing > 0 {
+			errs = append(errs, fmt.Sprintf(
+				"generator %d: %d events lost (published=%d, acked=%d, received=%d)",
+				id, missing, p.published, p.acked, received))
+		}
+		if p.duplicates > 0 {
+			errs = append(errs, fmt.Sprintf("generator %d: %d duplicate events", id, p.duplicates))
+		}
+		if p.outOfOrder > 0 {
+			errs = append(errs, fmt.Sprintf("generator %d: %d events out of order", id, p.outOfOrder))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("validation failed:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package stress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/publisher"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func seqEvents(id int, seqs ...uint64) []publisher.Event {
+	events := make([]publisher.Event, len(seqs))
+	for i, seq := range seqs {
+		events[i] = publisher.Event{Content: beat.Event{
+			Fields: mapstr.M{"id": id, "seq": seq},
+		}}
+	}
+	return events
+}
+
+func TestValidator(t *testing.T) {
+	t.Run("no mismatches", func(t *testing.T) {
+		v := newValidator()
+		v.checkBatchOrder = true
+		v.outputACKed(seqEvents(0, 0, 1, 2))
+		v.outputACKed(seqEvents(0, 3, 4))
+		v.producerACKed(0, 5)
+		v.published(0, 5)
+
+		assert.NoError(t, v.validate())
+	})
+
+	t.Run("lost events", func(t *testing.T) {
+		v := newValidator()
+		v.outputACKed(seqEvents(0, 0, 1))
+		v.producerACKed(0, 3)
+		v.published(0, 3)
+
+		err := v.validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "generator 0: 1 events lost (published=3, acked=3, received=2)")
+	})
+
+	t.Run("duplicates", func(t *testing.T) {
+		v := newValidator()
+		v.outputACKed(seqEvents(1, 0, 1))
+		v.outputACKed(seqEvents(1, 1))
+		v.producerACKed(1, 2)
+
+		err := v.validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "generator 1: 1 duplicate events")
+	})
+
+	t.Run("out of order within batch", func(t *testing.T) {
+		v := newValidator()
+		v.outputACKed(seqEvents(0, 1, 0))
+		v.producerACKed(0, 2)
+
+		err := v.validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "generator 0: 1 events out of order")
+	})
+
+	t.Run("batch order only checked if enabled", func(t *testing.T) {
+		v := newValidator()
+		v.outputACKed(seqEvents(0, 2, 3))
+		v.outputACKed(seqEvents(0, 0, 1))
+		v.producerACKed(0, 4)
+		assert.NoError(t, v.validate())
+
+		v = newValidator()
+		v.checkBatchOrder = true
+		v.outputACKed(seqEvents(0, 2, 3))
+		v.outputACKed(seqEvents(0, 0, 1))
+		v.producerACKed(0, 4)
+		assert.Error(t, v.validate())
+	})
+}
@@ -95,6 +95,13 @@ func (s *SyncClient) Close() error {
 	return s.client.Close()
 }
 
+// QueueFillRatio returns the fill ratio of the pipeline queue as reported by
+// the wrapped client, see beat.QueueFillReporter.
+func (s *SyncClient) QueueFillRatio() float64 {
+	ratio, _ := beat.QueueFillRatio(s.client)
+	return ratio
+}
+
 // Wait waits until we received a ACK for every events that were sent, this is useful in the
 // context of serverless, because when the handler return the execution of the process is suspended.
 func (s *SyncClient) Wait() {
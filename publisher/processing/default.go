@@ -18,6 +18,7 @@
 package processing
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/njcx/libbeat_v8/asset"
@@ -60,6 +61,9 @@ type builder struct {
 	// global pipeline processors
 	processors *group
 
+	// global-last processors, run after all other processors
+	lastProcessors *group
+
 	alwaysCopy bool
 }
 
@@ -74,6 +78,8 @@ type modifier interface {
 
 type builtinModifier func(beat.Info) mapstr.M
 
+type lastProcessorsModifier processors.PluginConfig
+
 // MakeDefaultBeatSupport creates a new SupportFactory based on NewDefaultSupport.
 // MakeDefaultBeatSupport automatically adds the `ecs.version`, `host.name` and `agent.X` fields
 // to each event.
@@ -97,6 +103,9 @@ func MakeDefaultObserverSupport(normalize bool) SupportFactory {
 // to each event. Builtin fields can be modified using global `processors`, and `fields` only.
 // the fleetDefaultProcessors argument will set the given global-level processors if the beat is currently running under fleet,
 // and no other global-level processors are set.
+// Use WithLastProcessors to declare processors that must run after all
+// client and global processors. Processors configured under
+// `last_processors` run after those.
 func MakeDefaultSupport(
 	normalize bool,
 	fleetDefaultProcessors processors.PluginConfig,
@@ -106,6 +115,7 @@ func MakeDefaultSupport(
 		cfg := struct {
 			mapstr.EventMetadata `config:",inline"`      // Fields and tags to add to each event.
 			Processors           processors.PluginConfig `config:"processors"`
+			LastProcessors       processors.PluginConfig `config:"last_processors"`
 			TimeSeries           bool                    `config:"timeseries.enabled"`
 		}{}
 		if err := beatCfg.Unpack(&cfg); err != nil {
@@ -123,15 +133,50 @@ func MakeDefaultSupport(
 			rawProcessors = cfg.Processors
 		}
 
+		var rawLastProcessors processors.PluginConfig
+		for _, mod := range modifiers {
+			if m, ok := mod.(lastProcessorsModifier); ok {
+				rawLastProcessors = append(rawLastProcessors, m...)
+			}
+		}
+		// The configured `last_processors` run after those of the Beat, so
+		// they can remove any field before the event is published.
+		rawLastProcessors = append(rawLastProcessors, cfg.LastProcessors...)
+		lastProcessors, err := processors.New(rawLastProcessors)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing global-last processors: %w", err)
+		}
+
 		processors, err := processors.New(rawProcessors)
 		if err != nil {
+			_ = lastProcessors.Close()
 			return nil, fmt.Errorf("error initializing processors: %w", err)
 		}
 
-		return newBuilder(info, log, processors, cfg.EventMetadata, modifiers, !normalize, cfg.TimeSeries)
+		b, err := newBuilder(info, log, processors, cfg.EventMetadata, modifiers, !normalize, cfg.TimeSeries)
+		if err != nil {
+			_ = lastProcessors.Close()
+			return nil, err
+		}
+		if len(lastProcessors.List) > 0 {
+			b.lastProcessors = newGroup("global-last", log)
+			for _, p := range lastProcessors.List {
+				b.lastProcessors.add(p)
+			}
+		}
+		return b, nil
 	}
 }
 
+// WithLastProcessors creates a modifier that runs the given processors after
+// all other processors, including the client processors and the global
+// `processors` from the configuration. Fields set by these processors can't be
+// overwritten by client or global processors, only by the processors
+// configured under `last_processors`.
+func WithLastProcessors(cfg processors.PluginConfig) modifier {
+	return lastProcessorsModifier(cfg)
+}
+
 // WithFields creates a modifier with the given default builtin fields.
 func WithFields(fields mapstr.M) modifier {
 	return builtinModifier(func(_ beat.Info) mapstr.M {
@@ -267,6 +312,11 @@ func (b *builder) Processors() []string {
 			procList = append(procList, proc.String())
 		}
 	}
+	if b.lastProcessors != nil {
+		for _, proc := range b.lastProcessors.list {
+			procList = append(procList, proc.String())
+		}
+	}
 
 	return procList
 }
@@ -283,9 +333,15 @@ func (b *builder) Processors() []string {
 //  6. (C) client processors list
 //  7. (P) add builtins
 //  8. (P) pipeline processors list
-//  9. (P) timeseries mangling
-//  10. (P) (if publish/debug enabled) log event
-//  11. (P) (if output disabled) dropEvent
+//  9. (P) global-last processors list
+//  10. (P) timeseries mangling
+//  11. (P) (if publish/debug enabled) log event
+//  12. (P) (if output disabled) dropEvent
+//
+// Later steps take precedence: builtin fields overwrite fields set by client
+// processors, global processors can modify builtin fields, and fields set by
+// global-last processors (see WithLastProcessors and `last_processors`) can't
+// be changed by any of the client or global processors.
 func (b *builder) Create(cfg beat.ProcessingConfig, drop bool) (beat.Processor, error) {
 	var (
 		// pipeline processors
@@ -296,7 +352,7 @@ func (b *builder) Create(cfg beat.ProcessingConfig, drop bool) (beat.Processor,
 		localProcessors = makeClientProcessors(b.log, cfg)
 	)
 
-	needsCopy := b.alwaysCopy || localProcessors != nil || b.processors != nil
+	needsCopy := b.alwaysCopy || localProcessors != nil || b.processors != nil || b.lastProcessors != nil
 
 	builtin := b.builtinMeta
 	if cfg.DisableHost {
@@ -378,21 +434,26 @@ func (b *builder) Create(cfg beat.ProcessingConfig, drop bool) (beat.Processor,
 
 	// setup 8: pipeline processors list
 	if b.processors != nil {
-		// Add the global pipeline as a function processor, so clients cannot close it
-		processors.add(newProcessor(b.processors.title, b.processors.Run))
+		// Add the global pipeline behind a wrapper, so clients cannot close it
+		processors.add(sharedGroup{b.processors})
 	}
 
-	// setup 9: time series metadata
+	// setup 9: global-last processors list
+	if b.lastProcessors != nil {
+		processors.add(sharedGroup{b.lastProcessors})
+	}
+
+	// setup 10: time series metadata
 	if b.timeSeries {
 		processors.add(timeseries.NewTimeSeriesProcessor(b.timeseriesFields))
 	}
 
-	// setup 10: debug print final event (P)
+	// setup 11: debug print final event (P)
 	if b.log.IsDebug() || management.UnderAgent() {
 		processors.add(debugPrintProcessor(b.info, b.log))
 	}
 
-	// setup 11: drop all events if outputs are disabled (P)
+	// setup 12: drop all events if outputs are disabled (P)
 	if drop {
 		processors.add(dropDisabledProcessor)
 	}
@@ -401,10 +462,14 @@ func (b *builder) Create(cfg beat.ProcessingConfig, drop bool) (beat.Processor,
 }
 
 func (b *builder) Close() error {
+	var errs []error
 	if b.processors != nil {
-		return b.processors.Close()
+		errs = append(errs, b.processors.Close())
 	}
-	return nil
+	if b.lastProcessors != nil {
+		errs = append(errs, b.lastProcessors.Close())
+	}
+	return errors.Join(errs...)
 }
 
 func makeClientProcessors(
@@ -428,3 +493,11 @@ func (b builtinModifier) BuiltinFields(info beat.Info) mapstr.M {
 func (b builtinModifier) ClientFields(_ beat.Info, _ beat.ProcessingConfig) mapstr.M {
 	return nil
 }
+
+func (lastProcessorsModifier) BuiltinFields(_ beat.Info) mapstr.M {
+	return nil
+}
+
+func (lastProcessorsModifier) ClientFields(_ beat.Info, _ beat.ProcessingConfig) mapstr.M {
+	return nil
+}
@@ -38,6 +38,7 @@ import (
 	_ "github.com/njcx/libbeat_v8/processors/add_docker_metadata"
 	_ "github.com/njcx/libbeat_v8/processors/add_host_metadata"
 	_ "github.com/njcx/libbeat_v8/processors/add_kubernetes_metadata"
+	_ "github.com/njcx/libbeat_v8/processors/split"
 )
 
 func TestGenerateProcessorList(t *testing.T) {
@@ -480,6 +481,91 @@ func TestProcessingClose(t *testing.T) {
 	assert.True(t, factoryProcessor.closed)
 }
 
+func TestGlobalLastProcessors(t *testing.T) {
+	lastProcessors, err := processors.NewPluginConfigFromList([]mapstr.M{
+		{"add_fields": mapstr.M{"target": "", "fields": mapstr.M{"service.name": "global-last"}}},
+	})
+	require.NoError(t, err)
+
+	beatCfg := config.MustNewConfigFrom(mapstr.M{
+		"processors": []mapstr.M{
+			{"add_fields": mapstr.M{"target": "", "fields": mapstr.M{"service.name": "global"}}},
+		},
+	})
+	factory, err := MakeDefaultSupport(true, nil, WithLastProcessors(lastProcessors))(beat.Info{}, logp.L(), beatCfg)
+	require.NoError(t, err)
+
+	clientProcessors := newGroup("test", logp.L())
+	clientProcessors.add(actions.NewAddFields(mapstr.M{"service": mapstr.M{"name": "client"}}, true, true))
+
+	prog, err := factory.Create(beat.ProcessingConfig{Processor: clientProcessors}, false)
+	require.NoError(t, err)
+
+	actual, err := prog.Run(&beat.Event{Fields: mapstr.M{"hello": "world"}})
+	require.NoError(t, err)
+	assert.Equal(t, mapstr.M{
+		"hello":   "world",
+		"service": mapstr.M{"name": "global-last"},
+	}, actual.Fields)
+
+	assert.Len(t, factory.Processors(), 2)
+	require.NoError(t, factory.Close())
+}
+
+func TestConfiguredLastProcessors(t *testing.T) {
+	lastProcessors, err := processors.NewPluginConfigFromList([]mapstr.M{
+		{"add_fields": mapstr.M{"target": "", "fields": mapstr.M{"service.name": "global-last"}}},
+	})
+	require.NoError(t, err)
+
+	beatCfg := config.MustNewConfigFrom(mapstr.M{
+		"processors": []mapstr.M{
+			{"add_fields": mapstr.M{"target": "", "fields": mapstr.M{"secret": "global"}}},
+		},
+		"last_processors": []mapstr.M{
+			{"drop_fields": mapstr.M{"fields": []string{"secret", "service"}}},
+		},
+	})
+	factory, err := MakeDefaultSupport(true, nil, WithLastProcessors(lastProcessors))(beat.Info{}, logp.L(), beatCfg)
+	require.NoError(t, err)
+
+	clientProcessors := newGroup("test", logp.L())
+	clientProcessors.add(actions.NewAddFields(mapstr.M{"secret": "client"}, true, true))
+
+	prog, err := factory.Create(beat.ProcessingConfig{Processor: clientProcessors}, false)
+	require.NoError(t, err)
+
+	actual, err := prog.Run(&beat.Event{Fields: mapstr.M{"hello": "world"}})
+	require.NoError(t, err)
+	assert.Equal(t, mapstr.M{"hello": "world"}, actual.Fields)
+
+	assert.Len(t, factory.Processors(), 3)
+	require.NoError(t, factory.Close())
+}
+
+func TestGlobalSplitProcessor(t *testing.T) {
+	beatCfg := config.MustNewConfigFrom(mapstr.M{
+		"processors": []mapstr.M{
+			{"split": mapstr.M{"field": "items", "target": "item"}},
+		},
+		"last_processors": []mapstr.M{
+			{"add_fields": mapstr.M{"target": "", "fields": mapstr.M{"last": true}}},
+		},
+	})
+	factory, err := MakeDefaultSupport(true, nil)(beat.Info{}, logp.L(), beatCfg)
+	require.NoError(t, err)
+	defer factory.Close()
+
+	prog, err := factory.Create(beat.ProcessingConfig{}, false)
+	require.NoError(t, err)
+
+	events, err := beat.RunMulti(prog, &beat.Event{Fields: mapstr.M{"items": []interface{}{"a", "b"}}})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, mapstr.M{"item": "a", "last": true}, events[0].Fields)
+	assert.Equal(t, mapstr.M{"item": "b", "last": true}, events[1].Fields)
+}
+
 func TestProcessingDiagnostics(t *testing.T) {
 	factory, err := MakeDefaultSupport(true, nil)(beat.Info{}, logp.L(), config.NewConfig())
 	require.NoError(t, err)
@@ -143,6 +143,45 @@ func (p *group) Run(event *beat.Event) (*beat.Event, error) {
 	return event, nil
 }
 
+// RunMulti is like Run, allowing processors implementing
+// beat.MultiEventProcessor to emit multiple events. Each event is passed on
+// to the remaining processors.
+func (p *group) RunMulti(event *beat.Event) ([]*beat.Event, error) {
+	events := []*beat.Event{event}
+	if p == nil || len(p.list) == 0 {
+		return events, nil
+	}
+
+	var err error
+	for _, sub := range p.list {
+		next := make([]*beat.Event, 0, len(events))
+		for _, e := range events {
+			var out []*beat.Event
+			out, err = beat.RunMulti(sub, e)
+			if err != nil {
+				p.log.Debugf("Fail to apply processor %s: %s", p, err)
+			}
+			next = append(next, out...)
+		}
+		if len(next) == 0 {
+			return nil, err
+		}
+		events = next
+	}
+	return events, nil
+}
+
+// sharedGroup adds a group to a client processor list without exposing
+// Close, so clients cannot close it. Unlike processorFn it forwards RunMulti
+// to support processors emitting multiple events.
+type sharedGroup struct {
+	group *group
+}
+
+func (p sharedGroup) String() string                                { return p.group.String() }
+func (p sharedGroup) Run(e *beat.Event) (*beat.Event, error)        { return p.group.Run(e) }
+func (p sharedGroup) RunMulti(e *beat.Event) ([]*beat.Event, error) { return p.group.RunMulti(e) }
+
 func newProcessor(name string, fn func(*beat.Event) (*beat.Event, error)) *processorFn {
 	return &processorFn{name: name, fn: fn}
 }
@@ -69,6 +69,16 @@ type Settings struct {
 
 	// UseCompression enables or disables LZ4 compression
 	UseCompression bool
+
+	// Serialization is the format used to encode events in new segments.
+	// Existing segments are always read with the format recorded in their
+	// header.
+	Serialization SerializationFormat
+
+	// MaxRetention is the maximum age of a segment that hasn't been read
+	// yet. Older segments are deleted even though their events were never
+	// acknowledged. A value of 0 keeps segments until they are acknowledged.
+	MaxRetention time.Duration
 }
 
 // userConfig holds the parameters for a disk queue that are configurable
@@ -83,6 +93,10 @@ type userConfig struct {
 
 	RetryInterval    *time.Duration `config:"retry_interval" validate:"positive"`
 	MaxRetryInterval *time.Duration `config:"max_retry_interval" validate:"positive"`
+
+	Serialization *SerializationFormat `config:"serialization"`
+
+	MaxRetention *time.Duration `config:"max_retention" validate:"positive"`
 }
 
 func (c *userConfig) Validate() error {
@@ -130,6 +144,8 @@ func DefaultSettings() Settings {
 
 		RetryInterval:    1 * time.Second,
 		MaxRetryInterval: 30 * time.Second,
+
+		Serialization: SerializationCBOR,
 	}
 }
 
@@ -166,6 +182,14 @@ func SettingsForUserConfig(config *config.C) (Settings, error) {
 		settings.MaxRetryInterval = *userConfig.MaxRetryInterval
 	}
 
+	if userConfig.Serialization != nil {
+		settings.Serialization = *userConfig.Serialization
+	}
+
+	if userConfig.MaxRetention != nil {
+		settings.MaxRetention = *userConfig.MaxRetention
+	}
+
 	return settings, nil
 }
 
@@ -18,6 +18,8 @@
 package diskqueue
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/njcx/libbeat_v8/publisher/queue"
@@ -28,12 +30,29 @@ type diskQueueBatch struct {
 	frames []*readFrame
 }
 
+// ErrGetCanceled is returned by GetWith when its context is done before any
+// event could be read from the queue.
+var ErrGetCanceled = errors.New("disk queue get canceled")
+
 func (dq *diskQueue) Get(eventCount int) (queue.Batch, error) {
+	return dq.GetWith(context.Background(), eventCount)
+}
+
+// GetWith is like Get, but returns ErrGetCanceled if ctx is done before
+// at least one event is available. Events already read from the reader loop
+// are always returned, so a canceled context never loses frames.
+func (dq *diskQueue) GetWith(ctx context.Context, eventCount int) (queue.Batch, error) {
 	// We can always eventually read at least one frame unless the queue or the
-	// consumer is closed.
-	frame, ok := <-dq.readerLoop.output
-	if !ok {
-		return nil, fmt.Errorf("tried to read from a closed disk queue")
+	// consumer is closed, or the caller gives up waiting.
+	var frame *readFrame
+	var ok bool
+	select {
+	case frame, ok = <-dq.readerLoop.output:
+		if !ok {
+			return nil, fmt.Errorf("tried to read from a closed disk queue")
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %w", ErrGetCanceled, ctx.Err())
 	}
 	frames := []*readFrame{frame}
 
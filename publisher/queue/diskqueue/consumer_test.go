@@ -18,7 +18,10 @@
 package diskqueue
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -44,6 +47,30 @@ func TestQueueGetObserver(t *testing.T) {
 	assertRegistryUint(t, reg, "queue.consumed.bytes", eventCount*123, "Get call should report consumed bytes")
 }
 
+func TestQueueGetWithCanceledContext(t *testing.T) {
+	reg := monitoring.NewRegistry()
+	dq := diskQueue{
+		observer: queue.NewQueueObserver(reg),
+		readerLoop: &readerLoop{
+			output: make(chan *readFrame, 1),
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	batch, err := dq.GetWith(ctx, 1)
+	assert.Nil(t, batch, "GetWith on an empty queue should not return a batch")
+	assert.True(t, errors.Is(err, ErrGetCanceled), "GetWith should return ErrGetCanceled")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "GetWith should wrap the context error")
+
+	// Once an event is available it is returned even with a live context.
+	dq.readerLoop.output <- &readFrame{bytesOnDisk: 123}
+	batch, err = dq.GetWith(context.Background(), 1)
+	assert.NoError(t, err, "GetWith should succeed when events are available")
+	assert.Equal(t, 1, batch.Count())
+}
+
 func assertRegistryUint(t *testing.T, reg *monitoring.Registry, key string, expected uint64, message string) {
 	t.Helper()
 
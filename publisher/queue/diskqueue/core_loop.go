@@ -17,7 +17,11 @@
 
 package diskqueue
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+	"time"
+)
 
 // This file contains the queue's "core loop" -- the central goroutine
 // that owns all queue state that is not encapsulated in one of the
@@ -29,7 +33,17 @@ func (dq *diskQueue) run() {
 	// Wake up the reader and deleter loops if there are segments to process
 	// from a previous instantiation of the queue.
 	dq.maybeReadPending()
+	dq.expireSegments(time.Now())
 	dq.maybeDeleteACKed()
+	dq.reportOldestSegment()
+
+	// Expired segments are checked periodically if a retention is set.
+	var retentionTick <-chan time.Time
+	if dq.settings.MaxRetention > 0 {
+		ticker := time.NewTicker(retentionCheckInterval(dq.settings.MaxRetention))
+		defer ticker.Stop()
+		retentionTick = ticker.C
+	}
 
 	for {
 		select {
@@ -47,8 +61,13 @@ func (dq *diskQueue) run() {
 			// After receiving new ACKs, a segment might be ready to delete.
 			dq.maybeDeleteACKed()
 
+			// The oldest unacknowledged segment may have changed.
+			dq.reportOldestSegment()
+
 		case <-dq.close:
 			dq.handleShutdown()
+			// Everything is persisted, unblock anyone waiting on Done().
+			close(dq.done)
 			return
 
 		// Writer loop handling
@@ -67,6 +86,10 @@ func (dq *diskQueue) run() {
 			// because pendingFrames hit settings.WriteAheadLimit, wake them up.
 			dq.maybeUnblockProducers()
 
+			// If the queue was empty, the segment just written holds the
+			// oldest entry.
+			dq.reportOldestSegment()
+
 		// Reader loop handling
 		case readerLoopResponse := <-dq.readerLoop.responseChan:
 			dq.handleReaderLoopResponse(readerLoopResponse)
@@ -84,8 +107,72 @@ func (dq *diskQueue) run() {
 			// If there were blocked producers waiting for more queue space,
 			// we might be able to unblock them now.
 			dq.maybeUnblockProducers()
+
+		case now := <-retentionTick:
+			dq.expireSegments(now)
+
+			// Expired segments are deleted like acknowledged ones.
+			dq.maybeDeleteACKed()
+		}
+	}
+}
+
+// retentionCheckInterval returns how often segments are checked for
+// expiration, so they are deleted at most a quarter of the retention time
+// late, or a minute for long retention times.
+func retentionCheckInterval(retention time.Duration) time.Duration {
+	interval := retention / 4
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	if interval <= 0 {
+		interval = retention
+	}
+	return interval
+}
+
+// expireSegments moves segments whose files were last modified more than
+// settings.MaxRetention before now from the reading list to the acked list,
+// so the deleter loop removes them even though their events were never
+// acknowledged. Their events are reported to the observer as expired instead
+// of removed. Only segments that haven't been read at all are expired:
+// the first segment in the reading list is always kept, since consumers may
+// already hold events from it. Since segments are written in order, the
+// oldest segments are expired first.
+func (dq *diskQueue) expireSegments(now time.Time) {
+	if dq.settings.MaxRetention <= 0 || len(dq.segments.reading) < 2 {
+		return
+	}
+	cutoff := now.Add(-dq.settings.MaxRetention)
+
+	expiredCount := 0
+	for _, segment := range dq.segments.reading[1:] {
+		info, err := os.Stat(dq.settings.segmentPath(segment.id))
+		if err != nil || info.ModTime().After(cutoff) {
+			break
 		}
+		expiredCount++
 	}
+	if expiredCount == 0 {
+		return
+	}
+
+	expired := dq.segments.reading[1 : 1+expiredCount]
+	eventCount, byteCount := 0, 0
+	for _, segment := range expired {
+		segment.expired = true
+		eventCount += int(segment.frameCount)
+		byteCount += int(segment.byteCount - segment.headerSize())
+	}
+	dq.segments.acked = append(dq.segments.acked, expired...)
+	dq.segments.reading = append(
+		[]*queueSegment{dq.segments.reading[0]},
+		dq.segments.reading[1+expiredCount:]...)
+
+	dq.observer.ExpireEvents(eventCount, byteCount)
+	dq.logger.Warnf(
+		"Expired %v segments holding %v events older than max_retention (%v)",
+		expiredCount, eventCount, dq.settings.MaxRetention)
 }
 
 func (dq *diskQueue) handleProducerWriteRequest(request producerWriteRequest) {
@@ -172,6 +259,33 @@ func (dq *diskQueue) handleReaderLoopResponse(response readerLoopResponse) {
 	}
 }
 
+// reportOldestSegment reports the modification time of the oldest segment
+// that still has unacknowledged frames to the observer. The disk queue does not
+// store per-event timestamps, so this is only an approximation of the age of
+// the oldest entry.
+func (dq *diskQueue) reportOldestSegment() {
+	segment := dq.segments.oldestUnacked()
+	if segment == nil {
+		if dq.oldestSegmentReported {
+			dq.observer.OldestEntry(time.Time{})
+			dq.oldestSegmentReported = false
+		}
+		return
+	}
+	if dq.oldestSegmentReported && dq.oldestSegmentID == segment.id {
+		return
+	}
+	info, err := os.Stat(dq.settings.segmentPath(segment.id))
+	if err != nil {
+		// The writer loop may not have created the file yet, try again on
+		// the next report.
+		return
+	}
+	dq.observer.OldestEntry(info.ModTime())
+	dq.oldestSegmentID = segment.id
+	dq.oldestSegmentReported = true
+}
+
 func (dq *diskQueue) handleDeleterLoopResponse(response deleterLoopResponse) {
 	dq.deleting = false
 	newAckedSegments := []*queueSegment{}
@@ -185,7 +299,7 @@ func (dq *diskQueue) handleDeleterLoopResponse(response deleterLoopResponse) {
 			errors = append(errors,
 				fmt.Errorf("couldn't delete segment %d: %w",
 					dq.segments.acked[i].id, err))
-		} else {
+		} else if !dq.segments.acked[i].expired {
 			removedEventCount += int(dq.segments.acked[i].frameCount)
 			// For the metrics observer, we (can) only report the size of the raw
 			// events, not the segment header, so subtract that here so it doesn't
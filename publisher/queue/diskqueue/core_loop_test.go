@@ -20,7 +20,11 @@ package diskqueue
 import (
 	"errors"
 	"fmt"
+	"os"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 
 	"github.com/njcx/libbeat_v8/publisher/queue"
 	"github.com/elastic/elastic-agent-libs/logp"
@@ -1012,6 +1016,110 @@ func TestObserverDeleteSegment(t *testing.T) {
 	assertRegistryUint(t, reg, "queue.removed.bytes", 1234+567, "Deleted bytes should be reported")
 }
 
+func TestExpireSegments(t *testing.T) {
+	now := time.Now()
+	makeQueue := func(t *testing.T, retention time.Duration) (*diskQueue, *monitoring.Registry) {
+		t.Helper()
+
+		reg := monitoring.NewRegistry()
+		dq := &diskQueue{
+			logger:   logp.NewLogger("testing"),
+			observer: queue.NewQueueObserver(reg),
+			settings: Settings{Path: t.TempDir(), MaxRetention: retention},
+		}
+		// Segments 0 to 2 are older than the retention time, segment 3 is not.
+		for id, age := range []time.Duration{time.Hour, time.Hour, 2 * time.Minute, time.Second} {
+			segment := &queueSegment{
+				id:         segmentID(id),
+				frameCount: 10,
+				byteCount:  uint64(100*(id+1)) + segmentHeaderSize,
+			}
+			path := dq.settings.segmentPath(segment.id)
+			if err := os.WriteFile(path, nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+				t.Fatal(err)
+			}
+			dq.segments.reading = append(dq.segments.reading, segment)
+		}
+		dq.observer.Restore(40, 100+200+300+400)
+		return dq, reg
+	}
+	segmentIDs := func(segments []*queueSegment) []segmentID {
+		ids := []segmentID{}
+		for _, segment := range segments {
+			ids = append(ids, segment.id)
+		}
+		return ids
+	}
+
+	t.Run("unread segments are expired", func(t *testing.T) {
+		dq, reg := makeQueue(t, time.Minute)
+		dq.expireSegments(now)
+
+		// The first segment may already be read, so it is never expired.
+		assert.Equal(t, []segmentID{0, 3}, segmentIDs(dq.segments.reading))
+		assert.Equal(t, []segmentID{1, 2}, segmentIDs(dq.segments.acked))
+		assertRegistryUint(t, reg, "queue.expired.events", 20, "Expired events should be reported")
+		assertRegistryUint(t, reg, "queue.expired.bytes", 200+300, "Expired bytes should be reported")
+		assertRegistryUint(t, reg, "queue.filled.events", 20, "Expired events should no longer fill the queue")
+	})
+
+	t.Run("newer segments are kept", func(t *testing.T) {
+		dq, reg := makeQueue(t, 30*time.Minute)
+		dq.expireSegments(now)
+
+		assert.Equal(t, []segmentID{0, 2, 3}, segmentIDs(dq.segments.reading))
+		assert.Equal(t, []segmentID{1}, segmentIDs(dq.segments.acked))
+		assertRegistryUint(t, reg, "queue.expired.events", 10, "Expired events should be reported")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		dq, reg := makeQueue(t, 0)
+		dq.expireSegments(now)
+
+		assert.Equal(t, []segmentID{0, 1, 2, 3}, segmentIDs(dq.segments.reading))
+		assert.Empty(t, dq.segments.acked)
+		assertRegistryUint(t, reg, "queue.expired.events", 0, "No events should expire")
+	})
+}
+
+func TestObserverDeleteExpiredSegment(t *testing.T) {
+	// Events of expired segments are reported when they expire, deleting the
+	// segment must not report them as removed (acknowledged) again.
+	reg := monitoring.NewRegistry()
+	dq := diskQueue{
+		logger:   logp.NewLogger("testing"),
+		observer: queue.NewQueueObserver(reg),
+	}
+	dq.observer.Restore(75, 1234+567)
+	dq.segments.acked = []*queueSegment{
+		{
+			frameCount: 50,
+			byteCount:  1234 + segmentHeaderSize,
+			expired:    true,
+		},
+		{
+			frameCount: 25,
+			byteCount:  567 + segmentHeaderSize,
+		},
+	}
+	dq.observer.ExpireEvents(50, 1234)
+	dq.handleDeleterLoopResponse(deleterLoopResponse{results: []error{nil, nil}})
+	assertRegistryUint(t, reg, "queue.removed.events", 25, "Expired events shouldn't be reported as removed")
+	assertRegistryUint(t, reg, "queue.acked", 25, "Expired events shouldn't be reported as acked")
+	assertRegistryUint(t, reg, "queue.removed.bytes", 567, "Expired bytes shouldn't be reported as removed")
+	assertRegistryUint(t, reg, "queue.expired.events", 50, "Expired events should be reported")
+	assertRegistryUint(t, reg, "queue.filled.events", 0, "Queue should be empty after deletion")
+	assertRegistryUint(t, reg, "queue.filled.bytes", 0, "Queue should be empty after deletion")
+}
+
+func TestRetentionCheckInterval(t *testing.T) {
+	assert.Equal(t, 250*time.Millisecond, retentionCheckInterval(time.Second))
+	assert.Equal(t, time.Minute, retentionCheckInterval(24*time.Hour))
+}
+
 func boolRef(b bool) *bool {
 	return &b
 }
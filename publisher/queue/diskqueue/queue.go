@@ -65,6 +65,12 @@ type diskQueue struct {
 	// otherwise.
 	deleting bool
 
+	// The segment whose modification time was last reported to the observer
+	// as the oldest entry time, used to avoid redundant stat calls. Only
+	// valid if oldestSegmentReported is true.
+	oldestSegmentID       segmentID
+	oldestSegmentReported bool
+
 	// The API channel used by diskQueueProducer to write events.
 	producerWriteRequestChan chan producerWriteRequest
 
@@ -272,7 +278,7 @@ func (dq *diskQueue) Producer(cfg queue.ProducerConfig) queue.Producer {
 	return &diskQueueProducer{
 		queue:   dq,
 		config:  cfg,
-		encoder: newEventEncoder(SerializationCBOR),
+		encoder: newEventEncoder(dq.settings.Serialization),
 		done:    make(chan struct{}),
 	}
 }
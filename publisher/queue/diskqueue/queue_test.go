@@ -75,6 +75,49 @@ func TestProduceConsumer(t *testing.T) {
 	t.Run("direct", testWith(makeTestQueue()))
 }
 
+func TestConsumerFaults(t *testing.T) {
+	events := 512
+	batchSize := 32
+	config := queuetest.FaultConfig{
+		Seed:             seed,
+		MaxACKDelay:      5 * time.Millisecond,
+		CrashProbability: 0.1,
+	}
+
+	t.Log("seed: ", seed)
+
+	t.Run("delayed acks", func(t *testing.T) {
+		queuetest.TestDelayedACKs(t, events, batchSize, makeTestQueue(), config)
+	})
+	t.Run("redelivery after restart", func(t *testing.T) {
+		queuetest.TestRedeliveryAfterRestart(t, events, batchSize, makePersistentTestQueue(), config)
+	})
+}
+
+func TestDoneClosedAfterClose(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Path = t.TempDir()
+	queue, err := NewQueue(logp.L(), nil, settings, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-queue.Done():
+		t.Fatal("Done channel closed before the queue was closed")
+	default:
+	}
+
+	if err := queue.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-queue.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Done channel not closed after the queue was closed")
+	}
+}
+
 func makeTestQueue() queuetest.QueueFactory {
 	return func(t *testing.T) queue.Queue {
 		dir, err := ioutil.TempDir("", "diskqueue_test")
@@ -93,6 +136,23 @@ func makeTestQueue() queuetest.QueueFactory {
 	}
 }
 
+// makePersistentTestQueue returns a factory reopening the disk queue on the
+// same directory, so events survive a queue restart.
+func makePersistentTestQueue() queuetest.PersistentQueueFactory {
+	return func(t *testing.T) func() queue.Queue {
+		dir := t.TempDir()
+		return func() queue.Queue {
+			settings := DefaultSettings()
+			settings.Path = dir
+			queue, err := NewQueue(logp.L(), nil, settings, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return queue
+		}
+	}
+}
+
 func (t testQueue) Close() error {
 	err := t.diskQueue.Close()
 	t.teardown()
@@ -108,10 +108,10 @@ func (rl *readerLoop) processRequest(request readerLoopRequest) readerLoopRespon
 
 	// Open the file and seek to the starting position.
 	handle, err := request.segment.getReader(rl.settings)
-	rl.decoder.serializationFormat = handle.serializationFormat
 	if err != nil {
 		return readerLoopResponse{err: err}
 	}
+	rl.decoder.serializationFormat = handle.serializationFormat
 	defer handle.Close()
 
 	_, err = handle.Seek(int64(request.startPosition), io.SeekStart)
@@ -119,6 +119,11 @@ type queueSegment struct {
 	//
 	// Used to count how many frames still need to be acknowledged by consumers.
 	framesRead uint64
+
+	// Set if the segment is deleted because it exceeded the queue's retention
+	// time. Its events were reported as expired rather than acknowledged, so
+	// they are not reported again when the segment is deleted.
+	expired bool
 }
 
 type segmentHeader struct {
@@ -250,11 +255,19 @@ func (segment *queueSegment) getReader(queueSettings Settings) (*segmentReader,
 		sr.serializationFormat = SerializationJSON
 	}
 
-	// Version 1 is CBOR, Version 2 could be CBOR or ProtoBuf, the
-	// options control which
-	if header.version > 0 {
+	// Version 1 is CBOR, Version 2 records the serialization format
+	// in the options.
+	if header.version == 1 {
 		sr.serializationFormat = SerializationCBOR
 	}
+	if header.version >= 2 {
+		sr.serializationFormat, err = serializationFormatForOptions(header.options)
+		if err != nil {
+			sr.src.Close()
+			return nil, fmt.Errorf(
+				"couldn't read serialization format for segment %d: %w", segment.id, err)
+		}
+	}
 
 	if (header.options & ENABLE_ENCRYPTION) == ENABLE_ENCRYPTION {
 		sr.er, err = NewEncryptionReader(sr.src, queueSettings.EncryptionKey)
@@ -295,6 +308,13 @@ func (segment *queueSegment) getWriter(queueSettings Settings) (*segmentWriter,
 		options = options | ENABLE_COMPRESSION
 	}
 
+	codecOptions, err := queueSettings.Serialization.headerOptions()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	options = options | codecOptions
+
 	sw := &segmentWriter{}
 	sw.dst = file
 
@@ -445,6 +465,17 @@ func readSegmentHeader(in io.Reader) (*segmentHeader, error) {
 	return header, nil
 }
 
+// oldestUnacked returns the oldest segment that still contains frames that
+// have not been acknowledged, or nil if there is none.
+func (segments *diskQueueSegments) oldestUnacked() *queueSegment {
+	for _, list := range [][]*queueSegment{segments.acking, segments.reading, segments.writing} {
+		if len(list) > 0 {
+			return list[0]
+		}
+	}
+	return nil
+}
+
 // The number of bytes occupied by all the queue's segment files. This
 // should only be called from the core loop.
 func (segments *diskQueueSegments) sizeOnDisk() uint64 {
@@ -231,3 +231,62 @@ func TestSegmentReaderSeekLocations(t *testing.T) {
 		assert.NotNil(t, err, name)
 	}
 }
+
+func TestSegmentsOldestUnacked(t *testing.T) {
+	segments := diskQueueSegments{}
+	assert.Nil(t, segments.oldestUnacked(), "Empty queue should have no unacked segment")
+
+	segments.writing = []*queueSegment{{id: 3}}
+	assert.Equal(t, segmentID(3), segments.oldestUnacked().id)
+
+	segments.reading = []*queueSegment{{id: 2}}
+	assert.Equal(t, segmentID(2), segments.oldestUnacked().id)
+
+	segments.acking = []*queueSegment{{id: 1}}
+	segments.acked = []*queueSegment{{id: 0}}
+	assert.Equal(t, segmentID(1), segments.oldestUnacked().id, "Acked segments should be ignored")
+}
+
+func TestSegmentsSerializationFormat(t *testing.T) {
+	tests := map[string]struct {
+		id     segmentID
+		format SerializationFormat
+	}{
+		"CBOR": {id: 0, format: SerializationCBOR},
+		"JSON": {id: 1, format: SerializationJSON},
+	}
+	dir := t.TempDir()
+	for name, tc := range tests {
+		settings := DefaultSettings()
+		settings.Path = dir
+		settings.Serialization = tc.format
+		qs := &queueSegment{
+			id: tc.id,
+		}
+		sw, err := qs.getWriter(settings)
+		assert.Nil(t, err, name)
+		err = sw.Close()
+		assert.Nil(t, err, name)
+
+		// The reader should use the format from the segment header, not
+		// the one in the current settings.
+		sr, err := qs.getReader(DefaultSettings())
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.format, sr.serializationFormat, name)
+		err = sr.Close()
+		assert.Nil(t, err, name)
+	}
+}
+
+func TestSerializationFormatForOptions(t *testing.T) {
+	// Segments written before the codec ID was added have no codec bits.
+	format, err := serializationFormatForOptions(ENABLE_ENCRYPTION | ENABLE_COMPRESSION)
+	assert.NoError(t, err)
+	assert.Equal(t, SerializationCBOR, format)
+
+	_, err = serializationFormatForOptions(0xff << segmentCodecShift)
+	assert.Error(t, err, "unknown codec IDs should be rejected")
+
+	_, err = serializationFormatForOptions(ENABLE_PROTOBUF)
+	assert.Error(t, err, "protobuf segments should be rejected")
+}
@@ -22,18 +22,23 @@ package diskqueue
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/njcx/libbeat_v8/beat"
 	"github.com/njcx/libbeat_v8/outputs/codec"
 	"github.com/njcx/libbeat_v8/publisher"
 	"github.com/elastic/elastic-agent-libs/mapstr"
+	structform "github.com/elastic/go-structform"
 	"github.com/elastic/go-structform/cborl"
 	"github.com/elastic/go-structform/gotype"
 	"github.com/elastic/go-structform/json"
 )
 
+// SerializationFormat selects how events are encoded in the data frames of
+// a segment.
 type SerializationFormat int
 
 const (
@@ -41,6 +46,67 @@ const (
 	SerializationCBOR                            // 1
 )
 
+var serializationFormatNames = map[SerializationFormat]string{
+	SerializationJSON: "json",
+	SerializationCBOR: "cbor",
+}
+
+func (f SerializationFormat) String() string {
+	if name, ok := serializationFormatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("SerializationFormat(%d)", int(f))
+}
+
+// Unpack sets the serialization format from its configuration name.
+func (f *SerializationFormat) Unpack(in string) error {
+	for format, name := range serializationFormatNames {
+		if strings.EqualFold(in, name) {
+			*f = format
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown serialization format '%v'", in)
+}
+
+// The codec ID of a schema version 2 segment is stored in the second byte
+// of the header options. Segments written before the codec ID existed have
+// 0 there and are always CBOR, so CBOR keeps ID 0.
+const (
+	segmentCodecShift        = 8
+	segmentCodecMask  uint32 = 0xff << segmentCodecShift
+)
+
+var serializationCodecIDs = map[SerializationFormat]uint32{
+	SerializationCBOR: 0,
+	SerializationJSON: 1,
+}
+
+// headerOptions returns the segment header option bits that record the
+// serialization format.
+func (f SerializationFormat) headerOptions() (uint32, error) {
+	id, ok := serializationCodecIDs[f]
+	if !ok {
+		return 0, fmt.Errorf("unknown serialization format: %d", f)
+	}
+	return id << segmentCodecShift, nil
+}
+
+// serializationFormatForOptions returns the serialization format recorded
+// in the options of a schema version 2 segment header.
+func serializationFormatForOptions(options uint32) (SerializationFormat, error) {
+	if options&ENABLE_PROTOBUF != 0 {
+		return 0, errors.New("protobuf serialization is not supported")
+	}
+	id := (options & segmentCodecMask) >> segmentCodecShift
+	for format, formatID := range serializationCodecIDs {
+		if formatID == id {
+			return format, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown serialization codec id %d", id)
+}
+
 type eventEncoder struct {
 	buf                 bytes.Buffer
 	folder              *gotype.Iterator
@@ -72,7 +138,12 @@ func newEventEncoder(format SerializationFormat) *eventEncoder {
 func (e *eventEncoder) reset() {
 	e.folder = nil
 
-	visitor := cborl.NewVisitor(&e.buf)
+	var visitor structform.Visitor
+	if e.serializationFormat == SerializationJSON {
+		visitor = json.NewVisitor(&e.buf)
+	} else {
+		visitor = cborl.NewVisitor(&e.buf)
+	}
 	// This can't return an error: NewIterator is deterministic based on its
 	// input, and doesn't return an error when called with valid options. In
 	// this case the options are hard-coded to fixed values, so they are
@@ -90,8 +161,8 @@ func (e *eventEncoder) reset() {
 func (e *eventEncoder) encode(evt interface{}) ([]byte, error) {
 	switch v := evt.(type) {
 	case publisher.Event:
-		if e.serializationFormat != SerializationCBOR {
-			return nil, fmt.Errorf("incompatible serialization for type %T. Only CBOR is supported", v)
+		if e.serializationFormat != SerializationCBOR && e.serializationFormat != SerializationJSON {
+			return nil, fmt.Errorf("incompatible serialization for type %T. Only CBOR and JSON are supported", v)
 		}
 		return e.encode_publisher_event(v)
 	default:
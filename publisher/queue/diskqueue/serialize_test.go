@@ -0,0 +1,71 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package diskqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/beat"
+	"github.com/njcx/libbeat_v8/publisher"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestEventEncoderRoundTrip(t *testing.T) {
+	event := publisher.Event{
+		Flags: publisher.GuaranteedSend,
+		Content: beat.Event{
+			Timestamp: time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC),
+			Meta:      mapstr.M{"pipeline": "test"},
+			Fields:    mapstr.M{"message": "hello", "count": int64(3)},
+		},
+	}
+
+	for _, format := range []SerializationFormat{SerializationCBOR, SerializationJSON} {
+		t.Run(format.String(), func(t *testing.T) {
+			encoder := newEventEncoder(format)
+			data, err := encoder.encode(event)
+			require.NoError(t, err)
+
+			decoder := newEventDecoder()
+			decoder.serializationFormat = format
+			copy(decoder.Buffer(len(data)), data)
+			decoded, err := decoder.Decode()
+			require.NoError(t, err)
+
+			result, ok := decoded.(publisher.Event)
+			require.True(t, ok)
+			assert.Equal(t, event.Flags, result.Flags)
+			assert.True(t, event.Content.Timestamp.Equal(result.Content.Timestamp))
+			assert.Equal(t, "test", result.Content.Meta["pipeline"])
+			assert.Equal(t, "hello", result.Content.Fields["message"])
+		})
+	}
+}
+
+func TestSerializationFormatUnpack(t *testing.T) {
+	var format SerializationFormat
+	require.NoError(t, format.Unpack("JSON"))
+	assert.Equal(t, SerializationJSON, format)
+	require.NoError(t, format.Unpack("cbor"))
+	assert.Equal(t, SerializationCBOR, format)
+	assert.Error(t, format.Unpack("protobuf"))
+}
@@ -0,0 +1,319 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package diskqueue
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VerifyReport describes the state of the segment files of a disk queue as
+// found by Verify or Repair.
+type VerifyReport struct {
+	// The segments found in the queue directory, sorted by segment ID.
+	Segments []SegmentReport
+
+	// The total number of valid and corrupt frames in all segments.
+	ValidFrames   uint64
+	CorruptFrames uint64
+}
+
+// SegmentReport describes the state of a single segment file.
+type SegmentReport struct {
+	ID   uint64
+	Path string
+
+	// The size of the segment file on disk, in bytes.
+	Size uint64
+
+	// The schema version and frame count read from the segment header. The
+	// frame count is 0 if the segment was not closed cleanly.
+	Version          uint32
+	HeaderFrameCount uint32
+
+	// The number of frames whose length and checksum are valid, and the
+	// number of frames that are truncated or fail their checksum. Frames
+	// after a corrupt frame length can't be located, so all the data after
+	// it is counted as a single corrupt frame.
+	ValidFrames   uint64
+	CorruptFrames uint64
+
+	// LastGoodPosition is the offset right after the last frame of the
+	// uncorrupted prefix of the segment, and LastGoodFrames the number of
+	// frames in that prefix. For encrypted or compressed segments the offset
+	// refers to the decoded data, not to the file on disk.
+	LastGoodPosition uint64
+	LastGoodFrames   uint64
+
+	// Repaired is set by Repair if the segment was truncated to
+	// LastGoodPosition.
+	Repaired bool
+
+	// Err is the first problem found in the segment, or nil if the segment
+	// is valid.
+	Err error
+}
+
+// Corrupt returns true if any problem was found in the segment.
+func (r SegmentReport) Corrupt() bool {
+	return r.Err != nil
+}
+
+// Verify scans the segment files of the disk queue configured by settings,
+// checking segment headers and frame checksums, without modifying them. It
+// must not be called while a queue is using the same directory. The returned
+// error is only set if the queue directory can't be read; problems in the
+// segments themselves are described in the report.
+func Verify(settings Settings) (VerifyReport, error) {
+	ids, err := segmentIDsInDirectory(settings.directoryPath())
+	if err != nil {
+		return VerifyReport{}, err
+	}
+
+	report := VerifyReport{Segments: make([]SegmentReport, 0, len(ids))}
+	for _, id := range ids {
+		segment := verifySegment(settings, id)
+		report.ValidFrames += segment.ValidFrames
+		report.CorruptFrames += segment.CorruptFrames
+		report.Segments = append(report.Segments, segment)
+	}
+	return report, nil
+}
+
+// Repair verifies the disk queue like Verify, then truncates every corrupt
+// segment to the last frame of its uncorrupted prefix and updates its header
+// frame count. Valid frames that follow a corrupt frame are discarded.
+// Encrypted or compressed segments and segments with an unreadable header
+// can't be truncated; an error is returned for them and they are left
+// unchanged.
+func Repair(settings Settings) (VerifyReport, error) {
+	report, err := Verify(settings)
+	if err != nil {
+		return report, err
+	}
+
+	var errs []error
+	for i := range report.Segments {
+		segment := &report.Segments[i]
+		if !segment.Corrupt() {
+			continue
+		}
+		if err := repairSegment(segment); err != nil {
+			errs = append(errs, fmt.Errorf("couldn't repair segment %d: %w", segment.ID, err))
+			continue
+		}
+		segment.Repaired = true
+	}
+	return report, errors.Join(errs...)
+}
+
+// segmentIDsInDirectory returns the IDs of all segment files in the given
+// directory in ascending order.
+func segmentIDsInDirectory(pathStr string) ([]segmentID, error) {
+	dirEntries, err := os.ReadDir(pathStr)
+	if err != nil {
+		return nil, fmt.Errorf("could not read queue directory '%s': %w", pathStr, err)
+	}
+
+	var ids []segmentID
+	for _, dirEntry := range dirEntries {
+		components := strings.Split(dirEntry.Name(), ".")
+		if len(components) == 2 && strings.ToLower(components[1]) == "seg" {
+			if id, err := strconv.ParseUint(components[0], 10, 64); err == nil {
+				ids = append(ids, segmentID(id))
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func verifySegment(settings Settings, id segmentID) SegmentReport {
+	report := SegmentReport{
+		ID:   uint64(id),
+		Path: settings.segmentPath(id),
+	}
+
+	info, err := os.Stat(report.Path)
+	if err != nil {
+		report.Err = err
+		return report
+	}
+	report.Size = uint64(info.Size())
+
+	file, err := os.Open(report.Path)
+	if err != nil {
+		report.Err = err
+		return report
+	}
+	header, err := readSegmentHeader(autoRetryReader{file})
+	file.Close()
+	if err != nil {
+		report.Err = fmt.Errorf("invalid segment header: %w", err)
+		return report
+	}
+	report.Version = header.version
+	report.HeaderFrameCount = header.frameCount
+
+	segment := &queueSegment{id: id, schemaVersion: &header.version}
+	handle, err := segment.getReader(settings)
+	if err != nil {
+		report.Err = err
+		return report
+	}
+	defer handle.Close()
+
+	position := segment.headerSize()
+	report.LastGoodPosition = position
+	reader := autoRetryReader{handle}
+	for {
+		frameLength, err := verifyFrame(reader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var mismatch errChecksumMismatch
+			if !errors.As(err, &mismatch) {
+				// The frame boundaries are lost, stop scanning.
+				report.CorruptFrames++
+				if report.Err == nil {
+					report.Err = fmt.Errorf("corrupt frame at position %d: %w", position, err)
+				}
+				break
+			}
+			// The frame length is consistent, so scanning can continue
+			// with the next frame.
+			report.CorruptFrames++
+			if report.Err == nil {
+				report.Err = fmt.Errorf("corrupt frame at position %d: %w", position, err)
+			}
+		} else {
+			report.ValidFrames++
+			if report.Err == nil {
+				report.LastGoodPosition = position + uint64(frameLength)
+				report.LastGoodFrames++
+			}
+		}
+		position += uint64(frameLength)
+	}
+
+	if report.Err == nil && header.frameCount > 0 && uint64(header.frameCount) != report.ValidFrames {
+		report.Err = fmt.Errorf("segment header frame count %d doesn't match the %d frames found",
+			header.frameCount, report.ValidFrames)
+	}
+	return report
+}
+
+type errChecksumMismatch struct {
+	checksum, expected uint32
+}
+
+func (e errChecksumMismatch) Error() string {
+	return fmt.Sprintf("data frame checksum mismatch (%x != %x)", e.checksum, e.expected)
+}
+
+// verifyFrame reads one frame from the reader and checks its length and
+// checksum. It returns io.EOF if the reader is at the end of the data, and
+// errChecksumMismatch if only the checksum is wrong, in which case the
+// returned frame length can be used to skip to the next frame.
+func verifyFrame(reader io.Reader) (uint32, error) {
+	var frameLength uint32
+	err := binary.Read(reader, binary.LittleEndian, &frameLength)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+		return 0, fmt.Errorf("couldn't read data frame header: %w", err)
+	}
+	if frameLength <= frameMetadataSize {
+		return 0, fmt.Errorf("data frame with no data (length %d)", frameLength)
+	}
+
+	// From here on the end of the data means the frame is truncated. The
+	// frame length can't be trusted, so the content is hashed as it is read
+	// instead of being buffered. This computes the same checksum as
+	// computeChecksum.
+	hash := crc32.NewIEEE()
+	_ = binary.Write(hash, binary.LittleEndian, &frameLength)
+	if _, err := io.CopyN(hash, reader, int64(frameLength-frameMetadataSize)); err != nil {
+		return 0, fmt.Errorf("couldn't read data frame content: %w", truncated(err))
+	}
+
+	var checksum, duplicateLength uint32
+	if err := binary.Read(reader, binary.LittleEndian, &checksum); err != nil {
+		return 0, fmt.Errorf("couldn't read data frame checksum: %w", truncated(err))
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &duplicateLength); err != nil {
+		return 0, fmt.Errorf("couldn't read data frame footer: %w", truncated(err))
+	}
+	if duplicateLength != frameLength {
+		return 0, fmt.Errorf("inconsistent data frame length (%d vs %d)", frameLength, duplicateLength)
+	}
+	if expected := hash.Sum32(); checksum != expected {
+		return frameLength, errChecksumMismatch{checksum, expected}
+	}
+	return frameLength, nil
+}
+
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func repairSegment(segment *SegmentReport) error {
+	if segment.LastGoodPosition == 0 {
+		// Verify couldn't get past the segment header.
+		return fmt.Errorf("segment can't be read: %w", segment.Err)
+	}
+
+	file, err := os.OpenFile(segment.Path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	header, err := readSegmentHeader(autoRetryReader{file})
+	if err != nil {
+		return err
+	}
+	if header.options&(ENABLE_ENCRYPTION|ENABLE_COMPRESSION) != 0 {
+		return errors.New("encrypted or compressed segments can't be truncated")
+	}
+
+	if err := file.Truncate(int64(segment.LastGoodPosition)); err != nil {
+		return err
+	}
+	if header.version >= 1 {
+		if _, err := file.Seek(4, io.SeekStart); err != nil {
+			return err
+		}
+		if err := binary.Write(file, binary.LittleEndian, uint32(segment.LastGoodFrames)); err != nil {
+			return err
+		}
+	}
+	return file.Sync()
+}
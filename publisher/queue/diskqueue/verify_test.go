@@ -0,0 +1,198 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package diskqueue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestSegment writes a plain schema version 2 segment containing the
+// given frames and returns the offset of each frame.
+func writeTestSegment(t *testing.T, settings Settings, id segmentID, frameCount uint32, frames ...[]byte) []int {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(2))
+	_ = binary.Write(buf, binary.LittleEndian, frameCount)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(0))
+
+	offsets := make([]int, 0, len(frames))
+	for _, data := range frames {
+		offsets = append(offsets, buf.Len())
+		frameLength := uint32(len(data) + frameMetadataSize)
+		_ = binary.Write(buf, binary.LittleEndian, frameLength)
+		buf.Write(data)
+		_ = binary.Write(buf, binary.LittleEndian, computeChecksum(data))
+		_ = binary.Write(buf, binary.LittleEndian, frameLength)
+	}
+	require.NoError(t, os.WriteFile(settings.segmentPath(id), buf.Bytes(), 0600))
+	return offsets
+}
+
+func testVerifySettings(t *testing.T) Settings {
+	settings := DefaultSettings()
+	settings.Path = t.TempDir()
+	return settings
+}
+
+func TestVerifyValidSegments(t *testing.T) {
+	settings := testVerifySettings(t)
+	writeTestSegment(t, settings, 0, 2, []byte("first"), []byte("second"))
+	writeTestSegment(t, settings, 1, 0, []byte("third"))
+	require.NoError(t, os.WriteFile(settings.stateFilePath(), []byte("ignored"), 0600))
+
+	report, err := Verify(settings)
+	require.NoError(t, err)
+	require.Len(t, report.Segments, 2)
+	assert.EqualValues(t, 3, report.ValidFrames)
+	assert.Zero(t, report.CorruptFrames)
+
+	for _, segment := range report.Segments {
+		assert.NoError(t, segment.Err)
+		assert.Equal(t, segment.Size, segment.LastGoodPosition, "Last good position of a valid segment should be its end")
+		assert.Equal(t, segment.ValidFrames, segment.LastGoodFrames)
+	}
+}
+
+func TestVerifyChecksumMismatch(t *testing.T) {
+	settings := testVerifySettings(t)
+	offsets := writeTestSegment(t, settings, 0, 3, []byte("first"), []byte("second"), []byte("third"))
+
+	// Corrupt the content of the second frame.
+	path := settings.segmentPath(0)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[offsets[1]+frameHeaderSize] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	report, err := Verify(settings)
+	require.NoError(t, err)
+	require.Len(t, report.Segments, 1)
+	segment := report.Segments[0]
+	assert.ErrorContains(t, segment.Err, "checksum mismatch")
+	assert.EqualValues(t, 2, segment.ValidFrames, "Frames after a checksum mismatch should still be checked")
+	assert.EqualValues(t, 1, segment.CorruptFrames)
+	assert.EqualValues(t, offsets[1], segment.LastGoodPosition)
+	assert.EqualValues(t, 1, segment.LastGoodFrames)
+
+	// Verify must not modify the segment.
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data, after)
+}
+
+func TestVerifyTruncatedFrame(t *testing.T) {
+	settings := testVerifySettings(t)
+	offsets := writeTestSegment(t, settings, 0, 0, []byte("first"), []byte("second"))
+
+	path := settings.segmentPath(0)
+	require.NoError(t, os.Truncate(path, int64(offsets[1]+6)))
+
+	report, err := Verify(settings)
+	require.NoError(t, err)
+	segment := report.Segments[0]
+	assert.ErrorContains(t, segment.Err, "corrupt frame")
+	assert.EqualValues(t, 1, segment.ValidFrames)
+	assert.EqualValues(t, 1, segment.CorruptFrames)
+	assert.EqualValues(t, offsets[1], segment.LastGoodPosition)
+}
+
+func TestVerifyOversizedFrameLength(t *testing.T) {
+	settings := testVerifySettings(t)
+	writeTestSegment(t, settings, 0, 0, []byte("first"))
+
+	// Append a frame header claiming far more data than the segment holds.
+	path := settings.segmentPath(0)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	goodSize := len(data)
+	data = binary.LittleEndian.AppendUint32(data, 0xfffffff0)
+	data = append(data, []byte("second")...)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	report, err := Verify(settings)
+	require.NoError(t, err)
+	segment := report.Segments[0]
+	assert.ErrorContains(t, segment.Err, "corrupt frame")
+	assert.EqualValues(t, 1, segment.ValidFrames)
+	assert.EqualValues(t, 1, segment.CorruptFrames)
+	assert.EqualValues(t, goodSize, segment.LastGoodPosition)
+}
+
+func TestVerifyInvalidHeader(t *testing.T) {
+	settings := testVerifySettings(t)
+	writeTestSegment(t, settings, 0, 1, []byte("first"))
+	require.NoError(t, os.WriteFile(settings.segmentPath(1), []byte{0xff, 0xff, 0xff, 0xff}, 0600))
+
+	report, err := Verify(settings)
+	require.NoError(t, err)
+	require.Len(t, report.Segments, 2)
+	assert.NoError(t, report.Segments[0].Err)
+	assert.ErrorContains(t, report.Segments[1].Err, "invalid segment header")
+
+	_, err = Repair(settings)
+	assert.Error(t, err, "Repair should fail for a segment with an invalid header")
+}
+
+func TestVerifyHeaderFrameCountMismatch(t *testing.T) {
+	settings := testVerifySettings(t)
+	writeTestSegment(t, settings, 0, 5, []byte("first"))
+
+	report, err := Verify(settings)
+	require.NoError(t, err)
+	assert.ErrorContains(t, report.Segments[0].Err, "frame count")
+	assert.Zero(t, report.CorruptFrames)
+}
+
+func TestRepair(t *testing.T) {
+	settings := testVerifySettings(t)
+	offsets := writeTestSegment(t, settings, 0, 3, []byte("first"), []byte("second"), []byte("third"))
+	writeTestSegment(t, settings, 1, 1, []byte("fourth"))
+
+	path := settings.segmentPath(0)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[offsets[2]+frameHeaderSize] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	report, err := Repair(settings)
+	require.NoError(t, err)
+	assert.True(t, report.Segments[0].Repaired)
+	assert.False(t, report.Segments[1].Repaired, "Valid segments should not be modified")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.EqualValues(t, offsets[2], info.Size())
+
+	header, err := readSegmentHeaderWithFrameCount(path)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, header.frameCount)
+
+	report, err = Verify(settings)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, report.ValidFrames)
+	assert.Zero(t, report.CorruptFrames)
+	for _, segment := range report.Segments {
+		assert.NoError(t, segment.Err)
+	}
+}
@@ -102,6 +102,14 @@ type Settings struct {
 	// The most events that will ever be returned from one Get request.
 	MaxGetRequest int
 
+	// If positive, a Get request that asks for more events than are
+	// available is answered as soon as MinEvents events are available,
+	// instead of waiting for the full request. If 0, the request waits
+	// for all requested events. Only used if FlushTimeout is positive.
+	// Get requests that don't specify a size return at most MinEvents
+	// events.
+	MinEvents int
+
 	// If positive, the amount of time the queue will wait to fill up
 	// a batch if a Get request asks for more events than we have.
 	FlushTimeout time.Duration
@@ -114,6 +122,10 @@ type queueEntry struct {
 
 	producer   *ackProducer
 	producerID producerID // The order of this entry within its producer
+
+	// The time the entry was added to the queue, used to report the age of
+	// the oldest entry to the observer.
+	enqueued time.Time
 }
 
 type batch struct {
@@ -264,7 +276,7 @@ func (b *broker) Producer(cfg queue.ProducerConfig) queue.Producer {
 	if b.encoderFactory != nil {
 		encoder = b.encoderFactory()
 	}
-	return newProducer(b, cfg.ACK, encoder)
+	return newProducer(b, cfg.ACK, encoder, cfg.PublishTimeout)
 }
 
 func (b *broker) Get(count int) (queue.Batch, error) {
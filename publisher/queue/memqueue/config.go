@@ -26,23 +26,19 @@ import (
 )
 
 type config struct {
-	Events int `config:"events" validate:"min=32"`
-	// This field is named MaxGetRequest because its logical effect is to give
-	// a maximum on the number of events a Get request can return, but the
-	// user-exposed name is "flush.min_events" for backwards compatibility,
-	// since it used to control buffer size in the internal buffer chain.
-	MaxGetRequest int           `config:"flush.min_events" validate:"min=0"`
-	FlushTimeout  time.Duration `config:"flush.timeout"`
+	Events       int           `config:"events" validate:"min=32"`
+	MinEvents    int           `config:"flush.min_events" validate:"min=0"`
+	FlushTimeout time.Duration `config:"flush.timeout"`
 }
 
 var defaultConfig = config{
-	Events:        3200,
-	MaxGetRequest: 1600,
-	FlushTimeout:  10 * time.Second,
+	Events:       3200,
+	MinEvents:    1600,
+	FlushTimeout: 10 * time.Second,
 }
 
 func (c *config) Validate() error {
-	if c.MaxGetRequest > c.Events {
+	if c.MinEvents > c.Events {
 		return errors.New("flush.min_events must be less events")
 	}
 	return nil
@@ -57,10 +53,24 @@ func SettingsForUserConfig(cfg *c.C) (Settings, error) {
 			return Settings{}, fmt.Errorf("couldn't unpack memory queue config: %w", err)
 		}
 	}
-	//nolint:gosimple // Actually want this conversion to be explicit since the types aren't definitionally equal.
+
+	// Backwards compatibility: setting "flush.min_events" to 0 or 1 selects
+	// synchronous mode, see newQueue.
+	if config.MinEvents <= 1 {
+		return Settings{
+			Events:        config.Events,
+			MaxGetRequest: config.MinEvents,
+			FlushTimeout:  config.FlushTimeout,
+		}, nil
+	}
+
+	// Otherwise batches are only limited by the size requested by the
+	// output, and a Get returns as soon as MinEvents events are available
+	// or the flush timeout expires, whichever comes first.
 	return Settings{
 		Events:        config.Events,
-		MaxGetRequest: config.MaxGetRequest,
+		MaxGetRequest: config.Events,
+		MinEvents:     config.MinEvents,
 		FlushTimeout:  config.FlushTimeout,
 	}, nil
 }
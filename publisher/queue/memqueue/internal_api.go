@@ -17,7 +17,11 @@
 
 package memqueue
 
-import "github.com/njcx/libbeat_v8/publisher/queue"
+import (
+	"sync/atomic"
+
+	"github.com/njcx/libbeat_v8/publisher/queue"
+)
 
 // producer -> broker API
 
@@ -36,6 +40,29 @@ type pushRequest struct {
 	// multiple acknowledgments for a producer to a single callback call.
 	producerID producerID
 	resp       chan queue.EntryID
+
+	// If the producer has a publish timeout, state is used to settle the
+	// race between the producer giving up on the request and the queue
+	// inserting the event. Nil if the request can't time out.
+	state *atomic.Int32
+}
+
+const (
+	pushPending int32 = iota
+	pushInserted
+	pushCanceled
+)
+
+// claim marks the request as inserted, reporting false if the producer gave
+// up on it.
+func (req *pushRequest) claim() bool {
+	return req.state == nil || req.state.CompareAndSwap(pushPending, pushInserted)
+}
+
+// cancel marks the request as abandoned by the producer, reporting false if
+// the queue has already inserted the event.
+func (req *pushRequest) cancel() bool {
+	return req.state != nil && req.state.CompareAndSwap(pushPending, pushCanceled)
 }
 
 // consumer -> broker API
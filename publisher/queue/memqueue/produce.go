@@ -18,10 +18,62 @@
 package memqueue
 
 import (
+	"sync/atomic"
+	"time"
+
 	"github.com/njcx/libbeat_v8/publisher/queue"
 	"github.com/elastic/elastic-agent-libs/logp"
 )
 
+// PublishResult is the outcome of an attempt to add an event to the queue.
+type PublishResult int
+
+const (
+	// Published means the event was added to the queue.
+	Published PublishResult = iota
+
+	// PublishQueueFull means TryPublish dropped the event because the queue
+	// was full.
+	PublishQueueFull
+
+	// PublishTimedOut means the queue stayed full for the producer's
+	// publish timeout, and the event was not added.
+	PublishTimedOut
+
+	// PublishClosed means the event was not added because the producer or
+	// the queue was closed.
+	PublishClosed
+)
+
+func (r PublishResult) String() string {
+	switch r {
+	case Published:
+		return "published"
+	case PublishQueueFull:
+		return "queue full"
+	case PublishTimedOut:
+		return "timed out"
+	case PublishClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// ResultProducer is implemented by the memory queue's producers, to report
+// why an event was not published.
+type ResultProducer interface {
+	queue.Producer
+
+	// PublishWithResult is like Publish, but returns the outcome of the
+	// attempt, so a publish timeout can be told apart from shutdown.
+	PublishWithResult(entry queue.Entry) (queue.EntryID, PublishResult)
+
+	// TryPublishWithResult is like TryPublish, but returns the outcome of
+	// the attempt.
+	TryPublishWithResult(entry queue.Entry) (queue.EntryID, PublishResult)
+}
+
 type forgetfulProducer struct {
 	broker    *broker
 	openState openState
@@ -40,6 +92,10 @@ type openState struct {
 	queueClosing <-chan struct{}
 	events       chan pushRequest
 	encoder      queue.Encoder
+
+	// If positive, the maximum time publish waits for the queue to accept
+	// an event.
+	publishTimeout time.Duration
 }
 
 // producerID stores the order of events within a single producer, so multiple
@@ -55,13 +111,14 @@ type produceState struct {
 
 type ackHandler func(count int)
 
-func newProducer(b *broker, cb ackHandler, encoder queue.Encoder) queue.Producer {
+func newProducer(b *broker, cb ackHandler, encoder queue.Encoder, publishTimeout time.Duration) queue.Producer {
 	openState := openState{
-		log:          b.logger,
-		done:         make(chan struct{}),
-		queueClosing: b.closingChan,
-		events:       b.pushChan,
-		encoder:      encoder,
+		log:            b.logger,
+		done:           make(chan struct{}),
+		queueClosing:   b.closingChan,
+		events:         b.pushChan,
+		encoder:        encoder,
+		publishTimeout: publishTimeout,
 	}
 
 	if cb != nil {
@@ -80,10 +137,20 @@ func (p *forgetfulProducer) makePushRequest(event queue.Entry) pushRequest {
 }
 
 func (p *forgetfulProducer) Publish(event queue.Entry) (queue.EntryID, bool) {
-	return p.openState.publish(p.makePushRequest(event))
+	id, result := p.PublishWithResult(event)
+	return id, result == Published
 }
 
 func (p *forgetfulProducer) TryPublish(event queue.Entry) (queue.EntryID, bool) {
+	id, result := p.TryPublishWithResult(event)
+	return id, result == Published
+}
+
+func (p *forgetfulProducer) PublishWithResult(event queue.Entry) (queue.EntryID, PublishResult) {
+	return p.openState.publish(p.makePushRequest(event))
+}
+
+func (p *forgetfulProducer) TryPublishWithResult(event queue.Entry) (queue.EntryID, PublishResult) {
 	return p.openState.tryPublish(p.makePushRequest(event))
 }
 
@@ -103,19 +170,29 @@ func (p *ackProducer) makePushRequest(event queue.Entry) pushRequest {
 }
 
 func (p *ackProducer) Publish(event queue.Entry) (queue.EntryID, bool) {
-	id, published := p.openState.publish(p.makePushRequest(event))
-	if published {
+	id, result := p.PublishWithResult(event)
+	return id, result == Published
+}
+
+func (p *ackProducer) TryPublish(event queue.Entry) (queue.EntryID, bool) {
+	id, result := p.TryPublishWithResult(event)
+	return id, result == Published
+}
+
+func (p *ackProducer) PublishWithResult(event queue.Entry) (queue.EntryID, PublishResult) {
+	id, result := p.openState.publish(p.makePushRequest(event))
+	if result == Published {
 		p.producedCount++
 	}
-	return id, published
+	return id, result
 }
 
-func (p *ackProducer) TryPublish(event queue.Entry) (queue.EntryID, bool) {
-	id, published := p.openState.tryPublish(p.makePushRequest(event))
-	if published {
+func (p *ackProducer) TryPublishWithResult(event queue.Entry) (queue.EntryID, PublishResult) {
+	id, result := p.openState.tryPublish(p.makePushRequest(event))
+	if result == Published {
 		p.producedCount++
 	}
-	return id, published
+	return id, result
 }
 
 func (p *ackProducer) Close() {
@@ -126,12 +203,24 @@ func (st *openState) Close() {
 	close(st.done)
 }
 
-func (st *openState) publish(req pushRequest) (queue.EntryID, bool) {
+func (st *openState) publish(req pushRequest) (queue.EntryID, PublishResult) {
 	// If we were given an encoder callback for incoming events, apply it before
 	// sending the entry to the queue.
 	if st.encoder != nil {
 		req.event, req.eventSize = st.encoder.EncodeEntry(req.event)
 	}
+
+	// The request state lets the producer give up on a buffered request if
+	// the publish timeout expires or the producer is closed.
+	req.state = &atomic.Int32{}
+
+	var timeout <-chan time.Time
+	if st.publishTimeout > 0 {
+		timer := time.NewTimer(st.publishTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
 	select {
 	case st.events <- req:
 		// The events channel is buffered, which means we may successfully
@@ -140,21 +229,39 @@ func (st *openState) publish(req pushRequest) (queue.EntryID, bool) {
 		// shutdown channel.
 		select {
 		case resp := <-req.resp:
-			return resp, true
+			return resp, Published
+		case <-timeout:
+			// The request is buffered but the queue is still full. Cancel it,
+			// unless the queue inserted the event in the meantime, in which
+			// case the response is already on its way.
+			if req.cancel() {
+				return 0, PublishTimedOut
+			}
+			return <-req.resp, Published
+		case <-st.done:
+			// The producer was closed while the queue was full, e.g. by a
+			// client shutting down. Cancel the request the same way.
+			if req.cancel() {
+				st.events = nil
+				return 0, PublishClosed
+			}
+			return <-req.resp, Published
 		case <-st.queueClosing:
 			st.events = nil
-			return 0, false
+			return 0, PublishClosed
 		}
+	case <-timeout:
+		return 0, PublishTimedOut
 	case <-st.done:
 		st.events = nil
-		return 0, false
+		return 0, PublishClosed
 	case <-st.queueClosing:
 		st.events = nil
-		return 0, false
+		return 0, PublishClosed
 	}
 }
 
-func (st *openState) tryPublish(req pushRequest) (queue.EntryID, bool) {
+func (st *openState) tryPublish(req pushRequest) (queue.EntryID, PublishResult) {
 	// If we were given an encoder callback for incoming events, apply it before
 	// sending the entry to the queue.
 	if st.encoder != nil {
@@ -168,16 +275,16 @@ func (st *openState) tryPublish(req pushRequest) (queue.EntryID, bool) {
 		// shutdown channel.
 		select {
 		case resp := <-req.resp:
-			return resp, true
+			return resp, Published
 		case <-st.queueClosing:
 			st.events = nil
-			return 0, false
+			return 0, PublishClosed
 		}
 	case <-st.done:
 		st.events = nil
-		return 0, false
+		return 0, PublishClosed
 	default:
 		st.log.Debugf("Dropping event, queue is blocked")
-		return 0, false
+		return 0, PublishQueueFull
 	}
 }
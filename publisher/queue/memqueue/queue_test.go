@@ -75,6 +75,26 @@ func TestProduceConsumer(t *testing.T) {
 	t.Run("flush", testWith(makeTestQueue(bufferSize, batchSize/2, 100*time.Millisecond)))
 }
 
+func TestConsumerFaults(t *testing.T) {
+	events := 512
+	batchSize := 32
+	config := queuetest.FaultConfig{
+		Seed:             seed,
+		MaxACKDelay:      5 * time.Millisecond,
+		CrashProbability: 0.1,
+	}
+
+	t.Log("seed: ", seed)
+
+	t.Run("delayed acks", func(t *testing.T) {
+		queuetest.TestDelayedACKs(t, events, batchSize, makeTestQueue(batchSize*2, 0, 0), config)
+	})
+	t.Run("abandoned batches", func(t *testing.T) {
+		// Abandoned events are never released, the queue must hold all events.
+		queuetest.TestAbandonedBatches(t, events, batchSize, makeTestQueue(events, 0, 0), config)
+	})
+}
+
 // TestProducerDoesNotBlockWhenQueueClosed ensures the producer Publish
 // does not block indefinitely during queue shutdown.
 //
@@ -149,6 +169,100 @@ func TestProducerDoesNotBlockWhenQueueClosed(t *testing.T) {
 		"test not flagged as successful, p.Publish likely blocked indefinitely")
 }
 
+func TestPublishTimeout(t *testing.T) {
+	q := NewQueue(nil, nil,
+		Settings{
+			Events:        2, // Queue size
+			MaxGetRequest: 1,
+			FlushTimeout:  time.Millisecond,
+		}, 0, nil)
+
+	acked := atomic.Int32{}
+	p := q.Producer(queue.ProducerConfig{
+		ACK:            func(count int) { acked.Add(int32(count)) },
+		PublishTimeout: 20 * time.Millisecond,
+	}).(ResultProducer)
+
+	// Fill the queue and its input channel, so further events have to wait.
+	_, result := p.PublishWithResult("Event 1")
+	require.Equal(t, Published, result)
+	_, result = p.PublishWithResult("Event 2")
+	require.Equal(t, Published, result)
+
+	start := time.Now()
+	_, result = p.PublishWithResult("Event 3")
+	assert.Equal(t, PublishTimedOut, result, "publishing to a full queue must time out")
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	_, ok := p.Publish("Event 4")
+	assert.False(t, ok, "Publish must report a timed out event as not published")
+
+	// Consuming and acknowledging the queued events makes room again, and
+	// only the events that were accepted are ACKed.
+	batch, err := q.Get(2)
+	require.NoError(t, err)
+	batch.Done()
+	batch, err = q.Get(2)
+	require.NoError(t, err)
+	batch.Done()
+	require.Eventually(t, func() bool { return acked.Load() == 2 },
+		time.Second, time.Millisecond, "the published events must be ACKed")
+
+	_, result = p.PublishWithResult("Event 5")
+	assert.Equal(t, Published, result)
+	batch, err = q.Get(1)
+	require.NoError(t, err)
+	assert.Equal(t, "Event 5", batch.Entry(0), "timed out events must not be queued")
+	batch.Done()
+	require.Eventually(t, func() bool { return acked.Load() == 3 },
+		time.Second, time.Millisecond, "the producer's ACK count must not include timed out events")
+
+	q.Close()
+	_, result = p.PublishWithResult("Event 6")
+	assert.Equal(t, PublishClosed, result, "publishing to a closed queue must report closed")
+}
+
+func TestProducerCloseUnblocksPublish(t *testing.T) {
+	q := NewQueue(nil, nil,
+		Settings{
+			Events:        1, // Queue size
+			MaxGetRequest: 1,
+			FlushTimeout:  time.Millisecond,
+		}, 0, nil)
+	defer q.Close()
+
+	p := q.Producer(queue.ProducerConfig{}).(ResultProducer)
+
+	// Fill the queue and its input channel, so the next event has to wait.
+	_, result := p.PublishWithResult("Event 1")
+	require.Equal(t, Published, result)
+
+	results := make(chan PublishResult, 1)
+	go func() {
+		_, result := p.PublishWithResult("Event 2")
+		results <- result
+	}()
+
+	select {
+	case result := <-results:
+		t.Fatalf("publishing to a full queue must block, got %v", result)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	p.Close()
+	select {
+	case result := <-results:
+		assert.Equal(t, PublishClosed, result)
+	case <-time.After(time.Second):
+		t.Fatal("closing the producer must unblock Publish")
+	}
+
+	batch, err := q.Get(2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, batch.Count(), "the canceled event must not be queued")
+	batch.Done()
+}
+
 func TestProducerClosePreservesEventCount(t *testing.T) {
 	// Check for https://github.com/elastic/beats/issues/37702, a problem
 	// where canceling a producer while it was waiting on a response
@@ -158,8 +158,17 @@ func (l *runLoop) runIteration() {
 }
 
 func (l *runLoop) handleGetRequest(req *getRequest) {
-	if req.entryCount <= 0 || req.entryCount > l.broker.settings.MaxGetRequest {
-		req.entryCount = l.broker.settings.MaxGetRequest
+	settings := l.broker.settings
+	if req.entryCount <= 0 {
+		// Requests without a size are capped at MinEvents, the limit
+		// flush.min_events used to put on every batch.
+		req.entryCount = settings.MaxGetRequest
+		if settings.MinEvents > 0 && settings.MinEvents < req.entryCount {
+			req.entryCount = settings.MinEvents
+		}
+	}
+	if req.entryCount > settings.MaxGetRequest {
+		req.entryCount = settings.MaxGetRequest
 	}
 	if l.getRequestShouldBlock(req) {
 		l.pendingGetRequest = req
@@ -175,8 +184,13 @@ func (l *runLoop) getRequestShouldBlock(req *getRequest) bool {
 		return false
 	}
 	eventsAvailable := l.eventCount - l.consumedCount
+	wanted := req.entryCount
+	if minEvents := l.broker.settings.MinEvents; minEvents > 0 && minEvents < wanted {
+		wanted = minEvents
+	}
 	// Block if the available events aren't enough to fill the request
-	return eventsAvailable < req.entryCount
+	// or reach the flush threshold
+	return eventsAvailable < wanted
 }
 
 // Respond to the given get request without blocking or waiting for more events
@@ -194,6 +208,9 @@ func (l *runLoop) handleGetReply(req *getRequest) {
 	for i := 0; i < batchSize; i++ {
 		batchBytes += batch.rawEntry(i).eventSize
 	}
+	if batchSize > 0 {
+		l.observer.ConsumeLatency(batchLatency(batch, time.Now()))
+	}
 
 	// Send the batch to the caller and update internal state
 	req.responseChan <- batch
@@ -202,6 +219,26 @@ func (l *runLoop) handleGetReply(req *getRequest) {
 	l.observer.ConsumeEvents(batchSize, batchBytes)
 }
 
+// maxLatencySamples is the maximum number of events per batch whose wait
+// time is sampled by batchLatency.
+const maxLatencySamples = 16
+
+// batchLatency returns the average time the events in batch waited in the
+// queue until now. Large batches are sampled evenly to bound the cost.
+func batchLatency(batch *batch, now time.Time) time.Duration {
+	step := 1
+	if n := batch.Count(); n > maxLatencySamples {
+		step = n / maxLatencySamples
+	}
+	var total time.Duration
+	samples := 0
+	for i := 0; i < batch.Count() && samples < maxLatencySamples; i += step {
+		total += now.Sub(batch.rawEntry(i).enqueued)
+		samples++
+	}
+	return total / time.Duration(samples)
+}
+
 func (l *runLoop) handleDelete(count int) {
 	byteCount := 0
 	for i := 0; i < count; i++ {
@@ -214,6 +251,11 @@ func (l *runLoop) handleDelete(count int) {
 	l.eventCount -= count
 	l.consumedCount -= count
 	l.observer.RemoveEvents(count, byteCount)
+	if l.eventCount > 0 {
+		l.observer.OldestEntry(l.broker.buf[l.bufPos].enqueued)
+	} else {
+		l.observer.OldestEntry(time.Time{})
+	}
 	if l.closing && l.eventCount == 0 {
 		// Our last events were acknowledged during shutdown, signal final shutdown
 		l.broker.ctxCancel()
@@ -221,6 +263,10 @@ func (l *runLoop) handleDelete(count int) {
 }
 
 func (l *runLoop) handleInsert(req *pushRequest) {
+	if !req.claim() {
+		// The producer's publish timeout expired, drop the request.
+		return
+	}
 	l.insert(req, l.nextEntryID)
 	// Send back the new event id.
 	req.resp <- l.nextEntryID
@@ -255,6 +301,11 @@ func (l *runLoop) insert(req *pushRequest, id queue.EntryID) {
 		id:         id,
 		producer:   req.producer,
 		producerID: req.producerID,
+		enqueued:   time.Now(),
 	}
 	l.observer.AddEvent(req.eventSize)
+	if l.eventCount == 0 {
+		// This is now the oldest entry in the queue.
+		l.observer.OldestEntry(l.broker.buf[index].enqueued)
+	}
 }
@@ -27,6 +27,7 @@ import (
 
 	"github.com/njcx/libbeat_v8/publisher"
 	"github.com/njcx/libbeat_v8/publisher/queue"
+	conf "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/monitoring"
 )
@@ -48,7 +49,7 @@ func TestFlushSettingsDoNotBlockFullBatches(t *testing.T) {
 		},
 		10, nil)
 
-	producer := newProducer(broker, nil, nil)
+	producer := newProducer(broker, nil, nil, 0)
 	rl := broker.runLoop
 	for i := 0; i < 100; i++ {
 		// Pair each publish call with an iteration of the run loop so we
@@ -87,7 +88,7 @@ func TestFlushSettingsBlockPartialBatches(t *testing.T) {
 		},
 		10, nil)
 
-	producer := newProducer(broker, nil, nil)
+	producer := newProducer(broker, nil, nil, 0)
 	rl := broker.runLoop
 	for i := 0; i < 100; i++ {
 		// Pair each publish call with an iteration of the run loop so we
@@ -117,6 +118,165 @@ func TestFlushSettingsBlockPartialBatches(t *testing.T) {
 	assert.Equal(t, 101, rl.consumedCount, "Queue should have a consumedCount of 101 after adding an event unblocked the pending get request")
 }
 
+func TestFlushSettingsMinEvents(t *testing.T) {
+	// With MinEvents set, a Get request that can't be completely filled is
+	// answered as soon as MinEvents events are available, without waiting
+	// for the flush timer.
+
+	broker := newQueue(
+		logp.NewLogger("testing"),
+		nil,
+		Settings{
+			Events:        1000,
+			MaxGetRequest: 1000,
+			MinEvents:     100,
+			FlushTimeout:  10 * time.Second,
+		},
+		10, nil)
+
+	producer := newProducer(broker, nil, nil, 0)
+	rl := broker.runLoop
+	for i := 0; i < 99; i++ {
+		go rl.runIteration()
+		_, ok := producer.Publish("some event")
+		require.True(t, ok, "Queue publish call must succeed")
+	}
+
+	go func() {
+		_, _ = broker.Get(500)
+	}()
+	rl.runIteration()
+	assert.NotNil(t, rl.pendingGetRequest, "Queue should have a pending get request since it has fewer than MinEvents events")
+
+	go func() {
+		_, _ = producer.Publish("some event")
+	}()
+	rl.runIteration()
+	assert.Nil(t, rl.pendingGetRequest, "Queue should have no pending get request once MinEvents events are available")
+	assert.Equal(t, 100, rl.consumedCount, "Get request should return all available events")
+}
+
+func TestFlushSettingsTimeoutBeforeMinEvents(t *testing.T) {
+	broker := newQueue(
+		logp.NewLogger("testing"),
+		nil,
+		Settings{
+			Events:        1000,
+			MaxGetRequest: 1000,
+			MinEvents:     100,
+			FlushTimeout:  time.Millisecond,
+		},
+		10, nil)
+
+	producer := newProducer(broker, nil, nil, 0)
+	rl := broker.runLoop
+	for i := 0; i < 10; i++ {
+		go rl.runIteration()
+		_, ok := producer.Publish("some event")
+		require.True(t, ok, "Queue publish call must succeed")
+	}
+
+	go func() {
+		_, _ = broker.Get(500)
+	}()
+	rl.runIteration()
+	require.NotNil(t, rl.pendingGetRequest, "Queue should have a pending get request since it has fewer than MinEvents events")
+
+	// The next iteration handles the expired flush timer.
+	rl.runIteration()
+	assert.Nil(t, rl.pendingGetRequest, "Flush timeout should unblock the pending get request")
+	assert.Equal(t, 10, rl.consumedCount, "Get request should return the available events after the timeout")
+}
+
+func TestMinEventsCapsUnsizedGetRequests(t *testing.T) {
+	// A Get request that doesn't specify a size returns at most MinEvents
+	// events, even if more are available.
+
+	broker := newQueue(
+		logp.NewLogger("testing"),
+		nil,
+		Settings{
+			Events:        1000,
+			MaxGetRequest: 1000,
+			MinEvents:     100,
+			FlushTimeout:  10 * time.Second,
+		},
+		10, nil)
+
+	producer := newProducer(broker, nil, nil)
+	rl := broker.runLoop
+	for i := 0; i < 150; i++ {
+		go rl.runIteration()
+		_, ok := producer.Publish("some event")
+		require.True(t, ok, "Queue publish call must succeed")
+	}
+
+	go func() {
+		_, _ = broker.Get(0)
+	}()
+	rl.runIteration()
+	assert.Nil(t, rl.pendingGetRequest, "Queue should have no pending get request since MinEvents events are available")
+	assert.Equal(t, 100, rl.consumedCount, "Get request without a size should return MinEvents events")
+}
+
+func TestSettingsForUserConfig(t *testing.T) {
+	tests := map[string]struct {
+		input    map[string]interface{}
+		expected Settings
+	}{
+		"defaults": {
+			input: nil,
+			expected: Settings{
+				Events:        3200,
+				MaxGetRequest: 3200,
+				MinEvents:     1600,
+				FlushTimeout:  10 * time.Second,
+			},
+		},
+		"min_events and timeout": {
+			input: map[string]interface{}{
+				"events":           4096,
+				"flush.min_events": 512,
+				"flush.timeout":    "5s",
+			},
+			expected: Settings{
+				Events:        4096,
+				MaxGetRequest: 4096,
+				MinEvents:     512,
+				FlushTimeout:  5 * time.Second,
+			},
+		},
+		"zero min_events keeps synchronous mode": {
+			input: map[string]interface{}{
+				"flush.min_events": 0,
+			},
+			expected: Settings{
+				Events:        3200,
+				MaxGetRequest: 0,
+				FlushTimeout:  10 * time.Second,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg *conf.C
+			if test.input != nil {
+				cfg = conf.MustNewConfigFrom(test.input)
+			}
+			settings, err := SettingsForUserConfig(cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, settings)
+		})
+	}
+
+	// Zero min_events must still produce immediate Get responses.
+	settings, err := SettingsForUserConfig(conf.MustNewConfigFrom(map[string]interface{}{"flush.min_events": 0}))
+	require.NoError(t, err)
+	broker := newQueue(logp.NewLogger("testing"), nil, settings, 10, nil)
+	assert.Zero(t, broker.settings.FlushTimeout, "Zero min_events should disable the flush timeout")
+}
+
 func TestObserverAddEvent(t *testing.T) {
 	// Confirm that an entry inserted into the queue is reported in
 	// queue.added.events and queue.added.bytes.
@@ -162,6 +322,45 @@ func TestObserverConsumeEvents(t *testing.T) {
 	assertRegistryUint(t, reg, "queue.consumed.bytes", 50*123, "Sending a batch to a Get caller should report the consumed bytes")
 }
 
+func TestObserverConsumeLatency(t *testing.T) {
+	// Confirm that the time events waited before being sent to the output
+	// is reported in queue.consume_latency.ms.
+	reg := monitoring.NewRegistry()
+	rl := &runLoop{
+		observer: queue.NewQueueObserver(reg),
+		broker: &broker{
+			buf: make([]queueEntry, 100),
+		},
+		eventCount: 50,
+	}
+	enqueued := time.Now().Add(-2 * time.Second)
+	for i := range rl.broker.buf {
+		rl.broker.buf[i].enqueued = enqueued
+	}
+	request := &getRequest{
+		entryCount:   len(rl.broker.buf),
+		responseChan: make(chan *batch, 1),
+	}
+	rl.handleGetReply(request)
+
+	latency, ok := reg.Get("queue.consume_latency.ms").(*monitoring.Float)
+	require.True(t, ok, "registry key 'queue.consume_latency.ms' should refer to a float")
+	assert.GreaterOrEqual(t, latency.Get(), 2000.0, "Sending a batch to a Get caller should report the consume latency")
+	assert.Less(t, latency.Get(), 3000.0, "Sending a batch to a Get caller should report the consume latency")
+}
+
+func TestBatchLatency(t *testing.T) {
+	now := time.Now()
+	b := &broker{buf: make([]queueEntry, 1000)}
+	for i := range b.buf {
+		b.buf[i].enqueued = now.Add(-time.Duration(i) * time.Millisecond)
+	}
+
+	assert.Equal(t, 2*time.Millisecond, batchLatency(newBatch(b, 0, 5), now), "Small batches should be averaged over all events")
+	// 1000 events are sampled at every 62nd event.
+	assert.Equal(t, 465*time.Millisecond, batchLatency(newBatch(b, 0, 1000), now), "Large batches should be sampled evenly")
+}
+
 func TestObserverRemoveEvents(t *testing.T) {
 	reg := monitoring.NewRegistry()
 	rl := &runLoop{
@@ -186,6 +385,40 @@ func TestObserverRemoveEvents(t *testing.T) {
 	assertRegistryUint(t, reg, "queue.removed.bytes", deleteCount*123, "Deleting from the queue should report the removed bytes")
 }
 
+func TestObserverOldestEntryAge(t *testing.T) {
+	reg := monitoring.NewRegistry()
+	observer := queue.NewQueueObserver(reg)
+	rl := &runLoop{
+		observer: observer,
+		broker: &broker{
+			ctx:        context.Background(),
+			buf:        make([]queueEntry, 100),
+			deleteChan: make(chan int, 1),
+		},
+	}
+	assert.Zero(t, observer.OldestEntryAge(), "Empty queue should report no oldest entry age")
+
+	for i := 0; i < 2; i++ {
+		rl.insert(&pushRequest{event: publisher.Event{}}, queue.EntryID(i))
+		rl.eventCount++
+	}
+	oldest := rl.broker.buf[0].enqueued
+	time.Sleep(10 * time.Millisecond)
+	assert.GreaterOrEqual(t, observer.OldestEntryAge(), 10*time.Millisecond, "Oldest entry age should grow while the entry waits")
+
+	// Backdate the second entry to check that the age follows the new
+	// oldest entry after a delete.
+	rl.broker.buf[1].enqueued = oldest.Add(-time.Hour)
+	rl.consumedCount = 2
+	rl.broker.deleteChan <- 1
+	rl.runIteration()
+	assert.GreaterOrEqual(t, observer.OldestEntryAge(), time.Hour, "Oldest entry age should be based on the remaining entry")
+
+	rl.broker.deleteChan <- 1
+	rl.runIteration()
+	assert.Zero(t, observer.OldestEntryAge(), "Empty queue should report no oldest entry age")
+}
+
 func assertRegistryUint(t *testing.T, reg *monitoring.Registry, key string, expected uint64, message string) {
 	t.Helper()
 
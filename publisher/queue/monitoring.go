@@ -18,6 +18,10 @@
 package queue
 
 import (
+	"math"
+	"sync/atomic"
+	"time"
+
 	"github.com/elastic/elastic-agent-libs/monitoring"
 )
 
@@ -36,6 +40,36 @@ type Observer interface {
 	AddEvent(byteCount int)
 	ConsumeEvents(eventCount int, byteCount int)
 	RemoveEvents(eventCount int, byteCount int)
+
+	// ExpireEvents reports events that are discarded without being
+	// acknowledged because they exceeded the queue's retention time. The
+	// events are no longer counted as filled, and are not reported to
+	// RemoveEvents.
+	ExpireEvents(eventCount int, byteCount int)
+
+	// OldestEntry reports when the oldest entry that is still waiting in the
+	// queue (not yet acknowledged) was added, or the zero time if the queue
+	// is empty. The memory queue reports the exact enqueue time of its oldest
+	// event. The disk queue doesn't store per-event timestamps, so it
+	// approximates using the modification time of its oldest segment file.
+	OldestEntry(timestamp time.Time)
+
+	// OldestEntryAge returns how long the oldest unacknowledged entry has
+	// been waiting in the queue based on the most recent OldestEntry report,
+	// or 0 if the queue is empty.
+	OldestEntryAge() time.Duration
+
+	// ConsumeLatency reports how long the events of a batch waited in the
+	// queue before a consumer read them. This is the time from enqueue to
+	// get, the time until the events are acknowledged by the output is not
+	// included. The observer aggregates the reports into a moving average.
+	ConsumeLatency(latency time.Duration)
+
+	// FillRatio returns the fraction of the queue capacity currently in use,
+	// between 0 and 1, as last reported in the filled.pct metric. Capacity is
+	// measured in bytes if the queue has a byte limit, and in events
+	// otherwise.
+	FillRatio() float64
 }
 
 type queueObserver struct {
@@ -48,17 +82,27 @@ type queueObserver struct {
 	consumedBytes  *monitoring.Uint
 	removedEvents  *monitoring.Uint
 	removedBytes   *monitoring.Uint
+	expiredEvents  *monitoring.Uint
+	expiredBytes   *monitoring.Uint
 
 	filledEvents *monitoring.Uint  // gauge
 	filledBytes  *monitoring.Uint  // gauge
 	filledPct    *monitoring.Float // gauge
 
+	// Exponentially weighted moving average of the reported consume
+	// latencies, in milliseconds. Only accessed by ConsumeLatency.
+	consumeLatency        *monitoring.Float // gauge
+	consumeLatencyStarted bool
+
 	// backwards compatibility: the metric "acked" is the old name for
 	// "removed.events". Ideally we would like to define an alias in the
 	// monitoring API, but until that's possible we shadow it with this
 	// extra variable and make sure to always change removedEvents and
 	// acked at the same time.
 	acked *monitoring.Uint
+
+	// Unix nanoseconds of the oldest unacknowledged entry, 0 if empty.
+	oldestEntry atomic.Int64
 }
 
 type nilObserver struct{}
@@ -88,11 +132,15 @@ func NewQueueObserver(metrics *monitoring.Registry) Observer {
 		consumedBytes:  monitoring.NewUint(queueMetrics, "consumed.bytes"),
 		removedEvents:  monitoring.NewUint(queueMetrics, "removed.events"),
 		removedBytes:   monitoring.NewUint(queueMetrics, "removed.bytes"),
+		expiredEvents:  monitoring.NewUint(queueMetrics, "expired.events"),
+		expiredBytes:   monitoring.NewUint(queueMetrics, "expired.bytes"),
 
 		filledEvents: monitoring.NewUint(queueMetrics, "filled.events"), // gauge
 		filledBytes:  monitoring.NewUint(queueMetrics, "filled.bytes"),  // gauge
 		filledPct:    monitoring.NewFloat(queueMetrics, "filled.pct"),   // gauge
 
+		consumeLatency: monitoring.NewFloat(queueMetrics, "consume_latency.ms"), // gauge
+
 		// backwards compatibility: "acked" is an alias for "removed.events".
 		acked: monitoring.NewUint(queueMetrics, "acked"),
 	}
@@ -137,6 +185,63 @@ func (ob *queueObserver) RemoveEvents(eventCount int, byteCount int) {
 	ob.updateFilledPct()
 }
 
+func (ob *queueObserver) ExpireEvents(eventCount int, byteCount int) {
+	ob.expiredEvents.Add(uint64(eventCount))
+	ob.expiredBytes.Add(uint64(byteCount))
+
+	ob.filledEvents.Sub(uint64(eventCount))
+	ob.filledBytes.Sub(uint64(byteCount))
+	ob.updateFilledPct()
+}
+
+func (ob *queueObserver) OldestEntry(timestamp time.Time) {
+	if timestamp.IsZero() {
+		ob.oldestEntry.Store(0)
+		return
+	}
+	ob.oldestEntry.Store(timestamp.UnixNano())
+}
+
+func (ob *queueObserver) OldestEntryAge() time.Duration {
+	ts := ob.oldestEntry.Load()
+	if ts == 0 {
+		return 0
+	}
+	if age := time.Since(time.Unix(0, ts)); age > 0 {
+		return age
+	}
+	return 0
+}
+
+// consumeLatencyWeight is the weight of a new report in the consume latency
+// moving average.
+const consumeLatencyWeight = 0.1
+
+// ConsumeLatency updates the moving average of the consume latency. It must
+// not be called concurrently.
+func (ob *queueObserver) ConsumeLatency(latency time.Duration) {
+	ms := float64(latency) / float64(time.Millisecond)
+	if ob.consumeLatencyStarted {
+		avg := ob.consumeLatency.Get()
+		ms = avg + consumeLatencyWeight*(ms-avg)
+	}
+	ob.consumeLatency.Set(ms)
+	ob.consumeLatencyStarted = true
+}
+
+// FillRatio returns the last value reported in filled.pct, bounded to
+// [0, 1].
+func (ob *queueObserver) FillRatio() float64 {
+	pct := ob.filledPct.Get()
+	if math.IsNaN(pct) || pct < 0 {
+		return 0
+	}
+	if pct > 1 {
+		return 1
+	}
+	return pct
+}
+
 func (ob *queueObserver) updateFilledPct() {
 	if maxBytes := ob.maxBytes.Get(); maxBytes > 0 {
 		ob.filledPct.Set(float64(ob.filledBytes.Get()) / float64(maxBytes))
@@ -151,3 +256,12 @@ func (nilObserver) Restore(_ int, _ int)       {}
 func (nilObserver) AddEvent(_ int)             {}
 func (nilObserver) ConsumeEvents(_ int, _ int) {}
 func (nilObserver) RemoveEvents(_ int, _ int)  {}
+func (nilObserver) ExpireEvents(_ int, _ int)  {}
+func (nilObserver) OldestEntry(_ time.Time)    {}
+func (nilObserver) OldestEntryAge() time.Duration {
+	return 0
+}
+func (nilObserver) FillRatio() float64 {
+	return 0
+}
+func (nilObserver) ConsumeLatency(_ time.Duration) {}
@@ -0,0 +1,47 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func TestObserverFillRatio(t *testing.T) {
+	observer := NewQueueObserver(monitoring.NewRegistry())
+	assert.Zero(t, observer.FillRatio(), "Queue without capacity should report an empty queue")
+
+	observer.MaxEvents(4)
+	observer.AddEvent(100)
+	assert.InDelta(t, 0.25, observer.FillRatio(), 1e-9, "Fill ratio should be based on events without a byte limit")
+
+	observer.MaxBytes(1000)
+	observer.AddEvent(100)
+	assert.InDelta(t, 0.2, observer.FillRatio(), 1e-9, "Fill ratio should be based on bytes with a byte limit")
+
+	observer.Restore(10, 2000)
+	assert.Equal(t, 1.0, observer.FillRatio(), "Fill ratio should not exceed 1")
+
+	observer.RemoveEvents(10, 2000)
+	assert.Zero(t, observer.FillRatio(), "Empty queue should report a fill ratio of 0")
+
+	assert.Zero(t, NewQueueObserver(nil).FillRatio(), "Queue without metrics should report 0")
+}
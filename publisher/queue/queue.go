@@ -18,6 +18,8 @@
 package queue
 
 import (
+	"time"
+
 	"github.com/elastic/elastic-agent-libs/logp"
 )
 
@@ -81,6 +83,11 @@ type ProducerConfig struct {
 	// if ACK is set, the callback will be called with number of events produced
 	// by the producer instance and being ACKed by the queue.
 	ACK func(count int)
+
+	// If PublishTimeout is positive, Publish gives up on an event after
+	// waiting this long for the queue to accept it. Queues that don't support
+	// a timeout block until the event is accepted.
+	PublishTimeout time.Duration
 }
 
 type EntryID uint64
@@ -122,6 +129,19 @@ type Batch interface {
 // case the queue will run the given encoder on events before they reach
 // consumers.
 // Encoders are provided as factories so each worker goroutine can have its own
+// encoder and its buffers.
+//
+// Early encoding is opt-in per output, through outputs.Group.EncoderFactory.
+// The memory queue encodes events once, as they are published, and keeps
+// only the encoded bytes, so the output sends the cached bytes on every
+// attempt (including retries) instead of serializing again. This is a win
+// when the encoded form is smaller than the beat.Event it replaces, as it is
+// for Elasticsearch, and the memory queue byte limits then apply to the
+// encoded size. The cost is that encoding moves to the publishing goroutine
+// and the unencoded event is no longer available to the output, so outputs
+// that need the raw event, or that format it differently per attempt (like
+// console), should not provide an encoder. The disk queue stores events
+// unencoded and runs the encoder when reading them back from disk.
 type EncoderFactory func() Encoder
 
 type Encoder interface {
@@ -0,0 +1,368 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package queuetest
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/njcx/libbeat_v8/publisher"
+	"github.com/njcx/libbeat_v8/publisher/queue"
+)
+
+// PersistentQueueFactory is used by tests that restart a queue. The returned
+// function opens a new queue instance on the same underlying storage each
+// time it is called.
+type PersistentQueueFactory func(t *testing.T) func() queue.Queue
+
+// FaultConfig configures the consumer faults injected by the fault tests.
+type FaultConfig struct {
+	// Seed initializes the random source deciding on delays and crashes.
+	Seed int64
+
+	// MaxACKDelay is the upper bound of the random delay before a consumer
+	// calls batch.Done(). Batches are acknowledged from separate goroutines,
+	// so with a delay set acknowledgments arrive in random order.
+	MaxACKDelay time.Duration
+
+	// CrashProbability is the probability of a consumer crashing while
+	// processing a batch, abandoning the batch without calling batch.Done().
+	CrashProbability float64
+
+	// Timeout bounds the time spent waiting for ACKs or events to arrive.
+	// Defaults to 10s.
+	Timeout time.Duration
+}
+
+var errGetTimeout = errors.New("timeout waiting for batch")
+
+// faultInjector makes the random choices of a faulty consumer.
+type faultInjector struct {
+	config FaultConfig
+	rng    *rand.Rand
+}
+
+func newFaultInjector(config FaultConfig) *faultInjector {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &faultInjector{
+		config: config,
+		rng:    rand.New(rand.NewSource(config.Seed)), //nolint:gosec // Deterministic randomness for tests.
+	}
+}
+
+func (f *faultInjector) ackDelay() time.Duration {
+	if f.config.MaxACKDelay <= 0 {
+		return 0
+	}
+	return time.Duration(f.rng.Int63n(int64(f.config.MaxACKDelay)))
+}
+
+func (f *faultInjector) crash() bool {
+	return f.config.CrashProbability > 0 && f.rng.Float64() < f.config.CrashProbability
+}
+
+// delayedACKs acknowledges batches after a random delay, each from its own
+// goroutine.
+type delayedACKs struct {
+	wg sync.WaitGroup
+}
+
+func (d *delayedACKs) done(batch queue.Batch, delay time.Duration) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		time.Sleep(delay)
+		batch.Done()
+	}()
+}
+
+func (d *delayedACKs) wait() { d.wg.Wait() }
+
+// TestDelayedACKs publishes events from a single producer and consumes them
+// with acknowledgments delayed randomly and completing out of order. It checks
+// that every event is delivered exactly once and that the producer receives
+// an ACK for every event.
+func TestDelayedACKs(
+	t *testing.T,
+	events, batchSize int,
+	queueFactory QueueFactory,
+	config FaultConfig,
+) {
+	faults := newFaultInjector(config)
+
+	q := queueFactory(t)
+	defer q.Close()
+
+	acked, allACKed := countProducerACKs(events)
+	publishEvents(q.Producer(queue.ProducerConfig{ACK: acked}), events)
+
+	received := make([]int, events)
+	var acks delayedACKs
+	for total := 0; total < events; {
+		batch, err := getWithTimeout(q, batchSize, faults.config.Timeout)
+		if err != nil {
+			t.Fatalf("failed to get batch after %v of %v events: %v", total, events, err)
+		}
+		for i := 0; i < batch.Count(); i++ {
+			if idx, ok := eventIndex(t, batch.Entry(i), events); ok {
+				received[idx]++
+			}
+		}
+		total += batch.Count()
+		acks.done(batch, faults.ackDelay())
+	}
+	acks.wait()
+
+	for idx, n := range received {
+		if n != 1 {
+			t.Errorf("event %v was received %v times", idx, n)
+		}
+	}
+
+	select {
+	case <-allACKed:
+	case <-time.After(faults.config.Timeout):
+		t.Errorf("producer did not receive ACKs for all %v events", events)
+	}
+}
+
+// TestAbandonedBatches publishes events from a single producer and consumes
+// them with consumers that crash randomly, abandoning their current batch. A
+// new consumer takes over after each crash. Other batches are acknowledged
+// with random delays.
+//
+// This is meant for queues that ACK events to producers once consumers are
+// done with them, in order, without ever redelivering an event (like the
+// memory queue): the producer must be ACKed for all events published before
+// the first abandoned batch, and for none after it. As abandoned events are
+// never released, the queue must be able to hold all events.
+func TestAbandonedBatches(
+	t *testing.T,
+	events, batchSize int,
+	queueFactory QueueFactory,
+	config FaultConfig,
+) {
+	faults := newFaultInjector(config)
+
+	q := queueFactory(t)
+	defer q.Close()
+
+	var producerACKed atomic.Int64
+	publishEvents(q.Producer(queue.ProducerConfig{
+		ACK: func(n int) { producerACKed.Add(int64(n)) },
+	}), events)
+
+	var acks delayedACKs
+	expected := 0
+	abandoned := false
+	crashes := 0
+	for total := 0; total < events; {
+		batch, err := getWithTimeout(q, batchSize, faults.config.Timeout)
+		if err != nil {
+			t.Fatalf("failed to get batch after %v of %v events: %v", total, events, err)
+		}
+		total += batch.Count()
+
+		if faults.crash() {
+			crashes++
+			abandoned = true
+			continue
+		}
+		if !abandoned {
+			expected += batch.Count()
+		}
+		acks.done(batch, faults.ackDelay())
+	}
+	acks.wait()
+	t.Logf("%v consumer crashes, expecting %v of %v events to be ACKed", crashes, expected, events)
+
+	deadline := time.Now().Add(faults.config.Timeout)
+	for producerACKed.Load() < int64(expected) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	// Give the queue a chance to report ACKs it shouldn't.
+	time.Sleep(10 * time.Millisecond)
+
+	if got := producerACKed.Load(); got != int64(expected) {
+		t.Errorf("producer received %v ACKs, expected %v", got, expected)
+	}
+}
+
+// TestRedeliveryAfterRestart publishes events to a persistent queue, waiting
+// until all of them are ACKed to the producer, and consumes them with
+// consumers that crash randomly, abandoning their current batch. Other
+// batches are acknowledged with random delays. The queue is then closed and
+// reopened, and every event that was not acknowledged before the restart
+// must be delivered again. Acknowledged events may be redelivered as well.
+func TestRedeliveryAfterRestart(
+	t *testing.T,
+	events, batchSize int,
+	queueFactory PersistentQueueFactory,
+	config FaultConfig,
+) {
+	faults := newFaultInjector(config)
+	open := queueFactory(t)
+
+	// First run: publish everything and consume with crashing consumers.
+	q := open()
+	acked, allACKed := countProducerACKs(events)
+	publishEvents(q.Producer(queue.ProducerConfig{ACK: acked}), events)
+	select {
+	case <-allACKed:
+	case <-time.After(faults.config.Timeout):
+		q.Close()
+		t.Fatalf("producer did not receive ACKs for all %v events", events)
+	}
+
+	unacked := map[int]bool{}
+	var acks delayedACKs
+	for total := 0; total < events; {
+		batch, err := getWithTimeout(q, batchSize, faults.config.Timeout)
+		if err != nil {
+			q.Close()
+			t.Fatalf("failed to get batch after %v of %v events: %v", total, events, err)
+		}
+		total += batch.Count()
+
+		if faults.crash() {
+			for i := 0; i < batch.Count(); i++ {
+				if idx, ok := eventIndex(t, batch.Entry(i), events); ok {
+					unacked[idx] = true
+				}
+			}
+			continue
+		}
+		acks.done(batch, faults.ackDelay())
+	}
+	acks.wait()
+	closeAndWait(t, q, faults.config.Timeout)
+	t.Logf("%v of %v events were not acknowledged before restart", len(unacked), events)
+
+	// Second run: all unacknowledged events must be redelivered.
+	q = open()
+	defer closeAndWait(t, q, faults.config.Timeout)
+
+	for len(unacked) > 0 {
+		batch, err := getWithTimeout(q, batchSize, faults.config.Timeout)
+		if err != nil {
+			t.Fatalf("%v unacknowledged events were not redelivered: %v", len(unacked), err)
+		}
+		for i := 0; i < batch.Count(); i++ {
+			if idx, ok := eventIndex(t, batch.Entry(i), events); ok {
+				delete(unacked, idx)
+			}
+		}
+		batch.Done()
+	}
+}
+
+// countProducerACKs returns a producer ACK callback and a channel that is
+// closed once total events have been ACKed.
+func countProducerACKs(total int) (func(int), <-chan struct{}) {
+	var count atomic.Int64
+	done := make(chan struct{})
+	return func(n int) {
+		if count.Add(int64(n)) == int64(total) {
+			close(done)
+		}
+	}, done
+}
+
+// publishEvents publishes events numbered from 0 to n-1 in the background.
+func publishEvents(producer queue.Producer, n int) {
+	go func() {
+		for i := 0; i < n; i++ {
+			producer.Publish(MakeEvent(countEvent(i)))
+		}
+	}()
+}
+
+// getWithTimeout reads a batch from the queue, giving up after timeout.
+func getWithTimeout(q queue.Queue, batchSize int, timeout time.Duration) (queue.Batch, error) {
+	type result struct {
+		batch queue.Batch
+		err   error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		batch, err := q.Get(batchSize)
+		ch <- result{batch, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.batch, r.err
+	case <-time.After(timeout):
+		return nil, errGetTimeout
+	}
+}
+
+// eventIndex returns the number of an event published by publishEvents.
+func eventIndex(t *testing.T, entry queue.Entry, total int) (int, bool) {
+	t.Helper()
+
+	event, ok := entry.(publisher.Event)
+	if !ok {
+		t.Errorf("unexpected queue entry type %T", entry)
+		return 0, false
+	}
+	v, err := event.Content.Fields.GetValue("count")
+	if err != nil {
+		t.Errorf("event without count field: %v", event.Content.Fields)
+		return 0, false
+	}
+
+	var idx int
+	switch n := v.(type) {
+	case int:
+		idx = n
+	case int64:
+		idx = int(n)
+	case uint64:
+		idx = int(n)
+	case float64:
+		idx = int(n)
+	default:
+		t.Errorf("unexpected count field type %T", v)
+		return 0, false
+	}
+	if idx < 0 || idx >= total {
+		t.Errorf("event number %v out of range [0, %v)", idx, total)
+		return 0, false
+	}
+	return idx, true
+}
+
+func closeAndWait(t *testing.T, q queue.Queue, timeout time.Duration) {
+	t.Helper()
+
+	if err := q.Close(); err != nil {
+		t.Error(err)
+	}
+	select {
+	case <-q.Done():
+	case <-time.After(timeout):
+		t.Errorf("queue did not shut down within %v", timeout)
+	}
+}
@@ -18,6 +18,8 @@
 package multiline
 
 import (
+	"time"
+
 	"github.com/njcx/libbeat_v8/reader"
 )
 
@@ -30,6 +32,8 @@ type messageBuffer struct {
 	numLines       int
 	processedLines int
 	truncated      int
+	fullSince      time.Time // time the first line was skipped, zero if none
+	now            func() time.Time
 	err            error // last seen error
 	message        reader.Message
 }
@@ -41,6 +45,7 @@ func newMessageBuffer(maxBytes, maxLines int, separator []byte, skipNewline bool
 		separator:   separator,
 		skipNewline: skipNewline,
 		message:     reader.Message{},
+		now:         time.Now,
 		err:         nil,
 	}
 }
@@ -66,6 +71,7 @@ func (b *messageBuffer) clear() {
 	b.numLines = 0
 	b.processedLines = 0
 	b.truncated = 0
+	b.fullSince = time.Time{}
 	b.err = nil
 }
 
@@ -108,7 +114,9 @@ func (b *messageBuffer) addLine(m reader.Message) {
 	} else {
 		// increase the number of skipped bytes, if cannot add
 		b.truncated += len(m.Content)
-
+		if b.fullSince.IsZero() {
+			b.fullSince = b.now()
+		}
 	}
 	b.processedLines++
 
@@ -142,6 +150,15 @@ func (b *messageBuffer) isEmpty() bool {
 	return b.numLines == 0
 }
 
+// fullFor returns for how long lines have been skipped because the message
+// reached the maximum number of bytes or lines.
+func (b *messageBuffer) fullFor() time.Duration {
+	if b.fullSince.IsZero() {
+		return 0
+	}
+	return b.now().Sub(b.fullSince)
+}
+
 func (b *messageBuffer) isEmptyMessage() bool {
 	return b.message.Bytes == 0
 }
@@ -22,6 +22,7 @@ package multiline
 import (
 	"bytes"
 	"errors"
+	"io"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -195,6 +196,107 @@ func TestMultilineAfterTruncated(t *testing.T) {
 	)
 }
 
+func TestMultilineJavaStackTrace(t *testing.T) {
+	pattern := match.MustCompile(`^[[:space:]]+(at|\.{3})[[:space:]]+\b|^Caused by:`)
+	testMultilineOK(t,
+		Config{
+			Type:    patternMode,
+			Pattern: &pattern,
+			Match:   "after",
+		},
+		2,
+		"Exception in thread \"main\" java.lang.IllegalStateException: boom\n"+
+			"\tat com.example.App.run(App.java:42)\n"+
+			"\tat com.example.App.main(App.java:12)\n"+
+			"Caused by: java.lang.NullPointerException\n"+
+			"\tat com.example.Service.call(Service.java:7)\n"+
+			"\t... 2 more\n",
+		"INFO application stopped\n",
+	)
+}
+
+func TestMultilineTimeoutFlushesPartialEvent(t *testing.T) {
+	pattern := match.MustCompile(`^[ ]`)
+	timeout := 50 * time.Millisecond
+	lines := make(chan string)
+	defer close(lines)
+	r, err := New(chanReader(lines), "\n", 1<<20, &Config{
+		Type:    patternMode,
+		Pattern: &pattern,
+		Match:   "after",
+		Timeout: &timeout,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize reader: %v", err)
+	}
+	defer r.Close()
+
+	go func() {
+		lines <- "line1"
+		lines <- " line1.1"
+		// No further line: the partial event must be flushed by the timeout.
+	}()
+
+	message, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "line1\n line1.1", string(message.Content))
+	assert.Equal(t, len("line1\n line1.1\n"), message.Bytes)
+}
+
+func TestMultilineTimeoutFlushesFullEvent(t *testing.T) {
+	pattern := match.MustCompile(`^[ ]`)
+	maxLines := 2
+	timeout := 2 * time.Second
+	_, buf := createLineBuffer("line1\n", " a\n", " b\n", " c\n", " d\n", " e\n", "line2\n")
+	r := createMultilineTestReader(t, buf, Config{
+		Type:     patternMode,
+		Pattern:  &pattern,
+		Match:    "after",
+		MaxLines: &maxLines,
+		Timeout:  &timeout,
+	})
+
+	// Every call to the clock advances it by a second.
+	now := time.Now()
+	r.(*patternReader).msgBuffer.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	var messages []reader.Message
+	for {
+		message, err := r.Next()
+		if err != nil {
+			break
+		}
+		messages = append(messages, message)
+	}
+
+	// The first event is full after " a", skips " b" and " c", and is flushed
+	// once the timeout passed. The remaining continuation lines start a new
+	// event.
+	if assert.Len(t, messages, 3) {
+		assert.Equal(t, "line1\n a", string(messages[0].Content))
+		assert.Equal(t, len("line1\n a\n b\n c\n"), messages[0].Bytes)
+		assert.Equal(t, " d\n e", string(messages[1].Content))
+		assert.Equal(t, "line2", string(messages[2].Content))
+	}
+}
+
+// chanReader returns the lines sent to the channel, blocking until a line is
+// available.
+type chanReader chan string
+
+func (r chanReader) Next() (reader.Message, error) {
+	line, ok := <-r
+	if !ok {
+		return reader.Message{}, io.EOF
+	}
+	return reader.Message{Ts: time.Now(), Content: []byte(line), Bytes: len(line) + 1}, nil
+}
+
+func (r chanReader) Close() error { return nil }
+
 func TestMultilineCount(t *testing.T) {
 	maxLines := 2
 	testMultilineOK(t,
@@ -36,7 +36,8 @@ import (
 //
 // The maximum number of bytes and lines to be returned is fully configurable.
 // Even if limits are reached subsequent lines are matched, until event is
-// fully finished.
+// fully finished or, if lines keep matching, until the timeout passed since
+// the limit was reached.
 //
 // Errors will force the multiline reader to return the currently active
 // multiline event first and finally return the actual error on next call to Next.
@@ -45,6 +46,7 @@ type patternReader struct {
 	pred         matcher
 	flushMatcher *match.Matcher
 	state        func(*patternReader) (reader.Message, error)
+	timeout      time.Duration
 	logger       *logp.Logger
 	msgBuffer    *messageBuffer
 }
@@ -96,6 +98,7 @@ func newMultilinePatternReader(
 		pred:         matcher,
 		flushMatcher: config.FlushPattern,
 		state:        (*patternReader).readFirst,
+		timeout:      tout,
 		msgBuffer:    newMessageBuffer(maxBytes, maxLines, []byte(separator), config.SkipNewLine),
 		logger:       logp.NewLogger("reader_multiline"),
 	}
@@ -227,6 +230,15 @@ func (pr *patternReader) readNext() (reader.Message, error) {
 
 		// add line to current multiline event
 		pr.msgBuffer.addLine(message)
+
+		// A full event is not held indefinitely while matching lines keep
+		// coming in and are skipped.
+		if pr.timeout > 0 && pr.msgBuffer.fullFor() >= pr.timeout {
+			pr.logger.Debug("Multiline event flushed because it is full and timeout reached.")
+			msg := pr.msgBuffer.finalize()
+			pr.resetState()
+			return msg, nil
+		}
 	}
 }
 
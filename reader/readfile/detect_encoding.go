@@ -0,0 +1,337 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package readfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	textencoding "golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+
+	"github.com/njcx/libbeat_v8/common"
+	"github.com/njcx/libbeat_v8/reader"
+	"github.com/njcx/libbeat_v8/reader/readfile/encoding"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const defaultSniffBytes = 4096
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// DetectEncodingConfig configures the encoding detection.
+type DetectEncodingConfig struct {
+	// Default is the encoding used if detection is ambiguous, e.g. for
+	// plain ASCII input. Defaults to utf-8.
+	Default string
+	// SniffBytes is the number of bytes read from the beginning of the
+	// file to detect the encoding. Defaults to 4096.
+	SniffBytes int
+}
+
+// DetectEncodeReader reads lines from a file with unknown encoding. The
+// encoding is detected once from the beginning of the input, lines are
+// transcoded to utf-8 and the detected encoding is stored in
+// log.file.encoding.
+type DetectEncodeReader struct {
+	reader   EncoderReader
+	encoding string
+}
+
+// NewDetectEncodeReader creates a new reader detecting the encoding of r.
+// The Codec in config is replaced with the detected encoding. If r is
+// seekable the sample is read from the beginning of the file and the
+// offset is restored afterwards.
+func NewDetectEncodeReader(r io.ReadCloser, config Config, detect DetectEncodingConfig) (*DetectEncodeReader, error) {
+	if detect.SniffBytes <= 0 {
+		detect.SniffBytes = defaultSniffBytes
+	}
+	if detect.Default == "" {
+		detect.Default = "utf-8"
+	}
+
+	sample, in, err := sniff(r, detect.SniffBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encoding sample: %w", err)
+	}
+
+	name, ok := DetectEncoding(sample)
+	if !ok {
+		name = detect.Default
+	}
+
+	codec, err := findDetectedEncoding(name, in)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Codec = codec
+	er, err := NewEncodeReader(in, config)
+	if err != nil {
+		return nil, err
+	}
+	return &DetectEncodeReader{reader: er, encoding: name}, nil
+}
+
+// Encoding returns the name of the detected encoding.
+func (r *DetectEncodeReader) Encoding() string {
+	return r.encoding
+}
+
+// Next returns the next line transcoded to utf-8.
+func (r *DetectEncodeReader) Next() (reader.Message, error) {
+	message, err := r.reader.Next()
+	if message.IsEmpty() {
+		return message, err
+	}
+
+	if message.Fields == nil {
+		message.Fields = mapstr.M{}
+	}
+	_, _ = message.Fields.Put("log.file.encoding", r.encoding)
+
+	return message, err
+}
+
+func (r *DetectEncodeReader) Close() error {
+	return r.reader.Close()
+}
+
+// DetectEncoding guesses the encoding of sample. A byte order mark is
+// used if present, otherwise the distribution of zero bytes is checked
+// for UTF-16 and the sample is validated as utf-8. Input not being valid
+// utf-8 is reported as latin-1. The second return value is false if the
+// input is ambiguous, e.g. plain ASCII.
+func DetectEncoding(sample []byte) (string, bool) {
+	switch {
+	case bytes.HasPrefix(sample, bomUTF8):
+		return "utf-8", true
+	case bytes.HasPrefix(sample, bomUTF16LE):
+		return "utf-16le", true
+	case bytes.HasPrefix(sample, bomUTF16BE):
+		return "utf-16be", true
+	}
+
+	pairs := len(sample) / 2
+	if pairs == 0 {
+		return "", false
+	}
+
+	var evenZeros, oddZeros int
+	for i, b := range sample[:pairs*2] {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	switch {
+	case oddZeros > pairs*3/10 && evenZeros < pairs/10:
+		return "utf-16le", true
+	case evenZeros > pairs*3/10 && oddZeros < pairs/10:
+		return "utf-16be", true
+	}
+
+	ascii := true
+	for _, b := range sample {
+		if b >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return "", false
+	}
+
+	if validUTF8Prefix(sample) {
+		return "utf-8", true
+	}
+	return "iso8859-1", true
+}
+
+// validUTF8Prefix checks b is valid utf-8, ignoring a rune truncated by
+// the end of the sample.
+func validUTF8Prefix(b []byte) bool {
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		if utf8.Valid(b) {
+			return true
+		}
+		b = b[:len(b)-1]
+	}
+	return false
+}
+
+// sniff reads up to n bytes from the beginning of r. If r is not seekable
+// the returned reader replays the sample before reading from r.
+func sniff(r io.ReadCloser, n int) ([]byte, io.ReadCloser, error) {
+	sample := make([]byte, n)
+
+	if rs, ok := r.(io.ReadSeeker); ok {
+		offset, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err = rs.Seek(0, io.SeekStart); err != nil {
+			return nil, nil, err
+		}
+		read, err := io.ReadFull(rs, sample)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, nil, err
+		}
+		if _, err = rs.Seek(offset, io.SeekStart); err != nil {
+			return nil, nil, err
+		}
+		return sample[:read], r, nil
+	}
+
+	read, err := io.ReadFull(r, sample)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, nil, err
+	}
+	sample = sample[:read]
+	return sample, &replayReader{Reader: io.MultiReader(bytes.NewReader(sample), r), closer: r}, nil
+}
+
+type replayReader struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *replayReader) Close() error {
+	return r.closer.Close()
+}
+
+func findDetectedEncoding(name string, in io.Reader) (encoding.Encoding, error) {
+	switch name {
+	case "utf-16le":
+		return utf16Encoding{bigEndian: false}, nil
+	case "utf-16be":
+		return utf16Encoding{bigEndian: true}, nil
+	}
+
+	factory, ok := encoding.FindEncoding(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown encoding: %s", name)
+	}
+	return factory(in)
+}
+
+// utf16Encoding decodes UTF-16 input using common.UTF16ToUTF8Bytes.
+type utf16Encoding struct {
+	bigEndian bool
+}
+
+func (e utf16Encoding) NewDecoder() *textencoding.Decoder {
+	return &textencoding.Decoder{Transformer: utf16Decoder{bigEndian: e.bigEndian}}
+}
+
+func (e utf16Encoding) NewEncoder() *textencoding.Encoder {
+	endianness := unicode.LittleEndian
+	if e.bigEndian {
+		endianness = unicode.BigEndian
+	}
+	return unicode.UTF16(endianness, unicode.IgnoreBOM).NewEncoder()
+}
+
+type utf16Decoder struct {
+	transform.NopResetter
+	bigEndian bool
+}
+
+// Transform converts UTF-16 code units from src to utf-8. A high
+// surrogate at the end of src is kept until the low surrogate is
+// available. Unlike common.UTF16ToUTF8Bytes zero code units are
+// kept, so null terminated lines are supported.
+func (d utf16Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	even := len(src) &^ 1
+	n := even
+
+	// every code unit is converted to at most 3 bytes
+	limited := false
+	if max := len(dst) / 3 * 2; n > max {
+		n, limited = max, true
+	}
+	if n > 0 && (limited || !atEOF) && d.isHighSurrogate(src[n-2:n]) {
+		n -= 2
+	}
+
+	units := src[:n]
+	if d.bigEndian {
+		units = make([]byte, n)
+		for i := 0; i < n; i += 2 {
+			units[i], units[i+1] = src[i+1], src[i]
+		}
+	}
+
+	var out bytes.Buffer
+	for len(units) > 0 {
+		i := indexZeroUnit(units)
+		if i == -1 {
+			_ = common.UTF16ToUTF8Bytes(units, &out)
+			break
+		}
+		_ = common.UTF16ToUTF8Bytes(units[:i], &out)
+		out.WriteByte(0)
+		units = units[i+2:]
+	}
+
+	nDst, nSrc = copy(dst, out.Bytes()), n
+	switch {
+	case nSrc == len(src):
+		return nDst, nSrc, nil
+	case limited:
+		return nDst, nSrc, transform.ErrShortDst
+	case !atEOF:
+		return nDst, nSrc, transform.ErrShortSrc
+	}
+
+	// odd trailing byte at EOF
+	if len(dst)-nDst < utf8.RuneLen(utf8.RuneError) {
+		return nDst, nSrc, transform.ErrShortDst
+	}
+	nDst += utf8.EncodeRune(dst[nDst:], utf8.RuneError)
+	return nDst, len(src), nil
+}
+
+func (d utf16Decoder) isHighSurrogate(unit []byte) bool {
+	v := uint16(unit[0]) | uint16(unit[1])<<8
+	if d.bigEndian {
+		v = uint16(unit[1]) | uint16(unit[0])<<8
+	}
+	return 0xd800 <= v && v < 0xdc00
+}
+
+func indexZeroUnit(units []byte) int {
+	for i := 0; i+1 < len(units); i += 2 {
+		if units[i] == 0 && units[i+1] == 0 {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,148 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package readfile
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	utf16le, _, _ := transform.Bytes(unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder(), []byte("hello world\n"))
+	utf16be, _, _ := transform.Bytes(unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder(), []byte("hello world\n"))
+	latin1, _, _ := transform.Bytes(charmap.ISO8859_1.NewEncoder(), []byte("grüße aus köln\n"))
+
+	tests := map[string]struct {
+		sample   []byte
+		encoding string
+		ok       bool
+	}{
+		"empty":              {nil, "", false},
+		"ascii":              {[]byte("hello world\n"), "", false},
+		"utf-8 bom":          {append([]byte{0xef, 0xbb, 0xbf}, "hello"...), "utf-8", true},
+		"utf-8":              {[]byte("grüße aus köln\n"), "utf-8", true},
+		"utf-8 truncated":    {[]byte("grüße aus köln")[:3], "utf-8", true},
+		"utf-16le bom":       {[]byte{0xff, 0xfe, 'h', 0}, "utf-16le", true},
+		"utf-16be bom":       {[]byte{0xfe, 0xff, 0, 'h'}, "utf-16be", true},
+		"utf-16le":           {utf16le, "utf-16le", true},
+		"utf-16be":           {utf16be, "utf-16be", true},
+		"latin-1":            {latin1, "iso8859-1", true},
+		"null terminated":    {[]byte("first\x00second\x00"), "", false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			encoding, ok := DetectEncoding(test.sample)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.encoding, encoding)
+		})
+	}
+}
+
+func TestDetectEncodeReader(t *testing.T) {
+	lines := []string{"first line\n", "grüße 😀\n", "last\n"}
+	text := strings.Join(lines, "")
+
+	encode := func(enc transform.Transformer) []byte {
+		b, _, err := transform.Bytes(enc, []byte(text))
+		require.NoError(t, err)
+		return b
+	}
+
+	tests := map[string]struct {
+		input    []byte
+		config   DetectEncodingConfig
+		encoding string
+	}{
+		"utf-16le with bom": {
+			input:    encode(unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder()),
+			encoding: "utf-16le",
+		},
+		"utf-16be": {
+			input:    encode(unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder()),
+			encoding: "utf-16be",
+		},
+		"utf-8": {
+			input:    []byte(text),
+			encoding: "utf-8",
+		},
+		"latin-1": {
+			input:    []byte("first line\ngr\xfc\xdfe\nlast\n"),
+			encoding: "iso8859-1",
+		},
+		"ascii uses default": {
+			input:    []byte("first\nsecond\n"),
+			config:   DetectEncodingConfig{Default: "windows1252"},
+			encoding: "windows1252",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			in := io.NopCloser(bytes.NewReader(test.input))
+			r, err := NewDetectEncodeReader(in, Config{BufferSize: 7, Terminator: LineFeed}, test.config)
+			require.NoError(t, err)
+			require.Equal(t, test.encoding, r.Encoding())
+
+			var total int
+			var content []string
+			for {
+				msg, err := r.Next()
+				if err != nil {
+					break
+				}
+				total += msg.Bytes
+				content = append(content, string(msg.Content))
+
+				encoding, err := msg.Fields.GetValue("log.file.encoding")
+				require.NoError(t, err)
+				assert.Equal(t, test.encoding, encoding)
+			}
+
+			// offsets are accounted in bytes of the original input
+			assert.Equal(t, len(test.input), total)
+			if strings.HasPrefix(test.encoding, "utf-") {
+				assert.Equal(t, lines, content)
+			}
+		})
+	}
+}
+
+func TestUTF16DecoderSplitSurrogate(t *testing.T) {
+	input, _, err := transform.Bytes(unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder(), []byte("a😀b\x00c"))
+	require.NoError(t, err)
+
+	// the surrogate pair of the emoji is split between two writes
+	var out bytes.Buffer
+	w := transform.NewWriter(&out, utf16Decoder{})
+	_, err = w.Write(input[:4])
+	require.NoError(t, err)
+	_, err = w.Write(input[4:])
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "a😀b\x00c", out.String())
+}
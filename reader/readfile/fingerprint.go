@@ -0,0 +1,152 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package readfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/njcx/libbeat_v8/reader"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var fingerprintMethods = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"xxhash": func() hash.Hash { return xxhash.New() },
+}
+
+// FingerprintConfig configures which bytes of a file are used to
+// compute its fingerprint and which hash method is applied.
+type FingerprintConfig struct {
+	Offset int64  `config:"offset" validate:"min=0"`
+	Length int64  `config:"length" validate:"min=1"`
+	Method string `config:"method"`
+}
+
+// DefaultFingerprintConfig returns the default fingerprint configuration,
+// hashing the first 1024 bytes of the file using sha256.
+func DefaultFingerprintConfig() FingerprintConfig {
+	return FingerprintConfig{
+		Offset: 0,
+		Length: 1024,
+		Method: "sha256",
+	}
+}
+
+// Validate checks the configured hash method is supported.
+func (c *FingerprintConfig) Validate() error {
+	if _, ok := fingerprintMethods[c.Method]; !ok {
+		return fmt.Errorf("unsupported fingerprint method: %s", c.Method)
+	}
+	return nil
+}
+
+// FingerprintReader sets the fingerprint of the file on every message
+// read. The fingerprint is computed over the configured range of the
+// file. As long as the file is shorter than the range no fingerprint is
+// set; once enough bytes are available the value is computed once and
+// stays the same for the lifetime of the reader.
+type FingerprintReader struct {
+	reader      reader.Reader
+	file        io.ReaderAt
+	offset      int64
+	buf         []byte
+	newHash     func() hash.Hash
+	fingerprint string
+}
+
+// NewFingerprintReader creates a new reader computing the fingerprint of
+// file and adding it to the messages read from r.
+func NewFingerprintReader(r reader.Reader, file io.ReaderAt, config FingerprintConfig) (*FingerprintReader, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Offset < 0 || config.Length < 1 {
+		return nil, fmt.Errorf("invalid fingerprint range: offset %d, length %d", config.Offset, config.Length)
+	}
+
+	return &FingerprintReader{
+		reader:  r,
+		file:    file,
+		offset:  config.Offset,
+		buf:     make([]byte, config.Length),
+		newHash: fingerprintMethods[config.Method],
+	}, nil
+}
+
+// Next returns the next message, adding log.file.fingerprint if the
+// fingerprint is available.
+func (r *FingerprintReader) Next() (reader.Message, error) {
+	message, err := r.reader.Next()
+
+	// if the message is empty, there is no need to enrich it with the fingerprint
+	if message.IsEmpty() {
+		return message, err
+	}
+
+	fingerprint, ferr := r.Fingerprint()
+	if ferr != nil {
+		return message, fmt.Errorf("failed to compute fingerprint: %w", ferr)
+	}
+
+	if fingerprint != "" {
+		if message.Fields == nil {
+			message.Fields = mapstr.M{}
+		}
+		_, perr := message.Fields.Put("log.file.fingerprint", fingerprint)
+		if perr != nil {
+			return message, fmt.Errorf("failed to set fingerprint: %w", perr)
+		}
+	}
+
+	return message, err
+}
+
+// Fingerprint returns the hex encoded fingerprint of the file. An empty
+// string is returned without error if the file does not contain enough
+// bytes yet.
+func (r *FingerprintReader) Fingerprint() (string, error) {
+	if r.fingerprint != "" {
+		return r.fingerprint, nil
+	}
+
+	n, err := r.file.ReadAt(r.buf, r.offset)
+	if n < len(r.buf) {
+		if err == nil || errors.Is(err, io.EOF) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	h := r.newHash()
+	_, _ = h.Write(r.buf)
+	r.fingerprint = hex.EncodeToString(h.Sum(nil))
+	r.buf = nil
+
+	return r.fingerprint, nil
+}
+
+func (r *FingerprintReader) Close() error {
+	return r.reader.Close()
+}
@@ -0,0 +1,110 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package readfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/njcx/libbeat_v8/reader"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type growingFile struct {
+	data []byte
+}
+
+func (f *growingFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestFingerprintReader(t *testing.T) {
+	content := []byte("0123456789abcdefghij")
+	file := &growingFile{data: content[:5]}
+
+	messages := make([]reader.Message, 3)
+	for i := range messages {
+		messages[i] = reader.Message{Content: []byte("line"), Bytes: 4, Fields: mapstr.M{}}
+	}
+
+	config := FingerprintConfig{Offset: 2, Length: 10, Method: "sha256"}
+	r, err := NewFingerprintReader(msgReader(messages), file, config)
+	require.NoError(t, err)
+
+	// file is shorter than offset+length, no fingerprint yet
+	msg, err := r.Next()
+	require.NoError(t, err)
+	_, err = msg.Fields.GetValue("log.file.fingerprint")
+	require.ErrorIs(t, err, mapstr.ErrKeyNotFound)
+
+	sum := sha256.Sum256(content[2:12])
+	expected := hex.EncodeToString(sum[:])
+
+	file.data = content
+	msg, err = r.Next()
+	require.NoError(t, err)
+	fingerprint, err := msg.Fields.GetValue("log.file.fingerprint")
+	require.NoError(t, err)
+	require.Equal(t, expected, fingerprint)
+
+	// the fingerprint stays stable after the head of the file changed
+	file.data = []byte("changed content of the file")
+	msg, err = r.Next()
+	require.NoError(t, err)
+	fingerprint, err = msg.Fields.GetValue("log.file.fingerprint")
+	require.NoError(t, err)
+	require.Equal(t, expected, fingerprint)
+}
+
+func TestFingerprintReaderXxhash(t *testing.T) {
+	content := []byte("0123456789")
+	messages := []reader.Message{{Content: []byte("line"), Bytes: 4}}
+
+	config := FingerprintConfig{Length: 10, Method: "xxhash"}
+	r, err := NewFingerprintReader(msgReader(messages), &growingFile{data: content}, config)
+	require.NoError(t, err)
+
+	msg, err := r.Next()
+	require.NoError(t, err)
+
+	h := xxhash.New()
+	_, _ = h.Write(content)
+	fingerprint, err := msg.Fields.GetValue("log.file.fingerprint")
+	require.NoError(t, err)
+	require.Equal(t, hex.EncodeToString(h.Sum(nil)), fingerprint)
+}
+
+func TestFingerprintConfigValidate(t *testing.T) {
+	config := DefaultFingerprintConfig()
+	require.NoError(t, config.Validate())
+
+	config.Method = "md4"
+	require.Error(t, config.Validate())
+}
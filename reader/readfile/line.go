@@ -42,6 +42,7 @@ type LineReader struct {
 	maxBytes     int // max bytes per line limit to avoid OOM with malformatted files
 	nl           []byte
 	decodedNl    []byte
+	autoNl       map[LineTerminator][]byte // encoded candidates, nil once the terminator is known
 	collectOnEOF bool
 	inBuffer     *streambuf.Buffer
 	outBuffer    *streambuf.Buffer
@@ -67,12 +68,25 @@ func NewLineReader(input io.ReadCloser, config Config) (*LineReader, error) {
 		return nil, err
 	}
 
+	var autoNl map[LineTerminator][]byte
+	if config.Terminator == AutoLineTerminator {
+		autoNl = make(map[LineTerminator][]byte, len(autoLineTerminators))
+		for _, t := range autoLineTerminators {
+			encoded, _, err := transform.Bytes(encoder, lineTerminatorCharacters[t])
+			if err != nil {
+				return nil, err
+			}
+			autoNl[t] = encoded
+		}
+	}
+
 	return &LineReader{
 		reader:       input,
 		maxBytes:     config.MaxBytes,
 		decoder:      config.Codec.NewDecoder(),
 		nl:           nl,
 		decodedNl:    terminator,
+		autoNl:       autoNl,
 		collectOnEOF: config.CollectOnEOF,
 		inBuffer:     streambuf.New(nil),
 		outBuffer:    streambuf.New(nil),
@@ -201,6 +215,10 @@ func (r *LineReader) advance() error {
 			return streambuf.ErrNoMoreBytes
 		}
 
+		if r.autoNl != nil {
+			r.detectTerminator()
+		}
+
 		// Check if buffer has newLine character
 		idx = r.inBuffer.IndexFrom(r.inOffset, r.nl)
 
@@ -253,6 +271,43 @@ func (r *LineReader) advance() error {
 	return err
 }
 
+// detectTerminator picks the line terminator for the auto mode from the
+// first line ending found in the input buffer. Until a line ending is found
+// line feed is used.
+func (r *LineReader) detectTerminator() {
+	buf := r.inBuffer.Bytes()
+
+	detected, first := InvalidTerminator, -1
+	for _, t := range autoLineTerminators {
+		idx := bytes.Index(buf, r.autoNl[t])
+		if idx != -1 && (first == -1 || idx < first) {
+			detected, first = t, idx
+		}
+	}
+
+	switch detected {
+	case InvalidTerminator:
+		return
+	case CarriageReturn:
+		// CR+LF is handled by splitting on LF. A trailing CR might still be
+		// followed by a LF not read yet, so wait for more input.
+		rest := buf[first+len(r.autoNl[CarriageReturn]):]
+		if len(rest) < len(r.autoNl[LineFeed]) {
+			return
+		}
+		if bytes.HasPrefix(rest, r.autoNl[LineFeed]) {
+			detected = LineFeed
+		}
+	}
+
+	if detected != LineFeed {
+		r.nl = r.autoNl[detected]
+		r.decodedNl = lineTerminatorCharacters[detected]
+	}
+	r.logger.Debugf("Detected line terminator %q", r.decodedNl)
+	r.autoNl = nil
+}
+
 func (r *LineReader) skipUntilNewLine() (int, error) {
 	// The length of the line skipped
 	skipped := r.inBuffer.Len()
@@ -268,19 +323,29 @@ func (r *LineReader) skipUntilNewLine() (int, error) {
 		return 0, err
 	}
 
-	// Read until the new line is found
+	// Read until the new line is found. The last bytes of the previous
+	// read are kept, so multi-byte terminators split across reads are
+	// found as well.
+	var tail []byte
 	for idx := -1; idx == -1; {
 		n, err := r.reader.Read(r.tempBuffer)
 
 		// Check bytes read for newLine
 		if n > 0 {
-			idx = bytes.Index(r.tempBuffer[:n], r.nl)
+			chunk := append(tail, r.tempBuffer[:n]...)
+			idx = bytes.Index(chunk, r.nl)
 
 			if idx != -1 {
-				_, _ = r.inBuffer.Write(r.tempBuffer[idx+len(r.nl) : n])
-				skipped += idx + len(r.nl)
+				end := idx + len(r.nl)
+				_, _ = r.inBuffer.Write(chunk[end:])
+				skipped += end - len(tail)
 			} else {
 				skipped += n
+				if keep := len(r.nl) - 1; len(chunk) > keep {
+					tail = append(tail[:0], chunk[len(chunk)-keep:]...)
+				} else {
+					tail = chunk
+				}
 			}
 		}
 
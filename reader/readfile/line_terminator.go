@@ -26,7 +26,9 @@ type LineTerminator uint8
 const (
 	// InvalidTerminator is the invalid terminator
 	InvalidTerminator LineTerminator = iota
-	// AutoLineTerminator accepts both LF and CR+LF
+	// AutoLineTerminator detects the terminator from the first line ending
+	// found in the input. LF and CR+LF are both accepted once a line feed
+	// is detected, otherwise CR or NUL are used.
 	AutoLineTerminator
 	// LineFeed is the unicode char LF
 	LineFeed
@@ -60,6 +62,19 @@ var (
 		"line_separator":            LineSeparator,
 		"paragraph_separator":       ParagraphSeparator,
 		"null_terminator":           NullTerminator,
+
+		// short aliases
+		"lf":   LineFeed,
+		"crlf": CarriageReturnLineFeed,
+		"cr":   CarriageReturn,
+		"null": NullTerminator,
+	}
+
+	// autoLineTerminators lists the terminators the auto mode can detect.
+	autoLineTerminators = []LineTerminator{
+		LineFeed,
+		CarriageReturn,
+		NullTerminator,
 	}
 
 	lineTerminatorCharacters = map[LineTerminator][]byte{
@@ -526,3 +526,66 @@ func (r *eofWithNonZeroNumberOfBytesReader) Read(d []byte) (int, error) {
 func TestReadWithNonZeroNumberOfBytesAndEOF(t *testing.T) {
 	testReadLines(t, [][]byte{[]byte("Hello world!\n")}, true)
 }
+
+func TestLineReaderAutoTerminator(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		lines []string
+	}{
+		"line feed": {
+			input: "first\nsecond\nthird\n",
+			lines: []string{"first\n", "second\n", "third\n"},
+		},
+		"carriage return line feed": {
+			input: "first\r\nsecond\r\nthird\r\n",
+			lines: []string{"first\r\n", "second\r\n", "third\r\n"},
+		},
+		"carriage return": {
+			input: "first\rsecond\rthird\r",
+			lines: []string{"first\r", "second\r", "third\r"},
+		},
+		"null terminator": {
+			input: "first\x00second\x00third\x00",
+			lines: []string{"first\x00", "second\x00", "third\x00"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			codecFactory, _ := encoding.FindEncoding("plain")
+			codec, _ := codecFactory(bytes.NewBuffer(nil))
+
+			// a small buffer splits CR+LF across reads
+			in := ioutil.NopCloser(strings.NewReader(test.input))
+			reader, err := NewLineReader(in, Config{codec, 6, AutoLineTerminator, 1024, false})
+			require.NoError(t, err)
+
+			var total int
+			for _, expected := range test.lines {
+				b, n, err := reader.Next()
+				require.NoError(t, err)
+				require.Equal(t, expected, string(b))
+				require.Equal(t, len(expected), n)
+				total += n
+			}
+			require.Equal(t, len(test.input), total)
+		})
+	}
+}
+
+func TestMaxBytesLimitSplitTerminator(t *testing.T) {
+	codecFactory, _ := encoding.FindEncoding("plain")
+	codec, _ := codecFactory(bytes.NewBuffer(nil))
+
+	// The CR+LF of the skipped line is split across two reads by the
+	// buffer size.
+	input := "this line is too long\r\nok\r\n"
+	in := ioutil.NopCloser(strings.NewReader(input))
+	reader, err := NewLineReader(in, Config{codec, 11, CarriageReturnLineFeed, 10, false})
+	require.NoError(t, err)
+
+	b, n, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "ok\r\n", string(b))
+	assert.Equal(t, len(input), n)
+}
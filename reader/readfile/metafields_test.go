@@ -18,6 +18,7 @@
 package readfile
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"os"
@@ -27,6 +28,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/njcx/libbeat_v8/reader"
+	"github.com/njcx/libbeat_v8/reader/readfile/encoding"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
 
@@ -80,6 +82,58 @@ func TestMetaFields(t *testing.T) {
 	}
 }
 
+func TestMetaFieldsTruncated(t *testing.T) {
+	input := "short\nthis line is too long\nnext\n"
+	path := "test/path"
+
+	codecFactory, ok := encoding.FindEncoding("plain")
+	require.True(t, ok)
+	r := io.NopCloser(bytes.NewBufferString(input))
+	codec, err := codecFactory(r)
+	require.NoError(t, err)
+
+	er, err := NewEncodeReader(r, Config{
+		Codec:      codec,
+		BufferSize: 1024,
+		Terminator: LineFeed,
+	})
+	require.NoError(t, err)
+
+	in := NewFilemeta(NewLimitReader(er, 10), path, createTestFileInfo(), "", 0)
+
+	expected := []struct {
+		content   string
+		offset    int64
+		truncated bool
+	}{
+		{"short\n", 0, false},
+		{"this line ", 6, true},
+		{"next\n", 28, false},
+	}
+
+	for _, exp := range expected {
+		msg, err := in.Next()
+		require.NoError(t, err)
+		require.Equal(t, exp.content, string(msg.Content))
+
+		offset, err := msg.Fields.GetValue("log.offset")
+		require.NoError(t, err)
+		require.Equal(t, exp.offset, offset)
+
+		filePath, err := msg.Fields.GetValue("log.file.path")
+		require.NoError(t, err)
+		require.Equal(t, path, filePath)
+
+		flags, err := msg.Fields.GetValue("log.flags")
+		if exp.truncated {
+			require.NoError(t, err)
+			require.Equal(t, []string{"truncated"}, flags)
+		} else {
+			require.ErrorIs(t, err, mapstr.ErrKeyNotFound)
+		}
+	}
+}
+
 func msgReader(m []reader.Message) reader.Reader {
 	return &messageReader{
 		messages: m,
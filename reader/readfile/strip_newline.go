@@ -73,6 +73,10 @@ func (p *StripNewline) lineEndingChars(l []byte) int {
 
 func (p *StripNewline) autoLineEndingChars(l []byte) int {
 	if !p.isLine(l) {
+		if bytes.HasSuffix(l, lineTerminatorCharacters[CarriageReturn]) ||
+			bytes.HasSuffix(l, lineTerminatorCharacters[NullTerminator]) {
+			return 1
+		}
 		return 0
 	}
 
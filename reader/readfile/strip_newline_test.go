@@ -51,4 +51,7 @@ func TestAutoLineEndingChars(t *testing.T) {
 
 	assert.Equal(t, reader.lineEndingFunc(reader, []byte("this is a windows line\r\n")), 2)
 	assert.Equal(t, reader.lineEndingFunc(reader, []byte("this is a not windows line\n")), 1)
+	assert.Equal(t, reader.lineEndingFunc(reader, []byte("this is a carriage return line\r")), 1)
+	assert.Equal(t, reader.lineEndingFunc(reader, []byte("this is a null terminated line\x00")), 1)
+	assert.Equal(t, reader.lineEndingFunc(reader, []byte("this is not a line")), 0)
 }
@@ -20,7 +20,9 @@ package readjson
 import (
 	"bytes"
 	gojson "encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/njcx/libbeat_v8/beat"
@@ -31,6 +33,11 @@ import (
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
 
+var (
+	errNotObject    = errors.New("JSON value is not an object")
+	errTrailingData = errors.New("invalid character after top-level value")
+)
+
 // JSONReader parses JSON inputs
 type JSONReader struct {
 	reader reader.Reader
@@ -70,7 +77,10 @@ func (r *JSONReader) decode(text []byte) ([]byte, mapstr.M) {
 	var jsonFields map[string]interface{}
 
 	err := unmarshal(text, &jsonFields)
-	if err != nil || jsonFields == nil {
+	if err == nil && jsonFields == nil {
+		err = errNotObject
+	}
+	if err != nil {
 		if !r.cfg.IgnoreDecodingError {
 			r.logger.Errorf("Error decoding JSON: %v", err)
 		}
@@ -112,6 +122,11 @@ func unmarshal(text []byte, fields *map[string]interface{}) error {
 	if err != nil {
 		return err
 	}
+	// Like json.Unmarshal, fail on anything but whitespace after the
+	// object, instead of silently dropping it.
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errTrailingData
+	}
 	jsontransform.TransformNumbers(*fields)
 	return nil
 }
@@ -145,7 +145,28 @@ func TestDecodeJSON(t *testing.T) {
 			Text:         `null`,
 			Config:       Config{MessageKey: "value", AddErrorKey: true},
 			ExpectedText: `null`,
-			ExpectedMap:  mapstr.M{"error": mapstr.M{"message": "Error decoding JSON: <nil>", "type": "json"}},
+			ExpectedMap:  mapstr.M{"error": mapstr.M{"message": "Error decoding JSON: JSON value is not an object", "type": "json"}},
+		},
+		{
+			// data after the object is an error, the text is passed as is
+			Text:         `{"message": "test"} trailing`,
+			Config:       Config{MessageKey: "message", AddErrorKey: true},
+			ExpectedText: `{"message": "test"} trailing`,
+			ExpectedMap:  mapstr.M{"error": mapstr.M{"message": "Error decoding JSON: invalid character after top-level value", "type": "json"}},
+		},
+		{
+			// so is a second object on the same line
+			Text:         `{"message": "a"}{"message": "b"}`,
+			Config:       Config{MessageKey: "message", AddErrorKey: true},
+			ExpectedText: `{"message": "a"}{"message": "b"}`,
+			ExpectedMap:  mapstr.M{"error": mapstr.M{"message": "Error decoding JSON: invalid character after top-level value", "type": "json"}},
+		},
+		{
+			// trailing whitespace is fine
+			Text:         "{\"message\": \"test\"}  \r",
+			Config:       Config{MessageKey: "message"},
+			ExpectedText: "test",
+			ExpectedMap:  mapstr.M{"message": "test"},
 		},
 		{
 			// Add key error helps debugging this
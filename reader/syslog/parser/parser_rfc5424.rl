@@ -104,6 +104,11 @@ func parseStructuredData(data string) map[string]interface{} {
         write exec;
     }%%
 
+    // Reject partially parsed values, like a missing closing bracket.
+    if cs < parse_sd_first_final {
+        return nil
+    }
+
     if len(structuredData) == 0 {
         return nil
     }
@@ -41,6 +41,7 @@ import (
 
 var (
 	duration   time.Duration // -duration <duration>
+	validate   bool          // -validate
 	overwrites = conf.SettingFlag(nil, "E", "Configuration overwrite")
 )
 
@@ -64,6 +65,7 @@ func run() error {
 	}
 
 	flag.DurationVar(&duration, "duration", 0, "Test duration (default 0)")
+	flag.BoolVar(&validate, "validate", false, "Check the test output for lost, duplicate and out of order events")
 	flag.Parse()
 
 	files := flag.Args()
@@ -95,7 +97,7 @@ func run() error {
 
 	common.PrintConfigDebugf(cfg, "input config:")
 
-	return stress.RunTests(info, duration, cfg, nil)
+	return stress.RunTests(info, duration, cfg, nil, validate)
 }
 
 func startHTTP(bind string) {
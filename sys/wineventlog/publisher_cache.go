@@ -0,0 +1,179 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build windows
+
+package wineventlog
+
+import (
+	"container/list"
+	"sync"
+
+	"go.uber.org/multierr"
+)
+
+// DefaultPublisherCacheSize is the default number of publisher metadata
+// handles kept open by the package level publisher cache.
+const DefaultPublisherCacheSize = 64
+
+// publisherCache holds the publisher metadata handles used by
+// FormatEventString and Message when the caller does not provide one.
+var publisherCache = newPublisherMetadataCache(DefaultPublisherCacheSize)
+
+// SetPublisherCacheSize sets the maximum number of publisher metadata handles
+// that are kept open between calls to FormatEventString, RenderEvent, and
+// Message. Handles above the limit are closed, least recently used first. A
+// size of 0 disables caching so that handles are opened and closed on each
+// call.
+func SetPublisherCacheSize(size int) {
+	publisherCache.resize(size)
+}
+
+// CloseCache releases all cached publisher metadata handles. Handles that are
+// in use are closed once they are released. The cache remains usable after
+// calling CloseCache.
+func CloseCache() error {
+	return publisherCache.closeAll()
+}
+
+type publisherCacheKey struct {
+	name string
+	lang uint32
+}
+
+type publisherCacheEntry struct {
+	key     publisherCacheKey
+	handle  EvtHandle
+	refs    int  // Number of callers currently using handle.
+	evicted bool // Close handle when refs drops to zero.
+}
+
+// publisherMetadataCache is an LRU cache of publisher metadata handles keyed
+// by provider name and language. Entries are reference counted so that a
+// handle evicted while another goroutine formats a message is only closed
+// once it is released.
+type publisherMetadataCache struct {
+	mutex sync.Mutex
+	size  int
+	lru   *list.List // Front is most recently used.
+	items map[publisherCacheKey]*list.Element
+}
+
+func newPublisherMetadataCache(size int) *publisherMetadataCache {
+	return &publisherMetadataCache{
+		size:  size,
+		lru:   list.New(),
+		items: map[publisherCacheKey]*list.Element{},
+	}
+}
+
+// acquire returns a publisher metadata handle for the publisher. The returned
+// release function must be called when the caller is done with the handle.
+func (c *publisherMetadataCache) acquire(publisher string, lang uint32) (EvtHandle, func(), error) {
+	key := publisherCacheKey{name: publisher, lang: lang}
+
+	c.mutex.Lock()
+	if elem, found := c.items[key]; found {
+		c.lru.MoveToFront(elem)
+		entry := elem.Value.(*publisherCacheEntry)
+		entry.refs++
+		c.mutex.Unlock()
+		return entry.handle, func() { c.release(entry) }, nil
+	}
+	size := c.size
+	c.mutex.Unlock()
+
+	// Open the handle without holding the lock. This is a slow call.
+	h, err := OpenPublisherMetadata(0, publisher, lang)
+	if err != nil {
+		return NilHandle, nil, err
+	}
+
+	if size <= 0 {
+		return h, func() { _EvtClose(h) }, nil //nolint:errcheck // This is just a resource release.
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Another goroutine may have opened the same publisher in the meantime.
+	if elem, found := c.items[key]; found {
+		_EvtClose(h) //nolint:errcheck // This is just a resource release.
+		c.lru.MoveToFront(elem)
+		entry := elem.Value.(*publisherCacheEntry)
+		entry.refs++
+		return entry.handle, func() { c.release(entry) }, nil
+	}
+
+	entry := &publisherCacheEntry{key: key, handle: h, refs: 1}
+	c.items[key] = c.lru.PushFront(entry)
+	c.evict()
+	return entry.handle, func() { c.release(entry) }, nil
+}
+
+func (c *publisherMetadataCache) release(entry *publisherCacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry.refs--
+	if entry.evicted && entry.refs == 0 {
+		_EvtClose(entry.handle) //nolint:errcheck // This is just a resource release.
+	}
+}
+
+// evict removes the least recently used entries above the size limit. It
+// must be called with the mutex held.
+func (c *publisherMetadataCache) evict() {
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back()) //nolint:errcheck // This is just a resource release.
+	}
+}
+
+// remove removes elem from the cache and closes its handle if unused. It
+// must be called with the mutex held.
+func (c *publisherMetadataCache) remove(elem *list.Element) error {
+	entry := elem.Value.(*publisherCacheEntry)
+	c.lru.Remove(elem)
+	delete(c.items, entry.key)
+
+	entry.evicted = true
+	if entry.refs == 0 {
+		return _EvtClose(entry.handle)
+	}
+	return nil
+}
+
+func (c *publisherMetadataCache) resize(size int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.size = size
+	c.evict()
+}
+
+func (c *publisherMetadataCache) closeAll() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var errs []error
+	for c.lru.Len() > 0 {
+		if err := c.remove(c.lru.Back()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return multierr.Combine(errs...)
+}
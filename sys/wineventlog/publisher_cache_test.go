@@ -0,0 +1,67 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build windows
+
+package wineventlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisherMetadataCache(t *testing.T) {
+	c := newPublisherMetadataCache(1)
+	defer c.closeAll()
+
+	h1, release1, err := c.acquire("Microsoft-Windows-PowerShell", 0)
+	require.NoError(t, err)
+
+	// The same publisher is served from the cache.
+	h2, release2, err := c.acquire("Microsoft-Windows-PowerShell", 0)
+	require.NoError(t, err)
+	assert.Equal(t, h1, h2)
+	release2()
+
+	// Acquiring another publisher evicts the first one, but its handle stays
+	// open until released.
+	_, release3, err := c.acquire("Microsoft-Windows-Eventlog", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.lru.Len())
+	assert.NotContains(t, c.items, publisherCacheKey{name: "Microsoft-Windows-PowerShell"})
+
+	_, err = EvtGetPublisherMetadataProperty(h1, EvtPublisherMetadataPublisherGuid)
+	assert.NoError(t, err)
+	release1()
+	release3()
+
+	require.NoError(t, c.closeAll())
+	assert.Zero(t, c.lru.Len())
+	assert.Empty(t, c.items)
+}
+
+func TestPublisherMetadataCacheDisabled(t *testing.T) {
+	c := newPublisherMetadataCache(0)
+
+	_, release, err := c.acquire("Microsoft-Windows-PowerShell", 0)
+	require.NoError(t, err)
+	release()
+
+	assert.Zero(t, c.lru.Len())
+}
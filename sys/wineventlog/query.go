@@ -32,19 +32,32 @@ import (
 const (
 	query = `<QueryList>
   <Query Id="0">
-    <Select Path="{{.Path}}">*{{if .Select}}[System[{{join .Select " and "}}]]{{end}}</Select>{{if .Suppress}}
-    <Suppress Path="{{.Path}}">*[System[{{.Suppress}}]]</Suppress>{{end}}
+    <Select Path="{{xmlattr .Path}}">*{{if .Select}}[System[{{join .Select " and "}}]]{{end}}</Select>{{if .Suppress}}
+    <Suppress Path="{{xmlattr .Path}}">*[System[{{.Suppress}}]]</Suppress>{{end}}
   </Query>
 </QueryList>`
 )
 
 var (
-	templateFuncMap      = template.FuncMap{"join": strings.Join}
+	templateFuncMap      = template.FuncMap{"join": strings.Join, "xmlattr": xmlAttrEscape}
 	queryTemplate        = template.Must(template.New("query").Funcs(templateFuncMap).Parse(query))
 	incEventIDRegex      = regexp.MustCompile(`^\d+$`)
 	incEventIDRangeRegex = regexp.MustCompile(`^(\d+)\s*-\s*(\d+)$`)
 	excEventIDRegex      = regexp.MustCompile(`^-(\d+)$`)
 	excEventIDRangeRegex = regexp.MustCompile(`^-(\d+)\s*-\s*(\d+)$`)
+
+	// keywordMasks maps the standard keyword names to their bit masks. These
+	// values are from winmeta.xml inside the Windows SDK.
+	keywordMasks = map[string]uint64{
+		"response_time":    0x1000000000000,
+		"wdi_context":      0x2000000000000,
+		"wdi_diag":         0x4000000000000,
+		"sqm":              0x8000000000000,
+		"audit_failure":    0x10000000000000,
+		"audit_success":    0x20000000000000,
+		"correlation_hint": 0x40000000000000,
+		"classic":          0x80000000000000,
+	}
 )
 
 // Query that identifies the source of the events and one or more selectors or
@@ -62,6 +75,16 @@ type Query struct {
 	// exclude (e.g. -4410).
 	EventID string
 
+	// Event IDs to exclude. The value is a comma-separated list of single
+	// event IDs (e.g. 4662) and ranges of event IDs (e.g. 5156-5158). They
+	// are combined with the excludes of EventID into the Suppress clause of
+	// the query, which applies to all events matching the Select clause.
+	//
+	// Excluding events here rather than after reading them is cheaper: the
+	// Event Log service doesn't render or deliver suppressed events to the
+	// subscription, saving the calls needed to fetch and render each of them.
+	SuppressEventID string
+
 	// Level or levels to include. The value is a comma-separated list of levels
 	// to include. The accepted levels are verbose (5), information (4),
 	// warning (3), error (2), and critical (1).
@@ -69,6 +92,17 @@ type Query struct {
 
 	// Providers (sources) to include records from.
 	Provider []string
+
+	// Since and Until limit the query to records created in the time range.
+	// Zero values leave the range open.
+	Since time.Time
+	Until time.Time
+
+	// Keywords to include records from. Records with any of the keywords are
+	// included. The accepted values are the standard keyword names (e.g.
+	// audit_failure or "Audit Failure") and numeric keyword masks (e.g.
+	// 0x10000000000000).
+	Keywords []string
 }
 
 // Build builds a query from the given parameters. The query is returned as a
@@ -82,9 +116,11 @@ func (q Query) Build() (string, error) {
 	qp := &queryParams{Path: q.Log}
 	builders := []func(Query) error{
 		qp.ignoreOlderSelect,
+		qp.timeRangeSelect,
 		qp.eventIDSelect,
 		qp.levelSelect,
 		qp.providerSelect,
+		qp.keywordsSelect,
 	}
 	for _, build := range builders {
 		if err := build(q); err != nil {
@@ -116,56 +152,144 @@ func (qp *queryParams) ignoreOlderSelect(q Query) error {
 	return nil
 }
 
-func (qp *queryParams) eventIDSelect(q Query) error {
-	if q.EventID == "" {
+func (qp *queryParams) timeRangeSelect(q Query) error {
+	if q.Since.IsZero() && q.Until.IsZero() {
 		return nil
 	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && q.Until.Before(q.Since) {
+		return fmt.Errorf("time range end (%v) is before its start (%v)", q.Until, q.Since)
+	}
+
+	const layout = "2006-01-02T15:04:05.000Z"
+	var conditions []string
+	if !q.Since.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("@SystemTime&gt;='%s'", q.Since.UTC().Format(layout)))
+	}
+	if !q.Until.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("@SystemTime&lt;='%s'", q.Until.UTC().Format(layout)))
+	}
+	qp.Select = append(qp.Select,
+		fmt.Sprintf("TimeCreated[%s]", strings.Join(conditions, " and ")))
+	return nil
+}
+
+func (qp *queryParams) eventIDSelect(q Query) error {
+	includes, excludes, err := parseEventIDs(q.EventID, true)
+	if err != nil {
+		return err
+	}
+	suppressed, _, err := parseEventIDs(q.SuppressEventID, false)
+	if err != nil {
+		return err
+	}
+	excludes = append(excludes, suppressed...)
+
+	// Suppress clauses apply to all selected events. A query where they
+	// cover every selected event ID would never return anything.
+	if len(includes) > 0 && allSuppressed(includes, excludes) {
+		return fmt.Errorf("all event IDs selected by '%s' are suppressed", q.EventID)
+	}
+
+	if len(includes) == 1 {
+		qp.Select = append(qp.Select, includes[0].selector())
+	} else if len(includes) > 1 {
+		qp.Select = append(qp.Select, "("+joinSelectors(includes, " or ")+")")
+	}
+
+	if len(excludes) > 0 {
+		qp.Suppress = "(" + joinSelectors(excludes, " or ") + ")"
+	}
+
+	return nil
+}
+
+// eventIDRange is an inclusive range of event IDs. A single event ID is a
+// range with equal bounds.
+type eventIDRange struct {
+	from, to int
+}
+
+func (r eventIDRange) selector() string {
+	if r.from == r.to {
+		return fmt.Sprintf("EventID=%d", r.from)
+	}
+	return fmt.Sprintf("(EventID &gt;= %d and EventID &lt;= %d)", r.from, r.to)
+}
 
-	var includes []string
-	var excludes []string
-	components := strings.Split(q.EventID, ",")
-	for _, c := range components {
+// parseEventIDs parses a comma-separated list of event IDs and ranges. If
+// negatable is true, values prefixed with a minus sign are returned as
+// excludes.
+func parseEventIDs(value string, negatable bool) (includes, excludes []eventIDRange, err error) {
+	if value == "" {
+		return nil, nil, nil
+	}
+
+	for _, c := range strings.Split(value, ",") {
 		c = strings.TrimSpace(c)
 		switch {
 		case incEventIDRegex.MatchString(c):
-			includes = append(includes, fmt.Sprintf("EventID=%s", c))
-		case excEventIDRegex.MatchString(c):
+			id, _ := strconv.Atoi(c)
+			includes = append(includes, eventIDRange{id, id})
+		case negatable && excEventIDRegex.MatchString(c):
 			m := excEventIDRegex.FindStringSubmatch(c)
-			excludes = append(excludes, fmt.Sprintf("EventID=%s", m[1]))
+			id, _ := strconv.Atoi(m[1])
+			excludes = append(excludes, eventIDRange{id, id})
 		case incEventIDRangeRegex.MatchString(c):
 			m := incEventIDRangeRegex.FindStringSubmatch(c)
 			r1, _ := strconv.Atoi(m[1])
 			r2, _ := strconv.Atoi(m[2])
 			if r1 >= r2 {
-				return fmt.Errorf("event ID range '%s' is invalid", c)
+				return nil, nil, fmt.Errorf("event ID range '%s' is invalid", c)
 			}
-			includes = append(includes,
-				fmt.Sprintf("(EventID &gt;= %d and EventID &lt;= %d)", r1, r2))
-		case excEventIDRangeRegex.MatchString(c):
+			includes = append(includes, eventIDRange{r1, r2})
+		case negatable && excEventIDRangeRegex.MatchString(c):
 			m := excEventIDRangeRegex.FindStringSubmatch(c)
 			r1, _ := strconv.Atoi(m[1])
 			r2, _ := strconv.Atoi(m[2])
 			if r1 >= r2 {
-				return fmt.Errorf("event ID range '%s' is invalid", c)
+				return nil, nil, fmt.Errorf("event ID range '%s' is invalid", c)
 			}
-			excludes = append(excludes,
-				fmt.Sprintf("(EventID &gt;= %d and EventID &lt;= %d)", r1, r2))
+			excludes = append(excludes, eventIDRange{r1, r2})
 		default:
-			return fmt.Errorf("invalid event ID query component ('%s')", c)
+			return nil, nil, fmt.Errorf("invalid event ID query component ('%s')", c)
 		}
 	}
+	return includes, excludes, nil
+}
 
-	if len(includes) == 1 {
-		qp.Select = append(qp.Select, includes...)
-	} else if len(includes) > 1 {
-		qp.Select = append(qp.Select, "("+strings.Join(includes, " or ")+")")
+// allSuppressed returns true if every event ID of includes is in one of the
+// excludes.
+func allSuppressed(includes, excludes []eventIDRange) bool {
+	for _, r := range includes {
+		// Skip to the end of the exclude covering the next ID until past
+		// the end of the range.
+		for id := r.from; id <= r.to; {
+			next, ok := coveredUntil(excludes, id)
+			if !ok {
+				return false
+			}
+			id = next + 1
+		}
 	}
+	return true
+}
 
-	if len(excludes) > 0 {
-		qp.Suppress = "(" + strings.Join(excludes, " or ") + ")"
+// coveredUntil returns the end of a range containing id.
+func coveredUntil(ranges []eventIDRange, id int) (int, bool) {
+	for _, r := range ranges {
+		if id >= r.from && id <= r.to {
+			return r.to, true
+		}
 	}
+	return 0, false
+}
 
-	return nil
+func joinSelectors(ranges []eventIDRange, sep string) string {
+	selectors := make([]string, len(ranges))
+	for i, r := range ranges {
+		selectors[i] = r.selector()
+	}
+	return strings.Join(selectors, sep)
 }
 
 // levelSelect returns a xpath selector for the event Level. The returned
@@ -221,7 +345,16 @@ func (qp *queryParams) providerSelect(q Query) error {
 
 	selects := make([]string, 0, len(q.Provider))
 	for _, p := range q.Provider {
-		selects = append(selects, fmt.Sprintf("@Name='%s'", p))
+		if strings.Contains(p, "'") && strings.Contains(p, `"`) {
+			return fmt.Errorf("provider name (%s) can't contain both single and double quotes", p)
+		}
+		// XPath has no escape sequences, so names containing a single quote
+		// are enclosed in double quotes.
+		name := "'" + p + "'"
+		if strings.Contains(p, "'") {
+			name = `"` + p + `"`
+		}
+		selects = append(selects, "@Name="+xmlEscape(name))
 	}
 
 	qp.Select = append(qp.Select,
@@ -229,6 +362,48 @@ func (qp *queryParams) providerSelect(q Query) error {
 	return nil
 }
 
+// keywordsSelect returns a xpath selector for records with any of the
+// keywords.
+func (qp *queryParams) keywordsSelect(q Query) error {
+	if len(q.Keywords) == 0 {
+		return nil
+	}
+
+	var mask uint64
+	for _, k := range q.Keywords {
+		name := strings.ToLower(strings.TrimSpace(k))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if m, found := keywordMasks[name]; found {
+			mask |= m
+			continue
+		}
+		m, err := strconv.ParseUint(name, 0, 64)
+		if err != nil || m == 0 {
+			return fmt.Errorf("invalid keyword ('%s') for query", k)
+		}
+		mask |= m
+	}
+
+	qp.Select = append(qp.Select, fmt.Sprintf("band(Keywords,%d)", mask))
+	return nil
+}
+
+var (
+	xmlEscaper     = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	xmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+// xmlEscape returns s with the characters that are special in XML text
+// escaped. Quotes are kept, as they delimit XPath string literals.
+func xmlEscape(s string) string {
+	return xmlEscaper.Replace(s)
+}
+
+// xmlAttrEscape returns s escaped for use in a double-quoted XML attribute.
+func xmlAttrEscape(s string) string {
+	return xmlAttrEscaper.Replace(s)
+}
+
 // executeTemplate populates a template with the given data and returns the
 // value as a string.
 func executeTemplate(t *template.Template, data interface{}) (string, error) {
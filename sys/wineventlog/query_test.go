@@ -20,11 +20,13 @@
 package wineventlog
 
 import (
+	"encoding/xml"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func ExampleQuery() {
@@ -67,6 +69,50 @@ func TestEventIDQuery(t *testing.T) {
 	}
 }
 
+func TestSuppressEventIDQuery(t *testing.T) {
+	const expected = `<QueryList>
+  <Query Id="0">
+    <Select Path="Security">*[System[(EventID &gt;= 4600 and EventID &lt;= 4700)]]</Select>
+    <Suppress Path="Security">*[System[(EventID=4610 or EventID=4662 or (EventID &gt;= 4670 and EventID &lt;= 4672))]]</Suppress>
+  </Query>
+</QueryList>`
+
+	q, err := Query{Log: "Security", EventID: "4600-4700, -4610", SuppressEventID: "4662, 4670-4672"}.Build()
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, q)
+		t.Log(q)
+	}
+
+	// Suppressing without selecting event IDs applies to all events.
+	q, err = Query{Log: "Security", SuppressEventID: "5156"}.Build()
+	if assert.NoError(t, err) {
+		assert.Contains(t, q, `<Select Path="Security">*</Select>`)
+		assert.Contains(t, q, `<Suppress Path="Security">*[System[(EventID=5156)]]</Suppress>`)
+	}
+}
+
+func TestSuppressEventIDQueryErrors(t *testing.T) {
+	tests := map[string]Query{
+		"negative suppress":     {Log: "Security", SuppressEventID: "-4662"},
+		"invalid suppress":      {Log: "Security", SuppressEventID: "all"},
+		"invalid range":         {Log: "Security", SuppressEventID: "10-1"},
+		"all suppressed":        {Log: "Security", EventID: "4624, 4625", SuppressEventID: "4624-4625"},
+		"range suppressed":      {Log: "Security", EventID: "100-200", SuppressEventID: "100-150, 151-300"},
+		"suppressed by exclude": {Log: "Security", EventID: "4624, -4624"},
+	}
+
+	for name, q := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := q.Build()
+			assert.Error(t, err)
+		})
+	}
+
+	// A partially suppressed range is valid.
+	_, err := Query{Log: "Security", EventID: "100-200", SuppressEventID: "100-150, 152-300"}.Build()
+	assert.NoError(t, err)
+}
+
 func TestLevelQuery(t *testing.T) {
 	const expected = `<QueryList>
   <Query Id="0">
@@ -95,6 +141,120 @@ func TestProviderQuery(t *testing.T) {
 	}
 }
 
+func TestProviderQueryEscaping(t *testing.T) {
+	const expected = `<QueryList>
+  <Query Id="0">
+    <Select Path="Ops &amp; Support">*[System[Provider[@Name='A &amp; B' or @Name="O'Brien"]]]</Select>
+  </Query>
+</QueryList>`
+
+	q, err := Query{Log: "Ops & Support", Provider: []string{"A & B", "O'Brien"}}.Build()
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, q)
+		t.Log(q)
+	}
+
+	_, err = Query{Log: "Application", Provider: []string{`'"`}}.Build()
+	assert.Error(t, err)
+}
+
+func TestTimeRangeQuery(t *testing.T) {
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	until := since.Add(time.Hour).In(time.FixedZone("UTC+2", 2*60*60))
+
+	tests := map[string]struct {
+		since, until time.Time
+		selector     string
+	}{
+		"since": {
+			since:    since,
+			selector: "TimeCreated[@SystemTime&gt;='2024-01-02T03:04:05.000Z']",
+		},
+		"until": {
+			until:    until,
+			selector: "TimeCreated[@SystemTime&lt;='2024-01-02T04:04:05.000Z']",
+		},
+		"range": {
+			since:    since,
+			until:    until,
+			selector: "TimeCreated[@SystemTime&gt;='2024-01-02T03:04:05.000Z' and @SystemTime&lt;='2024-01-02T04:04:05.000Z']",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expected := `<QueryList>
+  <Query Id="0">
+    <Select Path="Security">*[System[` + test.selector + `]]</Select>
+  </Query>
+</QueryList>`
+
+			q, err := Query{Log: "Security", Since: test.since, Until: test.until}.Build()
+			if assert.NoError(t, err) {
+				assert.Equal(t, expected, q)
+			}
+		})
+	}
+
+	_, err := Query{Log: "Security", Since: since, Until: since.Add(-time.Second)}.Build()
+	assert.Error(t, err)
+}
+
+func TestKeywordsQuery(t *testing.T) {
+	const expected = `<QueryList>
+  <Query Id="0">
+    <Select Path="Security">*[System[band(Keywords,49539595901075456)]]</Select>
+  </Query>
+</QueryList>`
+
+	q, err := Query{Log: "Security", Keywords: []string{"Audit Failure", "audit_success", "0x80000000000000"}}.Build()
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, q)
+		t.Log(q)
+	}
+
+	for _, keyword := range []string{"unknown", "0", ""} {
+		_, err = Query{Log: "Security", Keywords: []string{keyword}}.Build()
+		assert.Error(t, err, "keyword %q", keyword)
+	}
+}
+
+func TestQueryIsValidXML(t *testing.T) {
+	var queryList struct {
+		Query struct {
+			Select struct {
+				Path  string `xml:",attr"`
+				XPath string `xml:",chardata"`
+			}
+			Suppress struct {
+				Path  string `xml:",attr"`
+				XPath string `xml:",chardata"`
+			}
+		}
+	}
+
+	q, err := Query{
+		Log:         "Ops & <Support>",
+		IgnoreOlder: time.Hour,
+		Since:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		EventID:     "1-100, -75",
+		Level:       "error",
+		Provider:    []string{"A & B", "O'Brien"},
+		Keywords:    []string{"audit_success"},
+	}.Build()
+	require.NoError(t, err)
+	require.NoError(t, xml.Unmarshal([]byte(q), &queryList))
+
+	assert.Equal(t, "Ops & <Support>", queryList.Query.Select.Path)
+	assert.Equal(t, "*[System[TimeCreated[timediff(@SystemTime) <= 3600000] and "+
+		"TimeCreated[@SystemTime>='2024-01-02T03:04:05.000Z'] and "+
+		"(EventID >= 1 and EventID <= 100) and (Level = 2) and "+
+		`Provider[@Name='A & B' or @Name="O'Brien"] and band(Keywords,9007199254740992)]]`,
+		queryList.Query.Select.XPath)
+	assert.Equal(t, "Ops & <Support>", queryList.Query.Suppress.Path)
+	assert.Equal(t, "*[System[(EventID=75)]]", queryList.Query.Suppress.XPath)
+}
+
 func TestCombinedQuery(t *testing.T) {
 	const expected = `<QueryList>
   <Query Id="0">
@@ -131,6 +131,49 @@ func (r *Renderer) Render(handle EvtHandle) (*winevent.Event, error) {
 	return event, nil
 }
 
+// RenderEventValues renders only the event/user data of the event handle and
+// returns the values keyed by their EventData parameter names. This avoids
+// rendering the event to XML and parsing it back when only the event data is
+// needed. Parameters without a name (e.g. legacy providers that use unnamed
+// Data elements) are keyed as param1, param2, etc.
+func (r *Renderer) RenderEventValues(handle EvtHandle) (map[string]interface{}, error) {
+	event := &winevent.Event{}
+	if err := r.renderSystem(handle, event); err != nil {
+		return nil, fmt.Errorf("failed to render system properties: %w", err)
+	}
+
+	values, fingerprint, err := r.renderUser(handle, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render event data: %w", err)
+	}
+	if len(values) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	// Parameter names can still be resolved from the XML template if the
+	// publisher metadata is unavailable, so a metadata error is not fatal.
+	md, _ := r.getPublisherMetadata(event.Provider.Name)
+	eventMeta := md.getEventMetadata(uint16(event.EventIdentifier.ID), fingerprint, handle)
+
+	eventData := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		name := "param" + strconv.Itoa(i+1)
+		if eventMeta != nil && i < len(eventMeta.EventData) && eventMeta.EventData[i].Name != "" {
+			name = eventMeta.EventData[i].Name
+		}
+
+		switch t := v.(type) {
+		case *windows.SID:
+			eventData[name] = t.String()
+		case windows.GUID:
+			eventData[name] = t.String()
+		default:
+			eventData[name] = v
+		}
+	}
+	return eventData, nil
+}
+
 // getPublisherMetadata return a PublisherMetadataStore for the provider. It
 // never returns nil, but may return an error if it couldn't open a publisher.
 func (r *Renderer) getPublisherMetadata(publisher string) (*PublisherMetadataStore, error) {
@@ -150,6 +150,62 @@ func TestRenderer(t *testing.T) {
 	})
 }
 
+func TestRenderEventValues(t *testing.T) {
+	logp.TestingSetup()
+
+	t.Run(filepath.Base(security4752File), func(t *testing.T) {
+		log := openLog(t, security4752File)
+		defer log.Close()
+
+		r, err := NewRenderer(NilHandle, logp.L())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		h, done := nextHandle(t, log)
+		if done {
+			t.Fatal("no events found")
+		}
+		defer h.Close()
+
+		values, err := r.RenderEventValues(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Len(t, values, 10)
+		assert.Contains(t, values, "SubjectUserName")
+	})
+
+	t.Run(filepath.Base(winErrorReportingFile), func(t *testing.T) {
+		log := openLog(t, winErrorReportingFile)
+		defer log.Close()
+
+		r, err := NewRenderer(NilHandle, logp.L())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		h, done := nextHandle(t, log)
+		if done {
+			t.Fatal("no events found")
+		}
+		defer h.Close()
+
+		values, err := r.RenderEventValues(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Windows Error Reporting uses unnamed Data elements.
+		assert.Len(t, values, 23)
+		assert.Contains(t, values, "param1")
+		assert.Contains(t, values, "param23")
+	})
+}
+
 func TestTemplateFunc(t *testing.T) {
 	tmpl := template.Must(template.New("").
 		Funcs(eventMessageTemplateFuncs).
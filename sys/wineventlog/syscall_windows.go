@@ -51,6 +51,40 @@ const (
 	ERROR_EVT_CHANNEL_NOT_FOUND syscall.Errno = 15007
 )
 
+// EvtLoginClass defines the types of connection methods used to open a
+// session to a remote computer.
+type EvtLoginClass uint32
+
+// EVT_LOGIN_CLASS enumeration
+// https://learn.microsoft.com/en-us/windows/win32/api/winevt/ne-winevt-evt_login_class
+const (
+	EvtRpcLogin EvtLoginClass = 1
+)
+
+// EvtLoginFlag defines the types of authentication used when connecting to a
+// remote computer.
+type EvtLoginFlag uint32
+
+// EVT_RPC_LOGIN_FLAGS enumeration
+// https://learn.microsoft.com/en-us/windows/win32/api/winevt/ne-winevt-evt_rpc_login_flags
+const (
+	EvtRpcLoginAuthDefault   EvtLoginFlag = 0
+	EvtRpcLoginAuthNegotiate EvtLoginFlag = 1
+	EvtRpcLoginAuthKerberos  EvtLoginFlag = 2
+	EvtRpcLoginAuthNTLM      EvtLoginFlag = 3
+)
+
+// EvtRPCLogin contains the information used to connect to a remote computer.
+// It matches the EVT_RPC_LOGIN structure.
+// https://learn.microsoft.com/en-us/windows/win32/api/winevt/ns-winevt-evt_rpc_login
+type EvtRPCLogin struct {
+	Server   *uint16
+	User     *uint16
+	Domain   *uint16
+	Password *uint16
+	Flags    EvtLoginFlag
+}
+
 // EvtSubscribeFlag defines the possible values that specify when to start subscribing to events.
 type EvtSubscribeFlag uint32
 
@@ -497,7 +531,10 @@ func (v EvtVariant) Data(buf []byte) (interface{}, error) {
 		ft := (*windows.Filetime)(unsafe.Pointer(&v.Value))
 		return time.Unix(0, ft.Nanoseconds()).UTC(), nil
 	case EvtVarTypeSysTime:
-		st := (*windows.Systemtime)(unsafe.Pointer(&v.Value))
+		// SysTimeVal is a pointer to a SYSTEMTIME.
+		addr := unsafe.Pointer(&buf[0])
+		offset := v.ValueAsUintPtr() - uintptr(addr)
+		st := (*windows.Systemtime)(unsafe.Pointer(&buf[offset]))
 		var ft windows.Filetime
 		if err := sys.SystemTimeToFileTime(st, &ft); err != nil {
 			return nil, err
@@ -677,6 +714,7 @@ func EvtClearLog(session EvtHandle, channelPath string, targetFilePath string) e
 //sys   _EvtNextChannelPath(channelEnum EvtHandle, channelPathBufferSize uint32, channelPathBuffer *uint16, channelPathBufferUsed *uint32) (err error) = wevtapi.EvtNextChannelPath
 //sys   _EvtFormatMessage(publisherMetadata EvtHandle, event EvtHandle, messageID uint32, valueCount uint32, values *EvtVariant, flags EvtFormatMessageFlag, bufferSize uint32, buffer *byte, bufferUsed *uint32) (err error) = wevtapi.EvtFormatMessage
 //sys   _EvtOpenPublisherMetadata(session EvtHandle, publisherIdentity *uint16, logFilePath *uint16, locale uint32, flags uint32) (handle EvtHandle, err error) = wevtapi.EvtOpenPublisherMetadata
+//sys   _EvtOpenSession(loginClass EvtLoginClass, login *EvtRPCLogin, timeout uint32, flags uint32) (handle EvtHandle, err error) = wevtapi.EvtOpenSession
 //sys   _EvtGetPublisherMetadataProperty(publisherMetadata EvtHandle, propertyID EvtPublisherMetadataPropertyID, flags uint32, bufferSize uint32, variant *EvtVariant, bufferUsed *uint32) (err error) = wevtapi.EvtGetPublisherMetadataProperty
 //sys   _EvtGetEventMetadataProperty(eventMetadata EvtHandle, propertyID EvtEventMetadataPropertyID, flags uint32, bufferSize uint32,  variant *EvtVariant, bufferUsed *uint32) (err error) = wevtapi.EvtGetEventMetadataProperty
 //sys   _EvtOpenEventMetadataEnum(publisherMetadata EvtHandle, flags uint32) (handle EvtHandle, err error) = wevtapi.EvtOpenEventMetadataEnum
@@ -105,6 +105,66 @@ loop:
 	return channels, nil
 }
 
+// OpenSession opens a session to the remote computer identified by server. The
+// returned handle can be passed as the session to functions like Subscribe,
+// EvtQuery, and NewRenderer in order to read events from the remote computer.
+// If user is empty, the credentials of the current user are used.
+// CloseSession must be called on the returned EvtHandle when finished with the
+// session.
+func OpenSession(server, user, domain, password string, flags EvtLoginFlag) (EvtHandle, error) {
+	if server == "" {
+		return NilHandle, errors.New("server is required to open a remote session")
+	}
+
+	var login EvtRPCLogin
+	login.Flags = flags
+
+	// Keep the UTF-16 buffers around so that the credentials can be zeroed
+	// after the session has been opened.
+	var buffers [][]uint16
+	defer func() {
+		for _, b := range buffers {
+			for i := range b {
+				b[i] = 0
+			}
+		}
+	}()
+
+	for _, f := range []struct {
+		value string
+		dst   **uint16
+	}{
+		{server, &login.Server},
+		{user, &login.User},
+		{domain, &login.Domain},
+		{password, &login.Password},
+	} {
+		if f.value == "" {
+			continue
+		}
+		b, err := windows.UTF16FromString(f.value)
+		if err != nil {
+			return NilHandle, err
+		}
+		buffers = append(buffers, b)
+		*f.dst = &b[0]
+	}
+
+	h, err := _EvtOpenSession(EvtRpcLogin, &login, 0, 0)
+	if err != nil {
+		return NilHandle, fmt.Errorf("failed in EvtOpenSession for %v: %w", server, err)
+	}
+	return h, nil
+}
+
+// CloseSession closes a session opened with OpenSession.
+func CloseSession(session EvtHandle) error {
+	if session == NilHandle {
+		return nil
+	}
+	return _EvtClose(session)
+}
+
 // EvtOpenLog gets a handle to a channel or log file that you can then use to
 // get information about the channel or log file.
 func EvtOpenLog(session EvtHandle, path string, flags EvtOpenLogFlag) (EvtHandle, error) {
@@ -265,6 +325,15 @@ func Message(h EvtHandle, renderBuf []byte, pubHandleProvider func(string) sys.M
 			pub = EvtHandle(messageFiles.Handles[0].Handle)
 		}
 	}
+	if pub == NilHandle {
+		// Fallback to a cached publisher handle. If the publisher cannot be
+		// opened the message is formatted without one (e.g. forwarded
+		// events that contain RenderingInfo).
+		if h, release, err := publisherCache.acquire(providerName, 0); err == nil {
+			defer release()
+			pub = h
+		}
+	}
 	return getMessageStringFromHandle(&PublisherMetadata{Handle: pub}, h, nil)
 }
 
@@ -378,7 +447,8 @@ func Close(h EvtHandle) error {
 // eventHandle is the handle to the event.
 // publisher is the name of the event's publisher.
 // publisherHandle is a handle to the publisher's metadata as provided by
-// EvtOpenPublisherMetadata.
+// EvtOpenPublisherMetadata. If NilHandle, a handle is taken from the publisher
+// cache (see SetPublisherCacheSize and CloseCache).
 // lang is the language ID.
 // renderBuf is a scratch buffer to render the message, if not provided or of
 // insufficient size then a buffer from a system pool will be used
@@ -391,15 +461,16 @@ func FormatEventString(
 	renderBuf []byte,
 	out io.Writer,
 ) error {
-	// Open a publisher handle if one was not provided.
+	// Use a cached publisher handle if one was not provided.
 	ph := publisherHandle
 	if ph == NilHandle {
+		var release func()
 		var err error
-		ph, err = OpenPublisherMetadata(0, publisher, lang)
+		ph, release, err = publisherCache.acquire(publisher, lang)
 		if err != nil {
 			return err
 		}
-		defer _EvtClose(ph) //nolint:errcheck // This is just a resource release.
+		defer release()
 	}
 
 	var bufferPtr *byte
@@ -19,6 +19,7 @@ package wineventlog
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/xml"
 	"flag"
 	"fmt"
@@ -28,9 +29,12 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+	"unsafe"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows"
 
 	"github.com/njcx/libbeat_v8/sys/winevent"
 )
@@ -204,3 +208,102 @@ func TestPublishers(t *testing.T) {
 		t.Log(p)
 	}
 }
+
+func TestOpenSession(t *testing.T) {
+	t.Run("missing server", func(t *testing.T) {
+		_, err := OpenSession("", "", "", "", EvtRpcLoginAuthDefault)
+		assert.Error(t, err)
+	})
+
+	t.Run("localhost", func(t *testing.T) {
+		session, err := OpenSession("localhost", "", "", "", EvtRpcLoginAuthDefault)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer CloseSession(session)
+
+		// The session must be usable to list the channels of the computer.
+		h, err := _EvtOpenChannelEnum(session, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Close()
+	})
+}
+
+func TestEvtVariantData(t *testing.T) {
+	// Well-known SID S-1-5-18 (Local System) in binary form.
+	localSystemSID := []byte{0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0x12, 0x00, 0x00, 0x00}
+
+	// SYSTEMTIME for 2021-03-04 05:06:07.890 (Thursday).
+	sysTime := make([]byte, 16)
+	for i, v := range []uint16{2021, 3, 4, 4, 5, 6, 7, 890} {
+		binary.LittleEndian.PutUint16(sysTime[i*2:], v)
+	}
+
+	// FILETIME for 2021-03-04T05:06:07.89Z.
+	expectedTime := time.Date(2021, 3, 4, 5, 6, 7, 890*int(time.Millisecond), time.UTC)
+	fileTime := windows.NsecToFiletime(expectedTime.UnixNano())
+	fileTimeValue := uint64(fileTime.HighDateTime)<<32 | uint64(fileTime.LowDateTime)
+
+	tests := []struct {
+		name     string
+		typ      EvtVariantType
+		value    uint64
+		count    uint32
+		payload  []byte // Stored after the variant. value is set to its address.
+		expected interface{}
+	}{
+		{name: "null", typ: EvtVarTypeNull, expected: nil},
+		{name: "int32", typ: EvtVarTypeInt32, value: uint64(0xFFFFFFFF), expected: int32(-1)},
+		{name: "uint32", typ: EvtVarTypeUInt32, value: 4000000000, expected: uint32(4000000000)},
+		{name: "int64", typ: EvtVarTypeInt64, value: 0xFFFFFFFFFFFFFFFE, expected: int64(-2)},
+		{name: "uint64", typ: EvtVarTypeUInt64, value: 1 << 40, expected: uint64(1 << 40)},
+		{name: "boolean true", typ: EvtVarTypeBoolean, value: 1, expected: true},
+		{name: "boolean false", typ: EvtVarTypeBoolean, value: 0, expected: false},
+		{name: "filetime", typ: EvtVarTypeFileTime, value: fileTimeValue, expected: expectedTime},
+		{name: "systime", typ: EvtVarTypeSysTime, payload: sysTime, expected: expectedTime},
+		{name: "binary", typ: EvtVarTypeBinary, count: 3, payload: []byte{0xDE, 0xAD, 0xBF}, expected: "DEADBF"},
+		{name: "string", typ: EvtVarTypeString, payload: []byte{'h', 0, 'i', 0, 0, 0}, expected: "hi"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := evtVariantBuffer(tc.typ, tc.value, tc.count, tc.payload)
+
+			v, err := (*EvtVariant)(unsafe.Pointer(&buf[0])).Data(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.expected, v)
+		})
+	}
+
+	t.Run("sid", func(t *testing.T) {
+		buf := evtVariantBuffer(EvtVarTypeSid, 0, 0, localSystemSID)
+
+		v, err := (*EvtVariant)(unsafe.Pointer(&buf[0])).Data(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sid, ok := v.(*windows.SID)
+		if !ok {
+			t.Fatalf("expected *windows.SID, got %T", v)
+		}
+		assert.Equal(t, "S-1-5-18", sid.String())
+	})
+}
+
+// evtVariantBuffer returns a buffer containing an EVT_VARIANT followed by the
+// payload. If a payload is given then the variant's value is set to its address.
+func evtVariantBuffer(typ EvtVariantType, value uint64, count uint32, payload []byte) []byte {
+	buf := make([]byte, int(sizeofEvtVariant)+len(payload))
+	copy(buf[sizeofEvtVariant:], payload)
+	if len(payload) > 0 {
+		value = uint64(uintptr(unsafe.Pointer(&buf[sizeofEvtVariant])))
+	}
+	binary.LittleEndian.PutUint64(buf[0:], value)
+	binary.LittleEndian.PutUint32(buf[8:], count)
+	binary.LittleEndian.PutUint32(buf[12:], uint32(typ))
+	return buf
+}
@@ -87,6 +87,7 @@ var (
 	procEvtOpenLog                      = modwevtapi.NewProc("EvtOpenLog")
 	procEvtOpenPublisherEnum            = modwevtapi.NewProc("EvtOpenPublisherEnum")
 	procEvtOpenPublisherMetadata        = modwevtapi.NewProc("EvtOpenPublisherMetadata")
+	procEvtOpenSession                  = modwevtapi.NewProc("EvtOpenSession")
 	procEvtQuery                        = modwevtapi.NewProc("EvtQuery")
 	procEvtRender                       = modwevtapi.NewProc("EvtRender")
 	procEvtSeek                         = modwevtapi.NewProc("EvtSeek")
@@ -246,6 +247,15 @@ func _EvtOpenPublisherMetadata(session EvtHandle, publisherIdentity *uint16, log
 	return
 }
 
+func _EvtOpenSession(loginClass EvtLoginClass, login *EvtRPCLogin, timeout uint32, flags uint32) (handle EvtHandle, err error) {
+	r0, _, e1 := syscall.Syscall6(procEvtOpenSession.Addr(), 4, uintptr(loginClass), uintptr(unsafe.Pointer(login)), uintptr(timeout), uintptr(flags), 0, 0)
+	handle = EvtHandle(r0)
+	if handle == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func _EvtQuery(session EvtHandle, path *uint16, query *uint16, flags uint32) (handle EvtHandle, err error) {
 	r0, _, e1 := syscall.Syscall6(procEvtQuery.Addr(), 4, uintptr(session), uintptr(unsafe.Pointer(path)), uintptr(unsafe.Pointer(query)), uintptr(flags), 0, 0)
 	handle = EvtHandle(r0)